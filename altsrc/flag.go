@@ -1,10 +1,13 @@
 package altsrc
 
 import (
+	"flag"
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -24,6 +27,14 @@ func ApplyInputSourceValues(context *cli.Context, inputSourceContext InputSource
 	for _, f := range flags {
 		inputSourceExtendedFlag, isType := f.(FlagInputSourceExtension)
 		if isType {
+			if value, ok := inputSourceValue(inputSourceContext, f); ok {
+				context.AddSourceValue(f.Names()[0], cli.SourceValue{
+					Kind:   cli.SourceConfig,
+					Detail: inputSourceContext.Source(),
+					Value:  value,
+				})
+			}
+
 			err := inputSourceExtendedFlag.ApplyInputSourceValue(context, inputSourceContext)
 			if err != nil {
 				return err
@@ -62,6 +73,99 @@ func InitInputSourceWithContext(flags []cli.Flag, createInputSource func(context
 	}
 }
 
+// InitStrictInputSourceWithContext is InitInputSourceWithContext, but it also
+// returns an error naming the keys of the input source which match no flag,
+// for input sources which can list their keys, like the YAML and TOML ones.
+// The keys of nested tables are matched by their dotted names.
+func InitStrictInputSourceWithContext(flags []cli.Flag, createInputSource func(context *cli.Context) (InputSourceContext, error)) cli.BeforeFunc {
+	return func(context *cli.Context) error {
+		inputSource, err := createInputSource(context)
+		if err != nil {
+			return fmt.Errorf("Unable to create input source with context: inner error: \n'%v'", err.Error())
+		}
+
+		if keyed, ok := inputSource.(keyedInputSource); ok {
+			names := map[string]bool{}
+			for _, f := range flags {
+				for _, name := range f.Names() {
+					names[name] = true
+				}
+			}
+			if keys := keyed.unknownKeys(names); len(keys) != 0 {
+				return fmt.Errorf("Unknown keys in input source %s: %s", inputSource.Source(), strings.Join(keys, ", "))
+			}
+		}
+
+		return ApplyInputSourceValues(context, inputSource, flags)
+	}
+}
+
+// inputSourceValue returns the value held by isc for f as a string, for
+// cli.App.OnSourceConflict. Like when they are applied, zero values count as
+// no value, and the values which fail to be read are left to the flag to
+// report.
+func inputSourceValue(isc InputSourceContext, f cli.Flag) (string, bool) {
+	name := f.Names()[0]
+	switch f.(type) {
+	case *StringFlag, *PathFlag:
+		value, err := isc.String(name)
+		return value, err == nil && value != ""
+	case *IntFlag:
+		value, err := isc.Int(name)
+		return strconv.Itoa(value), err == nil && value > 0
+	case *DurationFlag:
+		value, err := isc.Duration(name)
+		return value.String(), err == nil && value > 0
+	case *Float64Flag:
+		value, err := isc.Float64(name)
+		return float64ToString(value), err == nil && value > 0
+	case *BoolFlag:
+		value, err := isc.Bool(name)
+		return strconv.FormatBool(value), err == nil && value
+	case *StringSliceFlag:
+		value, err := isc.StringSlice(name)
+		return strings.Join(value, ","), err == nil && value != nil
+	case *IntSliceFlag:
+		value, err := isc.IntSlice(name)
+		var values []string
+		for _, v := range value {
+			values = append(values, strconv.Itoa(v))
+		}
+		return strings.Join(values, ","), err == nil && value != nil
+	case *TimestampFlag:
+		if tsc, ok := isc.(timestampInputSource); ok {
+			value, err := tsc.Timestamp(name)
+			if err != nil {
+				return "", false
+			}
+			if value != nil {
+				return value.Format(f.(*TimestampFlag).Layout), true
+			}
+		}
+		value, err := isc.String(name)
+		return value, err == nil && value != ""
+	case *GenericFlag:
+		value, err := isc.Generic(name)
+		if err != nil || value == nil {
+			return "", false
+		}
+		return value.String(), true
+	}
+	return "", false
+}
+
+// keyedInputSource is implemented by input sources which can list the keys
+// they hold which are not flag names
+type keyedInputSource interface {
+	unknownKeys(names map[string]bool) []string
+}
+
+// timestampInputSource is implemented by input sources holding timestamps
+// which need no parsing, like the datetimes of TOML files
+type timestampInputSource interface {
+	Timestamp(name string) (*time.Time, error)
+}
+
 // ApplyInputSourceValue applies a generic value to the flagSet if required
 func (f *GenericFlag) ApplyInputSourceValue(context *cli.Context, isc InputSourceContext) error {
 	if f.set != nil {
@@ -90,11 +194,11 @@ func (f *StringSliceFlag) ApplyInputSourceValue(context *cli.Context, isc InputS
 				return err
 			}
 			if value != nil {
-				var sliceValue cli.StringSlice = *(cli.NewStringSlice(value...))
+				// set through the flag set so that the flag counts as set
+				serialized := cli.NewStringSlice(value...).Serialize()
 				for _, name := range f.Names() {
-					underlyingFlag := f.set.Lookup(name)
-					if underlyingFlag != nil {
-						underlyingFlag.Value = &sliceValue
+					if err := f.set.Set(name, serialized); err != nil {
+						return err
 					}
 				}
 			}
@@ -112,11 +216,11 @@ func (f *IntSliceFlag) ApplyInputSourceValue(context *cli.Context, isc InputSour
 				return err
 			}
 			if value != nil {
-				var sliceValue cli.IntSlice = *(cli.NewIntSlice(value...))
+				// set through the flag set so that the flag counts as set
+				serialized := cli.NewIntSlice(value...).Serialize()
 				for _, name := range f.Names() {
-					underlyingFlag := f.set.Lookup(name)
-					if underlyingFlag != nil {
-						underlyingFlag.Value = &sliceValue
+					if err := f.set.Set(name, serialized); err != nil {
+						return err
 					}
 				}
 			}
@@ -207,13 +311,82 @@ func (f *IntFlag) ApplyInputSourceValue(context *cli.Context, isc InputSourceCon
 	return nil
 }
 
+// TimestampFlag is the flag type that wraps cli.TimestampFlag to allow
+// for other values to be specified
+type TimestampFlag struct {
+	*cli.TimestampFlag
+	set *flag.FlagSet
+}
+
+// NewTimestampFlag creates a new TimestampFlag
+func NewTimestampFlag(fl *cli.TimestampFlag) *TimestampFlag {
+	return &TimestampFlag{TimestampFlag: fl, set: nil}
+}
+
+// Apply saves the flagSet for later usage calls, then calls
+// the wrapped TimestampFlag.Apply
+func (f *TimestampFlag) Apply(set *flag.FlagSet) error {
+	f.set = set
+	return f.TimestampFlag.Apply(set)
+}
+
+// ApplyInputSourceValue applies a Timestamp value to the flagSet if required.
+// A timestamp held by the input source as a time.Time is set as it is, other
+// values are parsed with the Layout of the flag.
+func (f *TimestampFlag) ApplyInputSourceValue(context *cli.Context, isc InputSourceContext) error {
+	if f.set != nil {
+		if !(context.IsSet(f.Name) || isEnvVarSet(f.EnvVars)) {
+			if tsc, ok := isc.(timestampInputSource); ok {
+				value, err := tsc.Timestamp(f.TimestampFlag.Name)
+				if err != nil {
+					return err
+				}
+				if value != nil {
+					for _, name := range f.Names() {
+						if ts, ok := f.set.Lookup(name).Value.(*cli.Timestamp); ok {
+							ts.SetTimestamp(*value)
+						}
+					}
+					f.HasBeenSet = true
+					return nil
+				}
+			}
+
+			value, err := isc.String(f.TimestampFlag.Name)
+			if err != nil {
+				return err
+			}
+			if value != "" {
+				for _, name := range f.Names() {
+					if err := f.set.Set(name, value); err != nil {
+						return fmt.Errorf("could not parse %q as timestamp value for flag %s: %s", value, f.TimestampFlag.Name, err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // ApplyInputSourceValue applies a Duration value to the flagSet if required
 func (f *DurationFlag) ApplyInputSourceValue(context *cli.Context, isc InputSourceContext) error {
 	if f.set != nil {
 		if !(context.IsSet(f.Name) || isEnvVarSet(f.EnvVars)) {
 			value, err := isc.Duration(f.DurationFlag.Name)
 			if err != nil {
-				return err
+				if f.DefaultUnit == 0 {
+					return err
+				}
+				bare, ok := bareDuration(isc, f.DurationFlag.Name)
+				if !ok {
+					return err
+				}
+				for _, name := range f.Names() {
+					if err := f.set.Set(name, bare); err != nil {
+						return fmt.Errorf("could not parse %q as duration value for flag %s: %s", bare, f.DurationFlag.Name, err)
+					}
+				}
+				return nil
 			}
 			if value > 0 {
 				for _, name := range f.Names() {
@@ -257,6 +430,21 @@ func isEnvVarSet(envVars []string) bool {
 	return false
 }
 
+// bareDuration returns the value of name as a string when the input source
+// holds a bare number for it, for a DurationFlag with a DefaultUnit to parse
+func bareDuration(isc InputSourceContext, name string) (string, bool) {
+	if value, err := isc.Int(name); err == nil {
+		return strconv.Itoa(value), true
+	}
+	if value, err := isc.Float64(name); err == nil {
+		return float64ToString(value), true
+	}
+	if value, err := isc.String(name); err == nil {
+		return value, true
+	}
+	return "", false
+}
+
 func float64ToString(f float64) string {
 	return fmt.Sprintf("%v", f)
 }
@@ -278,6 +278,29 @@ func TestDurationApplyInputSourceMethodEnvVarSet(t *testing.T) {
 	expect(t, 15*time.Second, c.Duration("test"))
 }
 
+func TestDurationApplyInputSourceMethodDefaultUnit(t *testing.T) {
+	for _, v := range []interface{}{30, 30.0, "30", "30s"} {
+		c := runTest(t, testApplyInputSource{
+			Flag:     NewDurationFlag(&cli.DurationFlag{Name: "test", DefaultUnit: time.Second}),
+			FlagName: "test",
+			MapValue: v,
+		})
+		expect(t, 30*time.Second, c.Duration("test"))
+	}
+}
+
+func TestDurationApplyInputSourceMethodNoDefaultUnit(t *testing.T) {
+	fl := NewDurationFlag(&cli.DurationFlag{Name: "test"})
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+	err := fl.ApplyInputSourceValue(cli.NewContext(nil, set, nil), &MapInputSource{
+		valueMap: map[interface{}]interface{}{"test": 30},
+	})
+	if err == nil {
+		t.Error("expected an error for a bare number without a DefaultUnit")
+	}
+}
+
 func TestFloat64ApplyInputSourceMethodSet(t *testing.T) {
 	c := runTest(t, testApplyInputSource{
 		Flag:     NewFloat64Flag(&cli.Float64Flag{Name: "test"}),
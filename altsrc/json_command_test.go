@@ -4,7 +4,9 @@ import (
 	"flag"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -312,6 +314,112 @@ func TestCommandJSONFileFlagHasDefaultGlobalEnvJSONSetGlobalEnvWinsNested(t *tes
 	expect(t, err, nil)
 }
 
+func TestCommandJSONFileTypedValues(t *testing.T) {
+	cleanup := writeTempFile(t, fileName, `{
+		"db": {"port": 5432, "timeout": "30s", "ratio": 0.5, "tls": true},
+		"hosts": ["a", "b"],
+		"ids": [1, 2, 3]
+	}`)
+	defer cleanup()
+
+	app := &cli.App{
+		Flags: []cli.Flag{
+			NewIntFlag(&cli.IntFlag{Name: "db.port"}),
+			NewDurationFlag(&cli.DurationFlag{Name: "db.timeout"}),
+			NewFloat64Flag(&cli.Float64Flag{Name: "db.ratio"}),
+			NewBoolFlag(&cli.BoolFlag{Name: "db.tls"}),
+			NewStringSliceFlag(&cli.StringSliceFlag{Name: "hosts"}),
+			NewIntSliceFlag(&cli.IntSliceFlag{Name: "ids"}),
+			NewStringFlag(&cli.StringFlag{Name: "db.user"}),
+			&cli.StringFlag{Name: "load"},
+		},
+		Action: func(c *cli.Context) error {
+			expect(t, c.Int("db.port"), 5432)
+			expect(t, c.Duration("db.timeout"), 30*time.Second)
+			expect(t, c.Float64("db.ratio"), 0.5)
+			expect(t, c.Bool("db.tls"), true)
+			expect(t, c.StringSlice("hosts"), []string{"a", "b"})
+			expect(t, c.IntSlice("ids"), []int{1, 2, 3})
+			expect(t, c.IsSet("db.port"), true)
+			expect(t, c.IsSet("ids"), true)
+			expect(t, c.IsSet("db.user"), false)
+			return nil
+		},
+	}
+	app.Before = InitInputSourceWithContext(app.Flags, NewJSONSourceFromFlagFunc("load"))
+
+	err := app.Run([]string{"test", "--load", fileName})
+	expect(t, err, nil)
+}
+
+func TestCommandJSONFileTypeMismatch(t *testing.T) {
+	tests := []struct {
+		json string
+		flag cli.Flag
+		err  string
+	}{
+		{
+			json: `{"db": {"port": "5432"}}`,
+			flag: NewIntFlag(&cli.IntFlag{Name: "db.port"}),
+			err:  `flag "db.port": JSON value at $.db.port is a string, expected an integer`,
+		},
+		{
+			json: `{"db": {"port": 54.32}}`,
+			flag: NewIntFlag(&cli.IntFlag{Name: "db.port"}),
+			err:  `flag "db.port": JSON value at $.db.port is a number, expected an integer`,
+		},
+		{
+			json: `{"db": "localhost"}`,
+			flag: NewIntFlag(&cli.IntFlag{Name: "db.port"}),
+			err:  `flag "db.port": JSON value at $.db is a string, expected an object`,
+		},
+		{
+			json: `{"timeout": 30}`,
+			flag: NewDurationFlag(&cli.DurationFlag{Name: "timeout"}),
+			err:  `flag "timeout": JSON value at $.timeout is a number, expected a duration string`,
+		},
+		{
+			json: `{"timeout": "soon"}`,
+			flag: NewDurationFlag(&cli.DurationFlag{Name: "timeout"}),
+			err:  `flag "timeout": JSON value at $.timeout is not a duration`,
+		},
+		{
+			json: `{"ids": [1, "2"]}`,
+			flag: NewIntSliceFlag(&cli.IntSliceFlag{Name: "ids"}),
+			err:  `flag "ids": JSON value at $.ids[1] is a string, expected an integer`,
+		},
+		{
+			json: `{"tls": "yes"}`,
+			flag: NewBoolFlag(&cli.BoolFlag{Name: "tls"}),
+			err:  `flag "tls": JSON value at $.tls is a string, expected a boolean`,
+		},
+	}
+
+	for _, test := range tests {
+		isc, err := NewJSONSource([]byte(test.json))
+		expect(t, err, nil)
+
+		app := &cli.App{
+			Flags:  []cli.Flag{test.flag},
+			Action: func(c *cli.Context) error { return nil },
+		}
+		app.Before = InitInputSourceWithContext(app.Flags, func(*cli.Context) (InputSourceContext, error) {
+			return isc, nil
+		})
+
+		err = app.Run([]string{"test"})
+		if err == nil {
+			t.Errorf("expected an error for %s", test.json)
+			continue
+		}
+		// the error of time.ParseDuration, which ends the message, is
+		// worded differently by Go versions
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("expected an error containing %q, got %q", test.err, err)
+		}
+	}
+}
+
 func writeTempFile(t *testing.T, name string, content string) func() {
 	if err := ioutil.WriteFile(name, []byte(content), 0666); err != nil {
 		t.Fatalf("cannot write %q: %v", name, err)
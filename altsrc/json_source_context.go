@@ -15,9 +15,14 @@ import (
 // and returns an InputSourceContext suitable for retrieving config
 // variables from a file containing JSON data with the file name defined
 // by the given flag.
+// A missing file is only an error when the flag is set, not for its default.
 func NewJSONSourceFromFlagFunc(flag string) func(c *cli.Context) (InputSourceContext, error) {
 	return func(context *cli.Context) (InputSourceContext, error) {
-		return NewJSONSourceFromFile(context.String(flag))
+		filePath := context.String(flag)
+		if defaultFileMissing(context, flag, filePath) {
+			return &MapInputSource{file: filePath, valueMap: map[interface{}]interface{}{}}, nil
+		}
+		return NewJSONSourceFromFile(filePath)
 	}
 }
 
@@ -59,74 +64,76 @@ func (x *jsonSource) Source() string {
 
 func (x *jsonSource) Int(name string) (int, error) {
 	i, err := x.getValue(name)
-	if err != nil {
+	if err != nil || i == nil {
 		return 0, err
 	}
-	switch v := i.(type) {
-	default:
-		return 0, fmt.Errorf("unexpected type %T for %q", i, name)
-	case int:
-		return v, nil
-	case float32:
-		return int(v), nil
-	case float64:
-		return int(v), nil
+	v, ok := jsonInt(i)
+	if !ok {
+		return 0, jsonTypeError(name, jsonPath(name), i, "an integer")
 	}
+	return v, nil
 }
 
+// Duration returns durations written as strings like "30s"
 func (x *jsonSource) Duration(name string) (time.Duration, error) {
 	i, err := x.getValue(name)
-	if err != nil {
+	if err != nil || i == nil {
 		return 0, err
 	}
-	v, ok := i.(time.Duration)
-	if !ok {
-		return 0, fmt.Errorf("unexpected type %T for %q", i, name)
+	switch v := i.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("flag %q: JSON value at %s is not a duration: %s", name, jsonPath(name), err)
+		}
+		return d, nil
 	}
-	return v, nil
+	return 0, jsonTypeError(name, jsonPath(name), i, "a duration string")
 }
 
 func (x *jsonSource) Float64(name string) (float64, error) {
 	i, err := x.getValue(name)
-	if err != nil {
+	if err != nil || i == nil {
 		return 0, err
 	}
 	v, ok := i.(float64)
 	if !ok {
-		return 0, fmt.Errorf("unexpected type %T for %q", i, name)
+		return 0, jsonTypeError(name, jsonPath(name), i, "a number")
 	}
 	return v, nil
 }
 
 func (x *jsonSource) String(name string) (string, error) {
 	i, err := x.getValue(name)
-	if err != nil {
+	if err != nil || i == nil {
 		return "", err
 	}
 	v, ok := i.(string)
 	if !ok {
-		return "", fmt.Errorf("unexpected type %T for %q", i, name)
+		return "", jsonTypeError(name, jsonPath(name), i, "a string")
 	}
 	return v, nil
 }
 
 func (x *jsonSource) StringSlice(name string) ([]string, error) {
 	i, err := x.getValue(name)
-	if err != nil {
+	if err != nil || i == nil {
 		return nil, err
 	}
 	switch v := i.(type) {
 	default:
-		return nil, fmt.Errorf("unexpected type %T for %q", i, name)
+		return nil, jsonTypeError(name, jsonPath(name), i, "an array")
 	case []string:
 		return v, nil
 	case []interface{}:
 		c := []string{}
-		for _, s := range v {
+		for ix, s := range v {
 			if str, ok := s.(string); ok {
 				c = append(c, str)
 			} else {
-				return c, fmt.Errorf("unexpected item type %T in %T for %q", s, c, name)
+				return c, jsonTypeError(name, fmt.Sprintf("%s[%d]", jsonPath(name), ix), s, "a string")
 			}
 		}
 		return c, nil
@@ -135,21 +142,21 @@ func (x *jsonSource) StringSlice(name string) ([]string, error) {
 
 func (x *jsonSource) IntSlice(name string) ([]int, error) {
 	i, err := x.getValue(name)
-	if err != nil {
+	if err != nil || i == nil {
 		return nil, err
 	}
 	switch v := i.(type) {
 	default:
-		return nil, fmt.Errorf("unexpected type %T for %q", i, name)
+		return nil, jsonTypeError(name, jsonPath(name), i, "an array")
 	case []int:
 		return v, nil
 	case []interface{}:
 		c := []int{}
-		for _, s := range v {
-			if i2, ok := s.(int); ok {
+		for ix, s := range v {
+			if i2, ok := jsonInt(s); ok {
 				c = append(c, i2)
 			} else {
-				return c, fmt.Errorf("unexpected item type %T in %T for %q", s, c, name)
+				return c, jsonTypeError(name, fmt.Sprintf("%s[%d]", jsonPath(name), ix), s, "an integer")
 			}
 		}
 		return c, nil
@@ -158,7 +165,7 @@ func (x *jsonSource) IntSlice(name string) ([]int, error) {
 
 func (x *jsonSource) Generic(name string) (cli.Generic, error) {
 	i, err := x.getValue(name)
-	if err != nil {
+	if err != nil || i == nil {
 		return nil, err
 	}
 	v, ok := i.(cli.Generic)
@@ -170,12 +177,12 @@ func (x *jsonSource) Generic(name string) (cli.Generic, error) {
 
 func (x *jsonSource) Bool(name string) (bool, error) {
 	i, err := x.getValue(name)
-	if err != nil {
+	if err != nil || i == nil {
 		return false, err
 	}
 	v, ok := i.(bool)
 	if !ok {
-		return false, fmt.Errorf("unexpected type %T for %q", i, name)
+		return false, jsonTypeError(name, jsonPath(name), i, "a boolean")
 	}
 	return v, nil
 }
@@ -184,6 +191,8 @@ func (x *jsonSource) getValue(key string) (interface{}, error) {
 	return jsonGetValue(key, x.deserialized)
 }
 
+// jsonGetValue returns the value at the dotted key, looking it up through
+// the nested objects of m. A missing key is not an error and returns nil.
 func jsonGetValue(key string, m map[string]interface{}) (interface{}, error) {
 	var ret interface{}
 	var ok bool
@@ -191,17 +200,59 @@ func jsonGetValue(key string, m map[string]interface{}) (interface{}, error) {
 	keys := strings.Split(key, ".")
 	for ix, k := range keys {
 		if ret, ok = working[k]; !ok {
-			return ret, fmt.Errorf("missing key %q", key)
+			return nil, nil
 		}
 		if working, ok = ret.(map[string]interface{}); !ok {
 			if ix < len(keys)-1 {
-				return ret, fmt.Errorf("unexpected intermediate value at %q segment of %q: %T", k, key, ret)
+				return ret, fmt.Errorf("flag %q: JSON value at %s is %s, expected an object",
+					key, jsonPath(strings.Join(keys[:ix+1], ".")), jsonTypeName(ret))
 			}
 		}
 	}
 	return ret, nil
 }
 
+// jsonInt returns i as an int if it is a whole number
+func jsonInt(i interface{}) (int, bool) {
+	switch v := i.(type) {
+	case int:
+		return v, true
+	case float32:
+		return int(v), float32(int(v)) == v
+	case float64:
+		return int(v), float64(int(v)) == v
+	}
+	return 0, false
+}
+
+// jsonPath returns the JSONPath of the dotted key
+func jsonPath(key string) string {
+	return "$." + key
+}
+
+func jsonTypeError(name, path string, value interface{}, expected string) error {
+	return fmt.Errorf("flag %q: JSON value at %s is %s, expected %s", name, path, jsonTypeName(value), expected)
+}
+
+// jsonTypeName describes the type of a value decoded from JSON
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case float32, float64, int:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
 type jsonSource struct {
 	file         string
 	deserialized map[string]interface{}
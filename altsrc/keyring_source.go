@@ -0,0 +1,235 @@
+package altsrc
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ErrKeyNotFound is returned by a Keyring when no secret is stored for a key
+var ErrKeyNotFound = errors.New("key not found in keyring")
+
+// Keyring gives access to the secrets stored in the keychain of the operating
+// system. Get returns ErrKeyNotFound when nothing is stored for the key.
+type Keyring interface {
+	Get(service, key string) (string, error)
+}
+
+// DefaultKeyring reads secrets with the security tool on macOS and with
+// secret-tool from libsecret on Linux. Lookups fail on other platforms.
+var DefaultKeyring Keyring = commandKeyring{}
+
+type commandKeyring struct{}
+
+func (commandKeyring) Get(service, key string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", key, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "key", key)
+	default:
+		return "", fmt.Errorf("no keyring available on %s", runtime.GOOS)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", keyringCommandError(runtime.GOOS, cmd.Path, out, err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// keyringCommandError returns ErrKeyNotFound when the keyring tool reports
+// that the key is not found, by exiting with 44 for security and with 1 and
+// no output for secret-tool, and an error describing the failure otherwise
+func keyringCommandError(goos, tool string, out []byte, err error) error {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return err
+	}
+
+	code := exitErr.ExitCode()
+	switch {
+	case goos == "darwin" && code == 44:
+		return ErrKeyNotFound
+	case goos == "linux" && code == 1 && len(out) == 0:
+		return ErrKeyNotFound
+	}
+
+	if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+		return fmt.Errorf("%s exited with status %d: %s", tool, code, stderr)
+	}
+	return fmt.Errorf("%s exited with status %d", tool, code)
+}
+
+// KeyringSource implements InputSourceContext to return the secrets stored
+// for Service in a keyring. Flags are looked up by name, unless Keys maps the
+// name to another key.
+type KeyringSource struct {
+	Service string
+	Keys    map[string]string
+	// Keyring defaults to DefaultKeyring
+	Keyring Keyring
+}
+
+// NewKeyringSource returns an InputSourceContext suitable for retrieving
+// the secrets stored for service in the DefaultKeyring.
+func NewKeyringSource(service string) *KeyringSource {
+	return &KeyringSource{Service: service}
+}
+
+// Source returns the keyring service the secrets are read from
+func (ks *KeyringSource) Source() string {
+	return "keyring:" + ks.Service
+}
+
+// Provenance returns where the value of the named flag is read from, in the
+// form keyring:<service>/<key>
+func (ks *KeyringSource) Provenance(name string) string {
+	return ks.Source() + "/" + ks.key(name)
+}
+
+func (ks *KeyringSource) key(name string) string {
+	if key, ok := ks.Keys[name]; ok {
+		return key
+	}
+	return name
+}
+
+// get returns the secret stored for the named flag. A key which is not found
+// is not an error, matching the other input sources.
+func (ks *KeyringSource) get(name string) (string, bool, error) {
+	keyring := ks.Keyring
+	if keyring == nil {
+		keyring = DefaultKeyring
+	}
+
+	val, err := keyring.Get(ks.Service, ks.key(name))
+	if err == ErrKeyNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("could not read flag %s from %s: %v", name, ks.Provenance(name), err)
+	}
+	return val, true, nil
+}
+
+func (ks *KeyringSource) parseError(name, typeName, val string, err error) error {
+	return fmt.Errorf("could not parse %q from %s as %s value for flag %s: %s", val, ks.Provenance(name), typeName, name, err)
+}
+
+// Int returns an int from the keyring if it exists otherwise returns 0
+func (ks *KeyringSource) Int(name string) (int, error) {
+	val, ok, err := ks.get(name)
+	if err != nil || !ok {
+		return 0, err
+	}
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, ks.parseError(name, "int", val, err)
+	}
+	return i, nil
+}
+
+// Duration returns a duration from the keyring if it exists otherwise returns 0
+func (ks *KeyringSource) Duration(name string) (time.Duration, error) {
+	val, ok, err := ks.get(name)
+	if err != nil || !ok {
+		return 0, err
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, ks.parseError(name, "duration", val, err)
+	}
+	return d, nil
+}
+
+// Float64 returns an float64 from the keyring if it exists otherwise returns 0
+func (ks *KeyringSource) Float64(name string) (float64, error) {
+	val, ok, err := ks.get(name)
+	if err != nil || !ok {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, ks.parseError(name, "float64", val, err)
+	}
+	return f, nil
+}
+
+// String returns a string from the keyring if it exists otherwise returns ""
+func (ks *KeyringSource) String(name string) (string, error) {
+	val, _, err := ks.get(name)
+	return val, err
+}
+
+// StringSlice returns the comma separated values of a secret from the keyring
+// if it exists otherwise returns nil
+func (ks *KeyringSource) StringSlice(name string) ([]string, error) {
+	val, ok, err := ks.get(name)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return strings.Split(val, ","), nil
+}
+
+// IntSlice returns the comma separated ints of a secret from the keyring if
+// it exists otherwise returns nil
+func (ks *KeyringSource) IntSlice(name string) ([]int, error) {
+	vals, err := ks.StringSlice(name)
+	if err != nil || vals == nil {
+		return nil, err
+	}
+
+	ints := make([]int, 0, len(vals))
+	for _, val := range vals {
+		i, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil {
+			return nil, ks.parseError(name, "int slice", val, err)
+		}
+		ints = append(ints, i)
+	}
+	return ints, nil
+}
+
+// Generic returns a cli.Generic holding the raw secret from the keyring if it
+// exists otherwise returns nil. The GenericFlag parses it.
+func (ks *KeyringSource) Generic(name string) (cli.Generic, error) {
+	val, ok, err := ks.get(name)
+	if err != nil || !ok {
+		return nil, err
+	}
+	v := keyringValue(val)
+	return &v, nil
+}
+
+// Bool returns an bool from the keyring if it exists otherwise returns false
+func (ks *KeyringSource) Bool(name string) (bool, error) {
+	val, ok, err := ks.get(name)
+	if err != nil || !ok {
+		return false, err
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, ks.parseError(name, "bool", val, err)
+	}
+	return b, nil
+}
+
+// keyringValue is the cli.Generic returned for a secret
+type keyringValue string
+
+func (v *keyringValue) Set(s string) error {
+	*v = keyringValue(s)
+	return nil
+}
+
+func (v *keyringValue) String() string {
+	return string(*v)
+}
@@ -0,0 +1,143 @@
+package altsrc
+
+import (
+	"errors"
+	"flag"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+type fakeKeyring struct {
+	secrets map[string]string
+	err     error
+}
+
+func (k *fakeKeyring) Get(service, key string) (string, error) {
+	if k.err != nil {
+		return "", k.err
+	}
+	if val, ok := k.secrets[service+"/"+key]; ok {
+		return val, nil
+	}
+	return "", ErrKeyNotFound
+}
+
+func TestKeyringSource(t *testing.T) {
+	ks := &KeyringSource{
+		Service: "mytool",
+		Keys:    map[string]string{"token": "api-token"},
+		Keyring: &fakeKeyring{secrets: map[string]string{
+			"mytool/api-token": "s3cret",
+			"mytool/retries":   "3",
+			"mytool/ports":     "80, 443",
+			"mytool/bad":       "many",
+		}},
+	}
+
+	expect(t, ks.Source(), "keyring:mytool")
+	expect(t, ks.Provenance("token"), "keyring:mytool/api-token")
+	expect(t, ks.Provenance("retries"), "keyring:mytool/retries")
+
+	s, err := ks.String("token")
+	expect(t, err, nil)
+	expect(t, s, "s3cret")
+
+	i, err := ks.Int("retries")
+	expect(t, err, nil)
+	expect(t, i, 3)
+
+	ports, err := ks.IntSlice("ports")
+	expect(t, err, nil)
+	expect(t, ports, []int{80, 443})
+
+	_, err = ks.Int("bad")
+	if err == nil || !strings.Contains(err.Error(), "for flag bad") || !strings.Contains(err.Error(), "keyring:mytool/bad") {
+		t.Errorf("expected a parse error naming the flag, got %v", err)
+	}
+
+	// a missing key is a miss, not an error
+	s, err = ks.String("missing")
+	expect(t, err, nil)
+	expect(t, s, "")
+	g, err := ks.Generic("missing")
+	expect(t, err, nil)
+	expect(t, g, cli.Generic(nil))
+}
+
+func TestKeyringSource_AccessError(t *testing.T) {
+	ks := &KeyringSource{
+		Service: "mytool",
+		Keyring: &fakeKeyring{err: errors.New("keychain is locked")},
+	}
+
+	_, err := ks.String("token")
+	expect(t, err.Error(), "could not read flag token from keyring:mytool/token: keychain is locked")
+}
+
+func TestKeyringCommandError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the failing tools are shell scripts")
+	}
+
+	exitErr := func(script string) error {
+		_, err := exec.Command("sh", "-c", script).Output()
+		if err == nil {
+			t.Fatalf("expected %q to fail", script)
+		}
+		return err
+	}
+
+	tests := []struct {
+		goos     string
+		out      string
+		err      error
+		expected error
+	}{
+		{goos: "darwin", err: exitErr("exit 44"), expected: ErrKeyNotFound},
+		{goos: "darwin", err: exitErr("echo 'user interaction is not allowed' >&2; exit 36"),
+			expected: errors.New("security exited with status 36: user interaction is not allowed")},
+		{goos: "linux", err: exitErr("exit 1"), expected: ErrKeyNotFound},
+		{goos: "linux", out: "partial", err: exitErr("exit 1"), expected: errors.New("secret-tool exited with status 1")},
+		{goos: "linux", err: exitErr("exit 2"), expected: errors.New("secret-tool exited with status 2")},
+		{goos: "linux", err: exec.ErrNotFound, expected: exec.ErrNotFound},
+	}
+
+	for _, test := range tests {
+		tool := map[string]string{"darwin": "security", "linux": "secret-tool"}[test.goos]
+		err := keyringCommandError(test.goos, tool, []byte(test.out), test.err)
+		if err == ErrKeyNotFound || test.expected == ErrKeyNotFound || err == exec.ErrNotFound {
+			expect(t, err, test.expected)
+			continue
+		}
+		expect(t, err.Error(), test.expected.Error())
+	}
+}
+
+func TestKeyringSource_AppliesToFlags(t *testing.T) {
+	keyring := &fakeKeyring{secrets: map[string]string{"mytool/token": "s3cret"}}
+	set := flag.NewFlagSet("test", 0)
+	_ = set.Parse([]string{"test-cmd"})
+	c := cli.NewContext(&cli.App{}, set, nil)
+
+	command := &cli.Command{
+		Name: "test-cmd",
+		Action: func(c *cli.Context) error {
+			expect(t, c.String("token"), "s3cret")
+			expect(t, c.String("user"), "nobody")
+			return nil
+		},
+		Flags: []cli.Flag{
+			NewStringFlag(&cli.StringFlag{Name: "token"}),
+			NewStringFlag(&cli.StringFlag{Name: "user", Value: "nobody"}),
+		},
+	}
+	command.Before = InitInputSource(command.Flags, func() (InputSourceContext, error) {
+		return &KeyringSource{Service: "mytool", Keyring: keyring}, nil
+	})
+
+	expect(t, command.Run(c), nil)
+}
@@ -3,6 +3,7 @@ package altsrc
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -239,6 +240,46 @@ func (fsm *MapInputSource) Bool(name string) (bool, error) {
 	return false, nil
 }
 
+// Timestamp returns a timestamp from the map if it exists and is a time.Time,
+// like the datetimes of TOML files, otherwise returns nil
+func (fsm *MapInputSource) Timestamp(name string) (*time.Time, error) {
+	otherGenericValue, exists := fsm.valueMap[name]
+	if !exists {
+		otherGenericValue, exists = nestedVal(name, fsm.valueMap)
+	}
+	if exists {
+		if otherValue, isType := otherGenericValue.(time.Time); isType {
+			return &otherValue, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// unknownKeys returns the dotted keys of the map which are not in names,
+// sorted. A table is only walked when its own key is not in names.
+func (fsm *MapInputSource) unknownKeys(names map[string]bool) []string {
+	var keys []string
+	var walk func(prefix string, m map[interface{}]interface{})
+	walk = func(prefix string, m map[interface{}]interface{}) {
+		for k, v := range m {
+			key := prefix + fmt.Sprint(k)
+			if names[key] {
+				continue
+			}
+			if child, ok := v.(map[interface{}]interface{}); ok {
+				walk(key+".", child)
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	walk("", fsm.valueMap)
+
+	sort.Strings(keys)
+	return keys
+}
+
 func incorrectTypeForFlagError(name, expectedTypeName string, value interface{}) error {
 	valueType := reflect.TypeOf(value)
 	valueTypeName := ""
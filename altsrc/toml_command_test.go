@@ -4,7 +4,9 @@ import (
 	"flag"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -303,3 +305,152 @@ func TestCommandTomlFileFlagHasDefaultGlobalEnvTomlSetGlobalEnvWinsNested(t *tes
 
 	expect(t, err, nil)
 }
+
+func TestCommandTomlFileTypedValues(t *testing.T) {
+	_ = ioutil.WriteFile("current.toml", []byte(`
+hosts = ["a", "b"]
+motd = """
+Hello,
+world"""
+
+[db]
+port = 5432
+started = 2021-02-03T04:05:06Z
+`), 0666)
+	defer os.Remove("current.toml")
+
+	app := &cli.App{
+		Flags: []cli.Flag{
+			NewStringSliceFlag(&cli.StringSliceFlag{Name: "hosts"}),
+			NewStringFlag(&cli.StringFlag{Name: "motd"}),
+			NewIntFlag(&cli.IntFlag{Name: "db.port"}),
+			NewTimestampFlag(&cli.TimestampFlag{Name: "db.started", Layout: "2006-01-02"}),
+			&cli.StringFlag{Name: "load"},
+		},
+		Action: func(c *cli.Context) error {
+			expect(t, c.StringSlice("hosts"), []string{"a", "b"})
+			expect(t, c.String("motd"), "Hello,\nworld")
+			expect(t, c.Int("db.port"), 5432)
+			expect(t, c.Timestamp("db.started").Equal(time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)), true)
+			expect(t, c.IsSet("db.started"), true)
+			return nil
+		},
+	}
+	app.Before = InitInputSourceWithContext(app.Flags, NewTomlSourceFromFlagFunc("load"))
+
+	err := app.Run([]string{"test", "--load", "current.toml"})
+	expect(t, err, nil)
+}
+
+func TestCommandTomlFileTimestampPrecedence(t *testing.T) {
+	_ = ioutil.WriteFile("current.toml", []byte("started = 2021-02-03T04:05:06Z"), 0666)
+	defer os.Remove("current.toml")
+
+	_ = os.Setenv("THE_STARTED", "2022-01-01")
+	defer os.Setenv("THE_STARTED", "")
+
+	tests := []struct {
+		args     []string
+		envVars  []string
+		expected time.Time
+	}{
+		{[]string{"test", "--load", "current.toml"}, nil, time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)},
+		{[]string{"test", "--load", "current.toml"}, []string{"THE_STARTED"}, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{[]string{"test", "--load", "current.toml", "--started", "2023-01-01"}, []string{"THE_STARTED"}, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		var started time.Time
+		app := &cli.App{
+			Flags: []cli.Flag{
+				NewTimestampFlag(&cli.TimestampFlag{Name: "started", Layout: "2006-01-02", EnvVars: test.envVars}),
+				&cli.StringFlag{Name: "load"},
+			},
+			Action: func(c *cli.Context) error {
+				started = *c.Timestamp("started")
+				return nil
+			},
+		}
+		app.Before = InitInputSourceWithContext(app.Flags, NewTomlSourceFromFlagFunc("load"))
+
+		err := app.Run(test.args)
+		expect(t, err, nil)
+		expect(t, started.Equal(test.expected), true)
+	}
+}
+
+func TestCommandTomlFileTimestamp_OnSourceConflict(t *testing.T) {
+	_ = ioutil.WriteFile("current.toml", []byte("started = 2021-02-03T04:05:06Z"), 0666)
+	defer os.Remove("current.toml")
+
+	_ = os.Setenv("THE_STARTED", "2022-01-01")
+	defer os.Setenv("THE_STARTED", "")
+
+	flags := []cli.Flag{
+		NewTimestampFlag(&cli.TimestampFlag{Name: "started", Layout: "2006-01-02", EnvVars: []string{"THE_STARTED"}}),
+		&cli.StringFlag{Name: "load", Value: "current.toml"},
+	}
+
+	var ignored []cli.SourceValue
+	app := &cli.App{
+		Writer:         ioutil.Discard,
+		Flags:          flags,
+		LoadFlagValues: InitInputSourceWithContext(flags, NewTomlSourceFromFlagFunc("load")),
+		OnSourceConflict: func(flag string, c cli.SourceValue, i []cli.SourceValue) {
+			ignored = i
+		},
+		Action: func(c *cli.Context) error { return nil },
+	}
+
+	expect(t, app.Run([]string{"test"}), nil)
+	expect(t, ignored, []cli.SourceValue{{Kind: cli.SourceConfig, Detail: "current.toml", Value: "2021-02-03"}})
+}
+
+func TestCommandTomlFileArrayOfTables(t *testing.T) {
+	_ = ioutil.WriteFile("current.toml", []byte(`
+[[servers]]
+name = "a"
+
+[[servers]]
+name = "b"
+`), 0666)
+	defer os.Remove("current.toml")
+
+	_, err := NewTomlSourceFromFile("current.toml")
+	if err == nil {
+		t.Fatal("expected an error for an array of tables")
+	}
+	if !strings.Contains(err.Error(), `"servers" is an array of tables`) {
+		t.Errorf("expected the error to name the array of tables, got %q", err)
+	}
+}
+
+func TestCommandTomlFileStrict(t *testing.T) {
+	_ = ioutil.WriteFile("current.toml", []byte(`
+test = 15
+typo = 1
+
+[db]
+port = 5432
+user = "me"
+`), 0666)
+	defer os.Remove("current.toml")
+
+	flags := []cli.Flag{
+		NewIntFlag(&cli.IntFlag{Name: "test"}),
+		NewIntFlag(&cli.IntFlag{Name: "db.port"}),
+		&cli.StringFlag{Name: "load"},
+	}
+	app := &cli.App{
+		Flags:  flags,
+		Action: func(c *cli.Context) error { return nil },
+	}
+	app.Before = InitStrictInputSourceWithContext(flags, NewTomlSourceFromFlagFunc("load"))
+
+	err := app.Run([]string{"test", "--load", "current.toml"})
+	expect(t, err.Error(), "Unknown keys in input source current.toml: db.user, typo")
+
+	app.Before = InitInputSourceWithContext(flags, NewTomlSourceFromFlagFunc("load"))
+	err = app.Run([]string{"test", "--load", "current.toml"})
+	expect(t, err, nil)
+}
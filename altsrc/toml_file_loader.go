@@ -3,6 +3,7 @@ package altsrc
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/urfave/cli/v2"
@@ -53,7 +54,23 @@ func unmarshalMap(i interface{}) (ret map[interface{}]interface{}, err error) {
 				return nil, err
 			}
 		case reflect.Array, reflect.Slice:
-			ret[key] = val.([]interface{})
+			items, ok := val.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Unsupported: %q is an array of tables, which can not be the value of a flag", key)
+			}
+			for _, item := range items {
+				if _, ok := item.(map[string]interface{}); ok {
+					return nil, fmt.Errorf("Unsupported: %q is an array of tables, which can not be the value of a flag", key)
+				}
+			}
+			ret[key] = items
+		case reflect.Struct:
+			// datetimes are kept as they are, for timestamp flags
+			t, ok := val.(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("Unsupported: type = %#v", v.Kind())
+			}
+			ret[key] = t
 		default:
 			return nil, fmt.Errorf("Unsupported: type = %#v", v.Kind())
 		}
@@ -85,9 +102,13 @@ func NewTomlSourceFromFile(file string) (InputSourceContext, error) {
 }
 
 // NewTomlSourceFromFlagFunc creates a new TOML InputSourceContext from a provided flag name and source context.
+// A missing file is only an error when the flag is set, not for its default.
 func NewTomlSourceFromFlagFunc(flagFileName string) func(context *cli.Context) (InputSourceContext, error) {
 	return func(context *cli.Context) (InputSourceContext, error) {
 		filePath := context.String(flagFileName)
+		if defaultFileMissing(context, flagFileName, filePath) {
+			return &MapInputSource{file: filePath, valueMap: map[interface{}]interface{}{}}, nil
+		}
 		return NewTomlSourceFromFile(filePath)
 	}
 }
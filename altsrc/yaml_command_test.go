@@ -2,9 +2,12 @@ package altsrc
 
 import (
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -306,3 +309,130 @@ func TestCommandYamlFileFlagHasDefaultGlobalEnvYamlSetGlobalEnvWinsNested(t *tes
 
 	expect(t, err, nil)
 }
+
+func yamlLoadFlagValuesApp(configFile string, action cli.ActionFunc) *cli.App {
+	flags := []cli.Flag{
+		&cli.StringFlag{Name: "config", Value: configFile},
+		NewIntFlag(&cli.IntFlag{
+			Name:     "port",
+			Required: true,
+			Validator: func(v interface{}) error {
+				if v.(int) > 65535 {
+					return fmt.Errorf("port %d out of range", v)
+				}
+				return nil
+			},
+		}),
+		NewStringSliceFlag(&cli.StringSliceFlag{Name: "tags"}),
+		NewStringFlag(&cli.StringFlag{Name: "db.host", Value: "localhost"}),
+	}
+	return &cli.App{
+		Writer:         ioutil.Discard,
+		ErrWriter:      ioutil.Discard,
+		Flags:          flags,
+		LoadFlagValues: InitInputSourceWithContext(flags, NewYamlSourceFromFlagFunc("config")),
+		Action:         action,
+	}
+}
+
+func TestYamlLoadFlagValues(t *testing.T) {
+	_ = ioutil.WriteFile("current.yaml", []byte(`port: 8080
+tags:
+  - a
+  - b
+db:
+  host: db.example.com`), 0666)
+	defer os.Remove("current.yaml")
+
+	var port int
+	var tags []string
+	var host string
+	var portSet, tagsSet bool
+	action := func(c *cli.Context) error {
+		port = c.Int("port")
+		tags = c.StringSlice("tags")
+		host = c.String("db.host")
+		portSet = c.IsSet("port")
+		tagsSet = c.IsSet("tags")
+		return nil
+	}
+
+	// the required port is satisfied by the file
+	expect(t, yamlLoadFlagValuesApp("current.yaml", action).Run([]string{"app"}), nil)
+	expect(t, port, 8080)
+	expect(t, tags, []string{"a", "b"})
+	expect(t, host, "db.example.com")
+	expect(t, portSet, true)
+	expect(t, tagsSet, true)
+
+	// the command line wins over the file
+	expect(t, yamlLoadFlagValuesApp("current.yaml", action).Run([]string{"app", "--port", "9090", "--tags", "c"}), nil)
+	expect(t, port, 9090)
+	expect(t, tags, []string{"c"})
+}
+
+func TestYamlLoadFlagValues_Validation(t *testing.T) {
+	_ = ioutil.WriteFile("current.yaml", []byte("port: 70000"), 0666)
+	defer os.Remove("current.yaml")
+
+	app := yamlLoadFlagValuesApp("current.yaml", func(c *cli.Context) error { return nil })
+	err := app.Run([]string{"app"})
+	if err == nil || !strings.Contains(err.Error(), "port 70000 out of range") {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestYamlLoadFlagValues_MissingFile(t *testing.T) {
+	var port int
+	app := yamlLoadFlagValuesApp("missing.yaml", func(c *cli.Context) error {
+		port = c.Int("port")
+		return nil
+	})
+
+	// a missing default file is not an error
+	expect(t, app.Run([]string{"app", "--port", "80"}), nil)
+	expect(t, port, 80)
+
+	err := app.Run([]string{"app", "--config", "missing.yaml", "--port", "80"})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected an error for the missing file, got %v", err)
+	}
+}
+
+func TestYamlLoadFlagValues_OnSourceConflict(t *testing.T) {
+	_ = ioutil.WriteFile("current.yaml", []byte("timeout: 1m\nport: 8080"), 0666)
+	defer os.Remove("current.yaml")
+	os.Clearenv()
+	_ = os.Setenv("MYAPP_TIMEOUT", "30s")
+
+	flags := []cli.Flag{
+		&cli.StringFlag{Name: "config", Value: "current.yaml"},
+		NewDurationFlag(&cli.DurationFlag{Name: "timeout", Value: 10 * time.Second, EnvVars: []string{"MYAPP_TIMEOUT"}}),
+		NewIntFlag(&cli.IntFlag{Name: "port", Value: 80}),
+	}
+
+	var timeout time.Duration
+	var conflicts []string
+	var chosen cli.SourceValue
+	var ignored []cli.SourceValue
+	app := &cli.App{
+		Writer:         ioutil.Discard,
+		Flags:          flags,
+		LoadFlagValues: InitInputSourceWithContext(flags, NewYamlSourceFromFlagFunc("config")),
+		OnSourceConflict: func(flag string, c cli.SourceValue, i []cli.SourceValue) {
+			conflicts = append(conflicts, flag)
+			chosen, ignored = c, i
+		},
+		Action: func(c *cli.Context) error {
+			timeout = c.Duration("timeout")
+			return nil
+		},
+	}
+
+	expect(t, app.Run([]string{"app"}), nil)
+	expect(t, timeout, 30*time.Second)
+	// the port of the file only overrides its default, which is no conflict
+	expect(t, conflicts, []string{"timeout"})
+	expect(t, chosen, cli.SourceValue{Kind: cli.SourceEnv, Detail: "MYAPP_TIMEOUT", Value: "30s"})
+	expect(t, ignored, []cli.SourceValue{{Kind: cli.SourceConfig, Detail: "current.yaml", Value: "1m0s"}})
+}
@@ -31,9 +31,13 @@ func NewYamlSourceFromFile(file string) (InputSourceContext, error) {
 }
 
 // NewYamlSourceFromFlagFunc creates a new Yaml InputSourceContext from a provided flag name and source context.
+// A missing file is only an error when the flag is set, not for its default.
 func NewYamlSourceFromFlagFunc(flagFileName string) func(context *cli.Context) (InputSourceContext, error) {
 	return func(context *cli.Context) (InputSourceContext, error) {
 		filePath := context.String(flagFileName)
+		if defaultFileMissing(context, flagFileName, filePath) {
+			return &MapInputSource{file: filePath, valueMap: map[interface{}]interface{}{}}, nil
+		}
 		return NewYamlSourceFromFile(filePath)
 	}
 }
@@ -53,6 +57,23 @@ func readCommandYaml(filePath string, container interface{}) (err error) {
 	return
 }
 
+// defaultFileMissing returns whether the file named by the flag flagFileName
+// is its default value and does not exist, in which case there is nothing to
+// load rather than an error
+func defaultFileMissing(context *cli.Context, flagFileName, filePath string) bool {
+	if context.IsSet(flagFileName) {
+		return false
+	}
+	if filePath == "" {
+		return true
+	}
+	if u, err := url.Parse(filePath); err == nil && u.Host != "" {
+		return false
+	}
+	_, err := os.Stat(filePath)
+	return os.IsNotExist(err)
+}
+
 func loadDataFrom(filePath string) ([]byte, error) {
 	u, err := url.Parse(filePath)
 	if err != nil {
@@ -5,9 +5,12 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -41,6 +44,12 @@ type App struct {
 	Commands []*Command
 	// List of flags to parse
 	Flags []Flag
+	// List of flags to parse, which are also available to all of the commands
+	// and their subcommands
+	PersistentFlags []Flag
+	// Groups of names of flags which must either all be set or all be unset,
+	// checked for the App and all of its commands and subcommands
+	FlagsRequiredTogether [][]string
 	// Boolean to enable bash completion commands
 	EnableBashCompletion bool
 	// Boolean to hide built-in help command and help flag
@@ -48,8 +57,34 @@ type App struct {
 	// Boolean to hide built-in help command but keep help flag.
 	// Ignored if HideHelp is true.
 	HideHelpCommand bool
+	// Boolean to hide the flags with a Deprecated message from help, like
+	// Hidden flags. They are still parsed and warned about when set.
+	HideDeprecated bool
 	// Boolean to hide built-in version flag and the VERSION section of help
 	HideVersion bool
+	// Boolean to add the built-in --color flag
+	EnableColorFlag bool
+	// Boolean to page the help through $PAGER, defaulting to "less -F -R -X",
+	// when it does not fit on the terminal. It also adds the built-in
+	// --no-pager flag, and paging is disabled by a non-empty NO_PAGER.
+	EnablePager bool
+	// Boolean to add the built-in --help-all flag
+	EnableHelpAll bool
+	// Format of the --help-all output, "text" (the default) or "markdown"
+	HelpAllFormat string
+	// Boolean to add the built-in completion command, which prints and
+	// installs the shell completion script. EnableBashCompletion has to be
+	// set as well for the script to work.
+	EnableCompletionCommand bool
+	// Boolean to add the built-in batch command, which runs the commands of
+	// a script file, one per line
+	EnableBatchCommand bool
+	// Boolean to allow distinct flags of a command to share a Destination,
+	// which is otherwise an error as the last one parsed silently wins
+	AllowSharedDestination bool
+	// Boolean to list the categories of flags in help in the order their
+	// first flag is declared, instead of sorted by name
+	KeepFlagCategoryOrder bool
 	// categories contains the categorized commands and is populated on app startup
 	categories CommandCategories
 	// An action to execute when the shell completion flag is set
@@ -57,6 +92,15 @@ type App struct {
 	// An action to execute before any subcommands are run, but after the context is ready
 	// If a non-nil error is returned, no subcommands are run
 	Before BeforeFunc
+	// An action to execute after the flags are parsed, but before the Required
+	// flags are checked and the flag Validators run, to set flag values from
+	// other sources like a config file, see altsrc.InitInputSourceWithContext
+	LoadFlagValues BeforeFunc
+	// An action to rewrite the arguments, without the program name, before
+	// any of them is parsed, like expanding -vvv into -v -v -v. The arguments
+	// returned are parsed as if they were given, so they go through the
+	// normalization of flag aliases and the subcommands like any other.
+	ArgsRewriter ArgsRewriterFunc
 	// An action to execute after any subcommands are run, but after the subcommand has finished
 	// It is run even if Action() panics
 	After AfterFunc
@@ -72,13 +116,54 @@ type App struct {
 	Authors []*Author
 	// Copyright of the binary if any
 	Copyright string
+	// Reader reader to read input from, used when prompting
+	Reader io.Reader
 	// Writer writer to write output to
 	Writer io.Writer
 	// ErrWriter writes error output
 	ErrWriter io.Writer
+	// Terminal detects whether Writer is a terminal and its width, defaults to
+	// DefaultTerminal
+	Terminal Terminal
+	// Profiles, when set, supplies named sets of flag values, selected with
+	// the persistent --profile flag and listed by the profiles command, see
+	// ProfilesFile. The flags given neither on the command line nor through
+	// env or file take the values of the selected profile, or else the base
+	// values shared by all of the profiles. A profile given to a command
+	// applies to the flags of its parent commands as well.
+	Profiles ProfileSource
+	// ProfileEnvVars are the environment variables selecting the profile
+	// when --profile is not given, like MYAPP_PROFILE
+	ProfileEnvVars []string
+	// Boolean to write usage errors, and the help printed along with them, to
+	// Writer instead of ErrWriter. Help that was explicitly asked for is
+	// always written to Writer. Writing to ErrWriter is the default, so the
+	// knob is named after the behavior it opts into rather than being a
+	// HelpToErrOnUsageError which would be true unless set.
+	HelpToStdoutOnUsageError bool
+	// ErrorPrefix is written before the errors printed by the framework,
+	// defaults to the Name followed by a colon
+	ErrorPrefix string
+	// Boolean to hide the ErrorPrefix
+	HideErrorPrefix bool
 	// Execute this function to handle ExitErrors. If not provided, HandleExitCoder is provided to
 	// function as a default, so this is optional.
 	ExitErrHandler ExitErrHandlerFunc
+	// The exit code of the error returned when required flags are not set,
+	// 2 by default like other usage errors
+	RequiredFlagsExitCode int
+	// Execute this function when the sources of a flag, like an environment
+	// variable and a config file, hold different values, after the value is
+	// chosen and before the flags are validated
+	OnSourceConflict SourceConflictFunc
+	// SensitiveFlags are the names of the flags whose values are masked in
+	// the SourceValues given to OnSourceConflict
+	SensitiveFlags []string
+	// Boolean to wrap the errors returned by Run in a *UsageError or a
+	// *RuntimeError, telling errors of the command line, which fail again
+	// when retried, from the errors of a Before, Action or After function.
+	// The ExitErrHandler is still called with the unwrapped errors.
+	ClassifyErrors bool
 	// Other custom info
 	Metadata map[string]interface{}
 	// Carries a function which returns app specific info.
@@ -91,6 +176,64 @@ type App struct {
 	// single-character bool arguments into one
 	// i.e. foobar -o -v -> foobar -ov
 	UseShortOptionHandling bool
+	// Boolean to leave out the help command at every level, so a "help"
+	// argument is always a positional argument. Help stays available through
+	// the help flag.
+	DisableHelpSubcommandInterception bool
+	// FlagFileFlag, when not empty, is the name of a flag taking a file to
+	// load flags from, one "name value" per line. Flags given on the command
+	// line override the ones from the file. The flag and the file belong to
+	// the App only: the file sets the flags of the App, including the
+	// persistent ones, and a line naming a flag of a command is an error as
+	// for an undefined flag.
+	FlagFileFlag string
+	// FlagReadRecorder, when not nil, records the flags read through the
+	// Context, see AssertAllFlagsRead
+	FlagReadRecorder *FlagReadRecorder
+	// MetricsCollector, when not nil, receives the number and duration of the
+	// parses and runs, see CounterMetrics
+	MetricsCollector MetricsCollector
+	// NumberParser and BoolParser, when not nil, parse the values of the
+	// numeric and bool flags given on the command line, the environment or a
+	// file before the standard parsing, e.g. CommaDecimalNumberParser. The
+	// values of a float slice flag are split on commas first, so a decimal
+	// comma is escaped there, like "--ratios 0\\,5,1".
+	NumberParser NumberParser
+	BoolParser   BoolParser
+	// DocsURL, when not empty, is the link to the documentation, shown at the
+	// end of the help and appended to the errors about invalid flag values
+	// and missing required flags. It is a template where {{.Command}} is the
+	// path of the command, its names separated by slashes, and {{.Flag}} the
+	// name of the flag, e.g. "https://example.com/cli/{{.Command}}#{{.Flag}}",
+	// so that it also makes the links of the commands and flags without a
+	// DocsURL of their own.
+	DocsURL string
+
+	// command is the Command run by the App of a subcommand
+	command *Command
+	// deprecatedFlagsWarned holds the deprecated flags already warned about
+	// during a run
+	deprecatedFlagsWarned map[Flag]bool
+	// flagActionsRun holds the flags whose Action already ran during a run
+	flagActionsRun map[Flag]bool
+	// flagDefaults holds the values the flags are declared with, shared with
+	// the commands and kept across runs
+	flagDefaults map[Flag]flagDefault
+	// prompts reads the answers to the prompts from Reader
+	prompts *lineReader
+	// inBatch is set while the batch command runs the lines of a script
+	inBatch bool
+	// quiet is set on the copy of the App run by RunCommand, so that errors
+	// are neither printed nor handled by the ExitErrHandler
+	quiet bool
+	// commandPath holds the commands RunCommand runs below the App, resolved
+	// beforehand rather than looked up in the arguments
+	commandPath []*Command
+	// commandArgs holds the flags and arguments of the last of commandPath
+	commandArgs []string
+	// globalFlags holds the values RunCommandWithFlags sets the flags of the
+	// App to, by flag name
+	globalFlags map[string]string
 
 	didSetup bool
 }
@@ -123,6 +266,10 @@ func NewApp() *App {
 // Setup runs initialization code to ensure all data structures are ready for
 // `Run` or inspection prior to `Run`.  It is internally called by `Run`, but
 // will return early if setup has already happened.
+//
+// Setup never reorders the declared Flags and Commands. The flags and the help
+// command it adds are appended after them, in the order help, help-all, version
+// and color for flags.
 func (a *App) Setup() {
 	if a.didSetup {
 		return
@@ -130,6 +277,10 @@ func (a *App) Setup() {
 
 	a.didSetup = true
 
+	if a.flagDefaults == nil {
+		a.flagDefaults = make(map[Flag]flagDefault)
+	}
+
 	if a.Name == "" {
 		a.Name = filepath.Base(os.Args[0])
 	}
@@ -158,6 +309,10 @@ func (a *App) Setup() {
 		a.Compiled = compileTime()
 	}
 
+	if a.Reader == nil {
+		a.Reader = os.Stdin
+	}
+
 	if a.Writer == nil {
 		a.Writer = os.Stdout
 	}
@@ -168,24 +323,62 @@ func (a *App) Setup() {
 		if c.HelpName == "" {
 			c.HelpName = fmt.Sprintf("%s %s", a.HelpName, c.Name)
 		}
+		c.appDocsURL = a.DocsURL
+		c.keepFlagCategoryOrder = a.KeepFlagCategoryOrder
+		c.hideDeprecated = a.HideDeprecated
 		newCommands = append(newCommands, c)
 	}
 	a.Commands = newCommands
 
+	if a.Profiles != nil {
+		a.PersistentFlags = append(a.PersistentFlags, profileFlag(a.ProfileEnvVars))
+		if a.Command(profilesCommand.Name) == nil {
+			a.appendCommand(profilesCommand)
+		}
+	}
+
+	for _, fl := range a.PersistentFlags {
+		a.appendFlag(fl)
+	}
+
 	if a.Command(helpCommand.Name) == nil && !a.HideHelp {
-		if !a.HideHelpCommand {
+		if !a.HideHelpCommand && !a.DisableHelpSubcommandInterception {
 			a.appendCommand(helpCommand)
 		}
 
 		if HelpFlag != nil {
 			a.appendFlag(HelpFlag)
 		}
+
+		if a.EnableHelpAll && HelpAllFlag != nil {
+			a.appendFlag(HelpAllFlag)
+		}
+	}
+
+	if a.EnableCompletionCommand && a.Command(completionCommand.Name) == nil {
+		a.appendCommand(completionCommand)
+	}
+
+	if a.EnableBatchCommand && a.Command(batchCommand.Name) == nil {
+		a.appendCommand(batchCommand)
+	}
+
+	if a.FlagFileFlag != "" {
+		a.appendFlag(flagFileFlag(a.FlagFileFlag))
 	}
 
 	if !a.HideVersion {
 		a.appendFlag(VersionFlag)
 	}
 
+	if a.EnableColorFlag && ColorFlag != nil {
+		a.appendFlag(ColorFlag)
+	}
+
+	if a.EnablePager && NoPagerFlag != nil {
+		a.appendFlag(NoPagerFlag)
+	}
+
 	a.categories = newCommandCategories()
 	for _, command := range a.Commands {
 		a.categories.AddCommand(command.Category, command)
@@ -202,7 +395,37 @@ func (a *App) Setup() {
 }
 
 func (a *App) newFlagSet() (*flag.FlagSet, error) {
-	return flagSet(a.Name, a.Flags)
+	useLocaleParsers(a.Flags, a.NumberParser, a.BoolParser)
+	recordFlagDefaults(a.flagDefaults, a.Flags)
+	set, err := flagSet(a.Name, a.Flags)
+	if err != nil {
+		return nil, err
+	}
+	setFileContentsReader(set, a.Reader)
+	setLocaleParsers(set, a.NumberParser, a.BoolParser)
+	return set, nil
+}
+
+// interceptsHelp returns whether a "help" argument runs the help command
+// rather than being a positional argument. It does when the App has other
+// commands or no Action of its own, unless "help" follows a "--" in args.
+func (a *App) interceptsHelp(args []string, set *flag.FlagSet) bool {
+	hasCommands := false
+	for _, c := range a.Commands {
+		if c != helpCommand {
+			hasCommands = true
+		}
+	}
+	if !hasCommands && reflect.ValueOf(a.Action).Pointer() != reflect.ValueOf(helpCommand.Action).Pointer() {
+		return false
+	}
+
+	for i, arg := range args {
+		if arg == "--" {
+			return len(args)-i-1 != set.NArg()
+		}
+	}
+	return true
 }
 
 func (a *App) useShortOptionHandling() bool {
@@ -219,7 +442,37 @@ func (a *App) Run(arguments []string) (err error) {
 // passed to its commands and sub-commands. Through this, you can
 // propagate timeouts and cancellation requests
 func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
+	invocationDir, _ := os.Getwd()
+
 	a.Setup()
+	a.deprecatedFlagsWarned = make(map[Flag]bool)
+	a.flagActionsRun = make(map[Flag]bool)
+
+	if a.ClassifyErrors {
+		defer func() {
+			err = classifyError(err)
+		}()
+	}
+
+	if a.MetricsCollector != nil {
+		defer func(start time.Time) {
+			a.MetricsCollector.ObserveRunDuration(time.Since(start))
+		}(time.Now())
+	}
+
+	if err := checkPersistentFlags(a.Name, a.Flags, a.PersistentFlags, a.Commands); err != nil {
+		return err
+	}
+
+	if err := checkCommandNames(a.Name, a.Commands); err != nil {
+		return err
+	}
+
+	if !a.AllowSharedDestination {
+		if err := checkSharedDestinations(a.Name, a.Flags, a.PersistentFlags, a.Commands); err != nil {
+			return err
+		}
+	}
 
 	// handle the completion flag separately from the flagset since
 	// completion could be attempted after a flag, but before its value was put
@@ -229,17 +482,33 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 	// always appends the completion flag at the end of the command
 	shellComplete, arguments := checkShellCompleteFlag(a, arguments)
 
+	if a.ArgsRewriter != nil && len(arguments) > 0 {
+		rewritten := a.ArgsRewriter(append([]string{}, arguments[1:]...))
+		arguments = append([]string{arguments[0]}, rewritten...)
+	}
+
+	metrics := startParse(a.MetricsCollector)
+	defer metrics.finish()
+
 	set, err := a.newFlagSet()
 	if err != nil {
+		metrics.fail(ParseErrorFlags)
 		return err
 	}
 
 	err = parseIter(set, a, arguments[1:], shellComplete)
+	if err == nil {
+		err = a.applyFlagFile(set)
+	}
+	if err == nil {
+		err = setGlobalFlags(set, a.globalFlags)
+	}
 	nerr := normalizeFlags(a.Flags, set)
-	context := NewContext(a, set, &Context{Context: ctx})
+	context := NewContext(a, set, &Context{Context: ctx, invocationDir: invocationDir})
 	if nerr != nil {
-		_, _ = fmt.Fprintln(a.Writer, nerr)
-		_ = ShowAppHelp(context)
+		metrics.fail(ParseErrorFlags)
+		a.printError(a.usageErrWriter(), nerr)
+		_ = showAppHelp(context, a.usageErrWriter())
 		return nerr
 	}
 	context.shellComplete = shellComplete
@@ -249,16 +518,24 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 	}
 
 	if err != nil {
+		metrics.fail(ParseErrorFlags)
+		addFlagSuggestion(err, context)
+		err = a.addDocsLink(nil, a.Flags, err)
 		if a.OnUsageError != nil {
 			err := a.OnUsageError(context, err, false)
 			a.handleExitCoder(context, err)
 			return err
 		}
-		_, _ = fmt.Fprintf(a.Writer, "%s %s\n\n", "Incorrect Usage.", err.Error())
-		_ = ShowAppHelp(context)
+		a.printError(a.usageErrWriter(), "Incorrect Usage.", err.Error())
+		_, _ = fmt.Fprintln(a.usageErrWriter())
+		_ = showAppHelp(context, a.usageErrWriter())
 		return err
 	}
 
+	if !a.HideHelp && checkHelpAll(context) {
+		return ShowAllHelp(context)
+	}
+
 	if !a.HideHelp && checkHelp(context) {
 		_ = ShowAppHelp(context)
 		return nil
@@ -269,17 +546,66 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 		return nil
 	}
 
+	commandLine := commandLineValues(context.flagSet, arguments[1:])
+	if perr := applyProfile(a.Flags, context); perr != nil {
+		metrics.fail(ParseErrorFlags)
+		a.handleExitCoder(context, perr)
+		return perr
+	}
+	if a.LoadFlagValues != nil {
+		if lerr := a.LoadFlagValues(context); lerr != nil {
+			metrics.fail(ParseErrorFlags)
+			a.handleExitCoder(context, lerr)
+			return lerr
+		}
+	}
+	a.reportSourceConflicts(a.Flags, context, commandLine)
+
+	if perr := resolvePathFlags(a.Flags, context); perr != nil {
+		metrics.fail(ParseErrorFlags)
+		a.handleExitCoder(context, perr)
+		return perr
+	}
+
+	warnDeprecatedFlags(a.Flags, context)
+
+	if verr := validateFlags(a.Flags, context); verr != nil {
+		metrics.fail(ParseErrorValidation)
+		_ = showAppHelp(context, a.usageErrWriter())
+		return a.addDocsLink(nil, a.Flags, verr)
+	}
+
 	cerr := checkRequiredFlags(a.Flags, context)
 	if cerr != nil {
-		_ = ShowAppHelp(context)
-		return cerr
+		metrics.fail(ParseErrorRequired)
+		_ = showAppHelp(context, a.usageErrWriter())
+		return a.addDocsLink(nil, a.Flags, cerr)
+	}
+
+	// a command run next checks the groups of the App too, once the flags
+	// it may set are parsed
+	if len(a.commandPath) == 0 && a.Command(context.Args().First()) == nil {
+		if gerr := checkFlagsRequiredTogether(context); gerr != nil {
+			metrics.fail(ParseErrorRequired)
+			_ = showAppHelp(context, a.usageErrWriter())
+			return gerr
+		}
+	}
+	metrics.finish()
+
+	if ferr := runFlagActions(a.Flags, context); ferr != nil {
+		a.handleExitCoder(context, ferr)
+		return ferr
 	}
 
 	if a.After != nil {
 		defer func() {
+			context.actionErr = err
 			if afterErr := a.After(context); afterErr != nil {
+				a.handleExitCoder(context, afterErr)
+				afterErr = a.runtimeError(afterErr)
 				if err != nil {
-					err = newMultiError(err, afterErr)
+					err = NewMultiError(err, afterErr)
 				} else {
 					err = afterErr
 				}
@@ -290,18 +616,28 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 	if a.Before != nil {
 		beforeErr := a.Before(context)
 		if beforeErr != nil {
-			_, _ = fmt.Fprintf(a.Writer, "%v\n\n", beforeErr)
-			_ = ShowAppHelp(context)
+			if !a.quiet {
+				a.printError(a.Writer, beforeErr)
+				_, _ = fmt.Fprintln(a.Writer)
+				_ = ShowAppHelp(context)
+			}
 			a.handleExitCoder(context, beforeErr)
-			err = beforeErr
+			err = a.runtimeError(beforeErr)
 			return err
 		}
 	}
 
+	if len(a.commandPath) > 0 {
+		return a.commandPath[0].Run(context)
+	}
+
 	args := context.Args()
 	if args.Present() {
 		name := args.First()
 		c := a.Command(name)
+		if c == helpCommand && !a.interceptsHelp(arguments[1:], set) {
+			c = nil
+		}
 		if c != nil {
 			return c.Run(context)
 		}
@@ -312,10 +648,112 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 	}
 
 	// Run default Action
+	context.startAction(a.Name, a.Action)
 	err = a.Action(context)
 
 	a.handleExitCoder(context, err)
-	return err
+	return a.actionError(a.Action, err)
+}
+
+// RunCommand runs the command at path, like []string{"remote", "add"}, with
+// args as its flags and arguments. The commands are resolved by path rather
+// than looked up in the arguments, so args are never taken for the names of
+// commands nor for the flags of the App and of the parent commands, which keep
+// the values they get from their defaults, environment variables and files.
+// The hooks of the App and of the parent commands run as with Run. Unlike Run,
+// errors are returned without being printed along with the help, nor handled
+// by the ExitErrHandler.
+func (a *App) RunCommand(ctx context.Context, path []string, args []string) error {
+	return a.RunCommandWithFlags(ctx, path, nil, args)
+}
+
+// RunCommandWithFlags is RunCommand setting the flags of the App to the values
+// of globalFlags, by flag name, as if they were given on the command line.
+func (a *App) RunCommandWithFlags(ctx context.Context, path []string, globalFlags map[string]string, args []string) error {
+	a.Setup()
+
+	commands, err := a.resolveCommandPath(path)
+	if err != nil {
+		return err
+	}
+
+	// the App is copied, so that the settings of the call are not left
+	// on it for the next calls, but shares the reader of the prompts
+	a.promptLines(a.Reader)
+	run := *a
+	run.quiet = true
+	run.commandPath = commands
+	run.commandArgs = args
+	run.globalFlags = globalFlags
+	return run.RunContext(ctx, []string{a.Name})
+}
+
+// promptLines returns the lineReader of r, replacing the one of a previous
+// Reader
+func (a *App) promptLines(r io.Reader) *lineReader {
+	if a.prompts != nil && reflect.TypeOf(r).Comparable() && a.prompts.r == r {
+		return a.prompts
+	}
+	if a.prompts != nil {
+		close(a.prompts.requests)
+	}
+	a.prompts = newLineReader(r)
+	return a.prompts
+}
+
+// setGlobalFlags sets the flags of set to values, by flag name
+func setGlobalFlags(set *flag.FlagSet, values map[string]string) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if set.Lookup(name) == nil {
+			return fmt.Errorf("flag provided but not defined: -%s", name)
+		}
+		if err := set.Set(name, values[name]); err != nil {
+			return fmt.Errorf("invalid value %q for flag -%s: %v", values[name], name, err)
+		}
+	}
+	return nil
+}
+
+// resolveCommandPath returns the commands at path, which may be given by
+// their aliases
+func (a *App) resolveCommandPath(path []string) ([]*Command, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("no command to run in %q", a.Name)
+	}
+
+	var resolved []*Command
+	parent, commands := a.Name, a.Commands
+	for _, name := range path {
+		var command *Command
+		for _, c := range commands {
+			if c.HasName(name) {
+				command = c
+				break
+			}
+		}
+		if command == nil {
+			var valid []string
+			for _, c := range commands {
+				if !c.Hidden {
+					valid = append(valid, c.Name)
+				}
+			}
+			if len(valid) == 0 {
+				return nil, fmt.Errorf("command %q not found: %q has no commands", name, parent)
+			}
+			return nil, fmt.Errorf("command %q not found in %q, valid commands: %s", name, parent, strings.Join(valid, ", "))
+		}
+
+		resolved = append(resolved, command)
+		parent, commands = parent+" "+command.Name, command.Subcommands
+	}
+	return resolved, nil
 }
 
 // RunAndExitOnError calls .Run() and exits non-zero if an error was returned
@@ -325,7 +763,7 @@ func (a *App) RunContext(ctx context.Context, arguments []string) (err error) {
 // code in the cli.ExitCoder
 func (a *App) RunAndExitOnError() {
 	if err := a.Run(os.Args); err != nil {
-		_, _ = fmt.Fprintln(a.errWriter(), err)
+		a.printError(a.errWriter(), err)
 		OsExiter(1)
 	}
 }
@@ -341,26 +779,44 @@ func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 		if c.HelpName == "" {
 			c.HelpName = fmt.Sprintf("%s %s", a.HelpName, c.Name)
 		}
+		c.appDocsURL = a.DocsURL
+		c.keepFlagCategoryOrder = a.KeepFlagCategoryOrder
+		c.hideDeprecated = a.HideDeprecated
 		newCmds = append(newCmds, c)
 	}
 	a.Commands = newCmds
 
+	metrics := startParse(a.MetricsCollector)
+	defer metrics.finish()
+
 	set, err := a.newFlagSet()
 	if err != nil {
+		metrics.fail(ParseErrorFlags)
 		return err
 	}
 
-	err = parseIter(set, a, ctx.Args().Tail(), ctx.shellComplete)
+	given := ctx.Args().Tail()
+	if ctx.App != nil && len(ctx.App.commandPath) == 1 && ctx.App.commandPath[0] == a.command {
+		// RunCommand gives the args of the command apart from the ones of
+		// its parents
+		given = ctx.App.commandArgs
+	}
+
+	err = parseIter(set, a, given, ctx.shellComplete)
 	nerr := normalizeFlags(a.Flags, set)
+	if err == nil && nerr == nil && ctx.App != nil {
+		inheritPersistentFlags(ctx.App.PersistentFlags, set, ctx)
+	}
 	context := NewContext(a, set, ctx)
 
 	if nerr != nil {
-		_, _ = fmt.Fprintln(a.Writer, nerr)
-		_, _ = fmt.Fprintln(a.Writer)
+		metrics.fail(ParseErrorFlags)
+		a.printError(a.usageErrWriter(), nerr)
+		_, _ = fmt.Fprintln(a.usageErrWriter())
 		if len(a.Commands) > 0 {
-			_ = ShowSubcommandHelp(context)
+			_ = showSubcommandHelp(context, a.usageErrWriter())
 		} else {
-			_ = ShowCommandHelp(ctx, context.Args().First())
+			_ = showCommandHelp(ctx, context.Args().First(), a.usageErrWriter())
 		}
 		return nerr
 	}
@@ -370,16 +826,24 @@ func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 	}
 
 	if err != nil {
+		metrics.fail(ParseErrorFlags)
+		addFlagSuggestion(err, context)
+		err = a.addDocsLink(nil, a.Flags, err)
 		if a.OnUsageError != nil {
 			err = a.OnUsageError(context, err, true)
 			a.handleExitCoder(context, err)
 			return err
 		}
-		_, _ = fmt.Fprintf(a.Writer, "%s %s\n\n", "Incorrect Usage.", err.Error())
-		_ = ShowSubcommandHelp(context)
+		a.printError(a.usageErrWriter(), "Incorrect Usage.", err.Error())
+		_, _ = fmt.Fprintln(a.usageErrWriter())
+		_ = showSubcommandHelp(context, a.usageErrWriter())
 		return err
 	}
 
+	if !a.HideHelp && checkHelpAll(context) {
+		return ShowAllHelp(context)
+	}
+
 	if len(a.Commands) > 0 {
 		if checkSubcommandHelp(context) {
 			return nil
@@ -390,19 +854,67 @@ func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 		}
 	}
 
+	commandLine := commandLineValues(context.flagSet, given)
+	if perr := applyProfile(a.Flags, context); perr != nil {
+		metrics.fail(ParseErrorFlags)
+		a.handleExitCoder(context, perr)
+		return perr
+	}
+	if a.LoadFlagValues != nil {
+		if lerr := a.LoadFlagValues(context); lerr != nil {
+			metrics.fail(ParseErrorFlags)
+			a.handleExitCoder(context, lerr)
+			return lerr
+		}
+	}
+	a.reportSourceConflicts(a.Flags, context, commandLine)
+
+	if perr := resolvePathFlags(a.Flags, context); perr != nil {
+		metrics.fail(ParseErrorFlags)
+		a.handleExitCoder(context, perr)
+		return perr
+	}
+
+	warnDeprecatedFlags(a.Flags, context)
+
+	if verr := validateFlags(a.Flags, context); verr != nil {
+		metrics.fail(ParseErrorValidation)
+		_ = showSubcommandHelp(context, a.usageErrWriter())
+		return a.addDocsLink(nil, a.Flags, verr)
+	}
+
 	cerr := checkRequiredFlags(a.Flags, context)
 	if cerr != nil {
-		_ = ShowSubcommandHelp(context)
-		return cerr
+		metrics.fail(ParseErrorRequired)
+		_ = showSubcommandHelp(context, a.usageErrWriter())
+		return a.addDocsLink(nil, a.Flags, cerr)
+	}
+
+	// a command run next checks the groups of the App too, once the flags
+	// it may set are parsed
+	if len(a.commandPath) == 0 && a.Command(context.Args().First()) == nil {
+		if gerr := checkFlagsRequiredTogether(context); gerr != nil {
+			metrics.fail(ParseErrorRequired)
+			_ = showSubcommandHelp(context, a.usageErrWriter())
+			return gerr
+		}
+	}
+	metrics.finish()
+
+	if ferr := runFlagActions(a.Flags, context); ferr != nil {
+		a.handleExitCoder(context, ferr)
+		return ferr
 	}
 
 	if a.After != nil {
 		defer func() {
+			context.actionErr = err
 			afterErr := a.After(context)
 			if afterErr != nil {
-				a.handleExitCoder(context, err)
+				a.handleExitCoder(context, afterErr)
+				afterErr = a.runtimeError(afterErr)
 				if err != nil {
-					err = newMultiError(err, afterErr)
+					err = NewMultiError(err, afterErr)
 				} else {
 					err = afterErr
 				}
@@ -414,25 +926,33 @@ func (a *App) RunAsSubcommand(ctx *Context) (err error) {
 		beforeErr := a.Before(context)
 		if beforeErr != nil {
 			a.handleExitCoder(context, beforeErr)
-			err = beforeErr
+			err = a.runtimeError(beforeErr)
 			return err
 		}
 	}
 
+	if len(a.commandPath) > 0 {
+		return a.commandPath[0].Run(context)
+	}
+
 	args := context.Args()
 	if args.Present() {
 		name := args.First()
 		c := a.Command(name)
+		if c == helpCommand && !a.interceptsHelp(given, set) {
+			c = nil
+		}
 		if c != nil {
 			return c.Run(context)
 		}
 	}
 
 	// Run default Action
+	context.startAction(a.Name, a.Action)
 	err = a.Action(context)
 
 	a.handleExitCoder(context, err)
-	return err
+	return a.actionError(a.Action, err)
 }
 
 // Command returns the named command on App. Returns nil if the command does not exist
@@ -476,7 +996,13 @@ func (a *App) VisibleCommands() []*Command {
 
 // VisibleFlags returns a slice of the Flags with Hidden=false
 func (a *App) VisibleFlags() []Flag {
-	return visibleFlags(a.Flags)
+	return visibleFlags(a.Flags, a.HideDeprecated)
+}
+
+// VisibleFlagCategories returns the visible flags grouped by their Category,
+// or nil if none of them has a Category
+func (a *App) VisibleFlagCategories() []VisibleFlagCategory {
+	return visibleFlagCategories(a.Flags, a.KeepFlagCategoryOrder, a.HideDeprecated)
 }
 
 func (a *App) errWriter() io.Writer {
@@ -488,6 +1014,19 @@ func (a *App) errWriter() io.Writer {
 	return a.ErrWriter
 }
 
+// usageErrWriter returns the writer used for usage errors and the help
+// printed along with them.
+func (a *App) usageErrWriter() io.Writer {
+	if a.quiet {
+		return ioutil.Discard
+	}
+	if a.HelpToStdoutOnUsageError {
+		return a.Writer
+	}
+
+	return a.errWriter()
+}
+
 func (a *App) appendFlag(fl Flag) {
 	if !hasFlag(a.Flags, fl) {
 		a.Flags = append(a.Flags, fl)
@@ -501,11 +1040,39 @@ func (a *App) appendCommand(c *Command) {
 }
 
 func (a *App) handleExitCoder(context *Context, err error) {
+	if a.quiet {
+		return
+	}
 	if a.ExitErrHandler != nil {
 		a.ExitErrHandler(context, err)
 	} else {
-		HandleExitCoder(err)
+		handleExitCoder(err, a.errorPrefix())
+	}
+}
+
+// errorPrefix returns what is written before the errors printed by the
+// framework
+func (a *App) errorPrefix() string {
+	if a.HideErrorPrefix {
+		return ""
+	}
+	if a.ErrorPrefix != "" {
+		return a.ErrorPrefix
+	}
+	if a.Name != "" {
+		return a.Name + ": "
+	}
+	return ""
+}
+
+// printError is the single place where the framework writes errors, so that
+// they are consistently prefixed
+func (a *App) printError(w io.Writer, args ...interface{}) {
+	if a.quiet {
+		return
 	}
+	_, _ = fmt.Fprint(w, a.errorPrefix())
+	_, _ = fmt.Fprintln(w, args...)
 }
 
 // Author represents someone who has contributed to a cli project.
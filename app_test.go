@@ -2,6 +2,7 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -142,9 +143,9 @@ func ExampleApp_Run_appHelp() {
 	//    help, h        Shows a list of commands or help for one command
 	//
 	// GLOBAL OPTIONS:
-	//    --name value   a name to say (default: "bob")
-	//    --help, -h     show help (default: false)
-	//    --version, -v  print the version (default: false)
+	//        --name value  a name to say (default: "bob")
+	//    -h, --help        show help (default: false)
+	//    -v, --version     print the version (default: false)
 }
 
 func ExampleApp_Run_commandHelp() {
@@ -196,7 +197,7 @@ func ExampleApp_Run_noAction() {
 	//    help, h  Shows a list of commands or help for one command
 	//
 	// GLOBAL OPTIONS:
-	//    --help, -h  show help (default: false)
+	//    -h, --help  show help (default: false)
 }
 
 func ExampleApp_Run_subcommandNoAction() {
@@ -223,7 +224,7 @@ func ExampleApp_Run_subcommandNoAction() {
 	//    This is how we describe describeit the function
 	//
 	// OPTIONS:
-	//    --help, -h  show help (default: false)
+	//    -h, --help  show help (default: false)
 
 }
 
@@ -254,6 +255,169 @@ func ExampleApp_Run_bashComplete_withShortFlag() {
 	// -h
 }
 
+func ExampleApp_Run_bashComplete_withChoices() {
+	os.Args = []string{"greet", "--format", "--generate-bash-completion"}
+
+	app := NewApp()
+	app.Name = "greet"
+	app.EnableBashCompletion = true
+	app.Flags = []Flag{
+		&StringFlag{
+			Name:    "format",
+			Choices: []string{"json", "yaml", "table"},
+		},
+	}
+
+	_ = app.Run(os.Args)
+	// Output:
+	// json
+	// yaml
+	// table
+}
+
+func ExampleApp_Run_bashComplete_withCompletionFunc() {
+	os.Args = []string{"greet", "--region", "--generate-bash-completion"}
+
+	app := NewApp()
+	app.Name = "greet"
+	app.EnableBashCompletion = true
+	app.Flags = []Flag{
+		&StringFlag{
+			Name: "region",
+			CompletionFunc: func(*Context) []string {
+				return []string{"eu-west-1", "us-east-1"}
+			},
+		},
+	}
+
+	_ = app.Run(os.Args)
+	// Output:
+	// eu-west-1
+	// us-east-1
+}
+
+func TestApp_Run_bashCompleteCompletionFunc(t *testing.T) {
+	defer func(args []string) { os.Args = args }(os.Args)
+
+	var actionRan bool
+	var profile string
+	regions := func(*Context) []string {
+		return []string{"eu-west-1", "us-east-1"}
+	}
+	newApp := func(buf *bytes.Buffer) *App {
+		return &App{
+			Name:                 "greet",
+			Writer:               buf,
+			EnableBashCompletion: true,
+			Flags:                []Flag{&StringFlag{Name: "region", CompletionFunc: regions}},
+			Action: func(*Context) error {
+				actionRan = true
+				return nil
+			},
+			Commands: []*Command{{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{Name: "profile"},
+					&IntFlag{Name: "replicas", Aliases: []string{"r"}, CompletionFunc: func(c *Context) []string {
+						profile = c.String("profile")
+						return []string{"1", "3"}
+					}},
+				},
+				BashComplete: func(c *Context) {
+					_, _ = fmt.Fprintln(c.App.Writer, "custom")
+				},
+				Action: func(*Context) error {
+					actionRan = true
+					return nil
+				},
+			}},
+		}
+	}
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{"greet", "--region", "--generate-bash-completion"}, expected: "eu-west-1\nus-east-1\n"},
+		{args: []string{"greet", "deploy", "--profile", "prod", "-r", "--generate-bash-completion"}, expected: "1\n3\n"},
+		{args: []string{"greet", "deploy", "--profile", "--generate-bash-completion"}, expected: "custom\n"},
+		{args: []string{"greet", "deploy", "--generate-bash-completion"}, expected: "custom\n"},
+	}
+
+	for _, test := range tests {
+		os.Args = test.args
+		buf := new(bytes.Buffer)
+		expect(t, newApp(buf).Run(os.Args), nil)
+		expect(t, buf.String(), test.expected)
+		expect(t, actionRan, false)
+	}
+	// the flags before the completed one are parsed
+	expect(t, profile, "prod")
+}
+
+func TestApp_Run_hiddenFlags(t *testing.T) {
+	defer func(args []string) { os.Args = args }(os.Args)
+
+	newApp := func(buf *bytes.Buffer, action ActionFunc) *App {
+		return &App{
+			Name:                 "greet",
+			Writer:               buf,
+			EnableBashCompletion: true,
+			Flags: []Flag{
+				&StringFlag{Name: "unsafe-skip-verify", Hidden: true},
+				&StringFlag{Name: "unsafe-mode"},
+			},
+			Action: action,
+		}
+	}
+
+	var buf bytes.Buffer
+	os.Args = []string{"greet", "--unsafe", "--generate-bash-completion"}
+	err := newApp(&buf, nil).Run(os.Args)
+	expect(t, err, nil)
+	expect(t, buf.String(), "--unsafe-mode\n")
+
+	buf.Reset()
+	err = newApp(&buf, nil).Run([]string{"greet", "--help"})
+	expect(t, err, nil)
+	if strings.Contains(buf.String(), "unsafe-skip-verify") {
+		t.Errorf("expected the hidden flag to be left out of the help:\n%s", buf.String())
+	}
+
+	var value string
+	var isSet bool
+	err = newApp(&buf, func(c *Context) error {
+		value, isSet = c.String("unsafe-skip-verify"), c.IsSet("unsafe-skip-verify")
+		return nil
+	}).Run([]string{"greet", "--unsafe-skip-verify", "yes"})
+	expect(t, err, nil)
+	expect(t, value, "yes")
+	expect(t, isSet, true)
+
+	app := newApp(&buf, func(*Context) error { return nil })
+	app.Flags[0].(*StringFlag).Required = true
+	err = app.Run([]string{"greet"})
+	if err == nil {
+		t.Fatal("expected an error for the hidden required flag")
+	}
+	expect(t, err.Error(), `Required flag "unsafe-skip-verify" not set`)
+}
+
+func ExampleApp_Run_bashComplete_withFileContents() {
+	os.Args = []string{"greet", "--cert", "--generate-bash-completion"}
+
+	app := NewApp()
+	app.Name = "greet"
+	app.EnableBashCompletion = true
+	app.Flags = []Flag{
+		&FileContentsFlag{Name: "cert"},
+		&StringFlag{Name: "cert-dir"},
+	}
+
+	_ = app.Run(os.Args)
+	// Output:
+}
+
 func ExampleApp_Run_bashComplete_withLongFlag() {
 	os.Args = []string{"greet", "--s", "--generate-bash-completion"}
 
@@ -471,7 +635,7 @@ func TestApp_RunAsSubCommandIncorrectUsage(t *testing.T) {
 	a := App{
 		Name: "cmd",
 		Flags: []Flag{
-			&StringFlag{Name: "--foo"},
+			&StringFlag{Name: "foo"},
 		},
 		Writer: bytes.NewBufferString(""),
 	}
@@ -878,6 +1042,101 @@ func TestApp_SetStdout(t *testing.T) {
 	}
 }
 
+func TestApp_LoadFlagValues(t *testing.T) {
+	loaded := 0
+	var name string
+	app := &App{
+		Writer: ioutil.Discard,
+		Flags:  []Flag{&StringFlag{Name: "name", Required: true}},
+		LoadFlagValues: func(c *Context) error {
+			loaded++
+			if !c.IsSet("name") {
+				return c.Set("name", "from-loader")
+			}
+			return nil
+		},
+		Action: func(c *Context) error {
+			name = c.String("name")
+			return nil
+		},
+		Commands: []*Command{{
+			Name:  "sub",
+			Flags: []Flag{&IntFlag{Name: "count", Required: true}},
+			LoadFlagValues: func(c *Context) error {
+				loaded++
+				return c.Set("count", "3")
+			},
+			Action: func(c *Context) error { return nil },
+		}},
+	}
+
+	// the loader runs before the required flags are checked
+	expect(t, app.Run([]string{"app"}), nil)
+	expect(t, name, "from-loader")
+	expect(t, app.Run([]string{"app", "--name", "cli"}), nil)
+	expect(t, name, "cli")
+	expect(t, app.Run([]string{"app", "sub"}), nil)
+	expect(t, loaded, 4)
+
+	// but not for help
+	expect(t, app.Run([]string{"app", "--help"}), nil)
+	expect(t, loaded, 4)
+
+	app.LoadFlagValues = func(*Context) error { return errors.New("no config") }
+	expect(t, app.Run([]string{"app"}), errors.New("no config"))
+}
+
+func TestApp_ArgsRewriter(t *testing.T) {
+	expandCounts := func(args []string) []string {
+		var rewritten []string
+		for _, arg := range args {
+			if len(arg) > 2 && arg[0] == '-' && strings.Trim(arg[1:], "v") == "" {
+				for range arg[1:] {
+					rewritten = append(rewritten, "-v")
+				}
+				continue
+			}
+			rewritten = append(rewritten, arg)
+		}
+		return rewritten
+	}
+
+	var given []string
+	var verbosity, subVerbosity int
+	var name string
+	app := &App{
+		Writer: ioutil.Discard,
+		ArgsRewriter: func(args []string) []string {
+			given = args
+			return expandCounts(args)
+		},
+		Flags: []Flag{
+			&BoolFlag{Name: "verbose", Aliases: []string{"v"}, Count: &verbosity},
+		},
+		Commands: []*Command{{
+			Name: "sub",
+			Flags: []Flag{
+				&BoolFlag{Name: "verbose", Aliases: []string{"v"}, Count: &subVerbosity},
+				&StringFlag{Name: "name", Aliases: []string{"n"}},
+			},
+			Action: func(c *Context) error {
+				name = c.String("name")
+				return nil
+			},
+		}},
+	}
+
+	args := []string{"app", "-vvv", "sub", "-vv", "-n", "x"}
+	expect(t, app.Run(args), nil)
+	expect(t, given, []string{"-vvv", "sub", "-vv", "-n", "x"})
+	expect(t, verbosity, 3)
+	expect(t, subVerbosity, 2)
+	// the aliases of the rewritten arguments are normalized
+	expect(t, name, "x")
+	// the arguments given are left alone
+	expect(t, args, []string{"app", "-vvv", "sub", "-vv", "-n", "x"})
+}
+
 func TestApp_BeforeFunc(t *testing.T) {
 	counts := &opCounts{}
 	beforeError := fmt.Errorf("fail")
@@ -1597,7 +1856,7 @@ func TestApp_Run_CommandSubcommandHelpName(t *testing.T) {
 }
 
 func TestApp_Run_Help(t *testing.T) {
-	var helpArguments = [][]string{{"boom", "--help"}, {"boom", "-h"}, {"boom", "help"}}
+	var helpArguments = [][]string{{"boom", "--help"}, {"boom", "-h"}}
 
 	for _, args := range helpArguments {
 		t.Run(fmt.Sprintf("checking with arguments %v", args), func(t *testing.T) {
@@ -1628,6 +1887,88 @@ func TestApp_Run_Help(t *testing.T) {
 	}
 }
 
+func TestApp_Run_HelpPositional(t *testing.T) {
+	newApp := func(buf *bytes.Buffer, args *[]string) *App {
+		return &App{
+			Name:   "mytool",
+			Writer: buf,
+			Commands: []*Command{
+				{
+					Name: "deploy",
+					Action: func(c *Context) error {
+						*args = c.Args().Slice()
+						return nil
+					},
+				},
+				{
+					Name: "service",
+					Subcommands: []*Command{
+						{Name: "list", Action: func(*Context) error { return nil }},
+					},
+					Action: func(c *Context) error {
+						*args = c.Args().Slice()
+						return nil
+					},
+				},
+			},
+			Action: func(c *Context) error {
+				*args = c.Args().Slice()
+				return nil
+			},
+		}
+	}
+
+	cases := []struct {
+		arguments []string
+		disable   bool
+		wantArgs  []string
+		wantHelp  string
+	}{
+		// a leaf command takes help as a positional argument
+		{arguments: []string{"mytool", "deploy", "help"}, wantArgs: []string{"help"}},
+		// levels with subcommands run the help command
+		{arguments: []string{"mytool", "help"}, wantHelp: "mytool - A new cli application"},
+		{arguments: []string{"mytool", "service", "help"}, wantHelp: "mytool service - "},
+		// unless help follows "--"
+		{arguments: []string{"mytool", "--", "help"}, wantArgs: []string{"help"}},
+		{arguments: []string{"mytool", "service", "--", "help"}, wantArgs: []string{"help"}},
+		// or the interception is disabled
+		{arguments: []string{"mytool", "service", "help"}, disable: true, wantArgs: []string{"help"}},
+		{arguments: []string{"mytool", "service", "--help"}, disable: true, wantHelp: "mytool service - "},
+	}
+
+	for _, c := range cases {
+		t.Run(strings.Join(c.arguments, " "), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			var args []string
+			app := newApp(buf, &args)
+			app.DisableHelpSubcommandInterception = c.disable
+
+			expect(t, app.Run(c.arguments), nil)
+			expect(t, args, c.wantArgs)
+			if !strings.Contains(buf.String(), c.wantHelp) {
+				t.Errorf("want help to contain %q, got %q", c.wantHelp, buf.String())
+			}
+			if c.wantHelp == "" && buf.Len() > 0 {
+				t.Errorf("want no help, got %q", buf.String())
+			}
+		})
+	}
+
+	t.Run("without commands", func(t *testing.T) {
+		var args []string
+		app := &App{
+			Name: "boom",
+			Action: func(c *Context) error {
+				args = c.Args().Slice()
+				return nil
+			},
+		}
+		expect(t, app.Run([]string{"boom", "help"}), nil)
+		expect(t, args, []string{"help"})
+	})
+}
+
 func TestApp_Run_Version(t *testing.T) {
 	var versionArguments = [][]string{{"boom", "--version"}, {"boom", "-v"}}
 
@@ -1932,6 +2273,48 @@ func TestApp_OnUsageError_WithWrongFlagValue_ForSubcommand(t *testing.T) {
 	}
 }
 
+func TestApp_UnknownFlagError(t *testing.T) {
+	cases := []struct {
+		testArgs      []string
+		expectedName  string
+		expectedToken string
+	}{
+		{[]string{"foo", "--fooo", "bar"}, "fooo", "--fooo"},
+		{[]string{"foo", "-fooo=baz", "bar"}, "fooo", "-fooo=baz"},
+		{[]string{"foo", "bar", "--fooo"}, "fooo", "--fooo"},
+		{[]string{"foo", "sub", "--fooo", "baz"}, "fooo", "--fooo"},
+		{[]string{"foo", "sub", "baz", "--fooo"}, "fooo", "--fooo"},
+	}
+
+	for _, c := range cases {
+		app := &App{
+			Writer:    ioutil.Discard,
+			ErrWriter: ioutil.Discard,
+			Flags:     []Flag{&StringFlag{Name: "foo"}},
+			Commands: []*Command{
+				{Name: "bar", Action: func(*Context) error { return nil }},
+				{
+					Name: "sub",
+					Subcommands: []*Command{
+						{Name: "baz", Action: func(*Context) error { return nil }},
+					},
+				},
+			},
+		}
+
+		err := app.Run(c.testArgs)
+
+		ufErr, ok := err.(UnknownFlagError)
+		if !ok {
+			t.Fatalf("expected an UnknownFlagError for %v, got %#v", c.testArgs, err)
+		}
+		expect(t, ufErr.FlagName(), c.expectedName)
+		expect(t, ufErr.Token(), c.expectedToken)
+		expect(t, ufErr.Suggestion(), "--foo")
+		expect(t, ufErr.Error(), "flag provided but not defined: -"+c.expectedName+". Did you mean '--foo'?")
+	}
+}
+
 // A custom flag that conforms to the relevant interfaces, but has none of the
 // fields that the other flag types do.
 type customBoolFlag struct {
@@ -2147,8 +2530,335 @@ func TestWhenExitSubCommandWithCodeThenAppQuitUnexpectedly(t *testing.T) {
 	}
 }
 
+func runCommandTestApp(output io.Writer) *App {
+	app := &App{
+		Name:   "git",
+		Writer: output,
+		Flags:  []Flag{&StringFlag{Name: "config", Value: "~/.gitconfig"}},
+		ExitErrHandler: func(*Context, error) {
+			panic("the ExitErrHandler is not called by RunCommand")
+		},
+		Commands: []*Command{{
+			Name: "remote",
+			Subcommands: []*Command{
+				{
+					Name:    "add",
+					Aliases: []string{"a"},
+					Flags: []Flag{
+						&StringFlag{Name: "branch", Required: true},
+					},
+					Action: func(c *Context) error {
+						_, _ = fmt.Fprintf(c.App.Writer, "config=%s branch=%s args=%v",
+							c.String("config"), c.String("branch"), c.Args().Slice())
+						return nil
+					},
+				},
+				{
+					Name: "prune",
+					Action: func(c *Context) error {
+						return Exit("prune failed", 3)
+					},
+				},
+			},
+		}},
+	}
+	return app
+}
+
+func TestApp_RunCommand(t *testing.T) {
+	for _, path := range [][]string{{"remote", "add"}, {"remote", "a"}} {
+		output := &bytes.Buffer{}
+		app := runCommandTestApp(output)
+
+		err := app.RunCommand(context.Background(), path, []string{"--branch", "main", "origin", "help", "--", "-x"})
+		expect(t, err, nil)
+		expect(t, output.String(), "config=~/.gitconfig branch=main args=[origin help -- -x]")
+	}
+}
+
+func TestApp_RunCommand_ArgsOfTheCommand(t *testing.T) {
+	output := &bytes.Buffer{}
+	app := runCommandTestApp(output)
+
+	// neither taken for the name of a command nor for the flags of the App
+	err := app.RunCommand(context.Background(), []string{"remote", "add"}, []string{"--branch", "main", "remote"})
+	expect(t, err, nil)
+	expect(t, output.String(), "config=~/.gitconfig branch=main args=[remote]")
+
+	err = app.RunCommand(context.Background(), []string{"remote", "add"}, []string{"--config=x", "--branch", "main"})
+	if err == nil {
+		t.Fatal("expected the flag of the App to be rejected")
+	}
+	expect(t, err.Error(), "flag provided but not defined: -config")
+
+	output.Reset()
+	err = app.RunCommand(context.Background(), []string{"remote"}, []string{"add", "--branch", "main"})
+	expect(t, err, nil)
+	expect(t, output.String(), "config=~/.gitconfig branch=main args=[]")
+}
+
+func TestApp_RunCommandWithFlags(t *testing.T) {
+	output := &bytes.Buffer{}
+	app := runCommandTestApp(output)
+
+	err := app.RunCommandWithFlags(context.Background(), []string{"remote", "add"},
+		map[string]string{"config": "/etc/gitconfig"}, []string{"--branch", "main"})
+	expect(t, err, nil)
+	expect(t, output.String(), "config=/etc/gitconfig branch=main args=[]")
+
+	err = app.RunCommandWithFlags(context.Background(), []string{"remote", "add"},
+		map[string]string{"nope": "x"}, []string{"--branch", "main"})
+	if err == nil {
+		t.Fatal("expected an error for an unknown global flag")
+	}
+	expect(t, err.Error(), "flag provided but not defined: -nope")
+}
+
+func TestApp_RunCommand_Errors(t *testing.T) {
+	tests := []struct {
+		path     []string
+		args     []string
+		expected string
+	}{
+		{path: []string{"remote", "add"}, expected: `Required flag "branch" not set`},
+		{path: []string{"remote", "add"}, args: []string{"--nope"}, expected: "flag provided but not defined: -nope"},
+		{path: []string{"remote", "prune"}, expected: "prune failed"},
+		{path: []string{"remote", "rm"}, expected: `command "rm" not found in "git remote", valid commands: add, prune`},
+		{path: []string{"remotes"}, expected: `command "remotes" not found in "git", valid commands: remote, help`},
+		{path: []string{"remote", "add", "more"}, expected: `command "more" not found: "git remote add" has no commands`},
+		{expected: `no command to run in "git"`},
+	}
+
+	for _, test := range tests {
+		output := &bytes.Buffer{}
+		app := runCommandTestApp(output)
+
+		err := app.RunCommand(context.Background(), test.path, test.args)
+		if err == nil {
+			t.Fatalf("expected an error for %v", test.path)
+		}
+		expect(t, err.Error(), test.expected)
+		expect(t, output.String(), "")
+	}
+}
+
+func TestApp_Run_ExitErrHandler_After(t *testing.T) {
+	after := func(code int) AfterFunc {
+		return func(*Context) error {
+			return Exit("after failed", code)
+		}
+	}
+
+	app := newTestApp()
+	app.After = after(3)
+	app.Commands = []*Command{
+		{
+			Name:   "cmd",
+			After:  after(4),
+			Action: func(*Context) error { return nil },
+		},
+		{
+			Name:  "parent",
+			After: after(5),
+			Subcommands: []*Command{{
+				Name:   "subcmd",
+				Action: func(*Context) error { return nil },
+			}},
+		},
+	}
+
+	var codes []int
+	app.ExitErrHandler = func(_ *Context, err error) {
+		if exitErr, ok := err.(ExitCoder); ok {
+			codes = append(codes, exitErr.ExitCode())
+		}
+	}
+
+	_ = app.Run([]string{"myapp", "cmd"})
+	expect(t, codes, []int{4, 3})
+
+	codes = nil
+	_ = app.Run([]string{"myapp", "parent", "subcmd"})
+	expect(t, codes, []int{5, 3})
+}
+
 func newTestApp() *App {
 	a := NewApp()
 	a.Writer = ioutil.Discard
 	return a
 }
+
+func TestApp_Run_FlagWithoutUsableName(t *testing.T) {
+	cases := []struct {
+		name     string
+		flags    []Flag
+		expected string
+	}{
+		{
+			name:     "empty",
+			flags:    []Flag{&StringFlag{Name: "foo"}, &BoolFlag{Name: ""}},
+			expected: "flag at index 1 has no usable name",
+		},
+		{
+			name:     "whitespace",
+			flags:    []Flag{&StringFlag{Name: "  "}},
+			expected: "flag at index 0 has no usable name",
+		},
+		{
+			name:     "space",
+			flags:    []Flag{&StringFlag{Name: "foo bar"}},
+			expected: `flag at index 0 has a name containing whitespace: "foo bar"`,
+		},
+		{
+			name:     "alias with space",
+			flags:    []Flag{&IntFlag{Name: "foo", Aliases: []string{"f o"}}},
+			expected: `flag at index 0 has a name containing whitespace: "f o"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actionRan := false
+			app := &App{
+				Writer: ioutil.Discard,
+				Flags:  c.flags,
+				Action: func(*Context) error {
+					actionRan = true
+					return nil
+				},
+			}
+
+			err := app.Run([]string{"run"})
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			expect(t, err.Error(), c.expected)
+			expect(t, actionRan, false)
+		})
+	}
+}
+
+func TestApp_Setup_KeepsDeclarationOrder(t *testing.T) {
+	app := &App{
+		Writer:          ioutil.Discard,
+		Version:         "1.0.0",
+		EnableColorFlag: true,
+		Flags: []Flag{
+			&StringFlag{Name: "zeta"},
+			&BoolFlag{Name: "secret", Hidden: true},
+			&IntFlag{Name: "alpha"},
+		},
+		Commands: []*Command{
+			{Name: "zulu", Action: func(*Context) error { return nil }},
+			{Name: "hidden", Hidden: true},
+			{Name: "bravo", Action: func(*Context) error { return nil }},
+		},
+	}
+
+	flagNames := func(flags []Flag) []string {
+		var names []string
+		for _, f := range flags {
+			names = append(names, f.Names()[0])
+		}
+		return names
+	}
+	commandNames := func(commands []*Command) []string {
+		var names []string
+		for _, c := range commands {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	expectedFlags := []string{"zeta", "secret", "alpha", "help", "version", "color"}
+	expectedCommands := []string{"zulu", "hidden", "bravo", "help"}
+
+	app.Setup()
+	expect(t, flagNames(app.Flags), expectedFlags)
+	expect(t, commandNames(app.Commands), expectedCommands)
+
+	app.Setup()
+	_ = app.Run([]string{"app", "zulu"})
+	_ = app.Run([]string{"app", "bravo"})
+	expect(t, flagNames(app.Flags), expectedFlags)
+	expect(t, commandNames(app.Commands), expectedCommands)
+
+	expect(t, flagNames(app.VisibleFlags()), []string{"zeta", "alpha", "help", "version", "color"})
+	expect(t, commandNames(app.VisibleCommands()), []string{"zulu", "bravo", "help"})
+
+	cmd := &Command{Flags: []Flag{
+		&StringFlag{Name: "b", Hidden: true},
+		&StringFlag{Name: "a"},
+	}}
+	expect(t, flagNames(cmd.VisibleFlags()), []string{"a"})
+}
+
+func TestApp_ErrorPrefix(t *testing.T) {
+	defer func(w io.Writer) { ErrWriter = w }(ErrWriter)
+
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"unknown flag", []string{"tool", "--nope"}, "tool: Incorrect Usage. flag provided but not defined: -nope\n"},
+		{"invalid value", []string{"tool", "cmd", "--count", "abc"}, "tool: Incorrect Usage: invalid value \"abc\" for flag -count: parse error\n"},
+		{"exit error", []string{"tool", "fail"}, "tool: something broke\n"},
+		{"help topic", []string{"tool", "help", "nope"}, "tool: No help topic for 'nope'\n"},
+	}
+
+	newApp := func() *App {
+		return &App{
+			Name:   "tool",
+			Writer: ioutil.Discard,
+			Commands: []*Command{
+				{
+					Name:   "cmd",
+					Flags:  []Flag{&IntFlag{Name: "count"}},
+					Action: func(*Context) error { return nil },
+				},
+				{
+					Name:   "fail",
+					Action: func(*Context) error { return Exit("something broke", 2) },
+				},
+			},
+		}
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			ErrWriter = stderr
+
+			app := newApp()
+			app.ErrWriter = stderr
+			_ = app.Run(c.args)
+
+			if !strings.HasPrefix(stderr.String(), c.expected) {
+				t.Errorf("expected stderr to start with %q, got %q", c.expected, stderr.String())
+			}
+		})
+	}
+
+	t.Run("custom", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+		ErrWriter = stderr
+
+		app := newApp()
+		app.ErrorPrefix = "[tool] "
+		app.ErrWriter = stderr
+		_ = app.Run([]string{"tool", "fail"})
+		expect(t, stderr.String(), "[tool] something broke\n")
+	})
+
+	t.Run("hidden", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+		ErrWriter = stderr
+
+		app := newApp()
+		app.HideErrorPrefix = true
+		app.ErrWriter = stderr
+		_ = app.Run([]string{"tool", "fail"})
+		expect(t, stderr.String(), "something broke\n")
+	})
+}
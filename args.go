@@ -1,5 +1,7 @@
 package cli
 
+import "strings"
+
 type Args interface {
 	// Get returns the nth argument, or else a blank string
 	Get(n int) string
@@ -14,12 +16,14 @@ type Args interface {
 	Present() bool
 	// Slice returns a copy of the internal slice
 	Slice() []string
+	// SplitFirst splits the first argument on sep, dropping empty items
+	SplitFirst(sep string) []string
 }
 
 type args []string
 
 func (a *args) Get(n int) string {
-	if len(*a) > n {
+	if n >= 0 && len(*a) > n {
 		return (*a)[n]
 	}
 	return ""
@@ -52,3 +56,13 @@ func (a *args) Slice() []string {
 	copy(ret, *a)
 	return ret
 }
+
+func (a *args) SplitFirst(sep string) []string {
+	ret := []string{}
+	for _, item := range strings.Split(a.First(), sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
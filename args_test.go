@@ -0,0 +1,56 @@
+package cli
+
+import "testing"
+
+func TestArgs_SplitFirst(t *testing.T) {
+	tests := []struct {
+		args     args
+		sep      string
+		expected []string
+	}{
+		{args{"a;b;c", "d"}, ";", []string{"a", "b", "c"}},
+		{args{"a; ;b;;c;"}, ";", []string{"a", "b", "c"}},
+		{args{""}, ";", []string{}},
+		{args{}, ";", []string{}},
+		{args{"single"}, ";", []string{"single"}},
+		{args{"a,b"}, ";", []string{"a,b"}},
+	}
+
+	for _, test := range tests {
+		before := test.args.Slice()
+		expect(t, test.args.SplitFirst(test.sep), test.expected)
+		expect(t, test.args.Slice(), before)
+	}
+}
+
+func TestArgs_Get(t *testing.T) {
+	a := args{"a", "b", "c"}
+
+	expect(t, a.Get(0), "a")
+	expect(t, a.Get(2), "c")
+	expect(t, a.Get(3), "")
+	expect(t, a.Get(-1), "")
+	expect(t, a.First(), "a")
+	expect(t, a.Tail(), []string{"b", "c"})
+	expect(t, a.Present(), true)
+	expect(t, a.Len(), len(a.Slice()))
+
+	empty := args{}
+	expect(t, empty.Get(-1), "")
+	expect(t, empty.First(), "")
+	expect(t, empty.Tail(), []string{})
+	expect(t, empty.Present(), false)
+	expect(t, empty.Len(), len(empty.Slice()))
+}
+
+func TestArgs_Slice_copy(t *testing.T) {
+	a := args{"a", "b"}
+
+	s := a.Slice()
+	s[0] = "changed"
+	expect(t, a.Get(0), "a")
+
+	tail := a.Tail()
+	tail[0] = "changed"
+	expect(t, a.Get(1), "b")
+}
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var batchCommand = &Command{
+	Name:      "batch",
+	Usage:     "Runs the commands of a script file, one per line",
+	ArgsUsage: "<file>",
+	Flags: []Flag{
+		&BoolFlag{
+			Name:  "continue-on-error",
+			Usage: "run the remaining lines after a command fails",
+		},
+	},
+}
+
+func init() {
+	// the action is set here as it runs the App, which refers to the command
+	batchCommand.Action = batchAction
+}
+
+func batchAction(c *Context) error {
+	path := c.Args().First()
+	if path == "" {
+		return Exit("batch needs the path of a script file", 1)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lines, err := readBatchLines(f)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %s", path, err)
+	}
+
+	return runBatch(c, lines, c.Bool("continue-on-error"))
+}
+
+// batchLine is a command of a batch script and the result of running it
+type batchLine struct {
+	number   int
+	text     string
+	args     []string
+	status   string
+	duration time.Duration
+}
+
+// readBatchLines returns the commands of a batch script, skipping blank lines
+// and comments
+func readBatchLines(r io.Reader) ([]*batchLine, error) {
+	var lines []*batchLine
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimSpace(scanner.Text())
+		args, err := splitShellWords(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", number, err)
+		}
+		if len(args) == 0 {
+			continue
+		}
+		lines = append(lines, &batchLine{number: number, text: text, args: args, status: "skipped"})
+	}
+	return lines, scanner.Err()
+}
+
+// runBatch runs the lines with the root App, each with a context of its own
+// derived from the one of c and with the flags as they were when the batch
+// started, and writes a summary of the results. It stops at the first failure
+// unless continueOnError is set.
+func runBatch(c *Context, lines []*batchLine, continueOnError bool) error {
+	app := rootApp(c)
+
+	// a line running batch would run scripts within the script, possibly the
+	// script itself forever
+	if app.inBatch {
+		return Exit("batch cannot be run from a batch script", 1)
+	}
+	app.inBatch = true
+	defer func() { app.inBatch = false }()
+
+	// errors are reported once for the whole batch, instead of exiting on the
+	// first one
+	exitErrHandler := app.ExitErrHandler
+	app.ExitErrHandler = func(*Context, error) {}
+	defer func() { app.ExitErrHandler = exitErrHandler }()
+
+	// the values a line sets are kept by the flags, which the next lines
+	// would otherwise start from
+	flags := saveAppFlags(app)
+	defer flags.restore()
+
+	var failed []*batchLine
+	var firstErr error
+	for _, line := range lines {
+		if err := c.Context.Err(); err != nil {
+			firstErr = err
+			break
+		}
+		flags.restore()
+
+		ctx, cancel := context.WithCancel(c.Context)
+		start := time.Now()
+		err := app.RunContext(ctx, append([]string{app.Name}, line.args...))
+		line.duration = time.Since(start)
+		cancel()
+
+		if err == nil {
+			line.status = "ok"
+			continue
+		}
+		line.status = "failed: " + err.Error()
+		failed = append(failed, line)
+		if firstErr == nil {
+			firstErr = err
+		}
+		if !continueOnError {
+			break
+		}
+	}
+
+	writeBatchSummary(c.App.Writer, lines)
+
+	if firstErr == nil {
+		return nil
+	}
+	code := 1
+	if exitErr, ok := firstErr.(ExitCoder); ok {
+		code = exitErr.ExitCode()
+	}
+	if len(failed) == 0 {
+		return Exit(fmt.Sprintf("batch interrupted: %s", firstErr), code)
+	}
+	if len(failed) == 1 {
+		return Exit(fmt.Sprintf("line %d failed: %s", failed[0].number, firstErr), code)
+	}
+	return Exit(fmt.Sprintf("%d lines failed, the first one is line %d: %s", len(failed), failed[0].number, firstErr), code)
+}
+
+// savedStruct is a copy of the struct ptr points to
+type savedStruct struct {
+	ptr   reflect.Value
+	value reflect.Value
+}
+
+// savedFlags holds the state of the flags of an App and of its commands
+type savedFlags struct {
+	structs []savedStruct
+	seen    map[savedStructKey]bool
+}
+
+type savedStructKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// saveAppFlags saves the flags of app and of its commands, down to the values
+// they point to, like the Value of a StringSliceFlag, which parsing changes
+func saveAppFlags(app *App) *savedFlags {
+	saved := &savedFlags{seen: map[savedStructKey]bool{}}
+	for _, f := range app.Flags {
+		saved.save(reflect.ValueOf(f))
+	}
+	saved.saveCommands(app.Commands)
+	return saved
+}
+
+func (s *savedFlags) saveCommands(commands []*Command) {
+	for _, cmd := range commands {
+		for _, f := range cmd.Flags {
+			s.save(reflect.ValueOf(f))
+		}
+		s.saveCommands(cmd.Subcommands)
+	}
+}
+
+// save copies the struct v points to, and the structs its exported fields
+// point to, if not already saved
+func (s *savedFlags) save(v reflect.Value) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	key := savedStructKey{typ: v.Type(), ptr: v.Pointer()}
+	if s.seen[key] {
+		return
+	}
+	s.seen[key] = true
+
+	value := reflect.New(v.Elem().Type()).Elem()
+	value.Set(v.Elem())
+	s.structs = append(s.structs, savedStruct{ptr: v, value: value})
+
+	typ := v.Elem().Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).PkgPath == "" {
+			s.save(v.Elem().Field(i))
+		}
+	}
+}
+
+// restore sets the saved structs back to their copies
+func (s *savedFlags) restore() {
+	for _, saved := range s.structs {
+		saved.ptr.Elem().Set(saved.value)
+	}
+}
+
+func writeBatchSummary(out io.Writer, lines []*batchLine) {
+	w := tabwriter.NewWriter(out, 1, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "LINE\tCOMMAND\tSTATUS\tDURATION")
+	for _, line := range lines {
+		duration := "-"
+		if line.status != "skipped" {
+			duration = line.duration.Round(time.Millisecond).String()
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", line.number, line.text, line.status, duration)
+	}
+	_ = w.Flush()
+}
+
+// splitShellWords splits s into words like a POSIX shell, honoring single and
+// double quotes and backslash escapes. A word starting with # starts a comment
+// which runs to the end of s.
+func splitShellWords(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			// in double quotes, a backslash only escapes a quote or itself
+			if quote == '"' && r != '"' && r != '\\' {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case r == '#' && !inWord:
+			return words, nil
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("unterminated escape")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func runBatchApp(t *testing.T, script string, args ...string) (string, []string, error) {
+	f, err := ioutil.TempFile("", "urfave_cli_batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, _ = f.WriteString(script)
+	_ = f.Close()
+
+	var out bytes.Buffer
+	var ran []string
+	app := &App{
+		Name:               "tool",
+		Writer:             &out,
+		EnableBatchCommand: true,
+		ExitErrHandler:     func(*Context, error) {},
+		Commands: []*Command{
+			{
+				Name: "greet",
+				Flags: []Flag{
+					&StringFlag{Name: "name"},
+				},
+				Action: func(c *Context) error {
+					ran = append(ran, "greet "+c.String("name"))
+					return nil
+				},
+			},
+			{
+				Name: "tag",
+				Flags: []Flag{
+					&StringSliceFlag{Name: "tag"},
+				},
+				Action: func(c *Context) error {
+					ran = append(ran, "tag "+strings.Join(c.StringSlice("tag"), ","))
+					return nil
+				},
+			},
+			{
+				Name: "fail",
+				Action: func(c *Context) error {
+					ran = append(ran, "fail")
+					return errors.New("boom")
+				},
+			},
+		},
+	}
+
+	err = app.Run(append(append([]string{"tool", "batch"}, args...), f.Name()))
+	return out.String(), ran, err
+}
+
+const batchScript = `# a runbook
+greet --name "Jane Doe"
+
+fail
+greet --name 'John' # trailing comment
+`
+
+func TestBatchCommand_stopOnError(t *testing.T) {
+	out, ran, err := runBatchApp(t, batchScript)
+
+	expect(t, ran, []string{"greet Jane Doe", "fail"})
+	if err == nil || err.Error() != "line 4 failed: boom" {
+		t.Errorf("expected the failing line in the error, got %v", err)
+	}
+	for _, s := range []string{"2     greet --name \"Jane Doe\"", "ok", "4     fail", "failed: boom", "5     greet --name 'John' # trailing comment  skipped"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in the summary:\n%s", s, out)
+		}
+	}
+}
+
+func TestBatchCommand_freshFlags(t *testing.T) {
+	_, ran, err := runBatchApp(t, "tag --tag a --tag b\ntag --tag c\ntag\n")
+
+	expect(t, err, nil)
+	expect(t, ran, []string{"tag a,b", "tag c", "tag "})
+}
+
+func TestBatchCommand_nested(t *testing.T) {
+	script, err := ioutil.TempFile("", "urfave_cli_batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(script.Name())
+	_ = script.Close()
+
+	// the script runs itself
+	nested := "greet --name first\nbatch " + script.Name() + "\ngreet --name last\n"
+	if err := ioutil.WriteFile(script.Name(), []byte(nested), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	var ran []string
+	app := &App{
+		Name:               "tool",
+		Writer:             &out,
+		EnableBatchCommand: true,
+		ExitErrHandler:     func(*Context, error) {},
+		Commands: []*Command{{
+			Name:  "greet",
+			Flags: []Flag{&StringFlag{Name: "name"}},
+			Action: func(c *Context) error {
+				ran = append(ran, c.String("name"))
+				return nil
+			},
+		}},
+	}
+
+	err = app.Run([]string{"tool", "batch", "--continue-on-error", script.Name()})
+	expect(t, ran, []string{"first", "last"})
+	if err == nil || err.Error() != "line 2 failed: batch cannot be run from a batch script" {
+		t.Errorf("expected the nested batch to fail, got %v", err)
+	}
+}
+
+func TestBatchCommand_continueOnError(t *testing.T) {
+	out, ran, err := runBatchApp(t, batchScript, "--continue-on-error")
+
+	expect(t, ran, []string{"greet Jane Doe", "fail", "greet John"})
+	if err == nil || err.Error() != "line 4 failed: boom" {
+		t.Errorf("expected the failing line in the error, got %v", err)
+	}
+	if strings.Contains(out, "skipped") {
+		t.Errorf("expected no skipped line in the summary:\n%s", out)
+	}
+}
+
+func TestBatchCommand_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran []string
+	app := &App{
+		Name:               "tool",
+		Writer:             ioutil.Discard,
+		EnableBatchCommand: true,
+		ExitErrHandler:     func(*Context, error) {},
+		Commands: []*Command{
+			{
+				Name: "stop",
+				Action: func(c *Context) error {
+					ran = append(ran, "stop")
+					cancel()
+					return nil
+				},
+			},
+		},
+	}
+
+	err := runBatch(&Context{Context: ctx, App: app}, []*batchLine{
+		{number: 1, text: "stop", args: []string{"stop"}},
+		{number: 2, text: "stop", args: []string{"stop"}},
+	}, false)
+
+	expect(t, ran, []string{"stop"})
+	if err == nil || !strings.Contains(err.Error(), "batch interrupted") {
+		t.Errorf("expected the batch to be interrupted, got %v", err)
+	}
+}
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"", nil},
+		{"# comment", nil},
+		{"a b\tc", []string{"a", "b", "c"}},
+		{`a "b c" 'd e'`, []string{"a", "b c", "d e"}},
+		{`a\ b "c\"d" "e\f" 'g\h'`, []string{"a b", `c"d`, `e\f`, `g\h`}},
+		{`a ""`, []string{"a", ""}},
+		{"a#b # c", []string{"a#b"}},
+	}
+
+	for _, test := range tests {
+		words, err := splitShellWords(test.line)
+		expect(t, err, nil)
+		expect(t, words, test.expected)
+	}
+
+	for _, line := range []string{`a "b`, `a 'b`, `a\`} {
+		if _, err := splitShellWords(line); err == nil {
+			t.Errorf("expected an error for %q", line)
+		}
+	}
+}
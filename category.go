@@ -1,5 +1,10 @@
 package cli
 
+import (
+	"reflect"
+	"sort"
+)
+
 // CommandCategories interface allows for category manipulation
 type CommandCategories interface {
 	// AddCommand adds a command to a category, creating a new category if necessary.
@@ -77,3 +82,89 @@ func (c *commandCategory) VisibleCommands() []*Command {
 	}
 	return ret
 }
+
+// DefaultFlagCategory is the heading of the flags without a Category when the
+// flags are grouped in help
+var DefaultFlagCategory = "MISC"
+
+// VisibleFlagCategory is a category containing visible flags.
+type VisibleFlagCategory interface {
+	// Name returns the category name string
+	Name() string
+	// Flags returns a slice of the flags of the category, in the order they
+	// are declared
+	Flags() []Flag
+}
+
+type flagCategory struct {
+	name  string
+	flags []Flag
+}
+
+func (c *flagCategory) Name() string {
+	return c.name
+}
+
+func (c *flagCategory) Flags() []Flag {
+	return c.flags
+}
+
+// visibleFlagCategories groups the visible flags by their Category, keeping
+// the order they are declared in within each category. The flags without a
+// Category come first, under DefaultFlagCategory, followed by the categories
+// sorted by name, or in the order they are first seen when keepOrder is set.
+// It returns nil when no visible flag has a Category, so help keeps listing
+// the flags in declaration order.
+func visibleFlagCategories(fl []Flag, keepOrder, hideDeprecated bool) []VisibleFlagCategory {
+	var categories []*flagCategory
+	var uncategorized *flagCategory
+	for _, f := range visibleFlags(fl, hideDeprecated) {
+		name := flagCategoryName(f)
+
+		var category *flagCategory
+		if name == "" {
+			if uncategorized == nil {
+				uncategorized = &flagCategory{name: DefaultFlagCategory}
+			}
+			category = uncategorized
+		}
+		for _, c := range categories {
+			if name != "" && c.name == name {
+				category = c
+			}
+		}
+		if category == nil {
+			category = &flagCategory{name: name}
+			categories = append(categories, category)
+		}
+
+		category.flags = append(category.flags, f)
+	}
+
+	if len(categories) == 0 {
+		return nil
+	}
+
+	if !keepOrder {
+		sort.SliceStable(categories, func(i, j int) bool {
+			return lexicographicLess(categories[i].name, categories[j].name)
+		})
+	}
+	if uncategorized != nil {
+		categories = append([]*flagCategory{uncategorized}, categories...)
+	}
+
+	ret := make([]VisibleFlagCategory, len(categories))
+	for i, c := range categories {
+		ret[i] = c
+	}
+	return ret
+}
+
+func flagCategoryName(f Flag) string {
+	field := flagValue(f).FieldByName("Category")
+	if field.IsValid() && field.Kind() == reflect.String {
+		return field.String()
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"flag"
+	"os"
+)
+
+// ColorFlag controls whether output is colored. It is only added to an App
+// when EnableColorFlag is true.
+var ColorFlag Flag = &StringFlag{
+	Name:    "color",
+	Usage:   "when to color output",
+	Value:   "auto",
+	Choices: []string{"auto", "always", "never"},
+}
+
+// ColorEnabled reports whether output written to App.Writer should be
+// colored. The --color flag wins when EnableColorFlag is set, followed by the
+// NO_COLOR, CLICOLOR_FORCE and CLICOLOR environment variables. Otherwise
+// colors are only enabled when writing to a terminal.
+func (c *Context) ColorEnabled() bool {
+	mode := ""
+	if c.App != nil && c.App.EnableColorFlag && ColorFlag != nil {
+		// the innermost command the flag was set on wins
+		for _, ctx := range c.Lineage() {
+			if ctx.flagSet == nil || mode != "" {
+				continue
+			}
+			ctx.flagSet.Visit(func(f *flag.Flag) {
+				if hasName(ColorFlag.Names(), f.Name) {
+					mode = f.Value.String()
+				}
+			})
+		}
+	}
+
+	terminal := false
+	if c.App != nil {
+		terminal = c.App.terminal().IsTerminal(c.App.Writer)
+	}
+
+	return colorEnabled(mode, os.LookupEnv, terminal)
+}
+
+// colorEnabled is the single place deciding whether to use colors
+func colorEnabled(mode string, lookupEnv func(string) (string, bool), terminal bool) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	if val, ok := lookupEnv("NO_COLOR"); ok && val != "" {
+		return false
+	}
+	if val, ok := lookupEnv("CLICOLOR_FORCE"); ok && val != "" && val != "0" {
+		return true
+	}
+	if val, ok := lookupEnv("CLICOLOR"); ok && val == "0" {
+		return false
+	}
+
+	return terminal
+}
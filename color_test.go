@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		env      map[string]string
+		terminal bool
+		expected bool
+	}{
+		{"terminal", "", nil, true, true},
+		{"piped", "", nil, false, false},
+		{"auto terminal", "auto", nil, true, true},
+		{"NO_COLOR", "", map[string]string{"NO_COLOR": "1"}, true, false},
+		{"empty NO_COLOR", "", map[string]string{"NO_COLOR": ""}, true, true},
+		{"CLICOLOR_FORCE piped", "", map[string]string{"CLICOLOR_FORCE": "1"}, false, true},
+		{"CLICOLOR_FORCE=0 piped", "", map[string]string{"CLICOLOR_FORCE": "0"}, false, false},
+		{"NO_COLOR beats CLICOLOR_FORCE", "", map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, true, false},
+		{"CLICOLOR=0", "", map[string]string{"CLICOLOR": "0"}, true, false},
+		{"CLICOLOR=1 piped", "", map[string]string{"CLICOLOR": "1"}, false, false},
+		{"CLICOLOR_FORCE beats CLICOLOR", "", map[string]string{"CLICOLOR": "0", "CLICOLOR_FORCE": "1"}, false, true},
+		{"always beats NO_COLOR", "always", map[string]string{"NO_COLOR": "1"}, false, true},
+		{"never beats CLICOLOR_FORCE", "never", map[string]string{"CLICOLOR_FORCE": "1"}, true, false},
+	}
+
+	for _, test := range tests {
+		lookupEnv := func(key string) (string, bool) {
+			val, ok := test.env[key]
+			return val, ok
+		}
+
+		if got := colorEnabled(test.mode, lookupEnv, test.terminal); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestContext_ColorEnabled(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"app", "cmd"}, false},
+		{[]string{"app", "--color=always", "cmd"}, true},
+		{[]string{"app", "cmd", "--color", "always"}, true},
+		{[]string{"app", "--color=never", "cmd", "--color", "always"}, true},
+		{[]string{"app", "--color=always", "cmd", "--color", "never"}, false},
+	}
+
+	for _, test := range tests {
+		var enabled bool
+		app := &App{
+			Writer:          &bytes.Buffer{},
+			EnableColorFlag: true,
+			Commands: []*Command{
+				{
+					Name: "cmd",
+					Action: func(c *Context) error {
+						enabled = c.ColorEnabled()
+						return nil
+					},
+				},
+			},
+		}
+
+		if err := app.Run(test.args); err != nil {
+			t.Fatalf("unexpected error for %v: %v", test.args, err)
+		}
+		if enabled != test.expected {
+			t.Errorf("%v: expected %v, got %v", test.args, test.expected, enabled)
+		}
+	}
+}
+
+func TestColorFlag_InvalidValue(t *testing.T) {
+	app := &App{
+		Writer:          ioutil.Discard,
+		ErrWriter:       ioutil.Discard,
+		EnableColorFlag: true,
+		Action: func(c *Context) error {
+			t.Error("the Action should not run with an invalid --color")
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"app", "--color=sometimes"})
+	if err == nil {
+		t.Fatal("expected an error for --color=sometimes")
+	}
+	expect(t, err.Error(), `invalid value "sometimes" for flag -color: invalid value "sometimes", expected one of auto, always, never`)
+}
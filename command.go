@@ -3,6 +3,7 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 )
@@ -28,6 +29,10 @@ type Command struct {
 	// An action to execute before any sub-subcommands are run, but after the context is ready
 	// If a non-nil error is returned, no sub-subcommands are run
 	Before BeforeFunc
+	// An action to execute after the flags are parsed, but before the Required
+	// flags are checked and the flag Validators run, to set flag values from
+	// other sources like a config file, see altsrc.InitInputSourceWithContext
+	LoadFlagValues BeforeFunc
 	// An action to execute after any subcommands are run, but after the subcommand has finished
 	// It is run even if Action() panics
 	After AfterFunc
@@ -39,6 +44,12 @@ type Command struct {
 	Subcommands []*Command
 	// List of flags to parse
 	Flags []Flag
+	// List of flags to parse, which are also available to all of the
+	// subcommands
+	PersistentFlags []Flag
+	// Groups of names of flags which must either all be set or all be unset,
+	// checked for the command and all of its subcommands
+	FlagsRequiredTogether [][]string
 	// Treat all flags as normal arguments if true
 	SkipFlagParsing bool
 	// Boolean to hide built-in help command and help flag
@@ -52,10 +63,31 @@ type Command struct {
 	// single-character bool arguments into one
 	// i.e. foobar -o -v -> foobar -ov
 	UseShortOptionHandling bool
+	// DocsURL, when not empty, is the link to the documentation of the
+	// command, a template like App.DocsURL
+	DocsURL string
+	// Boolean to take the arguments starting with a dash which are not flags
+	// of the command, like "-status:open", as positional arguments instead of
+	// failing on an unknown flag. The flags of the command are still parsed,
+	// up to the first other positional argument or "--". Ignored for commands
+	// with Subcommands.
+	TreatUnknownDashTokensAsArgs bool
 
 	// Full name of command for help, defaults to full command name, including parent commands.
 	HelpName        string
 	commandNamePath []string
+	// reader is where FileContentsFlags read "-" from, copied from App.Reader
+	reader io.Reader
+	// numberParser and boolParser are copied from the App
+	numberParser NumberParser
+	boolParser   BoolParser
+	// flagDefaults is shared with the App
+	flagDefaults map[Flag]flagDefault
+	// appDocsURL is the DocsURL of the App, for the commands without one
+	appDocsURL string
+	// keepFlagCategoryOrder and hideDeprecated are copied from the App
+	keepFlagCategoryOrder bool
+	hideDeprecated        bool
 
 	// CustomHelpTemplate the text template for the command help topic.
 	// cli.go uses text/template to render templates. You can
@@ -94,16 +126,51 @@ func (c *Command) Run(ctx *Context) (err error) {
 		return c.startApp(ctx)
 	}
 
+	// append the persistent flags of the ancestors and of the command itself
+	for _, fl := range ctx.App.PersistentFlags {
+		c.appendFlag(fl)
+	}
+	for _, fl := range c.PersistentFlags {
+		c.appendFlag(fl)
+	}
+
 	if !c.HideHelp && HelpFlag != nil {
 		// append help to flags
 		c.appendFlag(HelpFlag)
 	}
 
+	if ctx.App.EnableColorFlag && ColorFlag != nil {
+		c.appendFlag(ColorFlag)
+	}
+
+	if ctx.App.EnablePager && NoPagerFlag != nil {
+		c.appendFlag(NoPagerFlag)
+	}
+
 	if ctx.App.UseShortOptionHandling {
 		c.UseShortOptionHandling = true
 	}
 
-	set, err := c.parseFlags(ctx.Args(), ctx.shellComplete)
+	c.reader = ctx.App.Reader
+	c.numberParser = ctx.App.NumberParser
+	c.boolParser = ctx.App.BoolParser
+	c.flagDefaults = ctx.App.flagDefaults
+
+	metrics := startParse(ctx.App.MetricsCollector)
+	defer metrics.finish()
+
+	cmdArgs := ctx.Args()
+	if direct := ctx.App.commandPath; len(direct) == 1 && direct[0] == c {
+		// RunCommand gives the args of the command apart from the ones of
+		// its parents
+		given := args(append([]string{c.Name}, ctx.App.commandArgs...))
+		cmdArgs = &given
+	}
+
+	set, err := c.parseFlags(cmdArgs, ctx.shellComplete)
+	if err == nil {
+		inheritPersistentFlags(ctx.App.PersistentFlags, set, ctx)
+	}
 
 	context := NewContext(ctx.App, set, ctx)
 	context.Command = c
@@ -112,14 +179,17 @@ func (c *Command) Run(ctx *Context) (err error) {
 	}
 
 	if err != nil {
+		metrics.fail(ParseErrorFlags)
+		addFlagSuggestion(err, context)
+		err = context.App.addDocsLink(c, c.Flags, err)
 		if c.OnUsageError != nil {
 			err = c.OnUsageError(context, err, false)
 			context.App.handleExitCoder(context, err)
 			return err
 		}
-		_, _ = fmt.Fprintln(context.App.Writer, "Incorrect Usage:", err.Error())
-		_, _ = fmt.Fprintln(context.App.Writer)
-		_ = ShowCommandHelp(context, c.Name)
+		context.App.printError(context.App.usageErrWriter(), "Incorrect Usage:", err.Error())
+		_, _ = fmt.Fprintln(context.App.usageErrWriter())
+		_ = showCommandHelp(context, c.Name, context.App.usageErrWriter())
 		return err
 	}
 
@@ -127,19 +197,63 @@ func (c *Command) Run(ctx *Context) (err error) {
 		return nil
 	}
 
+	commandLine := commandLineValues(context.flagSet, cmdArgs.Tail())
+	if perr := applyProfile(c.Flags, context); perr != nil {
+		metrics.fail(ParseErrorFlags)
+		context.App.handleExitCoder(context, perr)
+		return perr
+	}
+	if c.LoadFlagValues != nil {
+		if lerr := c.LoadFlagValues(context); lerr != nil {
+			metrics.fail(ParseErrorFlags)
+			context.App.handleExitCoder(context, lerr)
+			return lerr
+		}
+	}
+	context.App.reportSourceConflicts(c.Flags, context, commandLine)
+
+	if perr := resolvePathFlags(c.Flags, context); perr != nil {
+		metrics.fail(ParseErrorFlags)
+		context.App.handleExitCoder(context, perr)
+		return perr
+	}
+
+	warnDeprecatedFlags(c.Flags, context)
+
+	if verr := validateFlags(c.Flags, context); verr != nil {
+		metrics.fail(ParseErrorValidation)
+		_ = showCommandHelp(context, c.Name, context.App.usageErrWriter())
+		return context.App.addDocsLink(c, c.Flags, verr)
+	}
+
 	cerr := checkRequiredFlags(c.Flags, context)
 	if cerr != nil {
-		_ = ShowCommandHelp(context, c.Name)
-		return cerr
+		metrics.fail(ParseErrorRequired)
+		_ = showCommandHelp(context, c.Name, context.App.usageErrWriter())
+		return context.App.addDocsLink(c, c.Flags, cerr)
+	}
+
+	if gerr := checkFlagsRequiredTogether(context); gerr != nil {
+		metrics.fail(ParseErrorRequired)
+		_ = showCommandHelp(context, c.Name, context.App.usageErrWriter())
+		return gerr
+	}
+	metrics.finish()
+
+	if ferr := runFlagActions(c.Flags, context); ferr != nil {
+		context.App.handleExitCoder(context, ferr)
+		return ferr
 	}
 
 	if c.After != nil {
 		defer func() {
+			context.actionErr = err
 			afterErr := c.After(context)
 			if afterErr != nil {
-				context.App.handleExitCoder(context, err)
+				context.App.handleExitCoder(context, afterErr)
+				afterErr = context.App.runtimeError(afterErr)
 				if err != nil {
-					err = newMultiError(err, afterErr)
+					err = NewMultiError(err, afterErr)
 				} else {
 					err = afterErr
 				}
@@ -150,9 +264,11 @@ func (c *Command) Run(ctx *Context) (err error) {
 	if c.Before != nil {
 		err = c.Before(context)
 		if err != nil {
-			_ = ShowCommandHelp(context, c.Name)
+			if !context.App.quiet {
+				_ = ShowCommandHelp(context, c.Name)
+			}
 			context.App.handleExitCoder(context, err)
-			return err
+			return context.App.runtimeError(err)
 		}
 	}
 
@@ -161,16 +277,25 @@ func (c *Command) Run(ctx *Context) (err error) {
 	}
 
 	context.Command = c
+	context.startAction(context.App.Name+" "+c.Name, c.Action)
 	err = c.Action(context)
 
 	if err != nil {
 		context.App.handleExitCoder(context, err)
 	}
-	return err
+	return context.App.actionError(c.Action, err)
 }
 
 func (c *Command) newFlagSet() (*flag.FlagSet, error) {
-	return flagSet(c.Name, c.Flags)
+	useLocaleParsers(c.Flags, c.numberParser, c.boolParser)
+	recordFlagDefaults(c.flagDefaults, c.Flags)
+	set, err := flagSet(c.Name, c.Flags)
+	if err != nil {
+		return nil, err
+	}
+	setFileContentsReader(set, c.reader)
+	setLocaleParsers(set, c.numberParser, c.boolParser)
+	return set, nil
 }
 
 func (c *Command) useShortOptionHandling() bool {
@@ -187,7 +312,12 @@ func (c *Command) parseFlags(args Args, shellComplete bool) (*flag.FlagSet, erro
 		return set, set.Parse(append([]string{"--"}, args.Tail()...))
 	}
 
-	err = parseIter(set, c, args.Tail(), shellComplete)
+	tail := args.Tail()
+	if c.TreatUnknownDashTokensAsArgs {
+		tail = separateUnknownDashTokens(set, tail, c.useShortOptionHandling())
+	}
+
+	err = parseIter(set, c, tail, shellComplete)
 	if err != nil {
 		return nil, err
 	}
@@ -238,16 +368,46 @@ func (c *Command) startApp(ctx *Context) error {
 	// set the flags and commands
 	app.Commands = c.Subcommands
 	app.Flags = c.Flags
+	app.PersistentFlags = append(append([]Flag{}, ctx.App.PersistentFlags...), c.PersistentFlags...)
+	app.FlagsRequiredTogether = c.FlagsRequiredTogether
 	app.HideHelp = c.HideHelp
 	app.HideHelpCommand = c.HideHelpCommand
 
 	app.Version = ctx.App.Version
 	app.HideVersion = ctx.App.HideVersion
 	app.Compiled = ctx.App.Compiled
+	app.Reader = ctx.App.Reader
 	app.Writer = ctx.App.Writer
 	app.ErrWriter = ctx.App.ErrWriter
+	app.Terminal = ctx.App.Terminal
+	app.ErrorPrefix = ctx.App.ErrorPrefix
+	app.HideErrorPrefix = ctx.App.HideErrorPrefix
+	app.EnableColorFlag = ctx.App.EnableColorFlag
+	app.EnablePager = ctx.App.EnablePager
+	app.EnableHelpAll = ctx.App.EnableHelpAll
+	app.HelpAllFormat = ctx.App.HelpAllFormat
+	app.HelpToStdoutOnUsageError = ctx.App.HelpToStdoutOnUsageError
 	app.ExitErrHandler = ctx.App.ExitErrHandler
+	app.RequiredFlagsExitCode = ctx.App.RequiredFlagsExitCode
+	app.OnSourceConflict = ctx.App.OnSourceConflict
+	app.SensitiveFlags = ctx.App.SensitiveFlags
+	app.ClassifyErrors = ctx.App.ClassifyErrors
+	app.KeepFlagCategoryOrder = ctx.App.KeepFlagCategoryOrder
+	app.HideDeprecated = ctx.App.HideDeprecated
+	app.quiet = ctx.App.quiet
+	app.flagDefaults = ctx.App.flagDefaults
+	if direct := ctx.App.commandPath; len(direct) > 0 && direct[0] == c {
+		app.commandPath = direct[1:]
+		app.commandArgs = ctx.App.commandArgs
+	}
 	app.UseShortOptionHandling = ctx.App.UseShortOptionHandling
+	app.DisableHelpSubcommandInterception = ctx.App.DisableHelpSubcommandInterception
+	app.FlagReadRecorder = ctx.App.FlagReadRecorder
+	app.MetricsCollector = ctx.App.MetricsCollector
+	app.NumberParser = ctx.App.NumberParser
+	app.BoolParser = ctx.App.BoolParser
+	app.DocsURL = ctx.App.DocsURL
+	app.command = c
 
 	app.categories = newCommandCategories()
 	for _, command := range c.Subcommands {
@@ -264,6 +424,7 @@ func (c *Command) startApp(ctx *Context) error {
 
 	// set the actions
 	app.Before = c.Before
+	app.LoadFlagValues = c.LoadFlagValues
 	app.After = c.After
 	if c.Action != nil {
 		app.Action = c.Action
@@ -281,7 +442,13 @@ func (c *Command) startApp(ctx *Context) error {
 
 // VisibleFlags returns a slice of the Flags with Hidden=false
 func (c *Command) VisibleFlags() []Flag {
-	return visibleFlags(c.Flags)
+	return visibleFlags(c.Flags, c.hideDeprecated)
+}
+
+// VisibleFlagCategories returns the visible flags grouped by their Category,
+// or nil if none of them has a Category
+func (c *Command) VisibleFlagCategories() []VisibleFlagCategory {
+	return visibleFlagCategories(c.Flags, c.keepFlagCategoryOrder, c.hideDeprecated)
 }
 
 func (c *Command) appendFlag(fl Flag) {
@@ -299,3 +466,31 @@ func hasCommand(commands []*Command, command *Command) bool {
 
 	return false
 }
+
+// checkCommandNames reports a name or an alias shared by two of the commands
+// of the command at path, or of one of its descendants. The help command
+// gives way to the commands named like it, so it is not checked.
+func checkCommandNames(path string, commands []*Command, ancestors ...*Command) error {
+	seen := make(map[string]*Command)
+	for _, c := range commands {
+		if c == helpCommand || c == helpSubcommand {
+			continue
+		}
+		for _, name := range c.Names() {
+			if other, ok := seen[name]; ok && other != c {
+				return fmt.Errorf("commands %q and %q of %q share the name %q", other.Name, c.Name, path, name)
+			}
+			seen[name] = c
+		}
+	}
+
+	for _, c := range commands {
+		if hasCommand(ancestors, c) {
+			continue
+		}
+		if err := checkCommandNames(path+" "+c.Name, c.Subcommands, append(ancestors, c)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
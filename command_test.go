@@ -18,7 +18,7 @@ func TestCommandFlagParsing(t *testing.T) {
 		expectedErr            error
 	}{
 		// Test normal "not ignoring flags" flow
-		{testArgs: []string{"test-cmd", "-break", "blah", "blah"}, skipFlagParsing: false, useShortOptionHandling: false, expectedErr: errors.New("flag provided but not defined: -break")},
+		{testArgs: []string{"test-cmd", "-break", "blah", "blah"}, skipFlagParsing: false, useShortOptionHandling: false, expectedErr: &errUnknownFlag{name: "break", token: "-break"}},
 		{testArgs: []string{"test-cmd", "blah", "blah"}, skipFlagParsing: true, useShortOptionHandling: false, expectedErr: nil},   // Test SkipFlagParsing without any args that look like flags
 		{testArgs: []string{"test-cmd", "blah", "-break"}, skipFlagParsing: true, useShortOptionHandling: false, expectedErr: nil}, // Test SkipFlagParsing with random flag arg
 		{testArgs: []string{"test-cmd", "blah", "-help"}, skipFlagParsing: true, useShortOptionHandling: false, expectedErr: nil},  // Test SkipFlagParsing with "special" help flag arg
@@ -61,11 +61,11 @@ func TestParseAndRunShortOpts(t *testing.T) {
 		{testArgs: args{"foo", "test", "-af"}, expectedErr: nil, expectedArgs: &args{}},
 		{testArgs: args{"foo", "test", "-cf"}, expectedErr: nil, expectedArgs: &args{}},
 		{testArgs: args{"foo", "test", "-acf"}, expectedErr: nil, expectedArgs: &args{}},
-		{testArgs: args{"foo", "test", "--acf"}, expectedErr: errors.New("flag provided but not defined: -acf"), expectedArgs: nil},
-		{testArgs: args{"foo", "test", "-invalid"}, expectedErr: errors.New("flag provided but not defined: -invalid"), expectedArgs: nil},
-		{testArgs: args{"foo", "test", "-acf", "-invalid"}, expectedErr: errors.New("flag provided but not defined: -invalid"), expectedArgs: nil},
-		{testArgs: args{"foo", "test", "--invalid"}, expectedErr: errors.New("flag provided but not defined: -invalid"), expectedArgs: nil},
-		{testArgs: args{"foo", "test", "-acf", "--invalid"}, expectedErr: errors.New("flag provided but not defined: -invalid"), expectedArgs: nil},
+		{testArgs: args{"foo", "test", "--acf"}, expectedErr: &errUnknownFlag{name: "acf", token: "--acf", suggestion: "--abc"}, expectedArgs: nil},
+		{testArgs: args{"foo", "test", "-invalid"}, expectedErr: &errUnknownFlag{name: "invalid", token: "-invalid"}, expectedArgs: nil},
+		{testArgs: args{"foo", "test", "-acf", "-invalid"}, expectedErr: &errUnknownFlag{name: "invalid", token: "-invalid"}, expectedArgs: nil},
+		{testArgs: args{"foo", "test", "--invalid"}, expectedErr: &errUnknownFlag{name: "invalid", token: "--invalid"}, expectedArgs: nil},
+		{testArgs: args{"foo", "test", "-acf", "--invalid"}, expectedErr: &errUnknownFlag{name: "invalid", token: "--invalid"}, expectedArgs: nil},
 		{testArgs: args{"foo", "test", "-acf", "arg1", "-invalid"}, expectedErr: nil, expectedArgs: &args{"arg1", "-invalid"}},
 		{testArgs: args{"foo", "test", "-acf", "arg1", "--invalid"}, expectedErr: nil, expectedArgs: &args{"arg1", "--invalid"}},
 		{testArgs: args{"foo", "test", "-acfi", "not-arg", "arg1", "-invalid"}, expectedErr: nil, expectedArgs: &args{"arg1", "-invalid"}},
@@ -181,6 +181,88 @@ func TestCommand_Run_BeforeSavesMetadata(t *testing.T) {
 	}
 }
 
+func TestCommand_Run_AfterHooksRunInReverseOrder(t *testing.T) {
+	var calls []string
+	actionErr := errors.New("action error")
+	hooks := func(name string) (BeforeFunc, AfterFunc) {
+		return func(c *Context) error {
+				calls = append(calls, "before "+name)
+				return nil
+			}, func(c *Context) error {
+				calls = append(calls, "after "+name)
+				expect(t, c.ActionErr(), actionErr)
+				return nil
+			}
+	}
+
+	appBefore, appAfter := hooks("app")
+	parentBefore, parentAfter := hooks("parent")
+	childBefore, childAfter := hooks("child")
+	app := &App{
+		Before: appBefore,
+		After:  appAfter,
+		Commands: []*Command{
+			{
+				Name:   "parent",
+				Before: parentBefore,
+				After:  parentAfter,
+				Subcommands: []*Command{
+					{
+						Name:   "child",
+						Before: childBefore,
+						After:  childAfter,
+						Action: func(c *Context) error {
+							calls = append(calls, "action")
+							return actionErr
+						},
+					},
+				},
+			},
+		},
+		ExitErrHandler: func(*Context, error) {},
+	}
+
+	err := app.Run([]string{"foo", "parent", "child"})
+	expect(t, err, actionErr)
+	expect(t, calls, []string{
+		"before app", "before parent", "before child",
+		"action",
+		"after child", "after parent", "after app",
+	})
+}
+
+func TestCommand_Run_BeforeErrorSkipsAction(t *testing.T) {
+	beforeErr := errors.New("before error")
+	actionRan := false
+	var afterErr error
+
+	app := &App{
+		Commands: []*Command{
+			{
+				Name: "bar",
+				Before: func(c *Context) error {
+					return beforeErr
+				},
+				Action: func(c *Context) error {
+					actionRan = true
+					return nil
+				},
+				After: func(c *Context) error {
+					afterErr = c.ActionErr()
+					return nil
+				},
+			},
+		},
+		Writer:         ioutil.Discard,
+		ExitErrHandler: func(*Context, error) {},
+	}
+
+	err := app.Run([]string{"foo", "bar"})
+	expect(t, err, beforeErr)
+	expect(t, actionRan, false)
+	expect(t, afterErr, beforeErr)
+}
+
 func TestCommand_OnUsageError_hasCommandContext(t *testing.T) {
 	app := &App{
 		Commands: []*Command{
@@ -377,3 +459,349 @@ func TestCommand_Run_CustomShellCompleteAcceptsMalformedFlags(t *testing.T) {
 	}
 
 }
+
+func TestCommand_PersistentFlags(t *testing.T) {
+	cases := []args{
+		{"app", "--verbose", "--log", "debug", "remote", "add"},
+		{"app", "remote", "--verbose", "add", "--log", "debug"},
+		{"app", "remote", "add", "--verbose", "--log=debug"},
+	}
+
+	for _, arguments := range cases {
+		actionRan := false
+		err := (&App{
+			PersistentFlags: []Flag{
+				&BoolFlag{Name: "verbose"},
+				&StringFlag{Name: "log", Value: "info"},
+			},
+			Commands: []*Command{{
+				Name:            "remote",
+				PersistentFlags: []Flag{&StringFlag{Name: "region", Value: "eu"}},
+				Subcommands: []*Command{{
+					Name: "add",
+					Action: func(ctx *Context) error {
+						actionRan = true
+						expect(t, ctx.Bool("verbose"), true)
+						expect(t, ctx.IsSet("verbose"), true)
+						expect(t, ctx.String("log"), "debug")
+						expect(t, ctx.String("region"), "eu")
+						return nil
+					},
+				}},
+			}},
+		}).Run(arguments)
+		expect(t, err, nil)
+		expect(t, actionRan, true)
+	}
+}
+
+func TestCommand_PersistentFlags_NotInheritedUpwards(t *testing.T) {
+	err := (&App{
+		Commands: []*Command{{
+			Name:            "remote",
+			PersistentFlags: []Flag{&StringFlag{Name: "region"}},
+			Subcommands:     []*Command{{Name: "add", Action: func(*Context) error { return nil }}},
+		}},
+		Writer:    ioutil.Discard,
+		ErrWriter: ioutil.Discard,
+	}).Run([]string{"app", "--region", "us", "remote", "add"})
+	if err == nil || !strings.Contains(err.Error(), "region") {
+		t.Errorf("expected an error for the undefined flag, got %v", err)
+	}
+}
+
+func TestCommand_PersistentFlags_Conflict(t *testing.T) {
+	actionRan := false
+	err := (&App{
+		Name:            "app",
+		PersistentFlags: []Flag{&BoolFlag{Name: "verbose", Aliases: []string{"v"}}},
+		Commands: []*Command{{
+			Name: "remote",
+			Subcommands: []*Command{{
+				Name:  "add",
+				Flags: []Flag{&StringFlag{Name: "v"}},
+				Action: func(*Context) error {
+					actionRan = true
+					return nil
+				},
+			}},
+		}},
+	}).Run([]string{"app", "remote", "add"})
+	if err == nil {
+		t.Fatal("expected an error for the conflicting flags")
+	}
+	expect(t, err.Error(), `persistent flag "v" conflicts with a flag of the same name of "app remote add"`)
+	expect(t, actionRan, false)
+}
+
+func TestCommand_Aliases(t *testing.T) {
+	var ran string
+	app := &App{
+		Name:   "app",
+		Writer: ioutil.Discard,
+		Commands: []*Command{
+			{
+				Name:    "remove",
+				Aliases: []string{"rm"},
+				Action: func(*Context) error {
+					ran = "remove"
+					return nil
+				},
+			},
+		},
+	}
+
+	err := app.Run([]string{"app", "rm"})
+	expect(t, err, nil)
+	expect(t, ran, "remove")
+}
+
+func TestCommand_Aliases_Conflict(t *testing.T) {
+	cases := []struct {
+		name     string
+		commands []*Command
+		expected string
+	}{
+		{
+			name: "alias of two commands",
+			commands: []*Command{
+				{Name: "remove", Aliases: []string{"rm"}},
+				{Name: "rmdir", Aliases: []string{"rm"}},
+			},
+			expected: `commands "remove" and "rmdir" of "app" share the name "rm"`,
+		},
+		{
+			name: "alias of a subcommand",
+			commands: []*Command{{
+				Name: "remote",
+				Subcommands: []*Command{
+					{Name: "add"},
+					{Name: "append", Aliases: []string{"add"}},
+				},
+			}},
+			expected: `commands "add" and "append" of "app remote" share the name "add"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := (&App{Name: "app", Writer: ioutil.Discard, Commands: c.commands}).Run([]string{"app"})
+			if err == nil {
+				t.Fatal("expected an error for the ambiguous alias")
+			}
+			expect(t, err.Error(), c.expected)
+		})
+	}
+}
+
+func TestCommand_SharedDestination(t *testing.T) {
+	var region string
+	var count int
+
+	cases := []struct {
+		name     string
+		app      *App
+		expected string
+	}{
+		{
+			name: "flags of a command",
+			app: &App{
+				Commands: []*Command{{
+					Name: "deploy",
+					Flags: []Flag{
+						&StringFlag{Name: "region", Destination: &region},
+						&StringFlag{Name: "zone", Destination: &region},
+					},
+				}},
+			},
+			expected: `flags "region" and "zone" of "app deploy" share a Destination`,
+		},
+		{
+			name: "flags of different types",
+			app: &App{
+				Flags: []Flag{
+					&StringFlag{Name: "region", Destination: &region},
+					&PathFlag{Name: "region-file", Destination: &region},
+				},
+			},
+			expected: `flags "region" and "region-file" of "app" share a Destination`,
+		},
+		{
+			name: "persistent flag",
+			app: &App{
+				PersistentFlags: []Flag{&IntFlag{Name: "count", Destination: &count}},
+				Commands: []*Command{{
+					Name:  "deploy",
+					Flags: []Flag{&IntFlag{Name: "replicas", Destination: &count}},
+				}},
+			},
+			expected: `flags "replicas" and "count" of "app deploy" share a Destination`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.app.Name = "app"
+			c.app.Writer = ioutil.Discard
+			err := c.app.Run([]string{"app"})
+			if err == nil {
+				t.Fatal("expected an error for the shared Destination")
+			}
+			expect(t, err.Error(), c.expected)
+		})
+	}
+}
+
+func TestCommand_SharedDestination_Allowed(t *testing.T) {
+	var region string
+
+	cases := []struct {
+		name string
+		app  *App
+		args []string
+	}{
+		{
+			name: "opt-out",
+			app: &App{
+				AllowSharedDestination: true,
+				Flags: []Flag{
+					&StringFlag{Name: "region", Destination: &region},
+					&StringFlag{Name: "zone", Destination: &region},
+				},
+			},
+			args: []string{"app", "--region", "eu"},
+		},
+		{
+			name: "different commands",
+			app: &App{
+				Commands: []*Command{
+					{Name: "deploy", Flags: []Flag{&StringFlag{Name: "region", Destination: &region}}},
+					{Name: "destroy", Flags: []Flag{&StringFlag{Name: "region", Destination: &region}}},
+				},
+			},
+			args: []string{"app", "destroy", "--region", "eu"},
+		},
+		{
+			name: "persistent flag",
+			app: &App{
+				PersistentFlags: []Flag{&StringFlag{Name: "region", Destination: &region}},
+				Commands:        []*Command{{Name: "deploy"}},
+			},
+			args: []string{"app", "deploy", "--region", "eu"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.app.Name = "app"
+			c.app.Writer = ioutil.Discard
+			region = ""
+			err := c.app.Run(c.args)
+			expect(t, err, nil)
+			expect(t, region, "eu")
+		})
+	}
+}
+
+func TestCommand_TreatUnknownDashTokensAsArgs(t *testing.T) {
+	cases := []struct {
+		name          string
+		args          []string
+		expectedArgs  []string
+		expectedLimit int
+		expectedAll   bool
+	}{
+		{
+			name:         "alone",
+			args:         []string{"app", "query", "-status:open"},
+			expectedArgs: []string{"-status:open"},
+		},
+		{
+			name:          "before the flags",
+			args:          []string{"app", "query", "-status:open", "--limit", "5", "-a"},
+			expectedArgs:  []string{"-status:open"},
+			expectedLimit: 5,
+			expectedAll:   true,
+		},
+		{
+			name:          "after the flags",
+			args:          []string{"app", "query", "--limit", "5", "-status:open"},
+			expectedArgs:  []string{"-status:open"},
+			expectedLimit: 5,
+		},
+		{
+			name:          "flag value starting with a dash",
+			args:          []string{"app", "query", "-status:open", "--limit", "-1"},
+			expectedArgs:  []string{"-status:open"},
+			expectedLimit: -1,
+		},
+		{
+			name:         "stops at the first positional",
+			args:         []string{"app", "query", "-status:open", "owner:me", "--limit", "5"},
+			expectedArgs: []string{"-status:open", "owner:me", "--limit", "5"},
+		},
+		{
+			name:         "stops at the terminator",
+			args:         []string{"app", "query", "-status:open", "--", "-a"},
+			expectedArgs: []string{"-status:open", "-a"},
+		},
+		{
+			name:          "combined short options",
+			args:          []string{"app", "query", "-al", "3", "-label:bug"},
+			expectedArgs:  []string{"-label:bug"},
+			expectedLimit: 3,
+			expectedAll:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var args []string
+			var limit int
+			var all bool
+			app := &App{
+				Name:                   "app",
+				Writer:                 ioutil.Discard,
+				UseShortOptionHandling: true,
+				Commands: []*Command{{
+					Name:                         "query",
+					TreatUnknownDashTokensAsArgs: true,
+					Flags: []Flag{
+						&IntFlag{Name: "limit", Aliases: []string{"l"}},
+						&BoolFlag{Name: "all", Aliases: []string{"a"}},
+					},
+					Action: func(c *Context) error {
+						args = c.Args().Slice()
+						limit = c.Int("limit")
+						all = c.Bool("all")
+						return nil
+					},
+				}},
+			}
+
+			err := app.Run(c.args)
+			expect(t, err, nil)
+			expect(t, args, c.expectedArgs)
+			expect(t, limit, c.expectedLimit)
+			expect(t, all, c.expectedAll)
+		})
+	}
+}
+
+func TestCommand_TreatUnknownDashTokensAsArgs_Disabled(t *testing.T) {
+	app := &App{
+		Name:      "app",
+		Writer:    ioutil.Discard,
+		ErrWriter: ioutil.Discard,
+		Commands: []*Command{{
+			Name:   "query",
+			Flags:  []Flag{&IntFlag{Name: "limit"}},
+			Action: func(*Context) error { return nil },
+		}},
+	}
+
+	err := app.Run([]string{"app", "query", "-status:open"})
+	if err == nil || !strings.Contains(err.Error(), "flag provided but not defined") {
+		t.Errorf("expected an unknown flag error, got %v", err)
+	}
+}
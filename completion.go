@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// completionShells lists the shells a completion script can be generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// geteuid is replaced in tests to run the install as root or not
+var geteuid = os.Geteuid
+
+var completionCommand = &Command{
+	Name:      "completion",
+	Usage:     "Prints the shell completion script",
+	ArgsUsage: "[bash|zsh|fish]",
+	Action: func(c *Context) error {
+		app := rootApp(c)
+		shell, err := completionShell(c.Args().First())
+		if err != nil {
+			return err
+		}
+
+		script, err := app.completionScript(shell)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprint(c.App.Writer, script)
+		return nil
+	},
+	Subcommands: []*Command{
+		{
+			Name:      "install",
+			Usage:     "Installs the shell completion script for the current user",
+			ArgsUsage: "[bash|zsh|fish]",
+			Flags: []Flag{
+				&BoolFlag{
+					Name:  "dry-run",
+					Usage: "print the planned changes without making them",
+				},
+			},
+			Action: func(c *Context) error {
+				shell, err := completionShell(c.Args().First())
+				if err != nil {
+					return err
+				}
+
+				return installCompletion(c, shell, c.Bool("dry-run"))
+			},
+		},
+	},
+}
+
+// rootApp returns the App the user created, as subcommands run inside an App
+// of their own
+func rootApp(c *Context) *App {
+	app := c.App
+	for _, ctx := range c.Lineage() {
+		if ctx.App != nil {
+			app = ctx.App
+		}
+	}
+	return app
+}
+
+// completionShell returns the given shell, or the one detected from $SHELL
+func completionShell(shell string) (string, error) {
+	if shell == "" {
+		shell = filepath.Base(os.Getenv("SHELL"))
+		if shell == "." || shell == string(filepath.Separator) {
+			return "", errors.New("could not detect the shell from $SHELL, pass it as an argument")
+		}
+	}
+
+	for _, s := range completionShells {
+		if s == shell {
+			return shell, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported shell %q, expected one of %s", shell, strings.Join(completionShells, ", "))
+}
+
+// completionScript returns the completion script of the App for shell
+func (a *App) completionScript(shell string) (string, error) {
+	switch shell {
+	case "fish":
+		return a.ToFishCompletion()
+	case "zsh":
+		return a.ToZshCompletion()
+	}
+
+	t, err := template.New(shell).Parse(BashCompletionTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var w bytes.Buffer
+	if err := t.Execute(&w, struct{ App *App }{a}); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+// completionPaths returns where the completion script of shell goes, and the
+// rc file which has to source it, if any
+func completionPaths(home, name, shell string) (script, rc string) {
+	switch shell {
+	case "fish":
+		return filepath.Join(home, ".config", "fish", "completions", name+".fish"), ""
+	case "zsh":
+		return filepath.Join(home, ".zsh", "completions", "_"+name), filepath.Join(home, ".zshrc")
+	default:
+		return filepath.Join(home, ".bash_completion.d", name), filepath.Join(home, ".bashrc")
+	}
+}
+
+func installCompletion(c *Context, shell string, dryRun bool) error {
+	if geteuid() == 0 && os.Getenv("SUDO_USER") != "" {
+		return fmt.Errorf("refusing to install completion as root for %s, run the command without sudo", os.Getenv("SUDO_USER"))
+	}
+
+	home := os.Getenv("HOME")
+	if home == "" {
+		return errors.New("could not find the home directory, $HOME is not set")
+	}
+
+	app := rootApp(c)
+	script, err := app.completionScript(shell)
+	if err != nil {
+		return err
+	}
+
+	w := c.App.Writer
+	scriptPath, rcPath := completionPaths(home, app.Name, shell)
+	var undo []string
+
+	if current, err := ioutil.ReadFile(scriptPath); err == nil && string(current) == script {
+		_, _ = fmt.Fprintf(w, "%s is already up to date\n", scriptPath)
+	} else if dryRun {
+		_, _ = fmt.Fprintf(w, "would write %s\n", scriptPath)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(scriptPath), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "wrote %s\n", scriptPath)
+	}
+	undo = append(undo, fmt.Sprintf("remove %s", scriptPath))
+
+	if rcPath != "" {
+		marker := fmt.Sprintf("# added by %s completion install", app.Name)
+		lines := fmt.Sprintf("%s\nsource %s\n", marker, scriptPath)
+
+		rc, err := ioutil.ReadFile(rcPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		switch {
+		case bytes.Contains(rc, []byte(marker)):
+			_, _ = fmt.Fprintf(w, "%s already sources the completion script\n", rcPath)
+		case dryRun:
+			_, _ = fmt.Fprintf(w, "would append to %s:\n%s", rcPath, lines)
+		default:
+			if len(rc) > 0 && !bytes.HasSuffix(rc, []byte("\n")) {
+				lines = "\n" + lines
+			}
+			f, err := os.OpenFile(rcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				return err
+			}
+			_, err = f.WriteString(lines)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(w, "appended to %s:\n%s", rcPath, strings.TrimPrefix(lines, "\n"))
+		}
+		undo = append(undo, fmt.Sprintf("delete the two lines starting with %q from %s", marker, rcPath))
+	}
+
+	if !dryRun {
+		_, _ = fmt.Fprintf(w, "to undo, %s\n", strings.Join(undo, " and "))
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func withTempHome(t *testing.T) (string, func()) {
+	home, err := ioutil.TempDir("", "urfave_cli_completion")
+	expect(t, err, nil)
+
+	oldHome := os.Getenv("HOME")
+	oldSudoUser, hadSudoUser := os.LookupEnv("SUDO_USER")
+	_ = os.Setenv("HOME", home)
+	_ = os.Unsetenv("SUDO_USER")
+	return home, func() {
+		_ = os.Setenv("HOME", oldHome)
+		if hadSudoUser {
+			_ = os.Setenv("SUDO_USER", oldSudoUser)
+		} else {
+			_ = os.Unsetenv("SUDO_USER")
+		}
+		_ = os.RemoveAll(home)
+	}
+}
+
+func runCompletionApp(t *testing.T, args ...string) (string, error) {
+	var out bytes.Buffer
+	app := &App{
+		Name:                    "greet",
+		EnableBashCompletion:    true,
+		EnableCompletionCommand: true,
+		Writer:                  &out,
+	}
+	err := app.Run(append([]string{"greet"}, args...))
+	return out.String(), err
+}
+
+func TestCompletionCommand_PrintsScript(t *testing.T) {
+	out, err := runCompletionApp(t, "completion", "zsh")
+	expect(t, err, nil)
+	if !strings.HasPrefix(out, "#compdef greet\n") || !strings.Contains(out, "compdef _greet greet") {
+		t.Errorf("unexpected zsh script:\n%s", out)
+	}
+
+	_, err = runCompletionApp(t, "completion", "tcsh")
+	if err == nil || !strings.Contains(err.Error(), `unsupported shell "tcsh"`) {
+		t.Errorf("expected an unsupported shell error, got %v", err)
+	}
+}
+
+func TestCompletionInstall(t *testing.T) {
+	cases := []struct {
+		shell  string
+		script string
+		rc     string
+	}{
+		{"bash", ".bash_completion.d/greet", ".bashrc"},
+		{"zsh", ".zsh/completions/_greet", ".zshrc"},
+		{"fish", ".config/fish/completions/greet.fish", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.shell, func(t *testing.T) {
+			home, cleanup := withTempHome(t)
+			defer cleanup()
+			scriptPath := filepath.Join(home, c.script)
+
+			out, err := runCompletionApp(t, "completion", "install", c.shell)
+			expect(t, err, nil)
+			if !strings.Contains(out, "wrote "+scriptPath) || !strings.Contains(out, "to undo, remove "+scriptPath) {
+				t.Errorf("unexpected output:\n%s", out)
+			}
+
+			script, err := ioutil.ReadFile(scriptPath)
+			expect(t, err, nil)
+			expect(t, strings.Contains(string(script), "greet"), true)
+
+			if c.rc != "" {
+				rcPath := filepath.Join(home, c.rc)
+				rc, err := ioutil.ReadFile(rcPath)
+				expect(t, err, nil)
+				expect(t, string(rc), "# added by greet completion install\nsource "+scriptPath+"\n")
+
+				out, err = runCompletionApp(t, "completion", "install", c.shell)
+				expect(t, err, nil)
+				if !strings.Contains(out, scriptPath+" is already up to date") || !strings.Contains(out, rcPath+" already sources") {
+					t.Errorf("unexpected output when already installed:\n%s", out)
+				}
+
+				again, err := ioutil.ReadFile(rcPath)
+				expect(t, err, nil)
+				expect(t, string(again), string(rc))
+			}
+		})
+	}
+}
+
+func TestCompletionInstall_RefusesRootForSudoUser(t *testing.T) {
+	home, cleanup := withTempHome(t)
+	defer cleanup()
+	defer func() { geteuid = os.Geteuid }()
+	geteuid = func() int { return 0 }
+	_ = os.Setenv("SUDO_USER", "jane")
+
+	_, err := runCompletionApp(t, "completion", "install", "bash")
+	if err == nil || err.Error() != "refusing to install completion as root for jane, run the command without sudo" {
+		t.Errorf("expected the install to be refused, got %v", err)
+	}
+	_, err = os.Stat(filepath.Join(home, ".bash_completion.d"))
+	expect(t, os.IsNotExist(err), true)
+
+	// root without sudo installs in its own home
+	_ = os.Unsetenv("SUDO_USER")
+	_, err = runCompletionApp(t, "completion", "install", "bash")
+	expect(t, err, nil)
+}
+
+func TestCompletionInstall_DetectsShell(t *testing.T) {
+	home, cleanup := withTempHome(t)
+	defer cleanup()
+	oldShell := os.Getenv("SHELL")
+	_ = os.Setenv("SHELL", "/usr/bin/zsh")
+	defer func() { _ = os.Setenv("SHELL", oldShell) }()
+
+	_, err := runCompletionApp(t, "completion", "install")
+	expect(t, err, nil)
+
+	_, err = os.Stat(filepath.Join(home, ".zsh", "completions", "_greet"))
+	expect(t, err, nil)
+}
+
+func TestCompletionInstall_DryRun(t *testing.T) {
+	home, cleanup := withTempHome(t)
+	defer cleanup()
+	rcPath := filepath.Join(home, ".bashrc")
+	expect(t, ioutil.WriteFile(rcPath, []byte("export EDITOR=vi"), 0644), nil)
+
+	out, err := runCompletionApp(t, "completion", "install", "--dry-run", "bash")
+	expect(t, err, nil)
+
+	scriptPath := filepath.Join(home, ".bash_completion.d", "greet")
+	expect(t, out, "would write "+scriptPath+"\n"+
+		"would append to "+rcPath+":\n"+
+		"# added by greet completion install\n"+
+		"source "+scriptPath+"\n")
+
+	_, err = os.Stat(scriptPath)
+	expect(t, os.IsNotExist(err), true)
+	rc, _ := ioutil.ReadFile(rcPath)
+	expect(t, string(rc), "export EDITOR=vi")
+
+	_, err = runCompletionApp(t, "completion", "install", "bash")
+	expect(t, err, nil)
+	rc, _ = ioutil.ReadFile(rcPath)
+	expect(t, string(rc), "export EDITOR=vi\n# added by greet completion install\nsource "+scriptPath+"\n")
+}
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -12,6 +15,11 @@ import (
 // each Handler action in a cli application. Context
 // can be used to retrieve context-specific args and
 // parsed command-line options.
+//
+// The embedded context.Context is inherited by the contexts of the commands
+// run afterwards. SetContext replaces it in place, so that values or a
+// deadline attached in a Before hook reach the subcommands, while WithContext
+// returns a copy holding another one.
 type Context struct {
 	context.Context
 	App           *App
@@ -19,6 +27,17 @@ type Context struct {
 	shellComplete bool
 	flagSet       *flag.FlagSet
 	parentContext *Context
+	actionErr     error
+	// sourceValues holds the values added by AddSourceValue, by flag name
+	sourceValues map[string][]SourceValue
+	// invocationDir is the working directory the App was run from, recorded
+	// on the root context before any command changes it
+	invocationDir string
+	// argsPath is the path of the command whose action is running, for the
+	// FlagReadRecorder to record the arguments it reads
+	argsPath string
+	// profileFlags holds the names of the flags set from App.Profiles
+	profileFlags map[string]bool
 }
 
 // NewContext creates a new context. For use in when invoking an App or Command action.
@@ -53,30 +72,69 @@ func (c *Context) Set(name, value string) error {
 
 // IsSet determines if the flag was actually set
 func (c *Context) IsSet(name string) bool {
-	if fs := lookupFlagSet(name, c); fs != nil {
-		if fs := lookupFlagSet(name, c); fs != nil {
-			isSet := false
-			fs.Visit(func(f *flag.Flag) {
-				if f.Name == name {
-					isSet = true
-				}
-			})
-			if isSet {
-				return true
-			}
-		}
+	c.recordFlagRead(name)
+	return c.isSet(name)
+}
 
-		f := lookupFlag(name, c)
-		if f == nil {
-			return false
+func (c *Context) isSet(name string) bool {
+	fs := findFlagSet(name, c)
+	if fs == nil {
+		return false
+	}
+
+	// a flag given on the command line is set, whatever its value
+	isSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			isSet = true
 		}
+	})
+	if isSet {
+		return true
+	}
 
-		return f.IsSet()
+	// otherwise it may have been set through env or file
+	f := lookupFlag(name, c)
+	if f == nil {
+		return false
+	}
+
+	return f.IsSet()
+}
+
+// IsSetFromAny determines if any of the named flags was set, whether on the
+// command line or through env or file
+func (c *Context) IsSetFromAny(names ...string) bool {
+	for _, name := range names {
+		if c.IsSet(name) {
+			return true
+		}
 	}
 
 	return false
 }
 
+// IsDefault determines if the flag holds the default value it is declared
+// with, whether it was set or not. A value from env or file differs from the
+// default like one given on the command line. Slices and maps hold their
+// default when their elements are the same, in the same order.
+func (c *Context) IsDefault(name string) bool {
+	fs := lookupFlagSet(name, c)
+	if fs == nil {
+		return false
+	}
+
+	f := fs.Lookup(name)
+	def, ok := rootApp(c).flagDefaults[lookupFlag(name, c)]
+	if !ok {
+		return f.Value.String() == f.DefValue
+	}
+	if def.elements.IsValid() {
+		return sameElements(flagElements(f.Value), def.elements)
+	}
+	return f.Value.String() == def.text
+}
+
 // LocalFlagNames returns a slice of flag names used in this context.
 func (c *Context) LocalFlagNames() []string {
 	var names []string
@@ -94,26 +152,113 @@ func (c *Context) FlagNames() []string {
 	return names
 }
 
+// maxLineageDepth bounds the contexts walked by Lineage and WithParent, so a
+// cycle of parent contexts fails instead of looping forever
+const maxLineageDepth = 1000
+
+// WithParent sets the parent context of the context. It returns an error
+// instead when the context is an ancestor of parent, which would make the
+// lineage a cycle, or when the lineage of parent is deeper than Lineage
+// allows, as when it is a cycle already.
+func (c *Context) WithParent(parent *Context) error {
+	depth := 0
+	for cur := parent; cur != nil; cur = cur.parentContext {
+		if cur == c {
+			return errors.New("cannot set a descendant context as parent: the context lineage would be a cycle")
+		}
+		if depth++; depth == maxLineageDepth {
+			return fmt.Errorf("cannot set a parent context whose lineage is deeper than %d contexts", maxLineageDepth)
+		}
+	}
+	c.parentContext = parent
+	return nil
+}
+
+// SetContext replaces the embedded context.Context of the context. The
+// contexts of the commands run afterwards inherit ctx. It panics if ctx is
+// nil, like http.Request.WithContext does.
+func (c *Context) SetContext(ctx context.Context) {
+	if ctx == nil {
+		panic("cli: nil context")
+	}
+	c.Context = ctx
+}
+
+// WithContext returns a copy of the context holding ctx as its embedded
+// context.Context, leaving the context itself as it is. The copy has the same
+// parent context, flags and arguments. It panics if ctx is nil.
+func (c *Context) WithContext(ctx context.Context) *Context {
+	if ctx == nil {
+		panic("cli: nil context")
+	}
+	cp := *c
+	cp.Context = ctx
+	return &cp
+}
+
 // Lineage returns *this* context and all of its ancestor contexts in order from
 // child to parent
 func (c *Context) Lineage() []*Context {
 	var lineage []*Context
 
 	for cur := c; cur != nil; cur = cur.parentContext {
+		if len(lineage) == maxLineageDepth {
+			panic(fmt.Sprintf("cli: context lineage is deeper than %d contexts, its parent contexts probably form a cycle", maxLineageDepth))
+		}
 		lineage = append(lineage, cur)
 	}
 
 	return lineage
 }
 
-// Value returns the value of the flag corresponding to `name`
+// Value returns the value of the flag corresponding to `name`, or nil if
+// there is no such flag in this context
 func (c *Context) Value(name string) interface{} {
-	return c.flagSet.Lookup(name).Value.(flag.Getter).Get()
+	c.recordFlagRead(name)
+	if c.flagSet == nil {
+		return nil
+	}
+	if f := c.flagSet.Lookup(name); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			return getter.Get()
+		}
+	}
+	return nil
+}
+
+// ValueOrDefault returns the value of the flag corresponding to `name`, or
+// def if there is no such flag in this context
+func (c *Context) ValueOrDefault(name string, def interface{}) interface{} {
+	c.recordFlagRead(name)
+	if c.flagSet == nil || c.flagSet.Lookup(name) == nil {
+		return def
+	}
+	return c.Value(name)
+}
+
+// Lookup returns the current value of the flag corresponding to `name` in
+// this context or its ancestors, and whether the flag exists. The value is
+// the one the type specific accessor, like Int or StringSlice, would return.
+func (c *Context) Lookup(name string) (interface{}, bool) {
+	fs := lookupFlagSet(name, c)
+	if fs == nil {
+		return nil, false
+	}
+	return parsedFlagValue(fs.Lookup(name).Value), true
+}
+
+// ActionErr returns the error the action, the Before hook or a nested command
+// failed with. It is only meaningful inside an After hook.
+func (c *Context) ActionErr() error {
+	return c.actionErr
 }
 
 // Args returns the command line arguments associated with the context.
 func (c *Context) Args() Args {
 	ret := args(c.flagSet.Args())
+	if r := c.flagReadRecorder(); r != nil && c.argsPath != "" {
+		return &recordedArgs{args: &ret, recorder: r, path: c.argsPath}
+	}
 	return &ret
 }
 
@@ -151,6 +296,13 @@ func lookupFlag(name string, ctx *Context) Flag {
 }
 
 func lookupFlagSet(name string, ctx *Context) *flag.FlagSet {
+	ctx.recordFlagRead(name)
+	return findFlagSet(name, ctx)
+}
+
+// findFlagSet is lookupFlagSet without recording the read, for the checks
+// done by the package itself
+func findFlagSet(name string, ctx *Context) *flag.FlagSet {
 	for _, c := range ctx.Lineage() {
 		if f := c.flagSet.Lookup(name); f != nil {
 			return c.flagSet
@@ -225,49 +377,304 @@ type requiredFlagsErr interface {
 	getMissingFlags() []string
 }
 
+// RequiredFlagsError is implemented by the error returned when required flags
+// are not set. MissingFlags returns the definitions of the missing flags, in
+// the order they are declared, so the sources of their values can be told.
+type RequiredFlagsError interface {
+	error
+	MissingFlags() []Flag
+	// MissingFlagNames returns the names the missing flags are reported by
+	MissingFlagNames() []string
+}
+
 type errRequiredFlags struct {
 	missingFlags []string
+	flags        []Flag
+	// exitCode is the App.RequiredFlagsExitCode
+	exitCode int
+	// conditions are the conditions making the missing flags required, like
+	// `when "mode" is "signed"`, or empty for Required flags
+	conditions []string
+	// docsURL is the link to the documentation appended to the error
+	docsURL string
 }
 
 func (e *errRequiredFlags) Error() string {
+	if e.docsURL != "" {
+		return e.message() + ", see " + e.docsURL
+	}
+	return e.message()
+}
+
+func (e *errRequiredFlags) message() string {
 	numberOfMissingFlags := len(e.missingFlags)
 	if numberOfMissingFlags == 1 {
-		return fmt.Sprintf("Required flag %q not set", e.missingFlags[0])
+		msg := fmt.Sprintf("Required flag %q not set", e.missingFlags[0])
+		if condition := e.condition(0); condition != "" {
+			msg = fmt.Sprintf("flag %q is required %s", e.missingFlags[0], condition)
+		}
+		if len(e.flags) == 1 {
+			if sources := flagSourcesText(e.flags[0]); sources != "" {
+				msg += " (can also be set via " + sources + ")"
+			}
+		}
+		return msg
 	}
 	joinedMissingFlags := strings.Join(e.missingFlags, ", ")
-	return fmt.Sprintf("Required flags %q not set", joinedMissingFlags)
+	msg := fmt.Sprintf("Required flags %q not set", joinedMissingFlags)
+
+	var hints []string
+	for i, f := range e.flags {
+		if condition := e.condition(i); condition != "" {
+			hints = append(hints, e.missingFlags[i]+" is required "+condition)
+		}
+		if sources := flagSourcesText(f); sources != "" {
+			hints = append(hints, e.missingFlags[i]+" can also be set via "+sources)
+		}
+	}
+	if len(hints) != 0 {
+		msg += " (" + strings.Join(hints, "; ") + ")"
+	}
+	return msg
+}
+
+func (e *errRequiredFlags) condition(i int) string {
+	if i < len(e.conditions) {
+		return e.conditions[i]
+	}
+	return ""
 }
 
 func (e *errRequiredFlags) getMissingFlags() []string {
 	return e.missingFlags
 }
 
+// MissingFlags returns the definitions of the missing flags
+func (e *errRequiredFlags) MissingFlags() []Flag {
+	return e.flags
+}
+
+// MissingFlagNames returns the names the missing flags are reported by
+func (e *errRequiredFlags) MissingFlagNames() []string {
+	return e.missingFlags
+}
+
+// ExitCode returns the App.RequiredFlagsExitCode, 2 by default
+func (e *errRequiredFlags) ExitCode() int {
+	if e.exitCode != 0 {
+		return e.exitCode
+	}
+	return 2
+}
+
+// flagSourcesText lists the environment variables and file which the value of
+// f can be read from, like "$TOKEN or the file /run/token"
+func flagSourcesText(f Flag) string {
+	var sources []string
+
+	prefix, suffix := "$", ""
+	if runtime.GOOS == "windows" {
+		prefix, suffix = "%", "%"
+	}
+	for _, envVar := range flagStringSliceField(f, "EnvVars") {
+		if envVar = strings.TrimSpace(envVar); envVar != "" {
+			sources = append(sources, prefix+envVar+suffix)
+		}
+	}
+	if filePath := flagStringField(f, "FilePath"); filePath != "" {
+		sources = append(sources, "the file "+filePath)
+	}
+
+	switch len(sources) {
+	case 0:
+		return ""
+	case 1:
+		return sources[0]
+	}
+	return strings.Join(sources[:len(sources)-1], ", ") + " or " + sources[len(sources)-1]
+}
+
 func checkRequiredFlags(flags []Flag, context *Context) requiredFlagsErr {
 	var missingFlags []string
+	var missing []Flag
+	var conditions []string
 	for _, f := range flags {
+		condition := ""
+		required := false
 		if rf, ok := f.(RequiredFlag); ok && rf.IsRequired() {
-			var flagPresent bool
+			required = true
+		} else if cond := flagRequiredCondition(f, "RequiredIf"); cond != nil && cond.Holds(context) {
+			required, condition = true, "when "+cond.String()
+		} else if cond := flagRequiredCondition(f, "RequiredUnless"); cond != nil && !cond.Holds(context) {
+			required, condition = true, "unless "+cond.String()
+		}
+
+		if required {
+			// a required flag is satisfied by a value from any of its
+			// sources: the command line, an environment variable, a file,
+			// or the LoadFlagValues of the App
+			flagPresent := f.IsSet()
 			var flagName string
 
+			// the longest name reports the flag, which may have only a
+			// single character one
 			for _, key := range f.Names() {
-				if len(key) > 1 {
+				if len(key) > len(flagName) {
 					flagName = key
 				}
 
-				if context.IsSet(strings.TrimSpace(key)) {
+				if context.isSet(strings.TrimSpace(key)) {
 					flagPresent = true
 				}
 			}
 
 			if !flagPresent && flagName != "" {
 				missingFlags = append(missingFlags, flagName)
+				missing = append(missing, f)
+				conditions = append(conditions, condition)
 			}
 		}
 	}
 
 	if len(missingFlags) != 0 {
-		return &errRequiredFlags{missingFlags: missingFlags}
+		err := &errRequiredFlags{missingFlags: missingFlags, flags: missing, conditions: conditions}
+		if context.App != nil {
+			err.exitCode = context.App.RequiredFlagsExitCode
+		}
+		return err
 	}
 
 	return nil
 }
+
+// FlagsRequiredTogetherError is implemented by the error returned when only
+// some of a group of flags required together are set, see
+// App.FlagsRequiredTogether.
+type FlagsRequiredTogetherError interface {
+	error
+	// Group returns the names of the flags of the group
+	Group() []string
+	// MissingFlags returns the names of the flags of the group not set
+	MissingFlags() []string
+	// ProvidedFlags returns the names of the flags of the group which are set
+	ProvidedFlags() []string
+}
+
+type errFlagsRequiredTogether struct {
+	group    []string
+	missing  []string
+	provided []string
+}
+
+func (e *errFlagsRequiredTogether) Error() string {
+	return fmt.Sprintf("Flags %q are required together: missing %s, provided %s",
+		strings.Join(e.group, ", "), quoteNames(e.missing), quoteNames(e.provided))
+}
+
+func (e *errFlagsRequiredTogether) Group() []string {
+	return e.group
+}
+
+func (e *errFlagsRequiredTogether) MissingFlags() []string {
+	return e.missing
+}
+
+func (e *errFlagsRequiredTogether) ProvidedFlags() []string {
+	return e.provided
+}
+
+// quoteNames returns the names quoted and separated by commas
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// checkFlagsRequiredTogether checks the groups of flags required together of
+// the App and commands of context and of its ancestors, so that a group of a
+// parent command is checked again once a subcommand has parsed its flags. Like
+// for Required flags, a flag is set on the command line, by env or by file,
+// but not by its default value.
+func checkFlagsRequiredTogether(context *Context) error {
+	seen := make(map[string]bool)
+	for _, ctx := range context.Lineage() {
+		var groups [][]string
+		if ctx.App != nil {
+			groups = append(groups, ctx.App.FlagsRequiredTogether...)
+		}
+		if ctx.Command != nil {
+			groups = append(groups, ctx.Command.FlagsRequiredTogether...)
+		}
+
+		for _, group := range groups {
+			key := strings.Join(group, "\x00")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			var missing, provided []string
+			for _, name := range group {
+				if context.isSet(name) {
+					provided = append(provided, name)
+				} else {
+					missing = append(missing, name)
+				}
+			}
+			if len(missing) != 0 && len(provided) != 0 {
+				return &errFlagsRequiredTogether{group: group, missing: missing, provided: provided}
+			}
+		}
+	}
+	return nil
+}
+
+// validateFlags runs the Validator of the flags which have been set, in the
+// order they are declared, and stops at the first failure
+func validateFlags(flags []Flag, context *Context) error {
+	for _, f := range flags {
+		fv := flagValue(f)
+		if fv.Kind() != reflect.Struct {
+			continue
+		}
+
+		field := fv.FieldByName("Validator")
+		if !field.IsValid() || field.IsNil() {
+			continue
+		}
+		validator, ok := field.Interface().(func(interface{}) error)
+		if !ok {
+			continue
+		}
+
+		for _, name := range f.Names() {
+			if !context.isSet(name) {
+				continue
+			}
+
+			ff := findFlagSet(name, context).Lookup(name)
+			if err := validator(parsedFlagValue(ff.Value)); err != nil {
+				return fmt.Errorf("invalid value for flag %s: %s", f.Names()[0], err)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// parsedFlagValue returns what the Context accessor of a flag would return,
+// preferring the Value method of slices and timestamps over flag.Getter
+func parsedFlagValue(v flag.Value) interface{} {
+	if m := reflect.ValueOf(v).MethodByName("Value"); m.IsValid() {
+		if t := m.Type(); t.NumIn() == 0 && t.NumOut() == 1 {
+			return m.Call(nil)[0].Interface()
+		}
+	}
+	if g, ok := v.(flag.Getter); ok {
+		return g.Get()
+	}
+	return v.String()
+}
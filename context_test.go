@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"flag"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"runtime"
 	"sort"
 	"strings"
 	"testing"
@@ -173,6 +177,37 @@ func TestContext_IsSet(t *testing.T) {
 	expect(t, ctx.IsSet("bogus"), false)
 }
 
+func TestContext_IsSet_explicitDefault(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"run"}, false},
+		{[]string{"run", "--count=0"}, true},
+		{[]string{"run", "-c", "0"}, true},
+		{[]string{"run", "--count", "3"}, true},
+	}
+
+	for _, test := range tests {
+		var countIsSet, cIsSet bool
+		a := &App{
+			Flags: []Flag{
+				&IntFlag{Name: "count", Aliases: []string{"c"}},
+			},
+			Action: func(ctx *Context) error {
+				countIsSet = ctx.IsSet("count")
+				cIsSet = ctx.IsSet("c")
+				return nil
+			},
+		}
+
+		err := a.Run(test.args)
+		expect(t, err, nil)
+		expect(t, countIsSet, test.expected)
+		expect(t, cIsSet, test.expected)
+	}
+}
+
 // XXX Corresponds to hack in context.IsSet for flags with EnvVar field
 // Should be moved to `flag_test` in v2
 func TestContext_IsSet_fromEnv(t *testing.T) {
@@ -219,6 +254,134 @@ func TestContext_IsSet_fromEnv(t *testing.T) {
 	expect(t, uIsSet, false)
 }
 
+func TestContext_IsSetFromAny(t *testing.T) {
+	set := flag.NewFlagSet("test", 0)
+	set.Bool("one-flag", false, "doc")
+	set.Bool("two-flag", false, "doc")
+	ctx := NewContext(nil, set, nil)
+
+	_ = set.Parse([]string{"--two-flag"})
+
+	expect(t, ctx.IsSetFromAny("one-flag", "two-flag"), true)
+	expect(t, ctx.IsSetFromAny("one-flag", "bogus"), false)
+	expect(t, ctx.IsSetFromAny(), false)
+}
+
+func TestContext_IsDefault(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected map[string]bool
+	}{
+		{
+			[]string{"run"},
+			map[string]bool{"count": true, "c": true, "tags": true, "labels": true, "bogus": false},
+		},
+		{
+			[]string{"run", "--count", "3", "--tags", "a,b", "--labels", "env=prod"},
+			map[string]bool{"count": true, "c": true, "tags": true, "labels": true},
+		},
+		{
+			[]string{"run", "-c", "4", "--tags", "b", "--tags", "a", "--labels", "env=dev"},
+			map[string]bool{"count": false, "c": false, "tags": false, "labels": false},
+		},
+	}
+
+	for _, test := range tests {
+		actual := map[string]bool{}
+		a := &App{
+			Flags: []Flag{
+				&IntFlag{Name: "count", Aliases: []string{"c"}, Value: 3},
+				&StringSliceFlag{Name: "tags", Value: NewStringSlice("a", "b")},
+				&StringMapFlag{Name: "labels", Value: NewStringMap(map[string]string{"env": "prod"})},
+			},
+			Action: func(ctx *Context) error {
+				for name := range test.expected {
+					actual[name] = ctx.IsDefault(name)
+				}
+				return nil
+			},
+		}
+
+		err := a.Run(test.args)
+		expect(t, err, nil)
+		expect(t, actual, test.expected)
+	}
+}
+
+func TestContext_IsDefault_EnvAndFile(t *testing.T) {
+	defer resetEnvVar("APP_COUNT")
+	defer resetEnvVar("APP_TAGS")
+	_ = os.Setenv("APP_COUNT", "5")
+	_ = os.Setenv("APP_TAGS", "a,b")
+
+	file, err := ioutil.TempFile("", "urfave_cli_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString("prod")
+	_ = file.Close()
+
+	actual := map[string]bool{}
+	a := &App{
+		Flags: []Flag{
+			&IntFlag{Name: "count", Value: 3, EnvVars: []string{"APP_COUNT"}},
+			&StringSliceFlag{Name: "tags", Value: NewStringSlice("a", "b"), EnvVars: []string{"APP_TAGS"}},
+			&StringFlag{Name: "env", Value: "dev", FilePath: file.Name()},
+		},
+		PersistentFlags: []Flag{
+			&UintFlag{Name: "jobs", Value: 1, EnvVars: []string{"APP_COUNT"}},
+		},
+		Commands: []*Command{{Name: "cmd", Action: func(ctx *Context) error {
+			actual["jobs"] = ctx.IsDefault("jobs")
+			return nil
+		}}},
+		Action: func(ctx *Context) error {
+			for _, name := range []string{"count", "tags", "env", "jobs"} {
+				actual[name] = ctx.IsDefault(name)
+			}
+			return nil
+		},
+	}
+
+	// the defaults are the declared values on the next runs too
+	for i := 0; i < 2; i++ {
+		actual = map[string]bool{}
+		expect(t, a.Run([]string{"run"}), nil)
+		expect(t, actual, map[string]bool{"count": false, "tags": true, "env": false, "jobs": false})
+	}
+
+	actual = map[string]bool{}
+	expect(t, a.Run([]string{"run", "cmd"}), nil)
+	expect(t, actual, map[string]bool{"jobs": false})
+}
+
+func TestContext_HiddenEnvOnlyBool(t *testing.T) {
+	var isSet, value bool
+
+	os.Clearenv()
+	_ = os.Setenv("MYAPP_NEW_THING", "true")
+	output := &bytes.Buffer{}
+	a := App{
+		Writer: output,
+		Flags: []Flag{
+			&BoolFlag{Name: "new-thing", EnvVars: []string{"MYAPP_NEW_THING"}, Hidden: true},
+		},
+		Action: func(ctx *Context) error {
+			isSet = ctx.IsSetFromAny("new-thing")
+			value = ctx.Bool("new-thing")
+			return ShowAppHelp(ctx)
+		},
+	}
+	_ = a.Run([]string{"run"})
+	expect(t, isSet, true)
+	expect(t, value, true)
+
+	if strings.Contains(output.String(), "new-thing") {
+		t.Errorf("expected hidden flag to be absent from help, got %q", output.String())
+	}
+}
+
 func TestContext_NumFlags(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Bool("myflag", false, "doc")
@@ -293,6 +456,93 @@ func TestContext_Lineage(t *testing.T) {
 	expect(t, lineage[1], parentCtx)
 }
 
+func TestContext_WithParent(t *testing.T) {
+	parentCtx := NewContext(nil, flag.NewFlagSet("parent", 0), nil)
+	ctx := NewContext(nil, flag.NewFlagSet("child", 0), nil)
+
+	expect(t, ctx.WithParent(parentCtx), nil)
+	expect(t, ctx.Lineage(), []*Context{ctx, parentCtx})
+
+	if err := parentCtx.WithParent(ctx); err == nil {
+		t.Fatal("expected an error for a cycle")
+	}
+	if err := ctx.WithParent(ctx); err == nil {
+		t.Fatal("expected an error for a context being its own parent")
+	}
+	expect(t, parentCtx.Lineage(), []*Context{parentCtx})
+
+	// a lineage which is a cycle already
+	a := NewContext(nil, flag.NewFlagSet("a", 0), nil)
+	b := NewContext(nil, flag.NewFlagSet("b", 0), a)
+	a.parentContext = b
+	if err := ctx.WithParent(a); err == nil {
+		t.Fatal("expected an error for a parent in a cycle")
+	}
+	expect(t, ctx.parentContext, parentCtx)
+}
+
+func TestContext_SetContext(t *testing.T) {
+	var got interface{}
+	app := &App{
+		Before: func(c *Context) error {
+			c.SetContext(context.WithValue(c.Context, "key", "val"))
+			return nil
+		},
+		Commands: []*Command{{
+			Name: "cmd",
+			Action: func(c *Context) error {
+				got = c.Context.Value("key")
+				return nil
+			},
+		}},
+	}
+
+	expect(t, app.Run([]string{"app", "cmd"}), nil)
+	expect(t, got, "val")
+}
+
+func TestContext_WithContext(t *testing.T) {
+	set := flag.NewFlagSet("child", 0)
+	set.Bool("local-flag", false, "doc")
+	parentCtx := NewContext(nil, flag.NewFlagSet("parent", 0), nil)
+	ctx := NewContext(nil, set, parentCtx)
+	_ = set.Parse([]string{"--local-flag", "arg"})
+
+	derived := ctx.WithContext(context.WithValue(ctx.Context, "key", "val"))
+	if derived == ctx {
+		t.Fatal("expected a copy of the context")
+	}
+	expect(t, derived.Context.Value("key"), "val")
+	expect(t, ctx.Context.Value("key"), nil)
+	expect(t, derived.Lineage(), []*Context{derived, parentCtx})
+	expect(t, derived.Bool("local-flag"), true)
+	expect(t, derived.Args().Slice(), []string{"arg"})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a nil context")
+		}
+	}()
+	ctx.SetContext(nil)
+}
+
+func TestContext_Lineage_cycle(t *testing.T) {
+	a := NewContext(nil, flag.NewFlagSet("a", 0), nil)
+	b := NewContext(nil, flag.NewFlagSet("b", 0), a)
+	a.parentContext = b
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected a panic for a cycle")
+		}
+		if !strings.Contains(fmt.Sprint(r), "cycle") {
+			t.Errorf("expected the panic to mention the cycle, got %v", r)
+		}
+	}()
+	_ = a.IsSet("anything")
+}
+
 func TestContext_lookupFlagSet(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Bool("local-flag", false, "doc")
@@ -526,6 +776,14 @@ func TestCheckRequiredFlags(t *testing.T) {
 			},
 			parseInput: []string{"-N", "asd", "-N", "qwe"},
 		},
+		{
+			testCase: "required_flag_with_only_a_short_name",
+			flags: []Flag{
+				&StringFlag{Name: "n", Required: true},
+			},
+			expectedAnError:       true,
+			expectedErrorContents: []string{`"n"`},
+		},
 		{
 			testCase: "required_flag_with_multiple_short_names",
 			flags: []Flag{
@@ -573,3 +831,487 @@ func TestCheckRequiredFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckRequiredFlags_sources(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("environment variables are written differently on windows")
+	}
+
+	tests := []struct {
+		flags    []Flag
+		expected string
+	}{
+		{
+			flags:    []Flag{&StringFlag{Name: "token", Required: true, EnvVars: []string{"MYAPP_TOKEN"}}},
+			expected: `Required flag "token" not set (can also be set via $MYAPP_TOKEN)`,
+		},
+		{
+			flags:    []Flag{&StringFlag{Name: "token", Required: true, FilePath: "/run/secrets/token"}},
+			expected: `Required flag "token" not set (can also be set via the file /run/secrets/token)`,
+		},
+		{
+			flags:    []Flag{&StringFlag{Name: "token", Required: true, EnvVars: []string{"A", "B"}, FilePath: "/token"}},
+			expected: `Required flag "token" not set (can also be set via $A, $B or the file /token)`,
+		},
+		{
+			flags:    []Flag{&StringFlag{Name: "token", Required: true}},
+			expected: `Required flag "token" not set`,
+		},
+		{
+			flags: []Flag{
+				&StringFlag{Name: "token", Required: true, EnvVars: []string{"MYAPP_TOKEN"}},
+				&StringFlag{Name: "user", Required: true},
+			},
+			expected: `Required flags "token, user" not set (token can also be set via $MYAPP_TOKEN)`,
+		},
+	}
+
+	for _, test := range tests {
+		set := flag.NewFlagSet("test", 0)
+		for _, f := range test.flags {
+			_ = f.Apply(set)
+		}
+
+		err := checkRequiredFlags(test.flags, NewContext(nil, set, nil))
+		if err == nil {
+			t.Fatalf("expected an error for %v", test.flags)
+		}
+		expect(t, err.Error(), test.expected)
+
+		rerr, ok := err.(RequiredFlagsError)
+		if !ok {
+			t.Fatalf("expected a RequiredFlagsError, got %T", err)
+		}
+		expect(t, rerr.MissingFlags(), test.flags)
+	}
+}
+
+func TestRequiredFlagsError_ExitCodeAndHelp(t *testing.T) {
+	for _, code := range []int{0, 64} {
+		var output, errOutput bytes.Buffer
+		app := &App{
+			Name:                  "app",
+			HelpName:              "app",
+			Writer:                &output,
+			ErrWriter:             &errOutput,
+			RequiredFlagsExitCode: code,
+			Commands: []*Command{{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{Name: "region", Required: true},
+					&StringFlag{Name: "z", Required: true},
+				},
+				Action: func(*Context) error { return nil },
+			}},
+		}
+
+		err := app.Run([]string{"app", "deploy"})
+		rerr, ok := err.(RequiredFlagsError)
+		if !ok {
+			t.Fatalf("expected a RequiredFlagsError, got %#v", err)
+		}
+		expect(t, rerr.MissingFlagNames(), []string{"region", "z"})
+
+		expectedCode := code
+		if code == 0 {
+			expectedCode = 2
+		}
+		expect(t, err.(ExitCoder).ExitCode(), expectedCode)
+
+		if !strings.Contains(errOutput.String(), "app deploy [command options]") {
+			t.Errorf("expected the help of the command on ErrWriter, got %q", errOutput.String())
+		}
+		expect(t, output.String(), "")
+	}
+}
+
+func TestCheckRequiredFlags_shortNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    []Flag
+		args     []string
+		expected string
+	}{
+		{
+			name:     "single short name",
+			flags:    []Flag{&BoolFlag{Name: "p", Required: true}},
+			expected: `Required flag "p" not set`,
+		},
+		{
+			name:     "short name with an alias",
+			flags:    []Flag{&StringFlag{Name: "p", Aliases: []string{"port"}, Required: true}},
+			expected: `Required flag "port" not set`,
+		},
+		{
+			name: "only the short named flag missing",
+			flags: []Flag{
+				&StringFlag{Name: "host", Required: true},
+				&BoolFlag{Name: "p", Required: true},
+				&StringFlag{Name: "u", Aliases: []string{"user"}, Required: true},
+			},
+			args:     []string{"--host", "h", "-u", "me"},
+			expected: `Required flag "p" not set`,
+		},
+		{
+			name: "several missing",
+			flags: []Flag{
+				&StringFlag{Name: "host", Required: true},
+				&BoolFlag{Name: "p", Required: true},
+			},
+			expected: `Required flags "host, p" not set`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", 0)
+			for _, f := range test.flags {
+				_ = f.Apply(set)
+			}
+			_ = set.Parse(test.args)
+
+			err := checkRequiredFlags(test.flags, NewContext(nil, set, nil))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			expect(t, err.Error(), test.expected)
+		})
+	}
+}
+
+func TestCheckRequiredFlags_satisfiedBySource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("environment variables are written differently on windows")
+	}
+	defer resetEnvVar("T")()
+	_ = os.Unsetenv("T")
+
+	file, err := ioutil.TempFile("", "urfave_cli_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString("from-file")
+	_ = file.Close()
+	defer os.Remove(file.Name())
+
+	tests := []struct {
+		name     string
+		args     []string
+		env      string
+		filePath string
+		expected string
+		err      string
+	}{
+		{name: "command line", args: []string{"-t", "from-cli"}, expected: "from-cli"},
+		{name: "short env var", env: "from-env", expected: "from-env"},
+		{name: "file", filePath: file.Name(), expected: "from-file"},
+		{name: "missing", err: `Required flag "token" not set (can also be set via $T)`},
+		{name: "missing file", filePath: file.Name() + ".missing", err: `Required flag "token" not set (can also be set via $T or the file ` + file.Name() + `.missing)`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = os.Unsetenv("T")
+			if test.env != "" {
+				_ = os.Setenv("T", test.env)
+			}
+
+			var token string
+			app := newTestApp()
+			app.Commands = []*Command{{
+				Name: "login",
+				Flags: []Flag{&StringFlag{
+					Name:     "token",
+					Aliases:  []string{"t"},
+					EnvVars:  []string{"T"},
+					FilePath: test.filePath,
+					Required: true,
+				}},
+				Action: func(c *Context) error {
+					token = c.String("token")
+					return nil
+				},
+			}}
+
+			err := app.Run(append([]string{"app", "login"}, test.args...))
+			if test.err != "" {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				expect(t, err.Error(), test.err)
+				return
+			}
+			expect(t, err, nil)
+			expect(t, token, test.expected)
+		})
+	}
+}
+
+func TestCheckRequiredFlags_conditional(t *testing.T) {
+	signedByParent := ConditionFunc(`the parent "mode" is "signed"`, func(c *Context) bool {
+		return c.String("mode") == "signed"
+	})
+
+	tests := []struct {
+		name     string
+		args     []string
+		flags    []Flag
+		expected string
+	}{
+		{
+			name:     "if value holds",
+			args:     []string{"app", "--mode", "signed", "sign"},
+			flags:    []Flag{&StringFlag{Name: "key", RequiredIf: FlagIs("mode", "signed")}},
+			expected: `flag "key" is required when "mode" is "signed"`,
+		},
+		{
+			name:  "if value does not hold",
+			args:  []string{"app", "--mode", "plain", "sign"},
+			flags: []Flag{&StringFlag{Name: "key", RequiredIf: FlagIs("mode", "signed")}},
+		},
+		{
+			name:  "if value holds and set",
+			args:  []string{"app", "--mode", "signed", "sign", "--key", "k"},
+			flags: []Flag{&StringFlag{Name: "key", RequiredIf: FlagIs("mode", "signed")}},
+		},
+		{
+			name: "if set",
+			args: []string{"app", "sign", "--cert", "c"},
+			flags: []Flag{
+				&StringFlag{Name: "cert"},
+				&StringFlag{Name: "key", RequiredIf: FlagIsSet("cert")},
+			},
+			expected: `flag "key" is required when "cert" is set`,
+		},
+		{
+			name:     "unless",
+			args:     []string{"app", "sign"},
+			flags:    []Flag{&StringFlag{Name: "key", RequiredUnless: FlagIsSet("mode")}},
+			expected: `flag "key" is required unless "mode" is set`,
+		},
+		{
+			name:  "unless holds",
+			args:  []string{"app", "--mode", "plain", "sign"},
+			flags: []Flag{&StringFlag{Name: "key", RequiredUnless: FlagIsSet("mode")}},
+		},
+		{
+			name:     "predicate",
+			args:     []string{"app", "--mode", "signed", "sign"},
+			flags:    []Flag{&StringFlag{Name: "key", RequiredIf: signedByParent}},
+			expected: `flag "key" is required when the parent "mode" is "signed"`,
+		},
+		{
+			name: "with required",
+			args: []string{"app", "--mode", "signed", "sign"},
+			flags: []Flag{
+				&StringFlag{Name: "id", Required: true},
+				&StringFlag{Name: "key", RequiredIf: FlagIs("mode", "signed")},
+			},
+			expected: `Required flags "id, key" not set (key is required when "mode" is "signed")`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := newTestApp()
+			app.Flags = []Flag{&StringFlag{Name: "mode"}}
+			app.Commands = []*Command{{
+				Name:   "sign",
+				Flags:  test.flags,
+				Action: func(*Context) error { return nil },
+			}}
+
+			err := app.Run(test.args)
+			if test.expected == "" {
+				expect(t, err, nil)
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			expect(t, err.Error(), test.expected)
+			if _, ok := err.(RequiredFlagsError); !ok {
+				t.Errorf("expected a RequiredFlagsError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestCheckFlagsRequiredTogether(t *testing.T) {
+	tlsFlags := func() []Flag {
+		return []Flag{
+			&StringFlag{Name: "tls-cert"},
+			&StringFlag{Name: "tls-key"},
+			&StringFlag{Name: "tls-ca", Value: "ca.pem"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		group    []string
+		missing  []string
+		provided []string
+	}{
+		{
+			name: "none set",
+			args: []string{"app"},
+		},
+		{
+			name: "all set",
+			args: []string{"app", "--tls-cert", "c", "--tls-key", "k"},
+		},
+		{
+			name:     "one set",
+			args:     []string{"app", "--tls-cert", "c"},
+			missing:  []string{"tls-key"},
+			provided: []string{"tls-cert"},
+		},
+		{
+			name:     "default value",
+			args:     []string{"app", "--tls-cert", "c", "--tls-key", "k"},
+			group:    []string{"tls-cert", "tls-key", "tls-ca"},
+			missing:  []string{"tls-ca"},
+			provided: []string{"tls-cert", "tls-key"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			group := test.group
+			if group == nil {
+				group = []string{"tls-cert", "tls-key"}
+			}
+			app := newTestApp()
+			app.Flags = tlsFlags()
+			app.FlagsRequiredTogether = [][]string{group}
+			app.ErrWriter = &bytes.Buffer{}
+			app.Action = func(*Context) error { return nil }
+
+			err := app.Run(test.args)
+			if test.missing == nil {
+				expect(t, err, nil)
+				return
+			}
+
+			gerr, ok := err.(FlagsRequiredTogetherError)
+			if !ok {
+				t.Fatalf("expected a FlagsRequiredTogetherError, got %v", err)
+			}
+			expect(t, gerr.Group(), group)
+			expect(t, gerr.MissingFlags(), test.missing)
+			expect(t, gerr.ProvidedFlags(), test.provided)
+		})
+	}
+}
+
+func TestCheckFlagsRequiredTogether_env(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_TLS_KEY", "key.pem")
+
+	app := newTestApp()
+	app.Flags = []Flag{
+		&StringFlag{Name: "tls-cert"},
+		&StringFlag{Name: "tls-key", EnvVars: []string{"APP_TLS_KEY"}},
+	}
+	app.FlagsRequiredTogether = [][]string{{"tls-cert", "tls-key"}}
+	app.ErrWriter = &bytes.Buffer{}
+	app.Action = func(*Context) error { return nil }
+
+	err := app.Run([]string{"app", "--tls-cert", "c"})
+	expect(t, err, nil)
+
+	err = app.Run([]string{"app"})
+	if err == nil {
+		t.Fatal("expected an error for the flag set by env alone")
+	}
+	expect(t, err.Error(), `Flags "tls-cert, tls-key" are required together: missing "tls-cert", provided "tls-key"`)
+}
+
+func TestCheckFlagsRequiredTogether_subcommand(t *testing.T) {
+	var ran []string
+	app := newTestApp()
+	app.ErrWriter = &bytes.Buffer{}
+	app.Commands = []*Command{{
+		Name: "server",
+		PersistentFlags: []Flag{
+			&StringFlag{Name: "tls-cert"},
+			&StringFlag{Name: "tls-key"},
+		},
+		FlagsRequiredTogether: [][]string{{"tls-cert", "tls-key"}},
+		Subcommands: []*Command{{
+			Name: "start",
+			Before: func(*Context) error {
+				ran = append(ran, "before")
+				return nil
+			},
+			Action: func(*Context) error {
+				ran = append(ran, "action")
+				return nil
+			},
+		}},
+	}}
+
+	err := app.Run([]string{"app", "server", "start", "--tls-key", "k"})
+	if _, ok := err.(FlagsRequiredTogetherError); !ok {
+		t.Fatalf("expected a FlagsRequiredTogetherError, got %v", err)
+	}
+	expect(t, len(ran), 0)
+
+	err = app.Run([]string{"app", "server", "--tls-cert", "c", "start", "--tls-key", "k"})
+	expect(t, err, nil)
+	expect(t, ran, []string{"before", "action"})
+}
+
+func TestContext_Lookup(t *testing.T) {
+	parentSet := flag.NewFlagSet("test", 0)
+	parentSet.Int("top", 12, "doc")
+	set := flag.NewFlagSet("test", 0)
+	set.String("name", "bob", "doc")
+	_ = (&StringSliceFlag{Name: "tag", Value: NewStringSlice("a", "b")}).Apply(set)
+
+	parentCtx := NewContext(nil, parentSet, nil)
+	c := NewContext(nil, set, parentCtx)
+
+	v, ok := c.Lookup("name")
+	expect(t, ok, true)
+	expect(t, v, "bob")
+
+	v, ok = c.Lookup("top")
+	expect(t, ok, true)
+	expect(t, v, 12)
+
+	v, ok = c.Lookup("tag")
+	expect(t, ok, true)
+	expect(t, v, []string{"a", "b"})
+
+	v, ok = c.Lookup("missing")
+	expect(t, ok, false)
+	expect(t, v, nil)
+}
+
+func TestContext_Value_unknownFlag(t *testing.T) {
+	set := flag.NewFlagSet("test", 0)
+	set.Int("myflag", 12, "doc")
+	c := NewContext(nil, set, nil)
+
+	expect(t, c.Value("myflag"), 12)
+	expect(t, c.Value("missing"), nil)
+
+	set.Var(&selectorValue{}, "selector", "doc")
+	expect(t, c.Value("selector"), nil)
+
+	expect(t, NewContext(nil, nil, nil).Value("myflag"), nil)
+}
+
+func TestContext_ValueOrDefault(t *testing.T) {
+	set := flag.NewFlagSet("test", 0)
+	set.Int("myflag", 12, "doc")
+	set.Var(&selectorValue{}, "selector", "doc")
+	c := NewContext(nil, set, nil)
+
+	expect(t, c.ValueOrDefault("myflag", 42), 12)
+	expect(t, c.ValueOrDefault("missing", 42), 42)
+	expect(t, c.ValueOrDefault("selector", 42), nil)
+	expect(t, NewContext(nil, nil, nil).ValueOrDefault("myflag", "def"), "def")
+}
@@ -47,13 +47,15 @@ func (a *App) writeDocTemplate(w io.Writer) error {
 	}
 	return t.ExecuteTemplate(w, name, &cliTemplate{
 		App:          a,
-		Commands:     prepareCommands(a.Commands, 0),
-		GlobalArgs:   prepareArgsWithValues(a.VisibleFlags()),
+		Commands:     prepareCommands(a.Commands, 0, a.DocsURL, ""),
+		GlobalArgs:   prepareArgsWithValues(a.VisibleFlags(), a.DocsURL, ""),
 		SynopsisArgs: prepareArgsSynopsis(a.VisibleFlags()),
 	})
 }
 
-func prepareCommands(commands []*Command, level int) []string {
+// prepareCommands returns the sections of the commands, whose documentation
+// links are made from base and the path of their parent, if any
+func prepareCommands(commands []*Command, level int, base, parent string) []string {
 	var coms []string
 	for _, command := range commands {
 		if command.Hidden {
@@ -64,13 +66,22 @@ func prepareCommands(commands []*Command, level int) []string {
 			usage = command.Usage
 		}
 
+		path := command.Name
+		if parent != "" {
+			path = parent + "/" + command.Name
+		}
+
 		prepared := fmt.Sprintf("%s %s\n\n%s\n",
 			strings.Repeat("#", level+2),
 			strings.Join(command.Names(), ", "),
 			usage,
 		)
 
-		flags := prepareArgsWithValues(command.Flags)
+		if link := command.docsLink(base, path); link != "" {
+			prepared += fmt.Sprintf("\n[Documentation](%s)\n", link)
+		}
+
+		flags := prepareArgsWithValues(command.VisibleFlags(), base, path)
 		if len(flags) > 0 {
 			prepared += fmt.Sprintf("\n%s", strings.Join(flags, "\n"))
 		}
@@ -81,7 +92,7 @@ func prepareCommands(commands []*Command, level int) []string {
 		if len(command.Subcommands) > 0 {
 			coms = append(
 				coms,
-				prepareCommands(command.Subcommands, level+1)...,
+				prepareCommands(command.Subcommands, level+1, base, path)...,
 			)
 		}
 	}
@@ -89,18 +100,23 @@ func prepareCommands(commands []*Command, level int) []string {
 	return coms
 }
 
-func prepareArgsWithValues(flags []Flag) []string {
-	return prepareFlags(flags, ", ", "**", "**", `""`, true)
+// prepareArgsWithValues returns the flags with their details, and their
+// documentation links made from base and the path of their command
+func prepareArgsWithValues(flags []Flag, base, path string) []string {
+	return prepareFlags(flags, ", ", "**", "**", `""`, true, func(f Flag) string {
+		return flagDocsLink(f, base, path)
+	})
 }
 
 func prepareArgsSynopsis(flags []Flag) []string {
-	return prepareFlags(flags, "|", "[", "]", "[value]", false)
+	return prepareFlags(flags, "|", "[", "]", "[value]", false, nil)
 }
 
 func prepareFlags(
 	flags []Flag,
 	sep, opener, closer, value string,
 	addDetails bool,
+	docsLink func(Flag) string,
 ) []string {
 	args := []string{}
 	for _, f := range flags {
@@ -130,6 +146,12 @@ func prepareFlags(
 			modifiedArg += flagDetails(flag)
 		}
 
+		if docsLink != nil {
+			if link := docsLink(f); link != "" {
+				modifiedArg += fmt.Sprintf(" [Documentation](%s)", link)
+			}
+		}
+
 		args = append(args, modifiedArg+"\n")
 
 	}
@@ -139,7 +161,7 @@ func prepareFlags(
 
 // flagDetails returns a string containing the flags metadata
 func flagDetails(flag DocGenerationFlag) string {
-	description := flag.GetUsage()
+	description := expandUsage(flag, flag.GetUsage())
 	value := flag.GetValue()
 	if value != "" {
 		description += " (default: " + value + ")"
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// invalidValueFlag matches the errors about an invalid flag value, from the
+// command line or from a Validator, capturing the name of the flag
+var invalidValueFlag = regexp.MustCompile(`^invalid value (?:.* )?for flag -*([^:\s]+):`)
+
+// docsURLData is what the DocsURL templates are executed with
+type docsURLData struct {
+	// Command is the path of the command, its names separated by slashes
+	Command string
+	// Flag is the name of the flag, if the link is for a flag
+	Flag string
+}
+
+// expandDocsURL executes the DocsURL template tmpl. A "#" left at the end by
+// an empty Flag is dropped, and a template which does not parse is used as
+// it is.
+func expandDocsURL(tmpl, command, flag string) string {
+	if !strings.Contains(tmpl, "{{") {
+		return tmpl
+	}
+
+	t, err := template.New("docs").Parse(tmpl)
+	if err != nil {
+		return tmpl
+	}
+	var w bytes.Buffer
+	if err := t.Execute(&w, docsURLData{Command: command, Flag: flag}); err != nil {
+		return tmpl
+	}
+	return strings.TrimSuffix(w.String(), "#")
+}
+
+// DocsLink returns the link to the documentation of the App, or of the
+// command the App runs as a subcommand, made from DocsURL
+func (a *App) DocsLink() string {
+	if a.command != nil {
+		return a.command.DocsLink()
+	}
+	return expandDocsURL(a.DocsURL, "", "")
+}
+
+// DocsLink returns the link to the documentation of the command, made from
+// its DocsURL or else from the one of the App
+func (c *Command) DocsLink() string {
+	return c.docsLink(c.appDocsURL, c.docsPath())
+}
+
+func (c *Command) docsLink(base, path string) string {
+	if c.DocsURL != "" {
+		return expandDocsURL(c.DocsURL, path, "")
+	}
+	return expandDocsURL(base, path, "")
+}
+
+// docsPath returns the names of the command from the App, separated by
+// slashes
+func (c *Command) docsPath() string {
+	if c.commandNamePath != nil {
+		return strings.Join(c.commandNamePath, "/")
+	}
+	return c.Name
+}
+
+// flagDocsLink returns the link to the documentation of f from its DocsURL,
+// or else from base when it has a {{.Flag}}, or an empty string
+func flagDocsLink(f Flag, base, path string) string {
+	name := ""
+	if names := f.Names(); len(names) > 0 {
+		name = names[0]
+	}
+	if url := flagStringField(f, "DocsURL"); url != "" {
+		return expandDocsURL(url, path, name)
+	}
+	if strings.Contains(base, ".Flag") {
+		return expandDocsURL(base, path, name)
+	}
+	return ""
+}
+
+// errWithDocsLink is an error followed by a link to the documentation
+type errWithDocsLink struct {
+	err error
+	url string
+}
+
+func (e *errWithDocsLink) Error() string {
+	return e.err.Error() + ", see " + e.url
+}
+
+// Unwrap returns the error the link is added to
+func (e *errWithDocsLink) Unwrap() error {
+	return e.err
+}
+
+// exitErrWithDocsLink is an errWithDocsLink keeping the exit code of its
+// ExitCoder
+type exitErrWithDocsLink struct {
+	errWithDocsLink
+}
+
+func (e *exitErrWithDocsLink) ExitCode() int {
+	return e.err.(ExitCoder).ExitCode()
+}
+
+// addDocsLink appends to err the link to the documentation of the flag it is
+// about, falling back to the link of the command c, or of the App when c is
+// nil. Only invalid values and missing required flags get a link.
+func (a *App) addDocsLink(c *Command, flags []Flag, err error) error {
+	path, link := "", a.DocsLink()
+	if c == nil {
+		c = a.command
+	}
+	if c != nil {
+		path, link = c.docsPath(), c.docsLink(a.DocsURL, c.docsPath())
+	}
+
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *errRequiredFlags:
+		for _, f := range e.flags {
+			if url := flagDocsLink(f, a.DocsURL, path); url != "" {
+				link = url
+				break
+			}
+		}
+		e.docsURL = link
+		return e
+	}
+
+	m := invalidValueFlag.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	for _, f := range flags {
+		if hasName(f.Names(), m[1]) {
+			if url := flagDocsLink(f, a.DocsURL, path); url != "" {
+				link = url
+			}
+			break
+		}
+	}
+	if link == "" {
+		return err
+	}
+	if _, ok := err.(ExitCoder); ok {
+		return &exitErrWithDocsLink{errWithDocsLink{err: err, url: link}}
+	}
+	return &errWithDocsLink{err: err, url: link}
+}
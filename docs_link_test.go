@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func docsLinkTestApp() *App {
+	return &App{
+		Name:        "deployer",
+		Usage:       "ships things",
+		DocsURL:     "https://docs.example.com/cli/{{.Command}}#{{.Flag}}",
+		HideVersion: true,
+		Writer:      &bytes.Buffer{},
+		ErrWriter:   &bytes.Buffer{},
+		Commands: []*Command{
+			{
+				Name:  "deploy",
+				Usage: "deploys the app",
+				Flags: []Flag{
+					&StringFlag{
+						Name:  "strategy",
+						Usage: "how to roll out",
+						Validator: func(v interface{}) error {
+							if s := v.(string); s != "rolling" && s != "canary" {
+								return Exit("must be rolling or canary", 1)
+							}
+							return nil
+						},
+					},
+					&IntFlag{Name: "replicas", Usage: "number of replicas"},
+				},
+				Action: func(*Context) error { return nil },
+			},
+			{
+				Name:    "login",
+				Usage:   "logs in",
+				DocsURL: "https://auth.example.com/login",
+				Flags: []Flag{
+					&StringFlag{
+						Name:     "token",
+						Usage:    "the API token",
+						Required: true,
+						DocsURL:  "https://auth.example.com/tokens",
+					},
+				},
+				Action: func(*Context) error { return nil },
+			},
+		},
+	}
+}
+
+func TestDocsLink_helpFooter(t *testing.T) {
+	app := docsLinkTestApp()
+	output := &bytes.Buffer{}
+	app.Writer = output
+
+	err := app.Run([]string{"deployer", "deploy", "--help"})
+	expect(t, err, nil)
+
+	expectFileContent(t, "testdata/expected-help-docs-link.txt", output.String())
+}
+
+func TestDocsLink_helpWithoutDocsURL(t *testing.T) {
+	app := docsLinkTestApp()
+	app.DocsURL = ""
+	output := &bytes.Buffer{}
+	app.Writer = output
+
+	_ = app.Run([]string{"deployer", "--help"})
+
+	if strings.Contains(output.String(), "DOCUMENTATION") {
+		t.Errorf("expected no documentation section, got %q", output.String())
+	}
+}
+
+func TestDocsLink_errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "invalid value",
+			args:     []string{"deployer", "deploy", "--replicas", "many"},
+			expected: `invalid value "many" for flag -replicas: parse error, see https://docs.example.com/cli/deploy#replicas`,
+		},
+		{
+			name:     "validator",
+			args:     []string{"deployer", "deploy", "--strategy", "blue"},
+			expected: `invalid value for flag strategy: must be rolling or canary, see https://docs.example.com/cli/deploy#strategy`,
+		},
+		{
+			name:     "required flag",
+			args:     []string{"deployer", "login"},
+			expected: `Required flag "token" not set, see https://auth.example.com/tokens`,
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"deployer", "deploy", "--force"},
+			expected: "flag provided but not defined: -force",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := docsLinkTestApp().Run(c.args)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			expect(t, err.Error(), c.expected)
+		})
+	}
+}
+
+func TestDocsLink_wrappedError(t *testing.T) {
+	app := docsLinkTestApp()
+	deploy := app.Commands[0]
+
+	parseErr := errors.New(`invalid value "many" for flag -replicas: parse error`)
+	err := app.addDocsLink(deploy, deploy.Flags, parseErr)
+	expect(t, err.(interface{ Unwrap() error }).Unwrap(), parseErr)
+	if _, ok := err.(ExitCoder); ok {
+		t.Error("expected no exit code for an error without one")
+	}
+
+	exitErr := Exit(`invalid value "many" for flag -replicas: parse error`, 4)
+	err = app.addDocsLink(deploy, deploy.Flags, exitErr)
+	expect(t, err.Error(), `invalid value "many" for flag -replicas: parse error, see https://docs.example.com/cli/deploy#replicas`)
+	expect(t, err.(ExitCoder).ExitCode(), 4)
+	expect(t, err.(interface{ Unwrap() error }).Unwrap(), exitErr)
+}
+
+func TestDocsLink_commandLink(t *testing.T) {
+	app := docsLinkTestApp()
+	app.Commands[0].Flags[1] = &IntFlag{Name: "replicas", DocsURL: "https://docs.example.com/replicas"}
+	app.DocsURL = "https://docs.example.com/cli/{{.Command}}"
+
+	err := app.Run([]string{"deployer", "deploy", "--strategy", "blue"})
+	expect(t, err.Error(), `invalid value for flag strategy: must be rolling or canary, see https://docs.example.com/cli/deploy`)
+
+	err = app.Run([]string{"deployer", "deploy", "--replicas", "many"})
+	expect(t, err.Error(), `invalid value "many" for flag -replicas: parse error, see https://docs.example.com/replicas`)
+}
+
+func TestDocsLink_markdown(t *testing.T) {
+	app := docsLinkTestApp()
+
+	res, err := app.ToMarkdown()
+	expect(t, err, nil)
+
+	for _, s := range []string{
+		"[Documentation](https://docs.example.com/cli/deploy)",
+		"**--strategy**=\"\": how to roll out [Documentation](https://docs.example.com/cli/deploy#strategy)",
+		"[Documentation](https://auth.example.com/login)",
+		"[Documentation](https://auth.example.com/tokens)",
+		"# SEE ALSO\n\n[Documentation](https://docs.example.com/cli/)",
+	} {
+		if !strings.Contains(res, s) {
+			t.Errorf("expected %q in\n%s", s, res)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"io/ioutil"
+	"strings"
 	"testing"
 )
 
@@ -136,6 +137,27 @@ func TestToMarkdownNoAuthors(t *testing.T) {
 	expectFileContent(t, "testdata/expected-doc-no-authors.md", res)
 }
 
+func TestToMarkdownHiddenCommandFlags(t *testing.T) {
+	// Given
+	app := testApp()
+	app.Commands[1].Flags = []Flag{
+		&StringFlag{Name: "visible-info-flag"},
+		&StringFlag{Name: "hidden-info-flag", Hidden: true},
+	}
+
+	// When
+	res, err := app.ToMarkdown()
+
+	// Then
+	expect(t, err, nil)
+	if !strings.Contains(res, "--visible-info-flag") {
+		t.Errorf("expected the visible flag of the command in:\n%s", res)
+	}
+	if strings.Contains(res, "hidden-info-flag") {
+		t.Errorf("expected no hidden flag of the command in:\n%s", res)
+	}
+}
+
 func TestToMan(t *testing.T) {
 	// Given
 	app := testApp()
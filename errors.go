@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 )
 
@@ -21,9 +22,16 @@ type MultiError interface {
 	Errors() []error
 }
 
-// NewMultiError creates a new MultiError. Pass in one or more errors.
-func newMultiError(err ...error) MultiError {
-	ret := multiError(err)
+// NewMultiError creates a new MultiError of the errors which are not nil. Its
+// exit code is the highest of the ones of the errors which are an ExitCoder,
+// or 1 if none is.
+func NewMultiError(errs ...error) MultiError {
+	ret := multiError{}
+	for _, err := range errs {
+		if err != nil {
+			ret = append(ret, err)
+		}
+	}
 	return &ret
 }
 
@@ -41,11 +49,34 @@ func (m *multiError) Error() string {
 
 // Errors returns a copy of the errors slice
 func (m *multiError) Errors() []error {
-	errs := make([]error, len(*m))
-	for _, err := range *m {
-		errs = append(errs, err)
+	errs := make([]error, 0, len(*m))
+	return append(errs, *m...)
+}
+
+// ExitCode implements the ExitCoder interface.
+func (m *multiError) ExitCode() int {
+	return multiErrorExitCode(m)
+}
+
+// multiErrorExitCode returns the highest exit code of the errors of multiErr
+// which are an ExitCoder, or 1 if none is
+func multiErrorExitCode(multiErr MultiError) int {
+	code, found := 1, false
+	for _, err := range multiErr.Errors() {
+		var errCode int
+		switch err := err.(type) {
+		case ExitCoder:
+			errCode = err.ExitCode()
+		case MultiError:
+			errCode = multiErrorExitCode(err)
+		default:
+			continue
+		}
+		if !found || errCode > code {
+			code, found = errCode, true
+		}
 	}
-	return errs
+	return code
 }
 
 // ErrorFormatter is the interface that will suitably format the error output
@@ -89,43 +120,163 @@ func (ee *exitError) ExitCode() int {
 
 // HandleExitCoder checks if the error fulfills the ExitCoder interface, and if
 // so prints the error to stderr (if it is non-empty) and calls OsExiter with the
-// given exit code.  If the given error is a MultiError, then this func is
-// called on all members of the Errors slice and calls OsExiter with the last exit code.
+// given exit code.  If the given error is a MultiError, then all members of the
+// Errors slice are printed and OsExiter is called with its highest exit code.
 func HandleExitCoder(err error) {
+	handleExitCoder(err, "")
+}
+
+// handleExitCoder is HandleExitCoder writing prefix before the error messages
+func handleExitCoder(err error, prefix string) {
 	if err == nil {
 		return
 	}
 
+	if multiErr, ok := err.(MultiError); ok {
+		code := handleMultiError(multiErr, prefix)
+		OsExiter(code)
+		return
+	}
+
 	if exitErr, ok := err.(ExitCoder); ok {
 		if err.Error() != "" {
 			if _, ok := exitErr.(ErrorFormatter); ok {
-				_, _ = fmt.Fprintf(ErrWriter, "%+v\n", err)
+				_, _ = fmt.Fprintf(ErrWriter, "%s%+v\n", prefix, err)
 			} else {
-				_, _ = fmt.Fprintln(ErrWriter, err)
+				_, _ = fmt.Fprintf(ErrWriter, "%s%v\n", prefix, err)
 			}
 		}
 		OsExiter(exitErr.ExitCode())
 		return
 	}
-
-	if multiErr, ok := err.(MultiError); ok {
-		code := handleMultiError(multiErr)
-		OsExiter(code)
-		return
-	}
 }
 
-func handleMultiError(multiErr MultiError) int {
-	code := 1
+// handleMultiError writes the errors of multiErr and returns its exit code
+func handleMultiError(multiErr MultiError, prefix string) int {
 	for _, merr := range multiErr.Errors() {
 		if multiErr2, ok := merr.(MultiError); ok {
-			code = handleMultiError(multiErr2)
+			handleMultiError(multiErr2, prefix)
 		} else if merr != nil {
-			fmt.Fprintln(ErrWriter, merr)
-			if exitErr, ok := merr.(ExitCoder); ok {
-				code = exitErr.ExitCode()
+			fmt.Fprintf(ErrWriter, "%s%v\n", prefix, merr)
+		}
+	}
+	return multiErrorExitCode(multiErr)
+}
+
+// UsageError marks the errors of the command line returned by App.Run when
+// App.ClassifyErrors is set: invalid flags or arguments, missing required
+// flags, failed validations and unknown help topics, as well as errors in the
+// definition of the App. Retrying the same command line fails again.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the command line
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of Err if it is an ExitCoder, or 2. For a
+// MultiError it is the highest exit code of its errors.
+func (e *UsageError) ExitCode() int {
+	return classifiedExitCode(e.Err, 2)
+}
+
+// RuntimeError marks the errors returned by App.Run when App.ClassifyErrors
+// is set, which come from a Before, Action or After function of the App or
+// of a command.
+type RuntimeError struct {
+	Err error
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the Before, Action or After function
+func (e *RuntimeError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of Err if it is an ExitCoder, or 1. For a
+// MultiError it is the highest exit code of its errors.
+func (e *RuntimeError) ExitCode() int {
+	return classifiedExitCode(e.Err, 1)
+}
+
+// classifiedExitCode returns the exit code of err if it is an ExitCoder, or
+// code. The errors of a MultiError which are not an ExitCoder exit with code.
+func classifiedExitCode(err error, code int) int {
+	if multiErr, ok := err.(MultiError); ok {
+		exitCode := 0
+		for _, merr := range multiErr.Errors() {
+			if c := classifiedExitCode(merr, code); c > exitCode {
+				exitCode = c
 			}
 		}
+		return exitCode
+	}
+	if exitErr, ok := err.(ExitCoder); ok {
+		return exitErr.ExitCode()
 	}
 	return code
 }
+
+// runtimeError marks err as a RuntimeError when the errors of a are
+// classified
+func (a *App) runtimeError(err error) error {
+	if err == nil || !a.ClassifyErrors {
+		return err
+	}
+	if _, ok := err.(*RuntimeError); ok {
+		return err
+	}
+	return &RuntimeError{Err: err}
+}
+
+// actionError marks the error of action as a RuntimeError, unless action
+// shows the help
+func (a *App) actionError(action ActionFunc, err error) error {
+	if isHelpAction(action) {
+		return err
+	}
+	return a.runtimeError(err)
+}
+
+func isHelpAction(action ActionFunc) bool {
+	pointer := reflect.ValueOf(action).Pointer()
+	return pointer == reflect.ValueOf(helpCommand.Action).Pointer() ||
+		pointer == reflect.ValueOf(helpSubcommand.Action).Pointer()
+}
+
+// classifyError marks the errors which are not a RuntimeError as a
+// UsageError. A MultiError is a RuntimeError only when all of its errors are.
+func classifyError(err error) error {
+	switch err.(type) {
+	case nil, *UsageError, *RuntimeError:
+		return err
+	}
+	if isRuntimeError(err) {
+		return &RuntimeError{Err: err}
+	}
+	return &UsageError{Err: err}
+}
+
+func isRuntimeError(err error) bool {
+	switch err := err.(type) {
+	case *RuntimeError:
+		return true
+	case MultiError:
+		for _, merr := range err.Errors() {
+			if !isRuntimeError(merr) {
+				return false
+			}
+		}
+		return len(err.Errors()) > 0
+	}
+	return false
+}
@@ -60,7 +60,7 @@ func TestHandleExitCoder_MultiErrorWithExitCoder(t *testing.T) {
 
 	exitErr := Exit("galactic perimeter breach", 9)
 	exitErr2 := Exit("last ExitCoder", 11)
-	err := newMultiError(errors.New("wowsa"), errors.New("egad"), exitErr, exitErr2)
+	err := NewMultiError(errors.New("wowsa"), errors.New("egad"), exitErr, exitErr2)
 	HandleExitCoder(err)
 
 	expect(t, exitCode, 11)
@@ -114,9 +114,166 @@ func TestHandleExitCoder_MultiErrorWithFormat(t *testing.T) {
 
 	defer func() { OsExiter = fakeOsExiter }()
 
-	err := newMultiError(NewErrorWithFormat("err1"), NewErrorWithFormat("err2"))
+	err := NewMultiError(NewErrorWithFormat("err1"), NewErrorWithFormat("err2"))
 	HandleExitCoder(err)
 
 	expect(t, called, true)
 	expect(t, ErrWriter.(*bytes.Buffer).String(), "This the format: err1\nThis the format: err2\n")
 }
+
+func TestNewMultiError(t *testing.T) {
+	err := NewMultiError(errors.New("wowsa"), nil, Exit("egad", 3))
+
+	expect(t, err.Error(), "wowsa\negad")
+	expect(t, len(err.Errors()), 2)
+
+	exitErr, ok := err.(ExitCoder)
+	if !ok {
+		t.Fatal("expected the MultiError to be an ExitCoder")
+	}
+	expect(t, exitErr.ExitCode(), 3)
+}
+
+func TestMultiError_ExitCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		errs     []error
+		expected int
+	}{
+		{
+			name:     "no ExitCoder",
+			errs:     []error{errors.New("wowsa")},
+			expected: 1,
+		},
+		{
+			name:     "highest",
+			errs:     []error{Exit("a", 7), errors.New("b"), Exit("c", 2)},
+			expected: 7,
+		},
+		{
+			name:     "nested",
+			errs:     []error{Exit("a", 2), NewMultiError(Exit("b", 5), errors.New("c"))},
+			expected: 5,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := NewMultiError(c.errs...)
+			expect(t, err.(ExitCoder).ExitCode(), c.expected)
+		})
+	}
+}
+
+func TestHandleExitCoder_MultiErrorHighestExitCode(t *testing.T) {
+	exitCode := 0
+	OsExiter = func(rc int) {
+		exitCode = rc
+	}
+	ErrWriter = &bytes.Buffer{}
+
+	defer func() {
+		OsExiter = fakeOsExiter
+		ErrWriter = fakeErrWriter
+	}()
+
+	HandleExitCoder(NewMultiError(Exit("first", 11), errors.New("second"), Exit("third", 4)))
+
+	expect(t, exitCode, 11)
+	expect(t, ErrWriter.(*bytes.Buffer).String(), "first\nsecond\nthird\n")
+}
+
+func TestApp_Run_ExitErrHandler_MultiError(t *testing.T) {
+	var handled error
+	app := newTestApp()
+	app.ExitErrHandler = func(_ *Context, err error) {
+		handled = err
+	}
+	app.Action = func(*Context) error {
+		return NewMultiError(Exit("a", 2), Exit("b", 6))
+	}
+
+	err := app.Run([]string{"app"})
+	expect(t, err, handled)
+	expect(t, handled.(ExitCoder).ExitCode(), 6)
+}
+
+func TestApp_Run_ClassifyErrors(t *testing.T) {
+	actionErr := errors.New("action failed")
+	afterErr := errors.New("after failed")
+
+	cases := []struct {
+		name    string
+		args    []string
+		action  error
+		after   error
+		usage   bool
+		wrapped error
+		code    int
+	}{
+		{name: "missing required flag", args: []string{"app", "deploy"}, usage: true, code: 2},
+		{name: "unknown flag", args: []string{"app", "deploy", "--region", "eu", "--nope"}, usage: true, code: 2},
+		{name: "action", args: []string{"app", "deploy", "--region", "eu"}, action: actionErr, wrapped: actionErr, code: 1},
+		{name: "action exit code", args: []string{"app", "deploy", "--region", "eu"}, action: Exit("quota", 75), code: 75},
+		{name: "after", args: []string{"app", "deploy", "--region", "eu"}, after: afterErr, wrapped: afterErr, code: 1},
+		{name: "action and after", args: []string{"app", "deploy", "--region", "eu"}, action: actionErr, after: afterErr, code: 1},
+		{name: "usage and after", args: []string{"app", "deploy"}, after: afterErr, usage: true, code: 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			app := newTestApp()
+			app.ClassifyErrors = true
+			app.ExitErrHandler = func(*Context, error) {}
+			app.Commands = []*Command{{
+				Name:   "deploy",
+				Flags:  []Flag{&StringFlag{Name: "region", Required: true}},
+				Action: func(*Context) error { return c.action },
+			}}
+			app.After = func(*Context) error { return c.after }
+
+			err := app.Run(c.args)
+
+			var inner error
+			switch err := err.(type) {
+			case *UsageError:
+				if !c.usage {
+					t.Fatalf("expected a *RuntimeError, got %#v", err)
+				}
+				inner = err.Unwrap()
+			case *RuntimeError:
+				if c.usage {
+					t.Fatalf("expected a *UsageError, got %#v", err)
+				}
+				inner = err.Unwrap()
+			default:
+				t.Fatalf("expected a classified error, got %#v", err)
+			}
+			if c.wrapped != nil {
+				expect(t, inner, c.wrapped)
+			}
+			expect(t, err.(ExitCoder).ExitCode(), c.code)
+		})
+	}
+}
+
+func TestApp_Run_ClassifyErrors_Help(t *testing.T) {
+	app := newTestApp()
+	app.ClassifyErrors = true
+	app.Commands = []*Command{{Name: "deploy"}}
+
+	err := app.Run([]string{"app", "help", "nope"})
+	if _, ok := err.(*UsageError); !ok {
+		t.Errorf("expected a *UsageError, got %#v", err)
+	}
+
+	expect(t, app.Run([]string{"app", "help", "deploy"}), nil)
+}
+
+func TestApp_Run_ClassifyErrors_Disabled(t *testing.T) {
+	actionErr := errors.New("action failed")
+	app := newTestApp()
+	app.Action = func(*Context) error { return actionErr }
+
+	expect(t, app.Run([]string{"app"}), actionErr)
+}
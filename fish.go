@@ -6,6 +6,7 @@ import (
 	"io"
 	"strings"
 	"text/template"
+	"unicode/utf8"
 )
 
 // ToFishCompletion creates a fish completion string for the `*App`
@@ -95,7 +96,7 @@ func (a *App) prepareFishCommands(commands []*Command, allCommands *[]string, pr
 		completions = append(completions, completion.String())
 		completions = append(
 			completions,
-			a.prepareFishFlags(command.Flags, command.Names())...,
+			a.prepareFishFlags(command.VisibleFlags(), command.Names())...,
 		)
 
 		// recursevly iterate subcommands
@@ -129,16 +130,15 @@ func (a *App) prepareFishFlags(flags []Flag, previousCommands []string) []string
 
 		fishAddFileFlag(f, completion)
 
-		for idx, opt := range flag.Names() {
-			if idx == 0 {
-				completion.WriteString(fmt.Sprintf(
-					" -l %s", strings.TrimSpace(opt),
-				))
+		for _, opt := range flag.Names() {
+			opt = strings.TrimSpace(opt)
+			if opt == "" {
+				continue
+			}
+			if utf8.RuneCountInString(opt) == 1 {
+				completion.WriteString(fmt.Sprintf(" -s %s", opt))
 			} else {
-				completion.WriteString(fmt.Sprintf(
-					" -s %s", strings.TrimSpace(opt),
-				))
-
+				completion.WriteString(fmt.Sprintf(" -l %s", opt))
 			}
 		}
 
@@ -148,7 +148,7 @@ func (a *App) prepareFishFlags(flags []Flag, previousCommands []string) []string
 
 		if flag.GetUsage() != "" {
 			completion.WriteString(fmt.Sprintf(" -d '%s'",
-				escapeSingleQuotes(flag.GetUsage())))
+				escapeSingleQuotes(expandUsage(flag, flag.GetUsage()))))
 		}
 
 		completions = append(completions, completion.String())
@@ -171,6 +171,12 @@ func fishAddFileFlag(flag Flag, completion *strings.Builder) {
 		if f.TakesFile {
 			return
 		}
+	case *PathSliceFlag:
+		if f.TakesFile {
+			return
+		}
+	case *FileContentsFlag:
+		return
 	}
 	completion.WriteString(" -f")
 }
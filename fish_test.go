@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -15,3 +16,30 @@ func TestFishCompletion(t *testing.T) {
 	expect(t, err, nil)
 	expectFileContent(t, "testdata/expected-fish-full.fish", res)
 }
+
+func TestFishCompletion_FlagNames(t *testing.T) {
+	app := &App{
+		Name:        "app",
+		HideHelp:    true,
+		HideVersion: true,
+		Commands: []*Command{{
+			Name:     "run",
+			HideHelp: true,
+			Flags: []Flag{
+				&BoolFlag{Name: "d", Aliases: []string{"detach"}, Usage: "run in the background"},
+				&StringFlag{Name: "secret", Hidden: true},
+			},
+		}},
+	}
+
+	res, err := app.ToFishCompletion()
+	expect(t, err, nil)
+
+	expected := "complete -c app -n '__fish_seen_subcommand_from run' -f -s d -l detach -d 'run in the background'\n"
+	if !strings.Contains(res, expected) {
+		t.Errorf("expected %q in:\n%s", expected, res)
+	}
+	if strings.Contains(res, "secret") {
+		t.Errorf("unexpected hidden flag in:\n%s", res)
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
+	"unicode"
 )
 
 const defaultPlaceholder = "value"
@@ -19,6 +22,7 @@ var (
 	slPfx = fmt.Sprintf("sl:::%d:::", time.Now().UTC().UnixNano())
 
 	commaWhitespace = regexp.MustCompile("[, ]+.*")
+	commaSeparator  = regexp.MustCompile(`\s*,\s*`)
 )
 
 // BashCompletionFlag enables bash-completion for all commands and subcommands
@@ -43,6 +47,14 @@ var HelpFlag Flag = &BoolFlag{
 	Usage:   "show help",
 }
 
+// HelpAllFlag prints the help of the app followed by the help of every
+// visible command and subcommand. It is only added when EnableHelpAll is set
+// on the App. Set to nil to disable the flag.
+var HelpAllFlag Flag = &BoolFlag{
+	Name:  "help-all",
+	Usage: "show help for all commands and subcommands",
+}
+
 // FlagStringer converts a flag definition to a string. This is used by help
 // to display a flag.
 var FlagStringer FlagStringFunc = stringifyFlag
@@ -60,7 +72,7 @@ var FlagNamePrefixer FlagNamePrefixFunc = prefixedNames
 // details. This is used by the default FlagStringer.
 var FlagEnvHinter FlagEnvHintFunc = withEnvHint
 
-// FlagFileHinter annotates flag help message with the environment variable
+// FlagFileHinter annotates flag help message with the file path
 // details. This is used by the default FlagStringer.
 var FlagFileHinter FlagFileHintFunc = withFileHint
 
@@ -103,6 +115,67 @@ type RequiredFlag interface {
 	IsRequired() bool
 }
 
+// RequiredCondition is a condition on the flags of a command, checked once
+// they are parsed, which makes a flag required through its RequiredIf or
+// RequiredUnless field
+type RequiredCondition interface {
+	// Holds returns whether the condition holds. Flags of the parent
+	// commands can be read through c as well.
+	Holds(c *Context) bool
+	// String describes the condition for the error of a missing flag, like
+	// `"mode" is "signed"`
+	String() string
+}
+
+type requiredCondition struct {
+	description string
+	holds       func(*Context) bool
+}
+
+func (r *requiredCondition) Holds(c *Context) bool {
+	return r.holds(c)
+}
+
+func (r *requiredCondition) String() string {
+	return r.description
+}
+
+// ConditionFunc makes a RequiredCondition of holds, described by description
+func ConditionFunc(description string, holds func(c *Context) bool) RequiredCondition {
+	return &requiredCondition{description: description, holds: holds}
+}
+
+// FlagIsSet makes a RequiredCondition holding when the flag name is set
+func FlagIsSet(name string) RequiredCondition {
+	return ConditionFunc(fmt.Sprintf("%q is set", name), func(c *Context) bool {
+		return c.isSet(name)
+	})
+}
+
+// FlagIs makes a RequiredCondition holding when the value of the flag name,
+// set or default, is value
+func FlagIs(name, value string) RequiredCondition {
+	return ConditionFunc(fmt.Sprintf("%q is %q", name, value), func(c *Context) bool {
+		fs := findFlagSet(name, c)
+		if fs == nil {
+			return false
+		}
+		f := fs.Lookup(name)
+		return f != nil && f.Value.String() == value
+	})
+}
+
+// flagRequiredCondition returns the RequiredCondition of the field name of f
+func flagRequiredCondition(f Flag, name string) RequiredCondition {
+	field := flagValue(f).FieldByName(name)
+	if field.IsValid() && !field.IsNil() {
+		if condition, ok := field.Interface().(RequiredCondition); ok {
+			return condition
+		}
+	}
+	return nil
+}
+
 // DocGenerationFlag is an interface that allows documentation generation for the flag
 type DocGenerationFlag interface {
 	Flag
@@ -118,9 +191,22 @@ type DocGenerationFlag interface {
 	GetValue() string
 }
 
+// ActionableFlag is an interface that allows running an action with the
+// value of a flag once the flags are parsed
+type ActionableFlag interface {
+	Flag
+
+	// RunAction runs the action of the flag, if it has one
+	RunAction(*Context) error
+}
+
 func flagSet(name string, flags []Flag) (*flag.FlagSet, error) {
 	set := flag.NewFlagSet(name, flag.ContinueOnError)
 
+	if err := validateFlagNames(flags); err != nil {
+		return nil, err
+	}
+
 	for _, f := range flags {
 		if err := f.Apply(set); err != nil {
 			return nil, err
@@ -130,17 +216,91 @@ func flagSet(name string, flags []Flag) (*flag.FlagSet, error) {
 	return set, nil
 }
 
-func visibleFlags(fl []Flag) []Flag {
+// validateFlagNames rejects flags without a usable name, as well as names
+// containing whitespace which would otherwise be silently truncated.
+func validateFlagNames(flags []Flag) error {
+	for i, f := range flags {
+		usable := false
+		for _, name := range f.Names() {
+			if strings.TrimSpace(name) != "" {
+				usable = true
+			}
+		}
+		if !usable {
+			return fmt.Errorf("flag at index %d has no usable name", i)
+		}
+
+		fv := flagValue(f)
+		if fv.Kind() != reflect.Struct {
+			continue
+		}
+		var names []string
+		if field := fv.FieldByName("Name"); field.IsValid() && field.Kind() == reflect.String {
+			names = append(names, field.String())
+		}
+		if field := fv.FieldByName("Aliases"); field.IsValid() {
+			if aliases, ok := field.Interface().([]string); ok {
+				names = append(names, aliases...)
+			}
+		}
+		for _, name := range names {
+			// whitespace around the commas of the v1 form is fine
+			for _, part := range commaSeparator.Split(strings.TrimSpace(name), -1) {
+				if strings.IndexFunc(part, unicode.IsSpace) >= 0 {
+					return fmt.Errorf("flag at index %d has a name containing whitespace: %q", i, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseSizedInt parses s as a signed integer of the given bit size, naming the
+// allowed range when s does not fit
+func parseSizedInt(s string, bitSize int) (int64, error) {
+	v, err := strconv.ParseInt(s, 0, bitSize)
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return 0, fmt.Errorf("%s is out of range [%d, %d]", s, -1<<uint(bitSize-1), 1<<uint(bitSize-1)-1)
+	}
+	return v, err
+}
+
+// parseSizedUint parses s as an unsigned integer of the given bit size, naming
+// the allowed range when s does not fit
+func parseSizedUint(s string, bitSize int) (uint64, error) {
+	v, err := strconv.ParseUint(s, 0, bitSize)
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrSyntax {
+		// negative numbers are a syntax error for ParseUint
+		if _, intErr := strconv.ParseInt(s, 0, 64); intErr == nil {
+			err = &strconv.NumError{Func: numErr.Func, Num: s, Err: strconv.ErrRange}
+		}
+	}
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return 0, fmt.Errorf("%s is out of range [0, %d]", s, uint64(1)<<uint(bitSize)-1)
+	}
+	return v, err
+}
+
+func visibleFlags(fl []Flag, hideDeprecated bool) []Flag {
 	var visible []Flag
 	for _, f := range fl {
-		field := flagValue(f).FieldByName("Hidden")
-		if !field.IsValid() || !field.Bool() {
+		if hideDeprecated && flagStringField(f, "Deprecated") != "" {
+			continue
+		}
+		if !isHiddenFlag(f) {
 			visible = append(visible, f)
 		}
 	}
 	return visible
 }
 
+// isHiddenFlag returns whether f is kept out of help and completion. A hidden
+// flag is still parsed and checked like the others.
+func isHiddenFlag(f Flag) bool {
+	field := flagValue(f).FieldByName("Hidden")
+	return field.IsValid() && field.Kind() == reflect.Bool && field.Bool()
+}
+
 func prefixFor(name string) (prefix string) {
 	if len(name) == 1 {
 		prefix = "-"
@@ -151,6 +311,46 @@ func prefixFor(name string) (prefix string) {
 	return
 }
 
+// expandUsage replaces the {{.Default}} and {{.EnvVars}} tokens in the usage
+// string of f with the default value and the environment variables of the
+// flag. Literal braces are written as \{{.
+func expandUsage(f Flag, usage string) string {
+	if !strings.Contains(usage, "{{") {
+		return usage
+	}
+
+	const escaped = "\x00"
+	usage = strings.Replace(usage, "\\{{", escaped, -1)
+
+	if strings.Contains(usage, "{{.Default}}") {
+		usage = strings.Replace(usage, "{{.Default}}", flagDefaultText(f), -1)
+	}
+	if strings.Contains(usage, "{{.EnvVars}}") {
+		var envVars []string
+		if flagValue(f).Kind() == reflect.Struct {
+			envVars = flagStringSliceField(f, "EnvVars")
+		}
+		usage = strings.Replace(usage, "{{.EnvVars}}", strings.Join(envVars, ", "), -1)
+	}
+
+	return strings.Replace(usage, escaped, "{{", -1)
+}
+
+// flagDefaultText returns the DefaultText of f if set, its default value
+// otherwise
+func flagDefaultText(f Flag) string {
+	fv := flagValue(f)
+	if fv.Kind() == reflect.Struct {
+		if text := fv.FieldByName("DefaultText"); text.IsValid() && text.String() != "" {
+			return text.String()
+		}
+	}
+	if df, ok := f.(DocGenerationFlag); ok {
+		return df.GetValue()
+	}
+	return ""
+}
+
 // Returns the placeholder, if any, and the unquoted usage string.
 func unquoteUsage(usage string) (string, string) {
 	for i := 0; i < len(usage); i++ {
@@ -168,6 +368,21 @@ func unquoteUsage(usage string) (string, string) {
 	return "", usage
 }
 
+// flagPlaceholder returns the placeholder shown after the names of a flag
+// taking a value: the backquoted word of its usage, or "value"
+func flagPlaceholder(f Flag) string {
+	usage := flagValue(f).FieldByName("Usage")
+	placeholder := ""
+	if usage.IsValid() && usage.Kind() == reflect.String {
+		placeholder, _ = unquoteUsage(usage.String())
+	}
+
+	if df, ok := f.(DocGenerationFlag); ok && placeholder == "" && df.TakesValue() {
+		placeholder = defaultPlaceholder
+	}
+	return placeholder
+}
+
 func prefixedNames(names []string, placeholder string) string {
 	var prefixed string
 	for i, name := range names {
@@ -206,17 +421,34 @@ func withEnvHint(envVars []string, str string) string {
 func flagNames(name string, aliases []string) []string {
 	var ret []string
 
+	seen := map[string]bool{}
 	for _, part := range append([]string{name}, aliases...) {
-		// v1 -> v2 migration warning zone:
-		// Strip off anything after the first found comma or space, which
-		// *hopefully* makes it a tiny bit more obvious that unexpected behavior is
-		// caused by using the v1 form of stringly typed "Name".
-		ret = append(ret, commaWhitespace.ReplaceAllString(part, ""))
+		// v1 -> v2 migration zone:
+		// The v1 form of stringly typed "Name", a comma-separated list of
+		// names like "lang, l", declares the aliases as well. Anything after
+		// a space in a name is stripped off.
+		names := commaSeparator.Split(strings.TrimSpace(part), -1)
+		for _, n := range names {
+			n = commaWhitespace.ReplaceAllString(n, "")
+			if (n == "" && len(names) > 1) || seen[n] {
+				continue
+			}
+			seen[n] = true
+			ret = append(ret, n)
+		}
 	}
 
 	return ret
 }
 
+func flagStringField(f Flag, name string) string {
+	field := flagValue(f).FieldByName(name)
+	if field.IsValid() && field.Kind() == reflect.String {
+		return field.String()
+	}
+	return ""
+}
+
 func flagStringSliceField(f Flag, name string) []string {
 	fv := flagValue(f)
 	field := fv.FieldByName(name)
@@ -228,6 +460,17 @@ func flagStringSliceField(f Flag, name string) []string {
 	return []string{}
 }
 
+// withSourceHints annotates the help message of f with the environment
+// variables and files its value is read from, and with its deprecation
+func withSourceHints(f Flag, str string) string {
+	str = FlagFileHinter(flagStringField(f, "FilePath"),
+		FlagEnvHinter(flagStringSliceField(f, "EnvVars"), str))
+	if msg := flagStringField(f, "Deprecated"); msg != "" {
+		str += fmt.Sprintf(" (DEPRECATED: %s)", msg)
+	}
+	return str
+}
+
 func withFileHint(filePath, str string) string {
 	fileText := ""
 	if filePath != "" {
@@ -248,25 +491,94 @@ func formatDefault(format string) string {
 	return " (default: " + format + ")"
 }
 
+// humanizeDuration formats d like time.Duration.String, without the units
+// which are zero at its end, like 1h30m rather than 1h30m0s
+func humanizeDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// formatUnitDefault formats the integer default val of a flag in unit, as a
+// size or a duration when humanize is set and the unit is known
+func formatUnitDefault(val reflect.Value, unit string, humanize bool) string {
+	var n int64
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = val.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = int64(val.Uint())
+	default:
+		return fmt.Sprintf("%v %s", val.Interface(), unit)
+	}
+
+	if humanize {
+		switch unit {
+		case "bytes":
+			return formatByteSize(n)
+		case "seconds":
+			return humanizeDuration(time.Duration(n) * time.Second)
+		case "milliseconds":
+			return humanizeDuration(time.Duration(n) * time.Millisecond)
+		}
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
+
 func stringifyFlag(f Flag) string {
 	fv := flagValue(f)
 
 	switch f := f.(type) {
 	case *IntSliceFlag:
-		return withEnvHint(flagStringSliceField(f, "EnvVars"),
+		return withSourceHints(f,
 			stringifyIntSliceFlag(f))
 	case *Int64SliceFlag:
-		return withEnvHint(flagStringSliceField(f, "EnvVars"),
+		return withSourceHints(f,
 			stringifyInt64SliceFlag(f))
+	case *Int32SliceFlag:
+		return withSourceHints(f,
+			stringifyInt32SliceFlag(f))
+	case *Uint32SliceFlag:
+		return withSourceHints(f,
+			stringifyUint32SliceFlag(f))
+	case *Float32SliceFlag:
+		return withSourceHints(f,
+			stringifyFloat32SliceFlag(f))
 	case *Float64SliceFlag:
-		return withEnvHint(flagStringSliceField(f, "EnvVars"),
+		return withSourceHints(f,
 			stringifyFloat64SliceFlag(f))
 	case *StringSliceFlag:
-		return withEnvHint(flagStringSliceField(f, "EnvVars"),
+		return withSourceHints(f,
 			stringifyStringSliceFlag(f))
+	case *PathSliceFlag:
+		return withSourceHints(f,
+			stringifyPathSliceFlag(f))
+	case *StringMapFlag:
+		return withSourceHints(f,
+			stringifyStringMapFlag(f))
+	case *GenericSliceFlag:
+		return withSourceHints(f,
+			stringifyGenericSliceFlag(f))
+	case *DurationSliceFlag:
+		return withSourceHints(f,
+			stringifyDurationSliceFlag(f))
+	case *TimestampSliceFlag:
+		return withSourceHints(f,
+			stringifyTimestampSliceFlag(f))
+	case *CountFlag:
+		return withSourceHints(f,
+			stringifyCountFlag(f))
 	}
 
-	placeholder, usage := unquoteUsage(fv.FieldByName("Usage").String())
+	placeholder, usage := unquoteUsage(expandUsage(f, fv.FieldByName("Usage").String()))
+	if sf, ok := f.(*StringFlag); ok && len(sf.Choices) > 0 {
+		usage = strings.TrimSpace(fmt.Sprintf("%s (one of: %s)", usage, strings.Join(sf.Choices, ", ")))
+	}
 
 	needsPlaceholder := false
 	defaultValueString := ""
@@ -275,9 +587,40 @@ func stringifyFlag(f Flag) string {
 		needsPlaceholder = val.Kind() != reflect.Bool
 		defaultValueString = fmt.Sprintf(formatDefault("%v"), val.Interface())
 
+		// a flag without a default, like a TimestampFlag or a GenericFlag
+		// with a nil Value, shows none
+		if (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) && val.IsNil() {
+			defaultValueString = ""
+		}
+
 		if val.Kind() == reflect.String && val.String() != "" {
 			defaultValueString = fmt.Sprintf(formatDefault("%q"), val.String())
 		}
+
+		if bf, ok := f.(*ByteSizeFlag); ok && !bf.RawDefault {
+			defaultValueString = fmt.Sprintf(formatDefault("%s"), formatByteSize(bf.Value))
+		}
+
+		if df, ok := f.(*DurationFlag); ok {
+			if df.RawDefault {
+				defaultValueString = fmt.Sprintf(formatDefault("%d"), int64(df.Value))
+			} else {
+				defaultValueString = fmt.Sprintf(formatDefault("%s"), humanizeDuration(df.Value))
+			}
+		}
+
+		if unit := flagStringField(f, "Unit"); unit != "" {
+			humanize := fv.FieldByName("HumanizeDefault")
+			defaultValueString = formatDefault(formatUnitDefault(val, unit, humanize.IsValid() && humanize.Bool()))
+		}
+
+		if tf, ok := f.(*TimestampFlag); ok && tf.Value != nil && tf.Value.timestamp != nil && len(tf.layouts()) > 0 {
+			defaultValueString = fmt.Sprintf(formatDefault("%s"), tf.Value.timestamp.Format(tf.layouts()[0]))
+		}
+
+		if val.Kind() == reflect.Float32 {
+			defaultValueString = fmt.Sprintf(formatDefault("%s"), strconv.FormatFloat(val.Float(), 'f', -1, 32))
+		}
 	}
 
 	helpText := fv.FieldByName("DefaultText")
@@ -296,7 +639,7 @@ func stringifyFlag(f Flag) string {
 
 	usageWithDefault := strings.TrimSpace(usage + defaultValueString)
 
-	return withEnvHint(flagStringSliceField(f, "EnvVars"),
+	return withSourceHints(f,
 		fmt.Sprintf("%s\t%s", prefixedNames(f.Names(), placeholder), usageWithDefault))
 }
 
@@ -308,7 +651,7 @@ func stringifyIntSliceFlag(f *IntSliceFlag) string {
 		}
 	}
 
-	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
 }
 
 func stringifyInt64SliceFlag(f *Int64SliceFlag) string {
@@ -319,7 +662,41 @@ func stringifyInt64SliceFlag(f *Int64SliceFlag) string {
 		}
 	}
 
-	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
+}
+
+func stringifyInt32SliceFlag(f *Int32SliceFlag) string {
+	var defaultVals []string
+	if f.Value != nil && len(f.Value.Value()) > 0 {
+		for _, i := range f.Value.Value() {
+			defaultVals = append(defaultVals, strconv.FormatInt(int64(i), 10))
+		}
+	}
+
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
+}
+
+func stringifyUint32SliceFlag(f *Uint32SliceFlag) string {
+	var defaultVals []string
+	if f.Value != nil && len(f.Value.Value()) > 0 {
+		for _, i := range f.Value.Value() {
+			defaultVals = append(defaultVals, strconv.FormatUint(uint64(i), 10))
+		}
+	}
+
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
+}
+
+func stringifyFloat32SliceFlag(f *Float32SliceFlag) string {
+	var defaultVals []string
+
+	if f.Value != nil && len(f.Value.Value()) > 0 {
+		for _, i := range f.Value.Value() {
+			defaultVals = append(defaultVals, strconv.FormatFloat(float64(i), 'g', -1, 32))
+		}
+	}
+
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
 }
 
 func stringifyFloat64SliceFlag(f *Float64SliceFlag) string {
@@ -331,7 +708,7 @@ func stringifyFloat64SliceFlag(f *Float64SliceFlag) string {
 		}
 	}
 
-	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
 }
 
 func stringifyStringSliceFlag(f *StringSliceFlag) string {
@@ -344,17 +721,92 @@ func stringifyStringSliceFlag(f *StringSliceFlag) string {
 		}
 	}
 
-	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
+}
+
+func stringifyPathSliceFlag(f *PathSliceFlag) string {
+	var defaultVals []string
+	if f.Value != nil && len(f.Value.Value()) > 0 {
+		for _, s := range f.Value.Value() {
+			if len(s) > 0 {
+				defaultVals = append(defaultVals, strconv.Quote(s))
+			}
+		}
+	}
+
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
+}
+
+func stringifyGenericSliceFlag(f *GenericSliceFlag) string {
+	var defaultVals []string
+	if f.Value != nil {
+		defaultVals = f.Value.strings()
+	}
+
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
+}
+
+func stringifyStringMapFlag(f *StringMapFlag) string {
+	var defaultVals []string
+	if f.Value != nil && len(f.Value.Value()) > 0 {
+		for k, v := range f.Value.Value() {
+			defaultVals = append(defaultVals, strconv.Quote(k+"="+v))
+		}
+		sort.Strings(defaultVals)
+	}
+
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
 }
 
-func stringifySliceFlag(usage string, names, defaultVals []string) string {
+func stringifyDurationSliceFlag(f *DurationSliceFlag) string {
+	var defaultVals []string
+	if f.Value != nil && len(f.Value.Value()) > 0 {
+		for _, d := range f.Value.Value() {
+			defaultVals = append(defaultVals, d.String())
+		}
+	}
+
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
+}
+
+func stringifyTimestampSliceFlag(f *TimestampSliceFlag) string {
+	var defaultVals []string
+	if f.Value != nil && len(f.Value.slice) > 0 {
+		for _, t := range f.Value.slice {
+			defaultVals = append(defaultVals, t.Format(f.Layout))
+		}
+	}
+
+	return stringifySliceFlag(expandUsage(f, f.Usage), f.Names(), defaultVals, f.DefaultText)
+}
+
+// stringifyCountFlag shows a CountFlag without a placeholder, as it takes no
+// value, telling that it may be repeated
+func stringifyCountFlag(f *CountFlag) string {
+	_, usage := unquoteUsage(expandUsage(f, f.Usage))
+	usage = strings.TrimSpace(usage + " (may be repeated)")
+
+	if f.DefaultText != "" {
+		usage += fmt.Sprintf(formatDefault("%s"), f.DefaultText)
+	} else if f.Value != 0 {
+		usage += fmt.Sprintf(formatDefault("%d"), f.Value)
+	}
+
+	return fmt.Sprintf("%s\t%s", prefixedNames(f.Names(), ""), usage)
+}
+
+// stringifySliceFlag shows defaultText as the default of the flag when it is
+// set, and the defaultVals otherwise
+func stringifySliceFlag(usage string, names, defaultVals []string, defaultText string) string {
 	placeholder, usage := unquoteUsage(usage)
 	if placeholder == "" {
 		placeholder = defaultPlaceholder
 	}
 
 	defaultVal := ""
-	if len(defaultVals) > 0 {
+	if defaultText != "" {
+		defaultVal = fmt.Sprintf(formatDefault("%s"), defaultText)
+	} else if len(defaultVals) > 0 {
 		defaultVal = fmt.Sprintf(formatDefault("%s"), strings.Join(defaultVals, ", "))
 	}
 
@@ -372,17 +824,347 @@ func hasFlag(flags []Flag, fl Flag) bool {
 	return false
 }
 
-func flagFromEnvOrFile(envVars []string, filePath string) (val string, ok bool) {
+// checkPersistentFlags reports a persistent flag sharing a name with another
+// flag of the command at path or of one of its descendants. The ancestors are
+// the commands on path, which are not visited again when commands loop.
+func checkPersistentFlags(path string, flags []Flag, persistent []Flag, commands []*Command, ancestors ...*Command) error {
+	for _, p := range persistent {
+		for _, f := range flags {
+			if f == p {
+				continue
+			}
+			for _, name := range p.Names() {
+				if hasName(f.Names(), name) {
+					return fmt.Errorf("persistent flag %q conflicts with a flag of the same name of %q", name, path)
+				}
+			}
+		}
+	}
+
+	for _, c := range commands {
+		if hasCommand(ancestors, c) {
+			continue
+		}
+		inherited := append(append([]Flag{}, persistent...), c.PersistentFlags...)
+		local := append(append([]Flag{}, c.Flags...), c.PersistentFlags...)
+		if err := checkPersistentFlags(path+" "+c.Name, local, inherited, c.Subcommands, append(ancestors, c)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// warnDeprecatedFlags writes a warning to the ErrWriter for each of the
+// deprecated flags which is set, once per run of the App. It does not count
+// as a read of the flags for the FlagReadRecorder.
+func warnDeprecatedFlags(flags []Flag, cCtx *Context) {
+	app := rootApp(cCtx)
+	for _, f := range flags {
+		msg := flagStringField(f, "Deprecated")
+		names := f.Names()
+		if msg == "" || len(names) == 0 || !cCtx.isSet(names[0]) {
+			continue
+		}
+		if handledFlag(app.deprecatedFlagsWarned, f) {
+			continue
+		}
+		_, _ = fmt.Fprintf(cCtx.App.errWriter(), "flag %s%s is deprecated: %s\n", prefixFor(names[0]), names[0], msg)
+	}
+}
+
+// handledFlag reports whether f is in handled, and adds it. The flags are
+// told apart by identity rather than by name, so that a persistent flag is
+// handled once while a flag of a command named like one of its parent is
+// handled on its own.
+func handledFlag(handled map[Flag]bool, f Flag) bool {
+	if handled == nil || !reflect.TypeOf(f).Comparable() {
+		return false
+	}
+	if handled[f] {
+		return true
+	}
+	handled[f] = true
+	return false
+}
+
+// flagDefault is the value a flag is declared with
+type flagDefault struct {
+	text string
+	// elements holds a copy of the elements of a slice or map value
+	elements reflect.Value
+}
+
+// recordFlagDefaults adds the flags missing from defaults with the values
+// they are declared with. It is called before the flags are applied, which
+// replaces their value by the one from env or file, and records a flag once
+// so that the value of an earlier run is not taken for its default.
+func recordFlagDefaults(defaults map[Flag]flagDefault, flags []Flag) {
+	if defaults == nil {
+		return
+	}
+	for _, f := range flags {
+		if !reflect.TypeOf(f).Comparable() {
+			continue
+		}
+		if _, ok := defaults[f]; ok {
+			continue
+		}
+		if def, ok := declaredDefault(f); ok {
+			defaults[f] = def
+		}
+	}
+}
+
+// declaredDefault applies a copy of f without its env vars, file and
+// destination to a scratch flag set, and returns the value it gets
+func declaredDefault(f Flag) (flagDefault, bool) {
+	fv := reflect.ValueOf(f)
+	names := f.Names()
+	if fv.Kind() != reflect.Ptr || fv.Elem().Kind() != reflect.Struct || len(names) == 0 {
+		return flagDefault{}, false
+	}
+
+	// the fields are cleared on the copy only, not through an embedded
+	// pointer to a flag shared with f
+	typ := fv.Elem().Type()
+	if field, ok := typ.FieldByName("EnvVars"); !ok || len(field.Index) != 1 {
+		return flagDefault{}, false
+	}
+
+	declared := reflect.New(typ)
+	declared.Elem().Set(fv.Elem())
+	for _, name := range []string{"EnvVars", "FilePath", "Destination", "DestinationSlice"} {
+		if field, ok := typ.FieldByName(name); ok && len(field.Index) == 1 {
+			v := declared.Elem().Field(field.Index[0])
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+
+	set := flag.NewFlagSet("", flag.ContinueOnError)
+	if err := declared.Interface().(Flag).Apply(set); err != nil {
+		return flagDefault{}, false
+	}
+	ff := set.Lookup(names[0])
+	if ff == nil {
+		return flagDefault{}, false
+	}
+	return flagDefault{text: ff.DefValue, elements: flagElements(ff.Value)}, true
+}
+
+// flagElements returns a copy of the elements of a slice or map value, as
+// returned by its Value method, or an invalid reflect.Value for other values
+func flagElements(v flag.Value) reflect.Value {
+	method := reflect.ValueOf(v).MethodByName("Value")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return reflect.Value{}
+	}
+
+	elements := method.Call(nil)[0]
+	switch elements.Kind() {
+	case reflect.Slice:
+		copied := reflect.MakeSlice(elements.Type(), elements.Len(), elements.Len())
+		reflect.Copy(copied, elements)
+		return copied
+	case reflect.Map:
+		copied := reflect.MakeMap(elements.Type())
+		for _, key := range elements.MapKeys() {
+			copied.SetMapIndex(key, elements.MapIndex(key))
+		}
+		return copied
+	}
+	return reflect.Value{}
+}
+
+// sameElements reports whether two slices, or two maps, of the same type have
+// the same elements, an empty one being the same as a nil one
+func sameElements(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return false
+	}
+	if a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// runFlagActions runs the actions of the flags which have been set, in the
+// order of flags, once per run of the App. The error of an action is prefixed
+// with the name of its flag.
+func runFlagActions(flags []Flag, cCtx *Context) error {
+	app := rootApp(cCtx)
+	for _, f := range flags {
+		af, ok := f.(ActionableFlag)
+		names := f.Names()
+		if !ok || len(names) == 0 || !cCtx.isSet(names[0]) {
+			continue
+		}
+		if handledFlag(app.flagActionsRun, f) {
+			continue
+		}
+		if err := af.RunAction(cCtx); err != nil {
+			if exitErr, ok := err.(ExitCoder); ok {
+				return Exit(fmt.Sprintf("flag %s: %s", names[0], err), exitErr.ExitCode())
+			}
+			return fmt.Errorf("flag %s: %s", names[0], err)
+		}
+	}
+	return nil
+}
+
+// checkSharedDestinations reports two distinct flags of the command at path,
+// or of one of its descendants, whose Destinations point at the same
+// variable. The persistent flags are the ones inherited from the ancestors.
+func checkSharedDestinations(path string, flags []Flag, persistent []Flag, commands []*Command, ancestors ...*Command) error {
+	destinations := make(map[uintptr]Flag)
+	for _, f := range append(append([]Flag{}, flags...), persistent...) {
+		dest := flagValue(f).FieldByName("Destination")
+		if !dest.IsValid() || dest.Kind() != reflect.Ptr || dest.IsNil() || len(f.Names()) == 0 {
+			continue
+		}
+		if other, ok := destinations[dest.Pointer()]; ok && other != f {
+			return fmt.Errorf("flags %q and %q of %q share a Destination", other.Names()[0], f.Names()[0], path)
+		}
+		destinations[dest.Pointer()] = f
+	}
+
+	for _, c := range commands {
+		if hasCommand(ancestors, c) {
+			continue
+		}
+		inherited := append(append([]Flag{}, persistent...), c.PersistentFlags...)
+		if err := checkSharedDestinations(path+" "+c.Name, c.Flags, inherited, c.Subcommands, append(ancestors, c)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inheritPersistentFlags copies the persistent flags which were set on an
+// ancestor of ctx into set, unless they were set in set itself
+func inheritPersistentFlags(persistent []Flag, set *flag.FlagSet, ctx *Context) {
+	visited := make(map[string]bool)
+	set.Visit(func(f *flag.Flag) {
+		visited[f.Name] = true
+	})
+
+	for _, p := range persistent {
+		names := p.Names()
+		if anyVisited(names, visited) {
+			continue
+		}
+		for _, c := range ctx.Lineage() {
+			if c.flagSet == nil {
+				continue
+			}
+			var ff *flag.Flag
+			c.flagSet.Visit(func(f *flag.Flag) {
+				if hasName(names, f.Name) {
+					ff = f
+				}
+			})
+			if ff != nil {
+				for _, name := range names {
+					if set.Lookup(name) != nil {
+						copyFlag(name, ff, set)
+					}
+				}
+				break
+			}
+		}
+	}
+}
+
+func anyVisited(names []string, visited map[string]bool) bool {
+	for _, name := range names {
+		if visited[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// flagFromEnvOrFile returns the value of the first non-empty environment
+// variable, else the trimmed contents of the first existing file of the comma
+// separated filePath. An environment variable which is set but empty comes
+// last, so it still counts as set. A file which exists but cannot be read is
+// an error.
+func flagFromEnvOrFile(envVars []string, filePath string) (val string, ok bool, err error) {
+	setButEmpty := false
 	for _, envVar := range envVars {
 		envVar = strings.TrimSpace(envVar)
 		if val, ok := syscall.Getenv(envVar); ok {
-			return val, true
+			if val != "" {
+				return val, true, nil
+			}
+			setButEmpty = true
 		}
 	}
 	for _, fileVar := range strings.Split(filePath, ",") {
-		if data, err := ioutil.ReadFile(fileVar); err == nil {
-			return string(data), true
+		if fileVar = strings.TrimSpace(fileVar); fileVar == "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(fileVar)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimSpace(string(data)), true, nil
+	}
+	return "", setButEmpty, nil
+}
+
+// splitSliceValue splits a value given to a string slice flag on commas, so
+// that "--tag a,b --tag c" is "--tag a --tag b --tag c". The values are kept
+// as they are, empty ones included, and a comma escaped as "\," is kept in
+// its value.
+func splitSliceValue(value string) []string {
+	if !strings.Contains(value, ",") {
+		return []string{value}
+	}
+
+	var (
+		values []string
+		cur    strings.Builder
+	)
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == ',':
+			cur.WriteByte(',')
+			i++
+		case value[i] == ',':
+			values = append(values, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(value[i])
+		}
+	}
+	return append(values, cur.String())
+}
+
+// splitSliceElements splits a value given to a numeric or duration slice flag
+// on commas, like splitSliceValue, and trims the elements. An empty element,
+// like in "1,,2" or after a trailing comma, is an error rather than a zero.
+func splitSliceElements(value, kind string) ([]string, error) {
+	elems := splitSliceValue(value)
+	for i, s := range elems {
+		if elems[i] = strings.TrimSpace(s); elems[i] == "" {
+			return nil, fmt.Errorf("empty %s in %q", kind, value)
 		}
 	}
-	return "", false
+	return elems, nil
+}
+
+// splitEnvValue splits the value of a slice flag from the environment or a
+// file on sep, on commas when sep is empty, or on runs of whitespace when sep
+// is blank
+func splitEnvValue(val, sep string) []string {
+	if sep == "" {
+		sep = ","
+	}
+	if strings.TrimSpace(sep) == "" {
+		return strings.Fields(val)
+	}
+	return strings.Split(val, sep)
 }
@@ -1,16 +1,138 @@
 package cli
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
+// Countable is implemented by flag values which keep track of how many times
+// they were set
+type Countable interface {
+	Count() int
+}
+
+// boolValue is the flag.Value used by BoolFlag. Unlike the stdlib bool value
+// it also counts the number of times the flag was set.
+type boolValue struct {
+	destination *bool
+	count       *int
+	parseBool   BoolParser
+}
+
+func newBoolValue(val bool, p *bool, count *int) *boolValue {
+	*p = val
+	*count = 0
+	return &boolValue{
+		destination: p,
+		count:       count,
+	}
+}
+
+// Set parses the value and increments the count
+func (b *boolValue) Set(s string) error {
+	if strings.HasPrefix(s, slPfx) {
+		// Deserializing assumes overwrite
+		var v struct {
+			Value bool
+			Count int
+		}
+		_ = json.Unmarshal([]byte(strings.Replace(s, slPfx, "", 1)), &v)
+		*b.destination = v.Value
+		*b.count = v.Count
+		return nil
+	}
+
+	v, err := parseBool(b.parseBool, s)
+	if err != nil {
+		return err
+	}
+
+	*b.destination = v
+	*b.count++
+	return nil
+}
+
+// String returns a readable representation of this value
+func (b *boolValue) String() string {
+	if b.destination != nil {
+		return strconv.FormatBool(*b.destination)
+	}
+	return strconv.FormatBool(false)
+}
+
+// Serialize allows boolValue to fulfill Serializer, so that the count
+// survives being copied to the aliases of a flag
+func (b *boolValue) Serialize() string {
+	jsonBytes, _ := json.Marshal(struct {
+		Value bool
+		Count int
+	}{*b.destination, *b.count})
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Get returns the value of the flag
+func (b *boolValue) Get() interface{} {
+	return *b.destination
+}
+
+// IsBoolFlag allows the flag to be set without a value
+func (b *boolValue) IsBoolFlag() bool {
+	return true
+}
+
+// Count returns the number of times the flag was set
+func (b *boolValue) Count() int {
+	if b.count != nil {
+		return *b.count
+	}
+	return 0
+}
+
+// negatedBoolValue is the flag.Value registered for the "no-" names of a
+// negatable BoolFlag
+type negatedBoolValue struct {
+	*boolValue
+}
+
+// Set parses the value and sets the negated flag to its opposite
+func (n *negatedBoolValue) Set(s string) error {
+	if strings.HasPrefix(s, slPfx) {
+		// copied from one of the other names of the flag
+		return n.boolValue.Set(s)
+	}
+
+	v, err := parseBool(n.parseBool, s)
+	if err != nil {
+		return err
+	}
+
+	*n.destination = !v
+	*n.count = 0
+	return nil
+}
+
+// String returns a readable representation of this value
+func (n *negatedBoolValue) String() string {
+	if n.boolValue == nil || n.destination == nil {
+		return strconv.FormatBool(false)
+	}
+	return strconv.FormatBool(!*n.destination)
+}
+
+// Get returns the value of the negated flag
+func (n *negatedBoolValue) Get() interface{} {
+	return !*n.destination
+}
+
 // BoolFlag is a flag with type bool
 type BoolFlag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -18,7 +140,33 @@ type BoolFlag struct {
 	Value       bool
 	DefaultText string
 	Destination *bool
-	HasBeenSet  bool
+	// Count, when not nil, is set to the number of times the flag was given
+	Count *int
+	// Negatable also registers a "no-" prefixed form of every long name,
+	// setting the flag to false
+	Negatable bool
+	// Validator, when set, is run with the value of the flag, a bool, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, bool) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// localeParsers parse the values from the environment or a file
+	localeParsers
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -34,7 +182,21 @@ func (f *BoolFlag) String() string {
 
 // Names returns the names of the flag
 func (f *BoolFlag) Names() []string {
-	return flagNames(f.Name, f.Aliases)
+	return append(flagNames(f.Name, f.Aliases), f.negatedNames()...)
+}
+
+func (f *BoolFlag) negatedNames() []string {
+	if !f.Negatable {
+		return nil
+	}
+
+	var names []string
+	for _, name := range flagNames(f.Name, f.Aliases) {
+		if len(name) > 1 {
+			names = append(names, "no-"+name)
+		}
+	}
+	return names
 }
 
 // IsRequired returns whether or not the flag is required
@@ -60,9 +222,13 @@ func (f *BoolFlag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *BoolFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
-			valBool, err := strconv.ParseBool(val)
+			valBool, err := parseBool(f.boolParser, val)
 
 			if err != nil {
 				return fmt.Errorf("could not parse %q as bool value for flag %s: %s", val, f.Name, err)
@@ -73,17 +239,36 @@ func (f *BoolFlag) Apply(set *flag.FlagSet) error {
 		}
 	}
 
-	for _, name := range f.Names() {
-		if f.Destination != nil {
-			set.BoolVar(f.Destination, name, f.Value, f.Usage)
-			continue
-		}
-		set.Bool(name, f.Value, f.Usage)
+	destination := f.Destination
+	if destination == nil {
+		destination = new(bool)
+	}
+	count := f.Count
+	if count == nil {
+		count = new(int)
+	}
+
+	// all names share the same value so that occurrences of any alias are
+	// counted together
+	value := newBoolValue(f.Value, destination, count)
+	for _, name := range flagNames(f.Name, f.Aliases) {
+		set.Var(value, name, f.Usage)
+	}
+	for _, name := range f.negatedNames() {
+		set.Var(&negatedBoolValue{value}, name, f.Usage)
 	}
 
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *BoolFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Bool(f.Names()[0]))
+	}
+	return nil
+}
+
 // Bool looks up the value of a local BoolFlag, returns
 // false if not found
 func (c *Context) Bool(name string) bool {
@@ -104,3 +289,22 @@ func lookupBool(name string, set *flag.FlagSet) bool {
 	}
 	return false
 }
+
+// Count returns the number of times the named BoolFlag or CountFlag was set,
+// or 0 if not found
+func (c *Context) Count(name string) int {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupCount(name, fs)
+	}
+	return 0
+}
+
+func lookupCount(name string, set *flag.FlagSet) int {
+	f := set.Lookup(name)
+	if f != nil {
+		if cv, ok := f.Value.(Countable); ok {
+			return cv.Count()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"EiB", 1 << 60},
+	{"EB", 1e18},
+	{"PiB", 1 << 50},
+	{"PB", 1e15},
+	{"TiB", 1 << 40},
+	{"TB", 1e12},
+	{"GiB", 1 << 30},
+	{"GB", 1e9},
+	{"MiB", 1 << 20},
+	{"MB", 1e6},
+	{"KiB", 1 << 10},
+	{"KB", 1e3},
+	{"B", 1},
+}
+
+// parseByteSize parses a number of bytes with an optional decimal (KB, MB,
+// ...) or binary (KiB, MiB, ...) suffix, case insensitively
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	number, suffix := s[:i], strings.TrimSpace(s[i:])
+
+	size := int64(-1)
+	if suffix == "" {
+		size = 1
+	}
+	for _, unit := range byteSizeUnits {
+		if strings.EqualFold(suffix, unit.suffix) {
+			size = unit.size
+		}
+	}
+	if size < 0 || number == "" {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	if !strings.Contains(number, ".") {
+		n, err := strconv.ParseInt(number, 10, 64)
+		if err != nil || n > math.MaxInt64/size {
+			return 0, fmt.Errorf("byte size %q overflows int64", s)
+		}
+		return n * size, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	f = math.Round(f * float64(size))
+	if f >= math.MaxInt64 {
+		return 0, fmt.Errorf("byte size %q overflows int64", s)
+	}
+	return int64(f), nil
+}
+
+// formatByteSize returns the shortest exact representation of n using the
+// suffixes understood by parseByteSize
+func formatByteSize(n int64) string {
+	best := ""
+	for _, unit := range byteSizeUnits {
+		if n < unit.size {
+			continue
+		}
+
+		// on ties the larger unit wins, as it is tried first
+		s := strconv.FormatFloat(float64(n)/float64(unit.size), 'f', -1, 64) + unit.suffix
+		if parsed, err := parseByteSize(s); err == nil && parsed == n && (best == "" || len(s) < len(best)) {
+			best = s
+		}
+	}
+	if best == "" {
+		return strconv.FormatInt(n, 10) + "B"
+	}
+	return best
+}
+
+// byteSizeValue is the flag.Value for ByteSizeFlag
+type byteSizeValue int64
+
+func newByteSizeValue(val int64, p *int64) *byteSizeValue {
+	*p = val
+	return (*byteSizeValue)(p)
+}
+
+func (b *byteSizeValue) Set(s string) error {
+	v, err := parseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = byteSizeValue(v)
+	return nil
+}
+
+func (b *byteSizeValue) Get() interface{} { return int64(*b) }
+
+func (b *byteSizeValue) String() string { return strconv.FormatInt(int64(*b), 10) }
+
+// ByteSizeFlag is a flag with type int64, parsed from human readable sizes
+// like 512MB or 1.5GiB
+type ByteSizeFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       int64
+	DefaultText string
+	Destination *int64
+	// Validator, when set, is run with the value of the flag, an int64 number of
+	// bytes, once the flags are parsed and only if the flag has been set. Its
+	// error rejects the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, int64) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// RawDefault shows the default in help as a number of bytes instead of
+	// in the largest unit it is a whole number of, like 1MiB
+	RawDefault bool
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *ByteSizeFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *ByteSizeFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *ByteSizeFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *ByteSizeFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *ByteSizeFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *ByteSizeFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *ByteSizeFlag) GetValue() string {
+	return formatByteSize(f.Value)
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *ByteSizeFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valInt, err := parseByteSize(val)
+		if err != nil {
+			return fmt.Errorf("could not parse %q as byte size value for flag %s: %s", val, f.Name, err)
+		}
+
+		f.Value = valInt
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newByteSizeValue(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newByteSizeValue(f.Value, new(int64)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *ByteSizeFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.ByteSize(f.Names()[0]))
+	}
+	return nil
+}
+
+// ByteSize looks up the value of a local ByteSizeFlag, returns
+// 0 if not found
+func (c *Context) ByteSize(name string) int64 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupByteSize(name, fs)
+	}
+	return 0
+}
+
+func lookupByteSize(name string, set *flag.FlagSet) int64 {
+	f := set.Lookup(name)
+	if f != nil {
+		parsed, err := strconv.ParseInt(f.Value.String(), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return parsed
+	}
+	return 0
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// countValue is the flag.Value used by CountFlag. It is set without a value
+// like a bool flag, and counts the number of times it is set.
+type countValue struct {
+	destination *int
+	parseBool   BoolParser
+	hasBeenSet  bool
+}
+
+// Set increments the count, or resets it when the value is false. The count
+// given on the command line replaces the default one.
+func (c *countValue) Set(s string) error {
+	if !c.hasBeenSet {
+		*c.destination = 0
+		c.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(s, slPfx) {
+		// Deserializing assumes overwrite
+		_ = json.Unmarshal([]byte(strings.Replace(s, slPfx, "", 1)), c.destination)
+		return nil
+	}
+
+	v, err := parseBool(c.parseBool, s)
+	if err != nil {
+		return err
+	}
+
+	if v {
+		*c.destination++
+	} else {
+		*c.destination = 0
+	}
+	return nil
+}
+
+// String returns a readable representation of this value
+func (c *countValue) String() string {
+	if c.destination != nil {
+		return strconv.Itoa(*c.destination)
+	}
+	return "0"
+}
+
+// Serialize allows countValue to fulfill Serializer, so that the count is
+// copied as is to the aliases of a flag
+func (c *countValue) Serialize() string {
+	return fmt.Sprintf("%s%d", slPfx, *c.destination)
+}
+
+// Get returns the count of the flag
+func (c *countValue) Get() interface{} {
+	return *c.destination
+}
+
+// IsBoolFlag allows the flag to be set without a value
+func (c *countValue) IsBoolFlag() bool {
+	return true
+}
+
+// Count returns the number of times the flag was set
+func (c *countValue) Count() int {
+	if c.destination != nil {
+		return *c.destination
+	}
+	return 0
+}
+
+// CountFlag is a flag counting the number of times it is given, like -v -v -v
+// for a verbosity of 3. It takes no value, and its aliases count together.
+type CountFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       int
+	DefaultText string
+	Destination *int
+	// Validator, when set, is run with the value of the flag, an int counting the
+	// times it was given, once the flags are parsed and only if the flag has been
+	// set. Its error rejects the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, int) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *CountFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *CountFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *CountFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *CountFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *CountFlag) TakesValue() bool {
+	return false
+}
+
+// GetUsage returns the usage string for the flag
+func (f *CountFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *CountFlag) GetValue() string {
+	return ""
+}
+
+// Apply populates the flag given the flag set and environment. A value from
+// the environment or a file is the count itself.
+func (f *CountFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valInt, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("could not parse %q as count value for flag %s: %s", val, f.Name, err)
+		}
+
+		f.Value = valInt
+		f.HasBeenSet = true
+	}
+
+	destination := f.Destination
+	if destination == nil {
+		destination = new(int)
+	}
+	*destination = f.Value
+
+	// all names share the same value so that occurrences of any alias are
+	// counted together
+	value := &countValue{destination: destination}
+	for _, name := range f.Names() {
+		set.Var(value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *CountFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Count(f.Names()[0]))
+	}
+	return nil
+}
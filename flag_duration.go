@@ -3,14 +3,73 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 )
 
+// parseDuration parses s as a time.Duration. When unit is not zero, a bare
+// number without a unit, like 30 or 1.5, is taken as a multiple of unit.
+// Explicit units always win.
+func parseDuration(s string, unit time.Duration) (time.Duration, error) {
+	if unit == 0 {
+		return time.ParseDuration(s)
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		d := time.Duration(n) * unit
+		if d/unit != time.Duration(n) {
+			return 0, fmt.Errorf("duration %q overflows", s)
+		}
+		return d, nil
+	}
+
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		v := math.Round(n * float64(unit))
+		if math.IsNaN(v) || math.IsInf(v, 0) || v >= math.MaxInt64 || v <= math.MinInt64 {
+			return 0, fmt.Errorf("duration %q overflows", s)
+		}
+		return time.Duration(v), nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+// durationValue is the flag.Value for a DurationFlag with a DefaultUnit
+type durationValue struct {
+	duration *time.Duration
+	unit     time.Duration
+}
+
+func newDurationValue(val time.Duration, p *time.Duration, unit time.Duration) *durationValue {
+	*p = val
+	return &durationValue{duration: p, unit: unit}
+}
+
+func (d *durationValue) Set(s string) error {
+	v, err := parseDuration(s, d.unit)
+	if err != nil {
+		return err
+	}
+	*d.duration = v
+	return nil
+}
+
+func (d *durationValue) Get() interface{} { return *d.duration }
+
+func (d *durationValue) String() string {
+	if d.duration == nil {
+		return ""
+	}
+	return d.duration.String()
+}
+
 // DurationFlag is a flag with type time.Duration (see https://golang.org/pkg/time/#ParseDuration)
 type DurationFlag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -18,7 +77,35 @@ type DurationFlag struct {
 	Value       time.Duration
 	DefaultText string
 	Destination *time.Duration
-	HasBeenSet  bool
+	// DefaultUnit, when not zero, is the unit of values given as bare
+	// numbers, e.g. time.Second to read 30 as 30s
+	DefaultUnit time.Duration
+	// Validator, when set, is run with the value of the flag, a time.Duration,
+	// once the flags are parsed and only if the flag has been set. Its error
+	// rejects the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, time.Duration) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// RawDefault shows the default in help as a number of nanoseconds instead
+	// of a duration like 1h30m
+	RawDefault bool
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -60,20 +147,30 @@ func (f *DurationFlag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *DurationFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
-		if val != "" {
-			valDuration, err := time.ParseDuration(val)
-
-			if err != nil {
-				return fmt.Errorf("could not parse %q as duration value for flag %s: %s", val, f.Name, err)
-			}
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valDuration, err := parseDuration(val, f.DefaultUnit)
 
-			f.Value = valDuration
-			f.HasBeenSet = true
+		if err != nil {
+			return fmt.Errorf("could not parse %q as duration value for flag %s: %s", val, f.Name, err)
 		}
+
+		f.Value = valDuration
+		f.HasBeenSet = true
 	}
 
 	for _, name := range f.Names() {
+		if f.DefaultUnit != 0 {
+			destination := f.Destination
+			if destination == nil {
+				destination = new(time.Duration)
+			}
+			set.Var(newDurationValue(f.Value, destination, f.DefaultUnit), name, f.Usage)
+			continue
+		}
 		if f.Destination != nil {
 			set.DurationVar(f.Destination, name, f.Value, f.Usage)
 			continue
@@ -83,6 +180,14 @@ func (f *DurationFlag) Apply(set *flag.FlagSet) error {
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *DurationFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Duration(f.Names()[0]))
+	}
+	return nil
+}
+
 // Duration looks up the value of a local DurationFlag, returns
 // 0 if not found
 func (c *Context) Duration(name string) time.Duration {
@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DurationSlice wraps []time.Duration to satisfy flag.Value
+type DurationSlice struct {
+	slice      []time.Duration
+	hasBeenSet bool
+}
+
+// NewDurationSlice makes a *DurationSlice with default values
+func NewDurationSlice(defaults ...time.Duration) *DurationSlice {
+	return &DurationSlice{slice: append([]time.Duration{}, defaults...)}
+}
+
+// Set parses the comma separated value into durations and appends them to the
+// list of values
+func (d *DurationSlice) Set(value string) error {
+	if !d.hasBeenSet {
+		d.slice = []time.Duration{}
+		d.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(value, slPfx) {
+		// Deserializing assumes overwrite
+		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &d.slice)
+		d.hasBeenSet = true
+		return nil
+	}
+
+	elems, err := splitSliceElements(value, "duration")
+	if err != nil {
+		return err
+	}
+	for _, s := range elems {
+		tmp, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		d.slice = append(d.slice, tmp)
+	}
+
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (d *DurationSlice) String() string {
+	return fmt.Sprintf("%v", d.slice)
+}
+
+// Serialize allows DurationSlice to fulfill Serializer
+func (d *DurationSlice) Serialize() string {
+	jsonBytes, _ := json.Marshal(d.slice)
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Value returns the slice of durations set by this flag
+func (d *DurationSlice) Value() []time.Duration {
+	return d.slice
+}
+
+// Get returns the slice of durations set by this flag
+func (d *DurationSlice) Get() interface{} {
+	return *d
+}
+
+// DurationSliceFlag is a flag with type *DurationSlice
+type DurationSliceFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       *DurationSlice
+	DefaultText string
+	// Validator, when set, is run with the value of the flag, a []time.Duration,
+	// once the flags are parsed and only if the flag has been set. Its error
+	// rejects the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []time.Duration) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *DurationSliceFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *DurationSliceFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *DurationSliceFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *DurationSliceFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *DurationSliceFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *DurationSliceFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *DurationSliceFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *DurationSliceFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		f.Value = &DurationSlice{}
+
+		for _, s := range splitEnvValue(val, f.Separator) {
+			if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
+				return fmt.Errorf("could not parse %q as duration slice value for flag %s: %s", val, f.Name, err)
+			}
+		}
+
+		// Set this to false so that we reset the slice if we then set values from
+		// flags that have already been set by the environment.
+		f.Value.hasBeenSet = false
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Value == nil {
+			f.Value = &DurationSlice{}
+		}
+		set.Var(f.Value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *DurationSliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.DurationSlice(f.Names()[0]))
+	}
+	return nil
+}
+
+// DurationSlice looks up the value of a local DurationSliceFlag, returns
+// nil if not found
+func (c *Context) DurationSlice(name string) []time.Duration {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupDurationSlice(name, fs)
+	}
+	return nil
+}
+
+func lookupDurationSlice(name string, set *flag.FlagSet) []time.Duration {
+	f := set.Lookup(name)
+	if f != nil {
+		if slice, ok := f.Value.(*DurationSlice); ok {
+			return slice.Value()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// fileContentsValue is the flag.Value for FileContentsFlag. It holds the path
+// given on the command line and the contents read from it.
+type fileContentsValue struct {
+	path        string
+	data        []byte
+	maxSize     int64
+	reader      io.Reader
+	destination *[]byte
+	hasBeenSet  bool
+}
+
+// Set reads the file at path, or the reader when path is "-"
+func (v *fileContentsValue) Set(path string) error {
+	// aliases share this value, so the same path is only read once
+	if v.hasBeenSet && path == v.path {
+		return nil
+	}
+
+	data, err := v.read(path)
+	if err != nil {
+		return err
+	}
+
+	v.path = path
+	v.data = data
+	v.hasBeenSet = true
+	if v.destination != nil {
+		*v.destination = data
+	}
+	return nil
+}
+
+func (v *fileContentsValue) read(path string) ([]byte, error) {
+	var r io.Reader
+	if path == "-" {
+		r = v.reader
+		if r == nil {
+			r = os.Stdin
+		}
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	if v.maxSize > 0 {
+		r = io.LimitReader(r, v.maxSize+1)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if v.maxSize > 0 && int64(len(data)) > v.maxSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", path, v.maxSize)
+	}
+	return data, nil
+}
+
+// Get returns the contents of the file
+func (v *fileContentsValue) Get() interface{} {
+	return v.data
+}
+
+// String returns the path of the file
+func (v *fileContentsValue) String() string {
+	return v.path
+}
+
+// setFileContentsReader makes the FileContentsFlags of set read "-" from r
+func setFileContentsReader(set *flag.FlagSet, r io.Reader) {
+	set.VisitAll(func(f *flag.Flag) {
+		if v, ok := f.Value.(*fileContentsValue); ok {
+			v.reader = r
+		}
+	})
+}
+
+// FileContentsFlag is a flag taking the path of a file whose contents are read
+// when the flag is parsed. The path "-" reads the App's Reader.
+type FileContentsFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       string
+	DefaultText string
+	Destination *[]byte
+	// MaxSize is the maximum size of the file in bytes, if not zero
+	MaxSize int64
+	// Validator, when set, is run with the value of the flag, the []byte read,
+	// once the flags are parsed and only if the flag has been set. Its error
+	// rejects the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []byte) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *FileContentsFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *FileContentsFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *FileContentsFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *FileContentsFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *FileContentsFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *FileContentsFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *FileContentsFlag) GetValue() string {
+	return f.Value
+}
+
+// Apply populates the flag given the flag set and environment. The default
+// Value is read if the file exists, while a path from the environment must be
+// readable.
+func (f *FileContentsFlag) Apply(set *flag.FlagSet) error {
+	value := &fileContentsValue{
+		path:        f.Value,
+		maxSize:     f.MaxSize,
+		destination: f.Destination,
+	}
+
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if err := value.Set(val); err != nil {
+			return fmt.Errorf("could not read %q as file contents for flag %s: %s", val, f.Name, err)
+		}
+
+		f.Value = val
+		f.HasBeenSet = true
+	} else if f.Value != "" {
+		data, err := value.read(f.Value)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not read %q as file contents for flag %s: %s", f.Value, f.Name, err)
+		}
+		value.data = data
+		if f.Destination != nil {
+			*f.Destination = data
+		}
+	}
+	// Set this to false so that a path given on the command line is read even
+	// if it is the same as the default or the one from the environment.
+	value.hasBeenSet = false
+
+	for _, name := range f.Names() {
+		set.Var(value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *FileContentsFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Bytes(f.Names()[0]))
+	}
+	return nil
+}
+
+// Bytes looks up the contents of the file of a local FileContentsFlag, returns
+// nil if not found
+func (c *Context) Bytes(name string) []byte {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupBytes(name, fs)
+	}
+	return nil
+}
+
+func lookupBytes(name string, set *flag.FlagSet) []byte {
+	f := set.Lookup(name)
+	if f != nil {
+		if v, ok := f.Value.(*fileContentsValue); ok {
+			return v.data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// float32Value is the flag.Value for Float32Flag, as the stdlib flag package
+// has no float32 support
+type float32Value float32
+
+func newFloat32Value(val float32, p *float32) *float32Value {
+	*p = val
+	return (*float32Value)(p)
+}
+
+func (f *float32Value) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return err
+	}
+	*f = float32Value(v)
+	return nil
+}
+
+func (f *float32Value) Get() interface{} { return float32(*f) }
+
+func (f *float32Value) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 32)
+}
+
+// Float32Flag is a flag with type float32
+type Float32Flag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       float32
+	DefaultText string
+	Destination *float32
+	// Validator, when set, is run with the value of the flag, a float32, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, float32) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// localeParsers parse the values from the environment or a file
+	localeParsers
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *Float32Flag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *Float32Flag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *Float32Flag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *Float32Flag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *Float32Flag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *Float32Flag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *Float32Flag) GetValue() string {
+	return strconv.FormatFloat(float64(f.Value), 'f', -1, 32)
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *Float32Flag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valFloat, err := strconv.ParseFloat(parseNumber(f.numberParser, val), 32)
+
+		if err != nil {
+			return fmt.Errorf("could not parse %q as float32 value for flag %s: %s", val, f.Name, err)
+		}
+
+		f.Value = float32(valFloat)
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newFloat32Value(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newFloat32Value(f.Value, new(float32)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Float32Flag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Float32(f.Names()[0]))
+	}
+	return nil
+}
+
+// Float32 looks up the value of a local Float32Flag, returns
+// 0 if not found
+func (c *Context) Float32(name string) float32 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupFloat32(name, fs)
+	}
+	return 0
+}
+
+func lookupFloat32(name string, set *flag.FlagSet) float32 {
+	f := set.Lookup(name)
+	if f != nil {
+		parsed, err := strconv.ParseFloat(f.Value.String(), 32)
+		if err != nil {
+			return 0
+		}
+		return float32(parsed)
+	}
+	return 0
+}
@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Float32Slice wraps []float32 to satisfy flag.Value
+type Float32Slice struct {
+	slice       []float32
+	hasBeenSet  bool
+	parseNumber NumberParser
+}
+
+// NewFloat32Slice makes a *Float32Slice with default values
+func NewFloat32Slice(defaults ...float32) *Float32Slice {
+	return &Float32Slice{slice: append([]float32{}, defaults...)}
+}
+
+// Set parses the comma separated value into float32s and appends them to the
+// list of values
+func (f *Float32Slice) Set(value string) error {
+	if !f.hasBeenSet {
+		f.slice = []float32{}
+		f.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(value, slPfx) {
+		// Deserializing assumes overwrite
+		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &f.slice)
+		f.hasBeenSet = true
+		return nil
+	}
+
+	elems, err := splitSliceElements(value, "float")
+	if err != nil {
+		return err
+	}
+	for _, s := range elems {
+		if err := f.add(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add parses a single value, with the NumberParser if any, and appends it to
+// the list of values
+func (f *Float32Slice) add(s string) error {
+	tmp, err := strconv.ParseFloat(parseNumber(f.parseNumber, s), 32)
+	if err != nil {
+		return err
+	}
+
+	f.slice = append(f.slice, float32(tmp))
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (f *Float32Slice) String() string {
+	return fmt.Sprintf("%#v", f.slice)
+}
+
+// Serialize allows Float32Slice to fulfill Serializer
+func (f *Float32Slice) Serialize() string {
+	jsonBytes, _ := json.Marshal(f.slice)
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Value returns the slice of float32s set by this flag
+func (f *Float32Slice) Value() []float32 {
+	return f.slice
+}
+
+// Get returns the slice of float32s set by this flag
+func (f *Float32Slice) Get() interface{} {
+	return *f
+}
+
+// Float32SliceFlag is a flag with type *Float32Slice
+type Float32SliceFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       *Float32Slice
+	DefaultText string
+	// Validator, when set, is run with the value of the flag, a []float32, once
+	// the flags are parsed and only if the flag has been set. Its error rejects
+	// the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []float32) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// localeParsers parse the values from the environment or a file
+	localeParsers
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *Float32SliceFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *Float32SliceFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *Float32SliceFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *Float32SliceFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true if the flag takes a value, otherwise false
+func (f *Float32SliceFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *Float32SliceFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *Float32SliceFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *Float32SliceFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		f.Value = &Float32Slice{parseNumber: f.numberParser}
+
+		// the values are split on the Separator alone
+		for _, s := range splitEnvValue(val, f.Separator) {
+			if err := f.Value.add(strings.TrimSpace(s)); err != nil {
+				return fmt.Errorf("could not parse %q as float32 slice value for flag %s: %s", val, f.Name, err)
+			}
+		}
+
+		// Set this to false so that we reset the slice if we then set values from
+		// flags that have already been set by the environment.
+		f.Value.hasBeenSet = false
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Value == nil {
+			f.Value = &Float32Slice{}
+		}
+		set.Var(f.Value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Float32SliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Float32Slice(f.Names()[0]))
+	}
+	return nil
+}
+
+// Float32Slice looks up the value of a local Float32SliceFlag, returns
+// nil if not found
+func (c *Context) Float32Slice(name string) []float32 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupFloat32Slice(name, fs)
+	}
+	return nil
+}
+
+func lookupFloat32Slice(name string, set *flag.FlagSet) []float32 {
+	f := set.Lookup(name)
+	if f != nil {
+		if slice, ok := f.Value.(*Float32Slice); ok {
+			return slice.Value()
+		}
+	}
+	return nil
+}
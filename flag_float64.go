@@ -11,6 +11,7 @@ type Float64Flag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -18,7 +19,31 @@ type Float64Flag struct {
 	Value       float64
 	DefaultText string
 	Destination *float64
-	HasBeenSet  bool
+	// Validator, when set, is run with the value of the flag, a float64, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, float64) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// localeParsers parse the values from the environment or a file
+	localeParsers
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -60,17 +85,19 @@ func (f *Float64Flag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *Float64Flag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
-		if val != "" {
-			valFloat, err := strconv.ParseFloat(val, 10)
-
-			if err != nil {
-				return fmt.Errorf("could not parse %q as float64 value for flag %s: %s", val, f.Name, err)
-			}
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valFloat, err := strconv.ParseFloat(parseNumber(f.numberParser, val), 10)
 
-			f.Value = valFloat
-			f.HasBeenSet = true
+		if err != nil {
+			return fmt.Errorf("could not parse %q as float64 value for flag %s: %s", val, f.Name, err)
 		}
+
+		f.Value = valFloat
+		f.HasBeenSet = true
 	}
 
 	for _, name := range f.Names() {
@@ -84,6 +111,14 @@ func (f *Float64Flag) Apply(set *flag.FlagSet) error {
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Float64Flag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Float64(f.Names()[0]))
+	}
+	return nil
+}
+
 // Float64 looks up the value of a local Float64Flag, returns
 // 0 if not found
 func (c *Context) Float64(name string) float64 {
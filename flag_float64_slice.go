@@ -10,8 +10,11 @@ import (
 
 // Float64Slice wraps []float64 to satisfy flag.Value
 type Float64Slice struct {
-	slice      []float64
-	hasBeenSet bool
+	slice       []float64
+	hasBeenSet  bool
+	parseNumber NumberParser
+	// destination, when set, is kept holding a copy of slice
+	destination *[]float64
 }
 
 // NewFloat64Slice makes a *Float64Slice with default values
@@ -19,8 +22,11 @@ func NewFloat64Slice(defaults ...float64) *Float64Slice {
 	return &Float64Slice{slice: append([]float64{}, defaults...)}
 }
 
-// Set parses the value into a float64 and appends it to the list of values
+// Set parses the comma separated value into float64s and appends them to the
+// list of values
 func (f *Float64Slice) Set(value string) error {
+	defer f.syncDestination()
+
 	if !f.hasBeenSet {
 		f.slice = []float64{}
 		f.hasBeenSet = true
@@ -33,7 +39,22 @@ func (f *Float64Slice) Set(value string) error {
 		return nil
 	}
 
-	tmp, err := strconv.ParseFloat(value, 64)
+	elems, err := splitSliceElements(value, "float")
+	if err != nil {
+		return err
+	}
+	for _, s := range elems {
+		if err := f.add(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add parses a single value, with the NumberParser if any, and appends it to
+// the list of values
+func (f *Float64Slice) add(s string) error {
+	tmp, err := strconv.ParseFloat(parseNumber(f.parseNumber, s), 64)
 	if err != nil {
 		return err
 	}
@@ -42,6 +63,13 @@ func (f *Float64Slice) Set(value string) error {
 	return nil
 }
 
+// syncDestination copies the values to the destination of the flag, if any
+func (f *Float64Slice) syncDestination() {
+	if f.destination != nil {
+		*f.destination = append([]float64{}, f.slice...)
+	}
+}
+
 // String returns a readable representation of this value (for usage defaults)
 func (f *Float64Slice) String() string {
 	return fmt.Sprintf("%#v", f.slice)
@@ -68,13 +96,45 @@ type Float64SliceFlag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
 	Hidden      bool
 	Value       *Float64Slice
 	DefaultText string
-	HasBeenSet  bool
+	// Validator, when set, is run with the value of the flag, a []float64, once
+	// the flags are parsed and only if the flag has been set. Its error rejects
+	// the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []float64) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// Destination, when set, holds the values of the flag once the flags are
+	// parsed: the defaults of Value, replaced by the values given on the
+	// command line or by env or file.
+	Destination *[]float64
+	// localeParsers parse the values from the environment or a file
+	localeParsers
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -119,30 +179,49 @@ func (f *Float64SliceFlag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *Float64SliceFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
-		if val != "" {
-			f.Value = &Float64Slice{}
-
-			for _, s := range strings.Split(val, ",") {
-				if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
-					return fmt.Errorf("could not parse %q as float64 slice value for flag %s: %s", f.Value, f.Name, err)
-				}
-			}
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		f.Value = &Float64Slice{parseNumber: f.numberParser}
 
-			f.HasBeenSet = true
+		// the values are split on the Separator alone
+		for _, s := range splitEnvValue(val, f.Separator) {
+			if err := f.Value.add(strings.TrimSpace(s)); err != nil {
+				return fmt.Errorf("could not parse %q as float64 slice value for flag %s: %s", val, f.Name, err)
+			}
 		}
+
+		f.HasBeenSet = true
+	}
+
+	if f.Value == nil {
+		f.Value = &Float64Slice{}
+	}
+	setValue := f.Value
+	if f.Destination != nil {
+		setValue = &Float64Slice{slice: append([]float64{}, f.Value.slice...), parseNumber: f.Value.parseNumber, destination: f.Destination}
+		setValue.syncDestination()
 	}
 
+	// all names share the same value, so that copying the value to the
+	// aliases leaves the destination as it is
 	for _, name := range f.Names() {
-		if f.Value == nil {
-			f.Value = &Float64Slice{}
-		}
-		set.Var(f.Value, name, f.Usage)
+		set.Var(setValue, name, f.Usage)
 	}
 
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Float64SliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Float64Slice(f.Names()[0]))
+	}
+	return nil
+}
+
 // Float64Slice looks up the value of a local Float64SliceFlag, returns
 // nil if not found
 func (c *Context) Float64Slice(name string) []float64 {
@@ -16,6 +16,7 @@ type GenericFlag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -23,7 +24,29 @@ type GenericFlag struct {
 	TakesFile   bool
 	Value       Generic
 	DefaultText string
-	HasBeenSet  bool
+	// Validator, when set, is run once the flags are parsed and only if the flag
+	// has been set, with what the Value or Get method of the Generic returns, or
+	// its string when it has neither. Its error rejects the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, interface{}) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -69,7 +92,11 @@ func (f *GenericFlag) GetValue() string {
 // Apply takes the flagset and calls Set on the generic flag with the value
 // provided by the user for parsing by the flag
 func (f GenericFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if val != "" {
 			if err := f.Value.Set(val); err != nil {
 				return fmt.Errorf("could not parse %q as value for flag %s: %s", val, f.Name, err)
@@ -86,6 +113,14 @@ func (f GenericFlag) Apply(set *flag.FlagSet) error {
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *GenericFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Generic(f.Names()[0]))
+	}
+	return nil
+}
+
 // Generic looks up the value of a local GenericFlag, returns
 // nil if not found
 func (c *Context) Generic(name string) interface{} {
@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// GenericSlice wraps a slice of Generic values to satisfy flag.Value. Every
+// value set is parsed by a new Generic made by the factory.
+type GenericSlice struct {
+	factory    func() Generic
+	slice      []Generic
+	hasBeenSet bool
+}
+
+// NewGenericSlice makes a *GenericSlice creating its values with factory,
+// with default values
+func NewGenericSlice(factory func() Generic, defaults ...Generic) *GenericSlice {
+	return &GenericSlice{factory: factory, slice: append([]Generic{}, defaults...)}
+}
+
+// Set parses the value with a new Generic and appends it to the list of values
+func (g *GenericSlice) Set(value string) error {
+	if !g.hasBeenSet {
+		g.slice = []Generic{}
+		g.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(value, slPfx) {
+		// Deserializing assumes overwrite
+		var values []string
+		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &values)
+		g.slice = []Generic{}
+		for _, v := range values {
+			if err := g.append(v); err != nil {
+				return err
+			}
+		}
+		g.hasBeenSet = true
+		return nil
+	}
+
+	return g.append(value)
+}
+
+func (g *GenericSlice) append(value string) error {
+	v := g.factory()
+	if err := v.Set(value); err != nil {
+		return err
+	}
+	g.slice = append(g.slice, v)
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (g *GenericSlice) String() string {
+	return strings.Join(g.strings(), ", ")
+}
+
+func (g *GenericSlice) strings() []string {
+	var values []string
+	for _, v := range g.slice {
+		values = append(values, v.String())
+	}
+	return values
+}
+
+// Serialize allows GenericSlice to fulfill Serializer. Each value is
+// serialized with its own Serializer if it has one.
+func (g *GenericSlice) Serialize() string {
+	values := []string{}
+	for _, v := range g.slice {
+		if s, ok := v.(Serializer); ok {
+			values = append(values, s.Serialize())
+			continue
+		}
+		values = append(values, v.String())
+	}
+
+	jsonBytes, _ := json.Marshal(values)
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Value returns the slice of Generic values set by this flag
+func (g *GenericSlice) Value() []Generic {
+	return g.slice
+}
+
+// Get returns the slice of Generic values set by this flag
+func (g *GenericSlice) Get() interface{} {
+	return *g
+}
+
+// GenericSliceFlag is a flag with type *GenericSlice
+type GenericSliceFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	TakesFile   bool
+	Value       *GenericSlice
+	DefaultText string
+	// Validator, when set, is run with the value of the flag, a []Generic, once
+	// the flags are parsed and only if the flag has been set. Its error rejects
+	// the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []interface{}) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *GenericSliceFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *GenericSliceFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *GenericSliceFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *GenericSliceFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *GenericSliceFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *GenericSliceFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *GenericSliceFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *GenericSliceFlag) Apply(set *flag.FlagSet) error {
+	if f.Value == nil || f.Value.factory == nil {
+		return fmt.Errorf("flag %s needs a Value made with NewGenericSlice", f.Name)
+	}
+
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		for _, s := range splitEnvValue(val, f.Separator) {
+			if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
+				return fmt.Errorf("could not parse %q as generic slice value for flag %s: %s", val, f.Name, err)
+			}
+		}
+
+		// Set this to false so that we reset the slice if we then set values from
+		// flags that have already been set by the environment.
+		f.Value.hasBeenSet = false
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		set.Var(f.Value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *GenericSliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.GenericSlice(f.Names()[0]))
+	}
+	return nil
+}
+
+// GenericSlice looks up the value of a local GenericSliceFlag, returns
+// nil if not found
+func (c *Context) GenericSlice(name string) []interface{} {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupGenericSlice(name, fs)
+	}
+	return nil
+}
+
+func lookupGenericSlice(name string, set *flag.FlagSet) []interface{} {
+	f := set.Lookup(name)
+	if f != nil {
+		if slice, ok := f.Value.(*GenericSlice); ok {
+			var values []interface{}
+			for _, v := range slice.Value() {
+				values = append(values, v)
+			}
+			return values
+		}
+	}
+	return nil
+}
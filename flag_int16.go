@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// int16Value is the flag.Value for Int16Flag, rejecting values which do not fit
+// in 16 bits
+type int16Value int16
+
+func newInt16Value(val int16, p *int16) *int16Value {
+	*p = val
+	return (*int16Value)(p)
+}
+
+func (i *int16Value) Set(s string) error {
+	v, err := parseSizedInt(s, 16)
+	if err != nil {
+		return err
+	}
+	*i = int16Value(v)
+	return nil
+}
+
+func (i *int16Value) Get() interface{} { return int16(*i) }
+
+func (i *int16Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// Int16Flag is a flag with type int16
+type Int16Flag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       int16
+	DefaultText string
+	Destination *int16
+	// Validator, when set, is run with the value of the flag, an int16, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, int16) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// localeParsers parse the values from the environment or a file
+	localeParsers
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *Int16Flag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *Int16Flag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *Int16Flag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *Int16Flag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *Int16Flag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *Int16Flag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *Int16Flag) GetValue() string {
+	return fmt.Sprintf("%d", f.Value)
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *Int16Flag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valInt, err := parseSizedInt(parseNumber(f.numberParser, val), 16)
+		if err != nil {
+			return fmt.Errorf("could not parse %q as int16 value for flag %s: %s", val, f.Name, err)
+		}
+
+		f.Value = int16(valInt)
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newInt16Value(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newInt16Value(f.Value, new(int16)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Int16Flag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Int16(f.Names()[0]))
+	}
+	return nil
+}
+
+// Int16 looks up the value of a local Int16Flag, returns
+// 0 if not found
+func (c *Context) Int16(name string) int16 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupInt16(name, fs)
+	}
+	return 0
+}
+
+func lookupInt16(name string, set *flag.FlagSet) int16 {
+	f := set.Lookup(name)
+	if f != nil {
+		parsed, err := parseSizedInt(f.Value.String(), 16)
+		if err != nil {
+			return 0
+		}
+		return int16(parsed)
+	}
+	return 0
+}
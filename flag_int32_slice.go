@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Int32Slice wraps []int32 to satisfy flag.Value
+type Int32Slice struct {
+	slice      []int32
+	hasBeenSet bool
+}
+
+// NewInt32Slice makes an *Int32Slice with default values
+func NewInt32Slice(defaults ...int32) *Int32Slice {
+	return &Int32Slice{slice: append([]int32{}, defaults...)}
+}
+
+// Set parses the comma separated value into int32s and appends them to the list
+// of values. Values out of the range of int32 are an error.
+func (i *Int32Slice) Set(value string) error {
+	if !i.hasBeenSet {
+		i.slice = []int32{}
+		i.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(value, slPfx) {
+		// Deserializing assumes overwrite
+		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &i.slice)
+		i.hasBeenSet = true
+		return nil
+	}
+
+	elems, err := splitSliceElements(value, "int")
+	if err != nil {
+		return err
+	}
+	for _, s := range elems {
+		tmp, err := parseSizedInt(s, 32)
+		if err != nil {
+			return err
+		}
+
+		i.slice = append(i.slice, int32(tmp))
+	}
+
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (i *Int32Slice) String() string {
+	return fmt.Sprintf("%#v", i.slice)
+}
+
+// Serialize allows Int32Slice to fulfill Serializer
+func (i *Int32Slice) Serialize() string {
+	jsonBytes, _ := json.Marshal(i.slice)
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Value returns the slice of int32s set by this flag
+func (i *Int32Slice) Value() []int32 {
+	return i.slice
+}
+
+// Get returns the slice of int32s set by this flag
+func (i *Int32Slice) Get() interface{} {
+	return *i
+}
+
+// Int32SliceFlag is a flag with type *Int32Slice
+type Int32SliceFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       *Int32Slice
+	DefaultText string
+	// Validator, when set, is run with the value of the flag, a []int32, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []int32) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *Int32SliceFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *Int32SliceFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *Int32SliceFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *Int32SliceFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *Int32SliceFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *Int32SliceFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *Int32SliceFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *Int32SliceFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		f.Value = &Int32Slice{}
+
+		for _, s := range splitEnvValue(val, f.Separator) {
+			if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
+				return fmt.Errorf("could not parse %q as int32 slice value for flag %s: %s", val, f.Name, err)
+			}
+		}
+
+		// Set this to false so that we reset the slice if we then set values from
+		// flags that have already been set by the environment.
+		f.Value.hasBeenSet = false
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Value == nil {
+			f.Value = &Int32Slice{}
+		}
+		set.Var(f.Value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Int32SliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Int32Slice(f.Names()[0]))
+	}
+	return nil
+}
+
+// Int32Slice looks up the value of a local Int32SliceFlag, returns
+// nil if not found
+func (c *Context) Int32Slice(name string) []int32 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupInt32Slice(name, fs)
+	}
+	return nil
+}
+
+func lookupInt32Slice(name string, set *flag.FlagSet) []int32 {
+	f := set.Lookup(name)
+	if f != nil {
+		if slice, ok := f.Value.(*Int32Slice); ok {
+			return slice.Value()
+		}
+	}
+	return nil
+}
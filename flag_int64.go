@@ -11,6 +11,7 @@ type Int64Flag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -18,7 +19,38 @@ type Int64Flag struct {
 	Value       int64
 	DefaultText string
 	Destination *int64
-	HasBeenSet  bool
+	// Validator, when set, is run with the value of the flag, an int64, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, int64) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// Unit annotates the default in help, like "(default: 30 seconds)".
+	// With HumanizeDefault, defaults in "bytes", "seconds" or "milliseconds"
+	// are shown as a size or a duration instead, like 1MiB or 1h30m.
+	Unit string
+	// HumanizeDefault shows a default with a known Unit as a size or a
+	// duration
+	HumanizeDefault bool
+	// localeParsers parse the values from the environment or a file
+	localeParsers
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -60,17 +92,19 @@ func (f *Int64Flag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *Int64Flag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
-		if val != "" {
-			valInt, err := strconv.ParseInt(val, 0, 64)
-
-			if err != nil {
-				return fmt.Errorf("could not parse %q as int value for flag %s: %s", val, f.Name, err)
-			}
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valInt, err := strconv.ParseInt(parseNumber(f.numberParser, val), 0, 64)
 
-			f.Value = valInt
-			f.HasBeenSet = true
+		if err != nil {
+			return fmt.Errorf("could not parse %q as int value for flag %s: %s", val, f.Name, err)
 		}
+
+		f.Value = valInt
+		f.HasBeenSet = true
 	}
 
 	for _, name := range f.Names() {
@@ -83,6 +117,14 @@ func (f *Int64Flag) Apply(set *flag.FlagSet) error {
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Int64Flag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Int64(f.Names()[0]))
+	}
+	return nil
+}
+
 // Int64 looks up the value of a local Int64Flag, returns
 // 0 if not found
 func (c *Context) Int64(name string) int64 {
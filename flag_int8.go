@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// int8Value is the flag.Value for Int8Flag, rejecting values which do not fit
+// in 8 bits
+type int8Value int8
+
+func newInt8Value(val int8, p *int8) *int8Value {
+	*p = val
+	return (*int8Value)(p)
+}
+
+func (i *int8Value) Set(s string) error {
+	v, err := parseSizedInt(s, 8)
+	if err != nil {
+		return err
+	}
+	*i = int8Value(v)
+	return nil
+}
+
+func (i *int8Value) Get() interface{} { return int8(*i) }
+
+func (i *int8Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// Int8Flag is a flag with type int8
+type Int8Flag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       int8
+	DefaultText string
+	Destination *int8
+	// Validator, when set, is run with the value of the flag, an int8, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, int8) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// localeParsers parse the values from the environment or a file
+	localeParsers
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *Int8Flag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *Int8Flag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *Int8Flag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *Int8Flag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *Int8Flag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *Int8Flag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *Int8Flag) GetValue() string {
+	return fmt.Sprintf("%d", f.Value)
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *Int8Flag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valInt, err := parseSizedInt(parseNumber(f.numberParser, val), 8)
+		if err != nil {
+			return fmt.Errorf("could not parse %q as int8 value for flag %s: %s", val, f.Name, err)
+		}
+
+		f.Value = int8(valInt)
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newInt8Value(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newInt8Value(f.Value, new(int8)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Int8Flag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Int8(f.Names()[0]))
+	}
+	return nil
+}
+
+// Int8 looks up the value of a local Int8Flag, returns
+// 0 if not found
+func (c *Context) Int8(name string) int8 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupInt8(name, fs)
+	}
+	return 0
+}
+
+func lookupInt8(name string, set *flag.FlagSet) int8 {
+	f := set.Lookup(name)
+	if f != nil {
+		parsed, err := parseSizedInt(f.Value.String(), 8)
+		if err != nil {
+			return 0
+		}
+		return int8(parsed)
+	}
+	return 0
+}
@@ -12,6 +12,8 @@ import (
 type IntSlice struct {
 	slice      []int
 	hasBeenSet bool
+	// destination, when set, is kept holding a copy of slice
+	destination *[]int
 }
 
 // NewIntSlice makes an *IntSlice with default values
@@ -22,6 +24,8 @@ func NewIntSlice(defaults ...int) *IntSlice {
 // TODO: Consistently have specific Set function for Int64 and Float64 ?
 // SetInt directly adds an integer to the list of values
 func (i *IntSlice) SetInt(value int) {
+	defer i.syncDestination()
+
 	if !i.hasBeenSet {
 		i.slice = []int{}
 		i.hasBeenSet = true
@@ -30,8 +34,11 @@ func (i *IntSlice) SetInt(value int) {
 	i.slice = append(i.slice, value)
 }
 
-// Set parses the value into an integer and appends it to the list of values
+// Set parses the comma separated value into integers and appends them to the
+// list of values
 func (i *IntSlice) Set(value string) error {
+	defer i.syncDestination()
+
 	if !i.hasBeenSet {
 		i.slice = []int{}
 		i.hasBeenSet = true
@@ -44,16 +51,29 @@ func (i *IntSlice) Set(value string) error {
 		return nil
 	}
 
-	tmp, err := strconv.ParseInt(value, 0, 64)
+	elems, err := splitSliceElements(value, "int")
 	if err != nil {
 		return err
 	}
+	for _, s := range elems {
+		tmp, err := strconv.ParseInt(s, 0, 64)
+		if err != nil {
+			return err
+		}
 
-	i.slice = append(i.slice, int(tmp))
+		i.slice = append(i.slice, int(tmp))
+	}
 
 	return nil
 }
 
+// syncDestination copies the values to the destination of the flag, if any
+func (i *IntSlice) syncDestination() {
+	if i.destination != nil {
+		*i.destination = append([]int{}, i.slice...)
+	}
+}
+
 // String returns a readable representation of this value (for usage defaults)
 func (i *IntSlice) String() string {
 	return fmt.Sprintf("%#v", i.slice)
@@ -80,13 +100,43 @@ type IntSliceFlag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
 	Hidden      bool
 	Value       *IntSlice
 	DefaultText string
-	HasBeenSet  bool
+	// Validator, when set, is run with the value of the flag, a []int, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []int) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// Destination, when set, holds the values of the flag once the flags are
+	// parsed: the defaults of Value, replaced by the values given on the
+	// command line or by env or file.
+	Destination *[]int
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -131,28 +181,51 @@ func (f *IntSliceFlag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *IntSliceFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		f.Value = &IntSlice{}
 
-		for _, s := range strings.Split(val, ",") {
+		for _, s := range splitEnvValue(val, f.Separator) {
 			if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
 				return fmt.Errorf("could not parse %q as int slice value for flag %s: %s", val, f.Name, err)
 			}
 		}
 
+		// Set this to false so that we reset the slice if we then set values from
+		// flags that have already been set by the environment.
+		f.Value.hasBeenSet = false
 		f.HasBeenSet = true
 	}
 
+	if f.Value == nil {
+		f.Value = &IntSlice{}
+	}
+	setValue := f.Value
+	if f.Destination != nil {
+		setValue = &IntSlice{slice: append([]int{}, f.Value.slice...), destination: f.Destination}
+		setValue.syncDestination()
+	}
+
+	// all names share the same value, so that copying the value to the
+	// aliases leaves the destination as it is
 	for _, name := range f.Names() {
-		if f.Value == nil {
-			f.Value = &IntSlice{}
-		}
-		set.Var(f.Value, name, f.Usage)
+		set.Var(setValue, name, f.Usage)
 	}
 
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *IntSliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.IntSlice(f.Names()[0]))
+	}
+	return nil
+}
+
 // IntSlice looks up the value of a local IntSliceFlag, returns
 // nil if not found
 func (c *Context) IntSlice(name string) []int {
@@ -1,11 +1,127 @@
 package cli
 
-import "flag"
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// The directories a PathFlag may resolve its relative paths against, see
+// PathFlag.ResolveRelativeTo
+const (
+	// PathRelativeToInvocation resolves against the working directory the App
+	// was run from, even when a command has changed it since
+	PathRelativeToInvocation = "invocation"
+	// PathRelativeToCurrent resolves against the working directory at the time
+	// the flags of the command are parsed
+	PathRelativeToCurrent = "current"
+)
+
+// expandPath resolves a leading ~ to the home directory of the current user
+// and substitutes the $VAR and ${VAR} references to environment variables.
+// Unset variables are an error when strict, otherwise they are left verbatim.
+func expandPath(path string, strict bool) (string, error) {
+	if path == "" || path == "-" {
+		return path, nil
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(os.PathSeparator)) {
+		home, err := homeDir()
+		if err != nil {
+			return "", err
+		}
+		path = home + path[1:]
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] != '$' || i+1 == len(path) {
+			b.WriteByte(path[i])
+			continue
+		}
+
+		var name, ref string
+		if path[i+1] == '{' {
+			end := strings.IndexByte(path[i:], '}')
+			if end < 0 {
+				b.WriteByte(path[i])
+				continue
+			}
+			name, ref = path[i+2:i+end], path[i:i+end+1]
+		} else {
+			end := i + 1
+			for end < len(path) && isEnvNameByte(path[end]) {
+				end++
+			}
+			name, ref = path[i+1:end], path[i:end]
+		}
+		if name == "" {
+			b.WriteByte(path[i])
+			continue
+		}
+
+		if val, ok := os.LookupEnv(name); ok {
+			b.WriteString(val)
+		} else if strict {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		} else {
+			b.WriteString(ref)
+		}
+		i += len(ref) - 1
+	}
+	return b.String(), nil
+}
+
+func isEnvNameByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func homeDir() (string, error) {
+	if home := os.Getenv("HOME"); home != "" {
+		return home, nil
+	}
+	u, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("could not find the home directory: %s", err)
+	}
+	return u.HomeDir, nil
+}
+
+// pathValue is the flag.Value for a PathFlag which expands its value. It
+// keeps the path as typed for error messages.
+type pathValue struct {
+	destination *string
+	raw         string
+	strict      bool
+}
+
+func (p *pathValue) Set(s string) error {
+	path, err := expandPath(s, p.strict)
+	if err != nil {
+		return err
+	}
+	p.raw = s
+	*p.destination = path
+	return nil
+}
+
+func (p *pathValue) Get() interface{} { return *p.destination }
+
+func (p *pathValue) String() string {
+	if p.destination == nil {
+		return ""
+	}
+	return *p.destination
+}
 
 type PathFlag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -14,7 +130,38 @@ type PathFlag struct {
 	Value       string
 	DefaultText string
 	Destination *string
-	HasBeenSet  bool
+	// Expand resolves a leading ~ and the environment variables in the path
+	Expand bool
+	// ExpandStrict makes an unset environment variable an error when
+	// expanding instead of leaving it verbatim
+	ExpandStrict bool
+	// Validator, when set, is run with the value of the flag, a string, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, string) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// ResolveRelativeTo, when set, makes a relative path absolute once the
+	// flags are parsed, resolving it against PathRelativeToInvocation or
+	// PathRelativeToCurrent
+	ResolveRelativeTo string
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -56,11 +203,30 @@ func (f *PathFlag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *PathFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		f.Value = val
 		f.HasBeenSet = true
 	}
 
+	if f.Expand {
+		for _, name := range f.Names() {
+			destination := f.Destination
+			if destination == nil {
+				destination = new(string)
+			}
+			value := &pathValue{destination: destination, strict: f.ExpandStrict}
+			if err := value.Set(f.Value); err != nil {
+				return fmt.Errorf("could not expand %q as path value for flag %s: %s", f.Value, f.Name, err)
+			}
+			set.Var(value, name, f.Usage)
+		}
+		return nil
+	}
+
 	for _, name := range f.Names() {
 		if f.Destination != nil {
 			set.StringVar(f.Destination, name, f.Value, f.Usage)
@@ -72,6 +238,14 @@ func (f *PathFlag) Apply(set *flag.FlagSet) error {
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *PathFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Path(f.Names()[0]))
+	}
+	return nil
+}
+
 // Path looks up the value of a local PathFlag, returns
 // "" if not found
 func (c *Context) Path(name string) string {
@@ -82,6 +256,32 @@ func (c *Context) Path(name string) string {
 	return ""
 }
 
+// PathAbs looks up the value of a local PathFlag as an absolute path, resolving
+// a relative path against the working directory the App was run from, returns
+// "" if not found
+func (c *Context) PathAbs(name string) string {
+	path := c.Path(name)
+	if path == "" || path == "-" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(lookupInvocationDir(c), path)
+}
+
+// RawPath looks up the path of a local PathFlag as it was given, before its
+// expansion, returns "" if not found
+func (c *Context) RawPath(name string) string {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		if f := fs.Lookup(name); f != nil {
+			if p, ok := f.Value.(*pathValue); ok {
+				return p.raw
+			}
+			return f.Value.String()
+		}
+	}
+
+	return ""
+}
+
 func lookupPath(name string, set *flag.FlagSet) string {
 	f := set.Lookup(name)
 	if f != nil {
@@ -93,3 +293,59 @@ func lookupPath(name string, set *flag.FlagSet) string {
 	}
 	return ""
 }
+
+// lookupInvocationDir returns the working directory the App was run from, or
+// the current one for a context which was not created by running an App
+func lookupInvocationDir(c *Context) string {
+	for _, ctx := range c.Lineage() {
+		if ctx.invocationDir != "" {
+			return ctx.invocationDir
+		}
+	}
+	wd, _ := os.Getwd()
+	return wd
+}
+
+// resolvePathFlags makes the relative paths of the PathFlags with a
+// ResolveRelativeTo absolute. The path as given stays available to RawPath.
+func resolvePathFlags(flags []Flag, cCtx *Context) error {
+	for _, fl := range flags {
+		f, ok := fl.(*PathFlag)
+		if !ok || f.ResolveRelativeTo == "" {
+			continue
+		}
+
+		var dir string
+		switch f.ResolveRelativeTo {
+		case PathRelativeToInvocation:
+			dir = lookupInvocationDir(cCtx)
+		case PathRelativeToCurrent:
+			wd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("could not resolve the path of flag %s: %s", f.Name, err)
+			}
+			dir = wd
+		default:
+			return fmt.Errorf("flag %s resolves its path relative to %q, expected %q or %q", f.Name, f.ResolveRelativeTo, PathRelativeToInvocation, PathRelativeToCurrent)
+		}
+
+		for _, name := range f.Names() {
+			ff := cCtx.flagSet.Lookup(name)
+			if ff == nil {
+				continue
+			}
+			path := ff.Value.String()
+			if path == "" || path == "-" || filepath.IsAbs(path) {
+				continue
+			}
+
+			path = filepath.Join(dir, path)
+			if p, ok := ff.Value.(*pathValue); ok {
+				*p.destination = path
+				continue
+			}
+			_ = ff.Value.Set(path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PathSlice wraps a []string of paths to satisfy flag.Value. When globbing,
+// every value set is expanded by filepath.Glob.
+type PathSlice struct {
+	slice      []string
+	hasBeenSet bool
+	glob       bool
+	// errorOnNoMatch makes a pattern matching no path an error
+	errorOnNoMatch bool
+}
+
+// NewPathSlice makes a *PathSlice with default values, which are not globbed
+func NewPathSlice(defaults ...string) *PathSlice {
+	return &PathSlice{slice: append([]string{}, defaults...)}
+}
+
+// Set appends the path to the list of values, or the paths it matches when
+// globbing. A pattern matching no path adds none.
+func (p *PathSlice) Set(value string) error {
+	if !p.hasBeenSet {
+		p.slice = []string{}
+		p.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(value, slPfx) {
+		// Deserializing assumes overwrite
+		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &p.slice)
+		p.hasBeenSet = true
+		return nil
+	}
+
+	// a path without any pattern is kept as given, whether it exists or not
+	if !p.glob || !strings.ContainsAny(value, "*?[") {
+		p.slice = append(p.slice, value)
+		return nil
+	}
+
+	matches, err := filepath.Glob(value)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %s", value, err)
+	}
+	if len(matches) == 0 && p.errorOnNoMatch {
+		return fmt.Errorf("no path matches %q", value)
+	}
+	p.slice = append(p.slice, matches...)
+
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (p *PathSlice) String() string {
+	return fmt.Sprintf("%s", p.slice)
+}
+
+// Serialize allows PathSlice to fulfill Serializer
+func (p *PathSlice) Serialize() string {
+	jsonBytes, _ := json.Marshal(p.slice)
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Value returns the slice of paths set by this flag
+func (p *PathSlice) Value() []string {
+	return p.slice
+}
+
+// Get returns the slice of paths set by this flag
+func (p *PathSlice) Get() interface{} {
+	return *p
+}
+
+// PathSliceFlag is a flag with type *PathSlice
+type PathSliceFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	TakesFile   bool
+	Value       *PathSlice
+	DefaultText string
+	// Validator, when set, is run with the value of the flag, a []string, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// Glob expands every value given by filepath.Glob. The paths matched are
+	// relative or absolute like the pattern.
+	Glob bool
+	// GlobErrorOnNoMatch makes a pattern matching no path an error instead of
+	// adding no path
+	GlobErrorOnNoMatch bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []string) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *PathSliceFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *PathSliceFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *PathSliceFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *PathSliceFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *PathSliceFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *PathSliceFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *PathSliceFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *PathSliceFlag) Apply(set *flag.FlagSet) error {
+	if f.Value == nil {
+		f.Value = &PathSlice{}
+	}
+	f.Value.glob = f.Glob
+	f.Value.errorOnNoMatch = f.GlobErrorOnNoMatch
+
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		f.Value = &PathSlice{glob: f.Glob, errorOnNoMatch: f.GlobErrorOnNoMatch}
+
+		for _, s := range splitEnvValue(val, f.Separator) {
+			if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
+				return fmt.Errorf("could not parse %q as path slice value for flag %s: %s", val, f.Name, err)
+			}
+		}
+
+		// Set this to false so that we reset the slice if we then set values from
+		// flags that have already been set by the environment.
+		f.Value.hasBeenSet = false
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		set.Var(f.Value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *PathSliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.PathSlice(f.Names()[0]))
+	}
+	return nil
+}
+
+// PathSlice looks up the value of a local PathSliceFlag, returns nil if not
+// found. Patterns given when globbing are replaced by the paths they match.
+func (c *Context) PathSlice(name string) []string {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupPathSlice(name, fs)
+	}
+	return nil
+}
+
+func lookupPathSlice(name string, set *flag.FlagSet) []string {
+	f := set.Lookup(name)
+	if f != nil {
+		if slice, ok := f.Value.(*PathSlice); ok {
+			return slice.Value()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FlagReadRecorder records the names of the flags read through the accessors
+// of a Context, like Bool, String or IsSet, and the positional arguments the
+// actions read through Context.Args. Attach it to App.FlagReadRecorder in
+// tests, then use AssertAllFlagsRead to find the flags that are never read and
+// AssertArgsAccessMatchesDeclaration to compare the arguments read with the
+// ArgsUsage of the commands.
+type FlagReadRecorder struct {
+	mu   sync.Mutex
+	read map[string]bool
+	// args holds the arguments read by the actions which ran, by the path of
+	// their command
+	args map[string]*argsAccess
+}
+
+// argsAccess is the positional arguments read by the action of a command
+type argsAccess struct {
+	read map[int]bool
+	// all is set when all of the arguments are read at once, like by Slice
+	all bool
+	// fewest is the fewest arguments given to a run whose action counted
+	// them, like by Len or Present, or -1 when they were never counted
+	fewest int
+}
+
+// NewFlagReadRecorder makes an empty *FlagReadRecorder
+func NewFlagReadRecorder() *FlagReadRecorder {
+	return &FlagReadRecorder{read: map[string]bool{}, args: map[string]*argsAccess{}}
+}
+
+// Read returns whether a flag with one of names has been read
+func (r *FlagReadRecorder) Read(names ...string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range names {
+		if r.read[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *FlagReadRecorder) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.read[name] = true
+}
+
+// recordAction records that the action of the command at path ran
+func (r *FlagReadRecorder) recordAction(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.args[path] == nil {
+		r.args[path] = &argsAccess{read: map[int]bool{}, fewest: -1}
+	}
+}
+
+// recordArgsRead records that the action of the command at path read the
+// argument n, or all of them when n is negative
+func (r *FlagReadRecorder) recordArgsRead(path string, n int) {
+	r.recordAction(path)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n < 0 {
+		r.args[path].all = true
+	} else {
+		r.args[path].read[n] = true
+	}
+}
+
+// recordArgsCounted records that the action of the command at path counted
+// its n arguments
+func (r *FlagReadRecorder) recordArgsCounted(path string, n int) {
+	r.recordAction(path)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if access := r.args[path]; access.fewest < 0 || n < access.fewest {
+		access.fewest = n
+	}
+}
+
+// argsRead returns the arguments read by the action of the command at path,
+// or nil if it never ran
+func (r *FlagReadRecorder) argsRead(path string) *argsAccess {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.args[path]
+}
+
+// flagReadRecorder returns the FlagReadRecorder of the App of the context, if
+// any
+func (c *Context) flagReadRecorder() *FlagReadRecorder {
+	for _, ctx := range c.Lineage() {
+		if ctx.App != nil {
+			return ctx.App.FlagReadRecorder
+		}
+	}
+	return nil
+}
+
+// recordFlagRead records name in the FlagReadRecorder of the App of the
+// context, if any
+func (c *Context) recordFlagRead(name string) {
+	if r := c.flagReadRecorder(); r != nil {
+		r.record(name)
+	}
+}
+
+// startAction makes the arguments read through Args from now on be recorded
+// for the command at path, if the App has a FlagReadRecorder. The built-in
+// help actions are not recorded.
+func (c *Context) startAction(path string, action ActionFunc) {
+	r := c.flagReadRecorder()
+	if r == nil || isHelpAction(action) {
+		return
+	}
+	c.argsPath = path
+	r.recordAction(path)
+}
+
+// recordedArgs records the arguments read in the FlagReadRecorder
+type recordedArgs struct {
+	*args
+	recorder *FlagReadRecorder
+	path     string
+}
+
+func (a *recordedArgs) Get(n int) string {
+	a.recorder.recordArgsRead(a.path, n)
+	return a.args.Get(n)
+}
+
+func (a *recordedArgs) First() string {
+	return a.Get(0)
+}
+
+func (a *recordedArgs) Tail() []string {
+	a.recorder.recordArgsRead(a.path, -1)
+	return a.args.Tail()
+}
+
+func (a *recordedArgs) Slice() []string {
+	a.recorder.recordArgsRead(a.path, -1)
+	return a.args.Slice()
+}
+
+func (a *recordedArgs) Len() int {
+	n := a.args.Len()
+	a.recorder.recordArgsCounted(a.path, n)
+	return n
+}
+
+func (a *recordedArgs) Present() bool {
+	return a.Len() != 0
+}
+
+func (a *recordedArgs) SplitFirst(sep string) []string {
+	a.recorder.recordArgsRead(a.path, 0)
+	return a.args.SplitFirst(sep)
+}
+
+// TestReporter is the part of testing.TB used by AssertAllFlagsRead
+type TestReporter interface {
+	Errorf(format string, args ...interface{})
+}
+
+// AssertAllFlagsRead fails t with the list of the flags of app and of its
+// commands that recorder has never seen read, unless one of their names is
+// in exceptions. The built-in help, version and color flags are ignored.
+// Flags are identified by name, so a flag is read when a flag of the same
+// name is read on any command.
+func AssertAllFlagsRead(t TestReporter, app *App, recorder *FlagReadRecorder, exceptions ...string) {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	flags := append(append([]Flag{}, app.Flags...), app.PersistentFlags...)
+	unread := unreadFlags(app.Name, flags, app.Commands, recorder, exceptions, map[Flag]bool{})
+	if len(unread) > 0 {
+		t.Errorf("flags never read: %s", strings.Join(unread, ", "))
+	}
+}
+
+// unreadFlags lists the unread flags of the command at path and of its
+// descendants. A flag shared by several commands, like a persistent flag, is
+// only listed once as seen records the flags already checked.
+func unreadFlags(path string, flags []Flag, commands []*Command, recorder *FlagReadRecorder, exceptions []string, seen map[Flag]bool, ancestors ...*Command) []string {
+	var unread []string
+	for _, f := range flags {
+		if seen[f] || isBuiltinFlag(f) {
+			continue
+		}
+		seen[f] = true
+
+		names := f.Names()
+		excepted := false
+		for _, name := range names {
+			excepted = excepted || hasName(exceptions, name)
+		}
+		if !excepted && !recorder.Read(names...) {
+			unread = append(unread, fmt.Sprintf("%s (%s)", names[0], path))
+		}
+	}
+	sort.Strings(unread)
+
+	for _, c := range commands {
+		if c == helpCommand || c == completionCommand || c == batchCommand || c == profilesCommand || hasCommand(ancestors, c) {
+			continue
+		}
+		flags := append(append([]Flag{}, c.Flags...), c.PersistentFlags...)
+		unread = append(unread, unreadFlags(path+" "+c.Name, flags, c.Subcommands, recorder, exceptions, seen, append(ancestors, c)...)...)
+	}
+	return unread
+}
+
+func isBuiltinFlag(f Flag) bool {
+	return f == HelpFlag || f == HelpAllFlag || f == VersionFlag || f == ColorFlag || f == NoPagerFlag
+}
+
+// AssertArgsAccessMatchesDeclaration fails t when the actions of app and of its
+// commands which ran read the positional arguments differently from their
+// ArgsUsage: when an action reads an argument past the ones declared, or never
+// reads a required one. In ArgsUsage, the arguments in brackets are optional
+// and one followed by "..." may be repeated, like "<src> [dst...]". The
+// commands without an ArgsUsage, or whose action never ran, are not checked,
+// nor are the arguments read all at once, like by Slice or Tail. A required
+// argument is not reported either when the action counted the arguments, like
+// by Len, Present or Context.NArg, in a run given too few of them to read it,
+// as the action may have rejected the run.
+func AssertArgsAccessMatchesDeclaration(t TestReporter, app *App, recorder *FlagReadRecorder) {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	mismatches := argsMismatches(app.Name, app.ArgsUsage, app.Commands, recorder)
+	if len(mismatches) > 0 {
+		t.Errorf("arguments not read as declared: %s", strings.Join(mismatches, ", "))
+	}
+}
+
+// argsMismatches lists the mismatches of the command at path and of its
+// descendants
+func argsMismatches(path, argsUsage string, commands []*Command, recorder *FlagReadRecorder, ancestors ...*Command) []string {
+	var mismatches []string
+	if access := recorder.argsRead(path); access != nil && argsUsage != "" {
+		required, max := declaredArgs(argsUsage)
+
+		var read []int
+		for n := range access.read {
+			read = append(read, n)
+		}
+		sort.Ints(read)
+		for _, n := range read {
+			if max >= 0 && n >= max {
+				mismatches = append(mismatches, fmt.Sprintf("argument %d read past %q (%s)", n, argsUsage, path))
+			}
+		}
+
+		for n := 0; n < required && !access.all; n++ {
+			if !access.read[n] && (access.fewest < 0 || n < access.fewest) {
+				mismatches = append(mismatches, fmt.Sprintf("required argument %d of %q never read (%s)", n, argsUsage, path))
+			}
+		}
+	}
+
+	for _, c := range commands {
+		if c == helpCommand || c == completionCommand || c == batchCommand || c == profilesCommand || hasCommand(ancestors, c) {
+			continue
+		}
+		mismatches = append(mismatches, argsMismatches(path+" "+c.Name, c.ArgsUsage, c.Subcommands, recorder, append(ancestors, c)...)...)
+	}
+	return mismatches
+}
+
+// declaredArgs returns the number of required arguments declared by
+// argsUsage, and the number of arguments it declares in all, or -1 when one
+// of them may be repeated
+func declaredArgs(argsUsage string) (required, max int) {
+	depth := 0
+	for _, word := range strings.Fields(argsUsage) {
+		optional := depth > 0 || strings.HasPrefix(word, "[")
+		depth += strings.Count(word, "[") - strings.Count(word, "]")
+
+		if strings.Trim(word, "[].") == "" {
+			// a lone "..." repeats the argument before it
+			if strings.Contains(word, "...") {
+				max = -1
+			}
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(word, "["), "-") {
+			// flags shown in the usage are no arguments
+			continue
+		}
+		if !optional {
+			required++
+		}
+		if max >= 0 {
+			max++
+		}
+		if strings.Contains(word, "...") {
+			max = -1
+		}
+	}
+	return required, max
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type fakeReporter struct {
+	errors []string
+}
+
+func (r *fakeReporter) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func flagRecorderTestApp(recorder *FlagReadRecorder) *App {
+	return &App{
+		Name:             "app",
+		FlagReadRecorder: recorder,
+		PersistentFlags:  []Flag{&BoolFlag{Name: "verbose"}},
+		Flags: []Flag{
+			&StringFlag{Name: "name", Aliases: []string{"n"}},
+			&IntFlag{Name: "legacy"},
+		},
+		Action: func(ctx *Context) error {
+			_ = ctx.String("n")
+			return nil
+		},
+		Commands: []*Command{{
+			Name: "remote",
+			Subcommands: []*Command{{
+				Name: "add",
+				Flags: []Flag{
+					&StringFlag{Name: "url"},
+					&BoolFlag{Name: "dead"},
+				},
+				Action: func(ctx *Context) error {
+					_ = ctx.IsSet("url")
+					return nil
+				},
+			}},
+		}},
+	}
+}
+
+func TestAssertAllFlagsRead(t *testing.T) {
+	recorder := NewFlagReadRecorder()
+	app := flagRecorderTestApp(recorder)
+	expect(t, app.Run([]string{"app", "--name", "a"}), nil)
+	expect(t, app.Run([]string{"app", "remote", "add", "--url", "u"}), nil)
+
+	expect(t, recorder.Read("name"), false)
+	expect(t, recorder.Read("name", "n"), true)
+
+	reporter := &fakeReporter{}
+	AssertAllFlagsRead(reporter, app, recorder)
+	expect(t, reporter.errors, []string{
+		"flags never read: legacy (app), verbose (app), dead (app remote add)",
+	})
+}
+
+func TestAssertAllFlagsRead_Exceptions(t *testing.T) {
+	recorder := NewFlagReadRecorder()
+	app := flagRecorderTestApp(recorder)
+	expect(t, app.Run([]string{"app", "remote", "add"}), nil)
+	expect(t, app.Run([]string{"app"}), nil)
+
+	reporter := &fakeReporter{}
+	AssertAllFlagsRead(reporter, app, recorder, "legacy", "verbose", "dead")
+	expect(t, len(reporter.errors), 0)
+}
+
+func TestFlagReadRecorder_InternalChecks(t *testing.T) {
+	recorder := NewFlagReadRecorder()
+	app := &App{
+		FlagReadRecorder: recorder,
+		Flags: []Flag{
+			&StringFlag{Name: "token", Required: true},
+			&IntFlag{Name: "port", Validator: func(interface{}) error { return nil }},
+		},
+		Action: func(*Context) error { return nil },
+	}
+	expect(t, app.Run([]string{"app", "--token", "t", "--port", "80"}), nil)
+	expect(t, recorder.Read("token"), false)
+	expect(t, recorder.Read("port"), false)
+}
+
+func argsRecorderTestApp(recorder *FlagReadRecorder) *App {
+	return &App{
+		Name:             "app",
+		Writer:           ioutil.Discard,
+		FlagReadRecorder: recorder,
+		ArgsUsage:        "<name>",
+		Action: func(ctx *Context) error {
+			_ = ctx.Args().First()
+			return nil
+		},
+		Commands: []*Command{
+			{
+				Name:      "copy",
+				ArgsUsage: "<src> <dst>",
+				Action: func(ctx *Context) error {
+					// the destination is never read, but a third argument is
+					_ = ctx.Args().Get(0)
+					_ = ctx.Args().Get(2)
+					return nil
+				},
+			},
+			{
+				Name:      "cat",
+				ArgsUsage: "<file> [file...]",
+				Action: func(ctx *Context) error {
+					for i := 0; i < ctx.Args().Len(); i++ {
+						_ = ctx.Args().Get(i)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "remote",
+				ArgsUsage: "[name]",
+				Subcommands: []*Command{{
+					Name:      "add",
+					ArgsUsage: "<name> <url>",
+					Action: func(ctx *Context) error {
+						_ = ctx.Args().Slice()
+						return nil
+					},
+				}},
+			},
+			{
+				Name:      "move",
+				ArgsUsage: "<src> <dst>",
+				Action:    func(*Context) error { return nil },
+			},
+			{
+				Name:      "link",
+				ArgsUsage: "<src> <dst>",
+				Action: func(ctx *Context) error {
+					_ = ctx.Args().Get(0)
+					if ctx.NArg() < 2 {
+						return errors.New("link needs a destination")
+					}
+					_ = ctx.Args().Get(1)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func TestAssertArgsAccessMatchesDeclaration(t *testing.T) {
+	recorder := NewFlagReadRecorder()
+	app := argsRecorderTestApp(recorder)
+	expect(t, app.Run([]string{"app", "x"}), nil)
+	expect(t, app.Run([]string{"app", "copy", "a", "b"}), nil)
+	expect(t, app.Run([]string{"app", "cat", "a", "b", "c"}), nil)
+	expect(t, app.Run([]string{"app", "remote", "add", "origin", "url"}), nil)
+	// help reads the arguments, but is not checked
+	expect(t, app.Run([]string{"app", "help", "move"}), nil)
+	// the action counted the arguments and rejected the run, so its second
+	// required argument is not reported
+	expect(t, app.Run([]string{"app", "link", "a"}).Error(), "link needs a destination")
+
+	reporter := &fakeReporter{}
+	AssertArgsAccessMatchesDeclaration(reporter, app, recorder)
+	expect(t, reporter.errors, []string{
+		`arguments not read as declared: argument 2 read past "<src> <dst>" (app copy), required argument 1 of "<src> <dst>" never read (app copy)`,
+	})
+
+	// a command whose action ran without reading its required arguments
+	expect(t, app.Run([]string{"app", "move", "a", "b"}), nil)
+	reporter = &fakeReporter{}
+	AssertArgsAccessMatchesDeclaration(reporter, app, recorder)
+	expect(t, len(reporter.errors), 1)
+	if !strings.HasSuffix(reporter.errors[0], `required argument 0 of "<src> <dst>" never read (app move), required argument 1 of "<src> <dst>" never read (app move)`) {
+		t.Errorf("expected the required arguments of move to be reported, got %q", reporter.errors[0])
+	}
+}
+
+func TestDeclaredArgs(t *testing.T) {
+	tests := []struct {
+		argsUsage string
+		required  int
+		max       int
+	}{
+		{"<src> <dst>", 2, 2},
+		{"FILE [DEST]", 1, 2},
+		{"[arguments...]", 0, -1},
+		{"<file> [file...]", 1, -1},
+		{"<file> ...", 1, -1},
+		{"[--force] <name>", 1, 1},
+		{"[src dst]", 0, 2},
+	}
+
+	for _, test := range tests {
+		required, max := declaredArgs(test.argsUsage)
+		expect(t, []int{required, max}, []int{test.required, test.max})
+	}
+}
@@ -1,12 +1,17 @@
 package cli
 
-import "flag"
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
 
 // StringFlag is a flag with type string
 type StringFlag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -15,7 +20,75 @@ type StringFlag struct {
 	Value       string
 	DefaultText string
 	Destination *string
-	HasBeenSet  bool
+	// Choices restricts the values accepted by the flag, which are listed
+	// in help and offered by shell completion
+	Choices []string
+	// CaseInsensitive accepts the Choices in any case, normalized to the
+	// declared casing
+	CaseInsensitive bool
+	// Validator, when set, is run with the value of the flag, a string, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, string) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// choiceValue is the flag.Value of a StringFlag with Choices
+type choiceValue struct {
+	destination     *string
+	choices         []string
+	caseInsensitive bool
+}
+
+func newChoiceValue(val string, p *string, choices []string, caseInsensitive bool) *choiceValue {
+	*p = val
+	return &choiceValue{destination: p, choices: choices, caseInsensitive: caseInsensitive}
+}
+
+// choose returns the declared choice matching s
+func (c *choiceValue) choose(s string) (string, error) {
+	for _, choice := range c.choices {
+		if choice == s || (c.caseInsensitive && strings.EqualFold(choice, s)) {
+			return choice, nil
+		}
+	}
+	return "", fmt.Errorf("invalid value %q, expected one of %s", s, strings.Join(c.choices, ", "))
+}
+
+func (c *choiceValue) Set(s string) error {
+	choice, err := c.choose(s)
+	if err != nil {
+		return err
+	}
+	*c.destination = choice
+	return nil
+}
+
+func (c *choiceValue) Get() interface{} { return *c.destination }
+
+func (c *choiceValue) String() string {
+	if c.destination == nil {
+		return ""
+	}
+	return *c.destination
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -57,11 +130,36 @@ func (f *StringFlag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *StringFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if len(f.Choices) > 0 {
+			choice, err := (&choiceValue{choices: f.Choices, caseInsensitive: f.CaseInsensitive}).choose(val)
+			if err != nil {
+				return fmt.Errorf("could not parse %q as choice value for flag %s: %s", val, f.Name, err)
+			}
+			val = choice
+		}
+
 		f.Value = val
 		f.HasBeenSet = true
 	}
 
+	if len(f.Choices) > 0 {
+		destination := f.Destination
+		if destination == nil {
+			destination = new(string)
+		}
+
+		value := newChoiceValue(f.Value, destination, f.Choices, f.CaseInsensitive)
+		for _, name := range f.Names() {
+			set.Var(value, name, f.Usage)
+		}
+		return nil
+	}
+
 	for _, name := range f.Names() {
 		if f.Destination != nil {
 			set.StringVar(f.Destination, name, f.Value, f.Usage)
@@ -73,6 +171,14 @@ func (f *StringFlag) Apply(set *flag.FlagSet) error {
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *StringFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.String(f.Names()[0]))
+	}
+	return nil
+}
+
 // String looks up the value of a local StringFlag, returns
 // "" if not found
 func (c *Context) String(name string) string {
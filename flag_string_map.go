@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// StringMap wraps a map[string]string to satisfy flag.Value
+type StringMap struct {
+	m                  map[string]string
+	hasBeenSet         bool
+	disallowDuplicates bool
+}
+
+// NewStringMap creates a *StringMap with default values
+func NewStringMap(defaults map[string]string) *StringMap {
+	m := make(map[string]string, len(defaults))
+	for k, v := range defaults {
+		m[k] = v
+	}
+	return &StringMap{m: m}
+}
+
+// Set parses a key=value pair and adds it to the map. Only the first "=" is
+// used as a separator, so values may themselves contain "=".
+func (s *StringMap) Set(value string) error {
+	if !s.hasBeenSet {
+		s.m = map[string]string{}
+		s.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(value, slPfx) {
+		// Deserializing assumes overwrite
+		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &s.m)
+		s.hasBeenSet = true
+		return nil
+	}
+
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("%q is not a key=value pair", value)
+	}
+
+	if _, ok := s.m[parts[0]]; ok && s.disallowDuplicates {
+		return fmt.Errorf("duplicate key %q", parts[0])
+	}
+
+	s.m[parts[0]] = parts[1]
+
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (s *StringMap) String() string {
+	return fmt.Sprintf("%v", s.m)
+}
+
+// Serialize allows StringMap to fulfill Serializer
+func (s *StringMap) Serialize() string {
+	jsonBytes, _ := json.Marshal(s.m)
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Value returns the map of strings set by this flag
+func (s *StringMap) Value() map[string]string {
+	return s.m
+}
+
+// Get returns the map of strings set by this flag
+func (s *StringMap) Get() interface{} {
+	return *s
+}
+
+// StringMapFlag is a flag with type *StringMap
+type StringMapFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       *StringMap
+	DefaultText string
+	// Validator, when set, is run with the value of the flag, a map[string]string,
+	// once the flags are parsed and only if the flag has been set. Its error
+	// rejects the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// DisallowDuplicates makes setting the same key twice an error instead
+	// of keeping the last value
+	DisallowDuplicates bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, map[string]string) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *StringMapFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *StringMapFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *StringMapFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *StringMapFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *StringMapFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *StringMapFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *StringMapFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *StringMapFlag) Apply(set *flag.FlagSet) error {
+	if f.Value == nil {
+		f.Value = &StringMap{m: map[string]string{}}
+	}
+	f.Value.disallowDuplicates = f.DisallowDuplicates
+
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		f.Value = &StringMap{disallowDuplicates: f.DisallowDuplicates}
+
+		for _, s := range splitEnvValue(val, f.Separator) {
+			if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
+				return fmt.Errorf("could not parse %q as string map value for flag %s: %s", val, f.Name, err)
+			}
+		}
+
+		// Set this to false so that we reset the map if we then set values from
+		// flags that have already been set by the environment.
+		f.Value.hasBeenSet = false
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		set.Var(f.Value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *StringMapFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.StringMap(f.Names()[0]))
+	}
+	return nil
+}
+
+// StringMap looks up the value of a local StringMapFlag, returns
+// nil if not found
+func (c *Context) StringMap(name string) map[string]string {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupStringMap(name, fs)
+	}
+	return nil
+}
+
+func lookupStringMap(name string, set *flag.FlagSet) map[string]string {
+	f := set.Lookup(name)
+	if f != nil {
+		if m, ok := f.Value.(*StringMap); ok {
+			return m.Value()
+		}
+	}
+	return nil
+}
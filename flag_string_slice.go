@@ -5,12 +5,20 @@ import (
 	"flag"
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 // StringSlice wraps a []string to satisfy flag.Value
 type StringSlice struct {
 	slice      []string
 	hasBeenSet bool
+	// raw holds the values as they were given, before they were split
+	raw []string
+	// noSplit keeps the values given whole instead of splitting them on
+	// commas
+	noSplit bool
+	// destination, when set, is kept holding a copy of slice
+	destination *[]string
 }
 
 // NewStringSlice creates a *StringSlice with default values
@@ -18,10 +26,15 @@ func NewStringSlice(defaults ...string) *StringSlice {
 	return &StringSlice{slice: append([]string{}, defaults...)}
 }
 
-// Set appends the string value to the list of values
+// Set appends the string value to the list of values. A value with commas is
+// split into several, unless they are escaped as "\,", see
+// StringSliceFlag.NoSplit.
 func (s *StringSlice) Set(value string) error {
+	defer s.syncDestination()
+
 	if !s.hasBeenSet {
 		s.slice = []string{}
+		s.raw = nil
 		s.hasBeenSet = true
 	}
 
@@ -32,11 +45,23 @@ func (s *StringSlice) Set(value string) error {
 		return nil
 	}
 
-	s.slice = append(s.slice, value)
+	s.raw = append(s.raw, value)
+	if s.noSplit {
+		s.slice = append(s.slice, value)
+		return nil
+	}
+	s.slice = append(s.slice, splitSliceValue(value)...)
 
 	return nil
 }
 
+// syncDestination copies the values to the destination of the flag, if any
+func (s *StringSlice) syncDestination() {
+	if s.destination != nil {
+		*s.destination = append([]string{}, s.slice...)
+	}
+}
+
 // String returns a readable representation of this value (for usage defaults)
 func (s *StringSlice) String() string {
 	return fmt.Sprintf("%s", s.slice)
@@ -53,6 +78,15 @@ func (s *StringSlice) Value() []string {
 	return s.slice
 }
 
+// Raw returns the values as they were given. It differs from Value when they
+// were split on commas.
+func (s *StringSlice) Raw() []string {
+	if s.raw != nil {
+		return s.raw
+	}
+	return s.slice
+}
+
 // Get returns the slice of strings set by this flag
 func (s *StringSlice) Get() interface{} {
 	return *s
@@ -63,6 +97,7 @@ type StringSliceFlag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -70,8 +105,40 @@ type StringSliceFlag struct {
 	TakesFile   bool
 	Value       *StringSlice
 	DefaultText string
+	// Validator, when set, is run with the value of the flag, a []string, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator   func(interface{}) error
 	HasBeenSet  bool
 	Destination *StringSlice
+	// NoSplit keeps the values whole instead of splitting them on commas,
+	// both on the command line and from env and file values
+	NoSplit bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []string) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// DestinationSlice, when set, holds the values of the flag once the flags
+	// are parsed, like the Destination of IntSliceFlag. Destination keeps its
+	// *StringSlice type for compatibility.
+	DestinationSlice *[]string
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -116,43 +183,88 @@ func (f *StringSliceFlag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *StringSliceFlag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		f.Value = &StringSlice{}
 		destination := f.Value
 		if f.Destination != nil {
 			destination = f.Destination
 		}
 
-		for _, s := range strings.Split(val, ",") {
+		values := []string{val}
+		if !f.NoSplit {
+			values = splitEnvValue(val, f.Separator)
+		}
+
+		// the values are split on the Separator alone
+		destination.noSplit = true
+		for _, s := range values {
 			if err := destination.Set(strings.TrimSpace(s)); err != nil {
 				return fmt.Errorf("could not parse %q as string value for flag %s: %s", val, f.Name, err)
 			}
 		}
+		if !f.NoSplit {
+			destination.raw = []string{val}
+		}
 
 		// Set this to false so that we reset the slice if we then set values from
 		// flags that have already been set by the environment.
 		destination.hasBeenSet = false
 		f.HasBeenSet = true
+	} else if f.Destination != nil && f.Value != nil {
+		// the destination starts from the defaults, which the values given on
+		// the command line replace
+		f.Destination.slice = append([]string{}, f.Value.slice...)
+		f.Destination.raw = append([]string{}, f.Value.raw...)
+		f.Destination.hasBeenSet = false
 	}
 
-	for _, name := range f.Names() {
-		if f.Value == nil {
-			f.Value = &StringSlice{}
-		}
+	if f.Value == nil {
+		f.Value = &StringSlice{}
+	}
+	f.Value.noSplit = f.NoSplit
+	if f.Destination != nil {
+		f.Destination.noSplit = f.NoSplit
+	}
 
-		if f.Destination != nil {
-			set.Var(f.Destination, name, f.Usage)
-			continue
+	setValue := f.Value
+	if f.Destination != nil {
+		setValue = f.Destination
+	}
+	if f.DestinationSlice != nil {
+		if f.Destination == nil {
+			// a copy, so that the values given leave the defaults as they are
+			setValue = &StringSlice{
+				slice:   append([]string{}, f.Value.slice...),
+				raw:     append([]string(nil), f.Value.raw...),
+				noSplit: f.NoSplit,
+			}
 		}
+		setValue.destination = f.DestinationSlice
+		setValue.syncDestination()
+	}
 
-		set.Var(f.Value, name, f.Usage)
+	for _, name := range f.Names() {
+		set.Var(setValue, name, f.Usage)
 	}
 
 	return nil
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *StringSliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.StringSlice(f.Names()[0]))
+	}
+	return nil
+}
+
 // StringSlice looks up the value of a local StringSliceFlag, returns
-// nil if not found
+// nil if not found. Values are returned in the order they were given, split on
+// commas unless the flag has NoSplit set.
 func (c *Context) StringSlice(name string) []string {
 	if fs := lookupFlagSet(name, c); fs != nil {
 		return lookupStringSlice(name, fs)
@@ -169,3 +281,51 @@ func lookupStringSlice(name string, set *flag.FlagSet) []string {
 	}
 	return nil
 }
+
+// StringSliceFlatten looks up the value of a local StringSliceFlag and further
+// splits each of its values on any of seps, or on commas and whitespace when
+// no seps are given. Empty values are dropped. The value of the flag itself is
+// left as is.
+func (c *Context) StringSliceFlatten(name string, seps ...rune) []string {
+	values := c.StringSlice(name)
+	if values == nil {
+		return nil
+	}
+
+	isSep := func(r rune) bool {
+		if len(seps) == 0 {
+			return r == ',' || unicode.IsSpace(r)
+		}
+		for _, sep := range seps {
+			if r == sep {
+				return true
+			}
+		}
+		return false
+	}
+
+	flattened := []string{}
+	for _, value := range values {
+		flattened = append(flattened, strings.FieldsFunc(value, isSep)...)
+	}
+	return flattened
+}
+
+// StringSliceRaw looks up the value of a local StringSliceFlag without
+// splitting its values on commas, returns nil if not found
+func (c *Context) StringSliceRaw(name string) []string {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupStringSliceRaw(name, fs)
+	}
+	return nil
+}
+
+func lookupStringSliceRaw(name string, set *flag.FlagSet) []string {
+	f := set.Lookup(name)
+	if f != nil {
+		if slice, ok := f.Value.(*StringSlice); ok {
+			return slice.Raw()
+		}
+	}
+	return nil
+}
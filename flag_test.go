@@ -1,14 +1,18 @@
 package cli
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -44,22 +48,109 @@ func TestBoolFlagApply_SetsAllNames(t *testing.T) {
 	expect(t, v, true)
 }
 
+func TestBoolFlagNegatableHelpOutput(t *testing.T) {
+	fl := &BoolFlag{Name: "cache", Aliases: []string{"c"}, Negatable: true, Usage: "use the cache"}
+	expect(t, fl.String(), "--cache, -c, --no-cache\tuse the cache (default: false)")
+}
+
+func TestParseBoolNegatable(t *testing.T) {
+	cases := []struct {
+		args          []string
+		expectedValue bool
+		expectedSet   bool
+	}{
+		{[]string{"run"}, true, false},
+		{[]string{"run", "--no-cache"}, false, true},
+		{[]string{"run", "--cache"}, true, true},
+		{[]string{"run", "-c=false"}, false, true},
+	}
+
+	for _, c := range cases {
+		actionRan := false
+		err := (&App{
+			Flags: []Flag{
+				&BoolFlag{Name: "cache", Aliases: []string{"c"}, Value: true, Negatable: true},
+			},
+			Action: func(ctx *Context) error {
+				actionRan = true
+				expect(t, ctx.Bool("cache"), c.expectedValue)
+				expect(t, ctx.Bool("c"), c.expectedValue)
+				expect(t, ctx.Bool("no-cache"), !c.expectedValue)
+				expect(t, ctx.IsSet("cache"), c.expectedSet)
+				expect(t, ctx.IsSet("no-cache"), c.expectedSet)
+				return nil
+			},
+		}).Run(c.args)
+		expect(t, err, nil)
+		expect(t, actionRan, true)
+	}
+}
+
+func TestParseBoolNegatable_Conflict(t *testing.T) {
+	err := (&App{
+		Writer: ioutil.Discard,
+		Flags: []Flag{
+			&BoolFlag{Name: "cache", Negatable: true},
+		},
+		Action: func(ctx *Context) error {
+			t.Errorf("action should not run")
+			return nil
+		},
+	}).Run([]string{"run", "--cache", "--no-cache"})
+	if err == nil || !strings.Contains(err.Error(), "Cannot use two forms of the same flag") {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestFlagUsageTemplate(t *testing.T) {
+	cases := []struct {
+		flag     Flag
+		expected string
+	}{
+		{
+			&DurationFlag{Name: "timeout", Value: 30 * time.Second, EnvVars: []string{"MYAPP_TIMEOUT"},
+				Usage: "connection timeout (default {{.Default}}, env {{.EnvVars}})"},
+			"--timeout value\tconnection timeout (default 30s, env MYAPP_TIMEOUT) (default: 30s)" + withEnvHint([]string{"MYAPP_TIMEOUT"}, ""),
+		},
+		{
+			&IntFlag{Name: "retries", Value: 3, DefaultText: "three", Usage: "retry up to {{.Default}} times"},
+			"--retries value\tretry up to three times (default: three)",
+		},
+		{
+			&StringFlag{Name: "format", Value: "json", Usage: "output as \\{{.Default}} or {{.Default}}"},
+			"--format value\toutput as {{.Default}} or json (default: \"json\")",
+		},
+		{
+			&StringSliceFlag{Name: "tag", EnvVars: []string{"TAGS", "LABELS"}, Usage: "tags, also read from {{.EnvVars}}"},
+			"--tag value\ttags, also read from TAGS, LABELS" + withEnvHint([]string{"TAGS", "LABELS"}, ""),
+		},
+	}
+
+	for _, c := range cases {
+		expect(t, c.flag.String(), c.expected)
+	}
+
+	expect(t, flagDetails(&IntFlag{Name: "retries", Value: 3, Usage: "retry up to {{.Default}} times"}),
+		": retry up to 3 times (default: 3)")
+}
+
 func TestFlagsFromEnv(t *testing.T) {
 	newSetIntSlice := func(defaults ...int) IntSlice {
 		s := NewIntSlice(defaults...)
-		s.hasBeenSet = true
+		s.hasBeenSet = false
 		return *s
 	}
 
 	newSetInt64Slice := func(defaults ...int64) Int64Slice {
 		s := NewInt64Slice(defaults...)
-		s.hasBeenSet = true
+		s.hasBeenSet = false
 		return *s
 	}
 
 	newSetStringSlice := func(defaults ...string) StringSlice {
 		s := NewStringSlice(defaults...)
 		s.hasBeenSet = false
+		s.raw = []string{strings.Join(defaults, ",")}
 		return *s
 	}
 
@@ -110,7 +201,7 @@ func TestFlagsFromEnv(t *testing.T) {
 		{"1.2", 0, &Uint64Flag{Name: "seconds", EnvVars: []string{"SECONDS"}}, `could not parse "1.2" as uint64 value for flag seconds: .*`},
 		{"foobar", 0, &Uint64Flag{Name: "seconds", EnvVars: []string{"SECONDS"}}, `could not parse "foobar" as uint64 value for flag seconds: .*`},
 
-		{"foo,bar", &Parser{"foo", "bar"}, &GenericFlag{Name: "names", Value: &Parser{}, EnvVars: []string{"NAMES"}}, ""},
+		{"foo,bar", &pairValue{"foo", "bar"}, &GenericFlag{Name: "names", Value: &pairValue{}, EnvVars: []string{"NAMES"}}, ""},
 	}
 
 	for i, test := range flagTests {
@@ -182,6 +273,90 @@ func TestStringFlagDefaultText(t *testing.T) {
 	}
 }
 
+func TestFlagNames_Aliases(t *testing.T) {
+	tests := []struct {
+		flag     Flag
+		expected []string
+	}{
+		{&StringFlag{Name: "lang", Aliases: []string{"l"}}, []string{"lang", "l"}},
+		{&StringFlag{Name: "lang, l"}, []string{"lang", "l"}},
+		{&StringFlag{Name: "lang,l,language"}, []string{"lang", "l", "language"}},
+		{&StringFlag{Name: "lang, l", Aliases: []string{"l", "language"}}, []string{"lang", "l", "language"}},
+		{&IntFlag{Name: "count"}, []string{"count"}},
+	}
+
+	for _, test := range tests {
+		expect(t, test.flag.Names(), test.expected)
+	}
+}
+
+func TestFlagNames_CommaSeparatedName(t *testing.T) {
+	fl := &StringFlag{Name: "lang, l", Value: "english"}
+	expect(t, fl.String(), "--lang value, -l value\t(default: \"english\")")
+
+	app := &App{
+		Flags: []Flag{fl},
+		Action: func(c *Context) error {
+			expect(t, c.String("lang"), "spanish")
+			expect(t, c.String("l"), "spanish")
+			expect(t, c.IsSet("lang"), true)
+			return nil
+		},
+	}
+	expect(t, app.Run([]string{"greet", "-l", "spanish"}), nil)
+}
+
+func TestFlagDefaultText(t *testing.T) {
+	tests := []struct {
+		flag     Flag
+		expected string
+	}{
+		{&StringFlag{Name: "dir", Value: "/tmp/x1y2", DefaultText: "a temporary directory"}, "--dir value\t(default: a temporary directory)"},
+		{&IntFlag{Name: "workers", Value: 8, DefaultText: "number of CPUs"}, "--workers value\t(default: number of CPUs)"},
+		{&DurationFlag{Name: "timeout", DefaultText: "none"}, "--timeout value\t(default: none)"},
+		{&StringSliceFlag{Name: "hosts", DefaultText: "localhost"}, "--hosts value\t(default: localhost)"},
+		{&StringSliceFlag{Name: "hosts", Value: NewStringSlice("a", "b"), DefaultText: "two hosts"}, "--hosts value\t(default: two hosts)"},
+		{&IntSliceFlag{Name: "ports", DefaultText: "80, 443"}, "--ports value\t(default: 80, 443)"},
+		{&StringMapFlag{Name: "labels", DefaultText: "none"}, "--labels value\t(default: none)"},
+		{&StringSliceFlag{Name: "hosts", Value: NewStringSlice("a", "b")}, "--hosts value\t(default: \"a\", \"b\")"},
+		{&IntFlag{Name: "workers", Value: 8}, "--workers value\t(default: 8)"},
+		{&BoolFlag{Name: "color", Value: true, DefaultText: "when a terminal"}, "--color\t(default: when a terminal)"},
+		{&ByteSizeFlag{Name: "cache", Value: 1024, DefaultText: "a tenth of the memory"}, "--cache value\t(default: a tenth of the memory)"},
+		{&UintFlag{Name: "port", Value: 49152, DefaultText: "a random port"}, "--port value\t(default: a random port)"},
+		{&Float64Flag{Name: "ratio", Value: 0.5, DefaultText: "half"}, "--ratio value\t(default: half)"},
+		{&PathFlag{Name: "home", Value: "/home/u", DefaultText: "$HOME"}, "--home value\t(default: $HOME)"},
+		{&URLFlag{Name: "endpoint", DefaultText: "the local server"}, "--endpoint value\t(default: the local server)"},
+		{&GenericFlag{Name: "mode", DefaultText: "auto"}, "--mode value\t(default: auto)"},
+		{&DurationSliceFlag{Name: "backoff", Value: NewDurationSlice(time.Second), DefaultText: "exponential"}, "--backoff value\t(default: exponential)"},
+		{&Float64SliceFlag{Name: "weights", DefaultText: "equal"}, "--weights value\t(default: equal)"},
+		{&TimestampFlag{Name: "since", Layout: "2006-01-02", DefaultText: "yesterday"}, "--since value\t(default: yesterday)"},
+		{&TimestampFlag{Name: "since", Layout: "2006-01-02", Value: NewTimestamp(time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC))}, "--since value\t(default: 2022-03-04)"},
+		{&TimestampSliceFlag{Name: "at", Layout: "2006-01-02", DefaultText: "today"}, "--at value\t(default: today)"},
+		// an empty default shows none
+		{&StringFlag{Name: "dir"}, "--dir value\t"},
+		{&StringSliceFlag{Name: "hosts"}, "--hosts value\t"},
+		{&TimestampFlag{Name: "since", Layout: "2006-01-02"}, "--since value\t"},
+		{&TimestampSliceFlag{Name: "at", Layout: "2006-01-02"}, "--at value\t"},
+		{&GenericFlag{Name: "mode"}, "--mode value\t"},
+	}
+
+	for _, test := range tests {
+		expect(t, test.flag.String(), test.expected)
+	}
+}
+
+func TestFlagDefaultText_AppliesDefault(t *testing.T) {
+	app := newTestApp()
+	app.Flags = []Flag{&IntFlag{Name: "port", Value: 49152, DefaultText: "a random port"}}
+	app.Action = func(c *Context) error {
+		expect(t, c.Int("port"), 49152)
+		return nil
+	}
+
+	err := app.Run([]string{"app"})
+	expect(t, err, nil)
+}
+
 func TestStringFlagWithEnvVarHelpOutput(t *testing.T) {
 
 	os.Clearenv()
@@ -240,6 +415,79 @@ func TestStringFlagApply_SetsAllNames(t *testing.T) {
 	expect(t, v, "YUUUU")
 }
 
+func TestStringFlagChoices(t *testing.T) {
+	newApp := func(action ActionFunc) *App {
+		return &App{
+			Flags: []Flag{
+				&StringFlag{
+					Name:            "format",
+					Aliases:         []string{"f"},
+					Value:           "table",
+					Choices:         []string{"json", "yaml", "table"},
+					CaseInsensitive: true,
+				},
+				&StringFlag{Name: "strict", Choices: []string{"on", "off"}, Required: true},
+			},
+			Action:    action,
+			Writer:    ioutil.Discard,
+			ErrWriter: ioutil.Discard,
+		}
+	}
+
+	actionRan := false
+	err := newApp(func(ctx *Context) error {
+		actionRan = true
+		expect(t, ctx.String("format"), "json")
+		expect(t, ctx.String("f"), "json")
+		expect(t, ctx.IsSet("format"), true)
+		expect(t, ctx.String("strict"), "off")
+		return nil
+	}).Run([]string{"run", "-f", "JSON", "--strict", "off"})
+	expect(t, err, nil)
+	expect(t, actionRan, true)
+
+	err = newApp(func(ctx *Context) error {
+		expect(t, ctx.String("format"), "table")
+		expect(t, ctx.IsSet("format"), false)
+		return nil
+	}).Run([]string{"run", "--strict", "on"})
+	expect(t, err, nil)
+
+	err = newApp(nil).Run([]string{"run", "--format", "xml", "--strict", "on"})
+	if err == nil || !strings.Contains(err.Error(), `invalid value "xml", expected one of json, yaml, table`) {
+		t.Errorf("expected an error listing the choices, got %v", err)
+	}
+
+	err = newApp(nil).Run([]string{"run", "--strict", "ON"})
+	if err == nil || !strings.Contains(err.Error(), `expected one of on, off`) {
+		t.Errorf("expected choices to be case sensitive by default, got %v", err)
+	}
+
+	err = newApp(nil).Run([]string{"run"})
+	expect(t, err.Error(), `Required flag "strict" not set`)
+}
+
+func TestStringFlagChoicesFromEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_FORMAT", "Yaml")
+	fl := StringFlag{Name: "format", EnvVars: []string{"APP_FORMAT"}, Choices: []string{"json", "yaml"}, CaseInsensitive: true}
+	set := flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, lookupString("format", set), "yaml")
+
+	fl = StringFlag{Name: "format", EnvVars: []string{"APP_FORMAT"}, Choices: []string{"json"}}
+	err := fl.Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), `could not parse "Yaml" as choice value for flag format: invalid value "Yaml", expected one of json`)
+}
+
+func TestStringFlagChoicesHelpOutput(t *testing.T) {
+	fl := &StringFlag{Name: "format", Usage: "output format", Value: "table", Choices: []string{"json", "table"}}
+	expect(t, fl.String(), "--format value\toutput format (one of: json, table) (default: \"table\")")
+
+	fl = &StringFlag{Name: "format", Choices: []string{"json", "table"}}
+	expect(t, fl.String(), "--format value\t(one of: json, table)")
+}
+
 var pathFlagTests = []struct {
 	name     string
 	aliases  []string
@@ -290,6 +538,295 @@ func TestPathFlagApply_SetsAllNames(t *testing.T) {
 	expect(t, v, "/path/to/file/PATH")
 }
 
+func TestExpandPath(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HOME", "/home/gopher")
+	_ = os.Setenv("APP_DIR", "/srv/app")
+
+	cases := []struct {
+		path     string
+		strict   bool
+		expected string
+		err      string
+	}{
+		{"", false, "", ""},
+		{"-", false, "-", ""},
+		{"~", false, "/home/gopher", ""},
+		{"~/app.yaml", false, "/home/gopher/app.yaml", ""},
+		{"~other/app.yaml", false, "~other/app.yaml", ""},
+		{"$APP_DIR/app.yaml", false, "/srv/app/app.yaml", ""},
+		{"${APP_DIR}.d/app.yaml", false, "/srv/app.d/app.yaml", ""},
+		{"$MISSING/${MISSING}/app.yaml", false, "$MISSING/${MISSING}/app.yaml", ""},
+		{"${MISSING/app.yaml", false, "${MISSING/app.yaml", ""},
+		{"cost$", false, "cost$", ""},
+		{"$MISSING/app.yaml", true, "", "environment variable MISSING is not set"},
+	}
+	for _, c := range cases {
+		path, err := expandPath(c.path, c.strict)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("expected error %q expanding %q, got %v", c.err, c.path, err)
+			}
+			continue
+		}
+		expect(t, err, nil)
+		expect(t, path, c.expected)
+	}
+}
+
+func TestParsePathFlagExpand(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HOME", "/home/gopher")
+
+	var dest string
+	actionRan := false
+	err := (&App{
+		Flags: []Flag{
+			&PathFlag{Name: "config", Aliases: []string{"c"}, Expand: true},
+			&PathFlag{Name: "cache", Value: "~/.cache", Expand: true, Destination: &dest},
+			&PathFlag{Name: "raw"},
+		},
+		Action: func(ctx *Context) error {
+			actionRan = true
+			expect(t, ctx.Path("config"), "/home/gopher/app.yaml")
+			expect(t, ctx.Path("c"), "/home/gopher/app.yaml")
+			expect(t, ctx.RawPath("config"), "~/app.yaml")
+			expect(t, ctx.Path("cache"), "/home/gopher/.cache")
+			expect(t, dest, "/home/gopher/.cache")
+			expect(t, ctx.Path("raw"), "~/raw")
+			expect(t, ctx.RawPath("raw"), "~/raw")
+			return nil
+		},
+	}).Run([]string{"run", "--config", "~/app.yaml", "--raw", "~/raw"})
+	expect(t, err, nil)
+	expect(t, actionRan, true)
+}
+
+func TestParsePathFlagExpandStrict(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_CONFIG", "$MISSING/app.yaml")
+
+	actionRan := false
+	err := (&App{
+		Flags: []Flag{
+			&PathFlag{Name: "config", Expand: true, ExpandStrict: true},
+		},
+		Writer:    ioutil.Discard,
+		ErrWriter: ioutil.Discard,
+		Action: func(ctx *Context) error {
+			actionRan = true
+			return nil
+		},
+	}).Run([]string{"run", "--config", "$MISSING/app.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "MISSING is not set") {
+		t.Errorf("expected an error for the unset variable, got %v", err)
+	}
+	expect(t, actionRan, false)
+
+	fl := &PathFlag{Name: "config", EnvVars: []string{"APP_CONFIG"}, Expand: true, ExpandStrict: true}
+	err = fl.Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), `could not expand "$MISSING/app.yaml" as path value for flag config: environment variable MISSING is not set`)
+}
+
+func TestPathFlagResolveRelativeTo(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "urfave_cli_resolve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	tmp, _ = filepath.EvalSymlinks(tmp)
+	invocation, project := filepath.Join(tmp, "invocation"), filepath.Join(tmp, "project")
+	for _, dir := range []string{invocation, project} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(invocation); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	tests := []struct {
+		resolve  string
+		args     []string
+		expected string
+		raw      string
+		abs      string
+	}{
+		{
+			resolve:  PathRelativeToInvocation,
+			args:     []string{"--output", "out/app.bin"},
+			expected: filepath.Join(invocation, "out/app.bin"),
+			raw:      "out/app.bin",
+			abs:      filepath.Join(invocation, "out/app.bin"),
+		},
+		{
+			resolve:  PathRelativeToCurrent,
+			args:     []string{"-o", "out/app.bin"},
+			expected: filepath.Join(project, "out/app.bin"),
+			raw:      "out/app.bin",
+			abs:      filepath.Join(project, "out/app.bin"),
+		},
+		{
+			args:     []string{"--output", "out/app.bin"},
+			expected: "out/app.bin",
+			raw:      "out/app.bin",
+			abs:      filepath.Join(invocation, "out/app.bin"),
+		},
+		{
+			resolve:  PathRelativeToInvocation,
+			args:     []string{"--output", "/tmp/app.bin"},
+			expected: "/tmp/app.bin",
+			raw:      "/tmp/app.bin",
+			abs:      "/tmp/app.bin",
+		},
+		{
+			resolve:  PathRelativeToCurrent,
+			args:     []string{"--output", "-"},
+			expected: "-",
+			raw:      "-",
+			abs:      "-",
+		},
+	}
+
+	for _, test := range tests {
+		for _, expand := range []bool{false, true} {
+			if err := os.Chdir(invocation); err != nil {
+				t.Fatal(err)
+			}
+
+			var output, outputAlias, raw, abs string
+			app := &App{
+				Before: func(*Context) error {
+					return os.Chdir(project)
+				},
+				Commands: []*Command{{
+					Name: "build",
+					Flags: []Flag{
+						&PathFlag{Name: "output", Aliases: []string{"o"}, Expand: expand, ResolveRelativeTo: test.resolve},
+					},
+					Action: func(ctx *Context) error {
+						output = ctx.Path("output")
+						outputAlias = ctx.Path("o")
+						raw = ctx.RawPath("output")
+						abs = ctx.PathAbs("output")
+						return nil
+					},
+				}},
+			}
+
+			err := app.Run(append([]string{"app", "build"}, test.args...))
+			expect(t, err, nil)
+			expect(t, output, test.expected)
+			expect(t, outputAlias, test.expected)
+			if expand {
+				expect(t, raw, test.raw)
+			}
+			expect(t, abs, test.abs)
+		}
+	}
+
+	err = (&App{
+		Flags:  []Flag{&PathFlag{Name: "output", ResolveRelativeTo: "home"}},
+		Action: func(*Context) error { return nil },
+	}).Run([]string{"app", "--output", "out"})
+	expect(t, err.Error(), `flag output resolves its path relative to "home", expected "invocation" or "current"`)
+}
+
+func TestParsePathSliceFlagGlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urfave_cli_glob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a.txt", "b.txt", "c.log"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	tests := []struct {
+		name     string
+		flag     *PathSliceFlag
+		args     []string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "relative",
+			flag:     &PathSliceFlag{Name: "in", Glob: true},
+			args:     []string{"--in", "*.txt", "--in", "c.log"},
+			expected: []string{"a.txt", "b.txt", "c.log"},
+		},
+		{
+			name:     "absolute",
+			flag:     &PathSliceFlag{Name: "in", Glob: true},
+			args:     []string{"--in", filepath.Join(dir, "*.log")},
+			expected: []string{filepath.Join(dir, "c.log")},
+		},
+		{
+			name:     "no glob",
+			flag:     &PathSliceFlag{Name: "in"},
+			args:     []string{"--in", "*.txt"},
+			expected: []string{"*.txt"},
+		},
+		{
+			name:     "no match",
+			flag:     &PathSliceFlag{Name: "in", Glob: true},
+			args:     []string{"--in", "*.md", "--in", "missing.md"},
+			expected: []string{"missing.md"},
+		},
+		{
+			name: "no match error",
+			flag: &PathSliceFlag{Name: "in", Glob: true, GlobErrorOnNoMatch: true},
+			args: []string{"--in", "*.md"},
+			err:  `invalid value "*.md" for flag -in: no path matches "*.md"`,
+		},
+		{
+			name:     "default",
+			flag:     &PathSliceFlag{Name: "in", Glob: true, Value: NewPathSlice("*.txt")},
+			expected: []string{"*.txt"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var paths []string
+			err := (&App{
+				Flags:     []Flag{test.flag},
+				Writer:    ioutil.Discard,
+				ErrWriter: ioutil.Discard,
+				Action: func(ctx *Context) error {
+					paths = ctx.PathSlice("in")
+					return nil
+				},
+			}).Run(append([]string{"run"}, test.args...))
+			if test.err != "" {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				expect(t, err.Error(), test.err)
+				return
+			}
+			expect(t, err, nil)
+			expect(t, paths, test.expected)
+		})
+	}
+}
+
+func TestPathSliceFlagHelpOutput(t *testing.T) {
+	fl := &PathSliceFlag{Name: "in", Aliases: []string{"i"}, Value: NewPathSlice("a.txt", "b.txt")}
+	expect(t, fl.String(), "--in value, -i value\t(default: \"a.txt\", \"b.txt\")")
+}
+
 var envHintFlagTests = []struct {
 	name     string
 	env      string
@@ -376,6 +913,58 @@ func TestStringSliceFlagApply_SetsAllNames(t *testing.T) {
 	expect(t, err, nil)
 }
 
+var stringMapFlagTests = []struct {
+	name     string
+	aliases  []string
+	value    *StringMap
+	expected string
+}{
+	{"label", nil, NewStringMap(nil), "--label value\t"},
+	{"l", nil, NewStringMap(map[string]string{"env": "prod"}), "-l value\t(default: \"env=prod\")"},
+	{"label", []string{"l"}, NewStringMap(map[string]string{"team": "infra", "env": "prod"}), "--label value, -l value\t(default: \"env=prod\", \"team=infra\")"},
+}
+
+func TestStringMapFlagHelpOutput(t *testing.T) {
+	for _, test := range stringMapFlagTests {
+		f := &StringMapFlag{Name: test.name, Aliases: test.aliases, Value: test.value}
+		output := f.String()
+
+		if output != test.expected {
+			t.Errorf("%q does not match %q", output, test.expected)
+		}
+	}
+}
+
+func TestStringMapFlagApply_SetsAllNames(t *testing.T) {
+	fl := StringMapFlag{Name: "label", Aliases: []string{"L", "labels"}}
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--label", "a=1", "-L", "b=2", "--labels", "c=3"})
+	expect(t, err, nil)
+	expect(t, fl.Value.Value(), map[string]string{"a": "1", "b": "2", "c": "3"})
+}
+
+func TestStringMapFlagApply_DisallowDuplicates(t *testing.T) {
+	fl := StringMapFlag{Name: "label", DisallowDuplicates: true}
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--label", "a=1", "--label", "a=2"})
+	expect(t, err, fmt.Errorf("invalid value \"a=2\" for flag -label: duplicate key \"a\""))
+}
+
+func TestStringMapFlagApply_NotKeyValue(t *testing.T) {
+	fl := StringMapFlag{Name: "label"}
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--label", "nope"})
+	expect(t, err, fmt.Errorf("invalid value \"nope\" for flag -label: \"nope\" is not a key=value pair"))
+}
+
 var intFlagTests = []struct {
 	name     string
 	expected string
@@ -583,6 +1172,57 @@ func TestDurationFlagApply_SetsAllNames(t *testing.T) {
 	expect(t, v, time.Hour*30)
 }
 
+func TestDurationFlagDefaultUnit(t *testing.T) {
+	cases := []struct {
+		unit     time.Duration
+		arg      string
+		expected time.Duration
+		err      bool
+	}{
+		{time.Second, "30", 30 * time.Second, false},
+		{time.Second, "1.5", 1500 * time.Millisecond, false},
+		{time.Second, "2m", 2 * time.Minute, false},
+		{time.Minute, "-2", -2 * time.Minute, false},
+		{time.Second, "soon", 0, true},
+		{0, "30", 0, true},
+		{0, "30s", 30 * time.Second, false},
+	}
+	for _, c := range cases {
+		var v time.Duration
+		fl := DurationFlag{Name: "timeout", Aliases: []string{"t"}, DefaultUnit: c.unit, Destination: &v}
+		set := flag.NewFlagSet("test", 0)
+		set.SetOutput(ioutil.Discard)
+		_ = fl.Apply(set)
+
+		err := set.Parse([]string{"--timeout", c.arg})
+		if c.err {
+			if err == nil {
+				t.Errorf("expected an error parsing %q with unit %s", c.arg, c.unit)
+			}
+			continue
+		}
+		expect(t, err, nil)
+		expect(t, v, c.expected)
+		expect(t, lookupDuration("timeout", set), c.expected)
+		expect(t, set.Lookup("timeout").Value.String(), c.expected.String())
+	}
+}
+
+func TestDurationFlagDefaultUnitFromEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_TIMEOUT", "45")
+	fl := DurationFlag{Name: "timeout", EnvVars: []string{"APP_TIMEOUT"}, DefaultUnit: time.Second}
+	set := flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, lookupDuration("timeout", set), 45*time.Second)
+	expect(t, fl.GetValue(), "45s")
+
+	err := (&DurationFlag{Name: "timeout", EnvVars: []string{"APP_TIMEOUT"}}).Apply(flag.NewFlagSet("test", 0))
+	if err == nil || !strings.Contains(err.Error(), "for flag timeout") {
+		t.Errorf("expected an error naming the flag, got %v", err)
+	}
+}
+
 var intSliceFlagTests = []struct {
 	name     string
 	aliases  []string
@@ -721,22 +1361,10 @@ func TestFloat64FlagApply_SetsAllNames(t *testing.T) {
 	expect(t, v, float64(43.33333))
 }
 
-var float64SliceFlagTests = []struct {
-	name     string
-	aliases  []string
-	value    *Float64Slice
-	expected string
-}{
-	{"heads", nil, NewFloat64Slice(), "--heads value\t"},
-	{"H", nil, NewFloat64Slice(), "-H value\t"},
-	{"heads", []string{"H"}, NewFloat64Slice(0.1234, -10.5),
-		"--heads value, -H value\t(default: 0.1234, -10.5)"},
-}
-
-func TestFloat64SliceFlagHelpOutput(t *testing.T) {
-	for _, test := range float64SliceFlagTests {
-		fl := Float64SliceFlag{Name: test.name, Aliases: test.aliases, Value: test.value}
-		output := fl.String()
+func TestFloat32FlagHelpOutput(t *testing.T) {
+	for _, test := range float64FlagTests {
+		f := &Float32Flag{Name: test.name, Value: 0.1}
+		output := f.String()
 
 		if output != test.expected {
 			t.Errorf("%q does not match %q", output, test.expected)
@@ -744,34 +1372,298 @@ func TestFloat64SliceFlagHelpOutput(t *testing.T) {
 	}
 }
 
-func TestFloat64SliceFlagWithEnvVarHelpOutput(t *testing.T) {
-	os.Clearenv()
-	_ = os.Setenv("APP_SMURF", "0.1234,-10.5")
-	for _, test := range float64SliceFlagTests {
-		fl := Float64SliceFlag{Name: test.name, Value: test.value, EnvVars: []string{"APP_SMURF"}}
-		output := fl.String()
+func TestFloat32FlagHelpOutput_ShortestDefault(t *testing.T) {
+	cases := []struct {
+		value    float32
+		expected string
+	}{
+		{0, "--ratio value\t(default: 0)"},
+		{0.3, "--ratio value\t(default: 0.3)"},
+		{-2.5, "--ratio value\t(default: -2.5)"},
+		{12345678, "--ratio value\t(default: 12345678)"},
+		{0.00001, "--ratio value\t(default: 0.00001)"},
+	}
 
-		expectedSuffix := " [$APP_SMURF]"
-		if runtime.GOOS == "windows" {
-			expectedSuffix = " [%APP_SMURF%]"
-		}
-		if !strings.HasSuffix(output, expectedSuffix) {
-			t.Errorf("%q does not end with"+expectedSuffix, output)
-		}
+	for _, c := range cases {
+		fl := &Float32Flag{Name: "ratio", Value: c.value}
+		expect(t, fl.String(), c.expected)
 	}
-}
 
-var genericFlagTests = []struct {
-	name     string
-	value    Generic
-	expected string
-}{
-	{"toads", &Parser{"abc", "def"}, "--toads value\ttest flag (default: abc,def)"},
-	{"t", &Parser{"abc", "def"}, "-t value\ttest flag (default: abc,def)"},
+	expect(t, (&Float32Flag{Value: 0.3}).GetValue(), "0.3")
 }
 
-func TestGenericFlagHelpOutput(t *testing.T) {
-	for _, test := range genericFlagTests {
+func TestFloat32FlagApply_SetsAllNames(t *testing.T) {
+	v := float32(99.1)
+	fl := Float32Flag{Name: "noodles", Aliases: []string{"N", "nurbles"}, Destination: &v}
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--noodles", "1.3", "-N", "11", "--nurbles", "43.33333"})
+	expect(t, err, nil)
+	expect(t, v, float32(43.33333))
+}
+
+func TestFloat32FlagApply_OutOfRange(t *testing.T) {
+	fl := Float32Flag{Name: "lat"}
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--lat", "1e39"})
+	if err == nil || !strings.Contains(err.Error(), "-lat") || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected an out of range error naming the flag, got %v", err)
+	}
+
+	os.Clearenv()
+	_ = os.Setenv("APP_LAT", "1e39")
+	fl = Float32Flag{Name: "lat", EnvVars: []string{"APP_LAT"}}
+	err = fl.Apply(flag.NewFlagSet("test", 0))
+	if err == nil || !strings.Contains(err.Error(), "for flag lat") {
+		t.Errorf("expected an error naming the flag, got %v", err)
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"512MB", 512000000},
+		{"2GiB", 2 << 30},
+		{"2gib", 2 << 30},
+		{"1.5GiB", 3 << 29},
+		{"1.5 kb", 1500},
+		{"8EiB", 0},
+	}
+
+	for _, c := range cases[:len(cases)-1] {
+		v, err := parseByteSize(c.input)
+		expect(t, err, nil)
+		expect(t, v, c.expected)
+	}
+
+	for _, input := range []string{"8EiB", "9223372036854775808", "9.3EB", "", "MB", "12XB", "-1KB", "1.2.3KB"} {
+		if _, err := parseByteSize(input); err == nil {
+			t.Errorf("expected an error parsing %q", input)
+		}
+	}
+}
+
+func TestByteSizeFlagHelpOutput(t *testing.T) {
+	cases := []struct {
+		value    int64
+		expected string
+	}{
+		{0, "--cache value\t(default: 0B)"},
+		{512000000, "--cache value\t(default: 512MB)"},
+		{2 << 30, "--cache value\t(default: 2GiB)"},
+		{3 << 29, "--cache value\t(default: 1.5GiB)"},
+		{1500, "--cache value\t(default: 1.5KB)"},
+		{1536000000, "--cache value\t(default: 1536MB)"},
+		{1023, "--cache value\t(default: 1023B)"},
+	}
+
+	for _, c := range cases {
+		fl := &ByteSizeFlag{Name: "cache", Value: c.value}
+		expect(t, fl.String(), c.expected)
+	}
+
+	expect(t, (&ByteSizeFlag{Value: 2 << 30}).GetValue(), "2GiB")
+}
+
+func TestParseByteSizeFlag(t *testing.T) {
+	var dest int64
+	actionRan := false
+	_ = (&App{
+		Flags: []Flag{
+			&ByteSizeFlag{Name: "max-upload", Aliases: []string{"m"}},
+			&ByteSizeFlag{Name: "cache", Value: 1 << 20, Destination: &dest},
+		},
+		Action: func(ctx *Context) error {
+			actionRan = true
+			expect(t, ctx.ByteSize("max-upload"), int64(512000000))
+			expect(t, ctx.ByteSize("m"), int64(512000000))
+			expect(t, ctx.ByteSize("cache"), int64(2<<30))
+			expect(t, dest, int64(2<<30))
+			expect(t, ctx.ByteSize("missing"), int64(0))
+			return nil
+		},
+	}).Run([]string{"run", "--max-upload", "512MB", "--cache=2GiB"})
+	expect(t, actionRan, true)
+}
+
+func TestParseByteSizeFlagFromEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_CACHE", "1.5KiB")
+	fl := ByteSizeFlag{Name: "cache", EnvVars: []string{"APP_CACHE"}}
+	set := flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, lookupByteSize("cache", set), int64(1536))
+
+	_ = os.Setenv("APP_CACHE", "lots")
+	err := (&ByteSizeFlag{Name: "cache", EnvVars: []string{"APP_CACHE"}}).Apply(flag.NewFlagSet("test", 0))
+	if err == nil || !strings.Contains(err.Error(), "for flag cache") {
+		t.Errorf("expected an error naming the flag, got %v", err)
+	}
+}
+
+func newFileContentsTestFile(t *testing.T, contents string) (string, func()) {
+	temp, err := ioutil.TempFile("", "urfave_cli_contents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.WriteString(temp, contents)
+	_ = temp.Close()
+	return temp.Name(), func() { _ = os.Remove(temp.Name()) }
+}
+
+func TestParseFileContentsFlag(t *testing.T) {
+	path, cleanup := newFileContentsTestFile(t, "-----BEGIN CERTIFICATE-----")
+	defer cleanup()
+
+	var dest []byte
+	actionRan := false
+	err := (&App{
+		Flags: []Flag{
+			&FileContentsFlag{Name: "cert", Aliases: []string{"c"}, Destination: &dest},
+			&FileContentsFlag{Name: "key", Value: "does-not-exist.pem"},
+		},
+		Action: func(ctx *Context) error {
+			actionRan = true
+			expect(t, string(ctx.Bytes("cert")), "-----BEGIN CERTIFICATE-----")
+			expect(t, string(ctx.Bytes("c")), "-----BEGIN CERTIFICATE-----")
+			expect(t, string(dest), "-----BEGIN CERTIFICATE-----")
+			expect(t, ctx.Path("cert"), path)
+			expect(t, ctx.Path("key"), "does-not-exist.pem")
+			expect(t, len(ctx.Bytes("key")), 0)
+			expect(t, len(ctx.Bytes("missing")), 0)
+			return nil
+		},
+	}).Run([]string{"run", "-c", path})
+	expect(t, err, nil)
+	expect(t, actionRan, true)
+}
+
+func TestParseFileContentsFlagFromReader(t *testing.T) {
+	actionRan := false
+	err := (&App{
+		Reader: strings.NewReader("from stdin"),
+		Commands: []*Command{{
+			Name:  "sign",
+			Flags: []Flag{&FileContentsFlag{Name: "payload"}},
+			Action: func(ctx *Context) error {
+				actionRan = true
+				expect(t, string(ctx.Bytes("payload")), "from stdin")
+				expect(t, ctx.Path("payload"), "-")
+				return nil
+			},
+		}},
+	}).Run([]string{"run", "sign", "--payload", "-"})
+	expect(t, err, nil)
+	expect(t, actionRan, true)
+}
+
+func TestParseFileContentsFlagUsageErrors(t *testing.T) {
+	path, cleanup := newFileContentsTestFile(t, "too large")
+	defer cleanup()
+
+	for _, args := range [][]string{
+		{"run", "--cert", "does-not-exist.pem"},
+		{"run", "--cert", path},
+	} {
+		actionRan := false
+		var usageErr error
+		_ = (&App{
+			Flags: []Flag{&FileContentsFlag{Name: "cert", MaxSize: 4}},
+			OnUsageError: func(ctx *Context, err error, isSubcommand bool) error {
+				usageErr = err
+				return err
+			},
+			Action: func(ctx *Context) error {
+				actionRan = true
+				return nil
+			},
+		}).Run(args)
+		if usageErr == nil || !strings.Contains(usageErr.Error(), "-cert") {
+			t.Errorf("expected a usage error for %v, got %v", args, usageErr)
+		}
+		expect(t, actionRan, false)
+	}
+}
+
+func TestParseFileContentsFlagFromEnv(t *testing.T) {
+	path, cleanup := newFileContentsTestFile(t, "secret")
+	defer cleanup()
+
+	os.Clearenv()
+	_ = os.Setenv("APP_KEY", path)
+	fl := FileContentsFlag{Name: "key", EnvVars: []string{"APP_KEY"}}
+	set := flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, string(lookupBytes("key", set)), "secret")
+	expect(t, fl.IsSet(), true)
+
+	_ = os.Setenv("APP_KEY", "does-not-exist.pem")
+	err := (&FileContentsFlag{Name: "key", EnvVars: []string{"APP_KEY"}}).Apply(flag.NewFlagSet("test", 0))
+	if err == nil || !strings.Contains(err.Error(), "for flag key") {
+		t.Errorf("expected an error naming the flag, got %v", err)
+	}
+}
+
+var float64SliceFlagTests = []struct {
+	name     string
+	aliases  []string
+	value    *Float64Slice
+	expected string
+}{
+	{"heads", nil, NewFloat64Slice(), "--heads value\t"},
+	{"H", nil, NewFloat64Slice(), "-H value\t"},
+	{"heads", []string{"H"}, NewFloat64Slice(0.1234, -10.5),
+		"--heads value, -H value\t(default: 0.1234, -10.5)"},
+}
+
+func TestFloat64SliceFlagHelpOutput(t *testing.T) {
+	for _, test := range float64SliceFlagTests {
+		fl := Float64SliceFlag{Name: test.name, Aliases: test.aliases, Value: test.value}
+		output := fl.String()
+
+		if output != test.expected {
+			t.Errorf("%q does not match %q", output, test.expected)
+		}
+	}
+}
+
+func TestFloat64SliceFlagWithEnvVarHelpOutput(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_SMURF", "0.1234,-10.5")
+	for _, test := range float64SliceFlagTests {
+		fl := Float64SliceFlag{Name: test.name, Value: test.value, EnvVars: []string{"APP_SMURF"}}
+		output := fl.String()
+
+		expectedSuffix := " [$APP_SMURF]"
+		if runtime.GOOS == "windows" {
+			expectedSuffix = " [%APP_SMURF%]"
+		}
+		if !strings.HasSuffix(output, expectedSuffix) {
+			t.Errorf("%q does not end with"+expectedSuffix, output)
+		}
+	}
+}
+
+var genericFlagTests = []struct {
+	name     string
+	value    Generic
+	expected string
+}{
+	{"toads", &pairValue{"abc", "def"}, "--toads value\ttest flag (default: abc,def)"},
+	{"t", &pairValue{"abc", "def"}, "-t value\ttest flag (default: abc,def)"},
+}
+
+func TestGenericFlagHelpOutput(t *testing.T) {
+	for _, test := range genericFlagTests {
 		fl := &GenericFlag{Name: test.name, Value: test.value, Usage: "test flag"}
 		output := fl.String()
 
@@ -800,7 +1692,7 @@ func TestGenericFlagWithEnvVarHelpOutput(t *testing.T) {
 }
 
 func TestGenericFlagApply_SetsAllNames(t *testing.T) {
-	fl := GenericFlag{Name: "orbs", Aliases: []string{"O", "obrs"}, Value: &Parser{}}
+	fl := GenericFlag{Name: "orbs", Aliases: []string{"O", "obrs"}, Value: &pairValue{}}
 	set := flag.NewFlagSet("test", 0)
 	_ = fl.Apply(set)
 
@@ -881,6 +1773,45 @@ func TestParseMultiStringFromEnvCascade(t *testing.T) {
 	}).Run([]string{"run"})
 }
 
+func TestParseStringSliceOrderAndRaw(t *testing.T) {
+	cases := []struct {
+		env         string
+		noSplit     bool
+		args        []string
+		expected    []string
+		expectedRaw []string
+	}{
+		{"", false, []string{"run", "-s", "c", "-s", "a,b", "-s", "b"}, []string{"c", "a", "b", "b"}, []string{"c", "a,b", "b"}},
+		{"", true, []string{"run", "-s", "c", "-s", "a,b", "-s", "b"}, []string{"c", "a,b", "b"}, []string{"c", "a,b", "b"}},
+		{"x, y,z", false, []string{"run"}, []string{"x", "y", "z"}, []string{"x, y,z"}},
+		{"x, y,z", true, []string{"run"}, []string{"x, y,z"}, []string{"x, y,z"}},
+		{"x, y,z", false, []string{"run", "-s", "b", "-s", "a"}, []string{"b", "a"}, []string{"b", "a"}},
+	}
+
+	for _, c := range cases {
+		os.Clearenv()
+		if c.env != "" {
+			_ = os.Setenv("APP_SERVE", c.env)
+		}
+
+		actionRan := false
+		err := (&App{
+			Flags: []Flag{
+				&StringSliceFlag{Name: "serve", Aliases: []string{"s"}, EnvVars: []string{"APP_SERVE"}, NoSplit: c.noSplit},
+			},
+			Action: func(ctx *Context) error {
+				actionRan = true
+				expect(t, ctx.StringSlice("serve"), c.expected)
+				expect(t, ctx.StringSlice("s"), c.expected)
+				expect(t, ctx.StringSliceRaw("serve"), c.expectedRaw)
+				return nil
+			},
+		}).Run(c.args)
+		expect(t, err, nil)
+		expect(t, actionRan, true)
+	}
+}
+
 func TestParseMultiStringSlice(t *testing.T) {
 	_ = (&App{
 		Flags: []Flag{
@@ -1076,6 +2007,207 @@ func TestParseMultiStringSliceFromEnvWithDestination(t *testing.T) {
 	}).Run([]string{"run"})
 }
 
+func TestSliceFlagDestination(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []int
+	}{
+		{name: "defaults", args: []string{"run"}, expected: []int{1, 2}},
+		{name: "given", args: []string{"run", "-n", "3", "-n", "4,5"}, expected: []int{3, 4, 5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				ints      []int
+				int64s    []int64
+				float64s  []float64
+				strValues []string
+			)
+			strs := &StringSlice{}
+			app := &App{
+				Flags: []Flag{
+					&IntSliceFlag{Name: "num", Aliases: []string{"n"}, Value: NewIntSlice(1, 2), Destination: &ints},
+					&Int64SliceFlag{Name: "num64", Value: NewInt64Slice(1, 2), Destination: &int64s},
+					&Float64SliceFlag{Name: "float", Value: NewFloat64Slice(1, 2), Destination: &float64s},
+					&StringSliceFlag{Name: "str", Value: NewStringSlice("1", "2"), Destination: strs},
+					&StringSliceFlag{Name: "strings", Value: NewStringSlice("1", "2"), DestinationSlice: &strValues},
+				},
+				Action: func(*Context) error { return nil },
+				Writer: ioutil.Discard,
+			}
+			args := test.args
+			if len(args) > 1 {
+				args = append(args, "--num64", "3", "--num64", "4,5", "--float", "3,4,5", "--str", "3", "--str", "4,5", "--strings", "3", "--strings", "4,5")
+			}
+			expect(t, app.Run(args), nil)
+
+			var (
+				expected64  []int64
+				expectedF64 []float64
+				expectedStr []string
+			)
+			for _, i := range test.expected {
+				expected64 = append(expected64, int64(i))
+				expectedF64 = append(expectedF64, float64(i))
+				expectedStr = append(expectedStr, strconv.Itoa(i))
+			}
+			expect(t, ints, test.expected)
+			expect(t, int64s, expected64)
+			expect(t, float64s, expectedF64)
+			expect(t, strs.Value(), expectedStr)
+			expect(t, strValues, expectedStr)
+		})
+	}
+}
+
+func TestSliceFlagDestination_EnvThenCommandLine(t *testing.T) {
+	defer resetEnvVar("APP_NUMS")
+	_ = os.Setenv("APP_NUMS", "7,8")
+
+	// the values given on the command line replace the ones from env, with or
+	// without a Destination
+	for _, withDestination := range []bool{false, true} {
+		var (
+			ints     []int
+			int64s   []int64
+			float64s []float64
+			strs     []string
+			actual   []interface{}
+		)
+		intFlag := &IntSliceFlag{Name: "num", EnvVars: []string{"APP_NUMS"}}
+		int64Flag := &Int64SliceFlag{Name: "num64", EnvVars: []string{"APP_NUMS"}}
+		float64Flag := &Float64SliceFlag{Name: "float", EnvVars: []string{"APP_NUMS"}}
+		stringFlag := &StringSliceFlag{Name: "str", EnvVars: []string{"APP_NUMS"}}
+		if withDestination {
+			intFlag.Destination = &ints
+			int64Flag.Destination = &int64s
+			float64Flag.Destination = &float64s
+			stringFlag.DestinationSlice = &strs
+		}
+		app := &App{
+			Flags: []Flag{intFlag, int64Flag, float64Flag, stringFlag},
+			Action: func(c *Context) error {
+				actual = []interface{}{c.IntSlice("num"), c.Int64Slice("num64"), c.Float64Slice("float"), c.StringSlice("str")}
+				return nil
+			},
+		}
+
+		expect(t, app.Run([]string{"run", "--num", "1", "--num64", "1", "--float", "1", "--str", "1"}), nil)
+		expect(t, actual, []interface{}{[]int{1}, []int64{1}, []float64{1}, []string{"1"}})
+		if withDestination {
+			expect(t, ints, []int{1})
+			expect(t, int64s, []int64{1})
+			expect(t, float64s, []float64{1})
+			expect(t, strs, []string{"1"})
+		}
+	}
+}
+
+func TestSliceFlagDestination_AppAndCommand(t *testing.T) {
+	// the command applies its flag anew, so its parse wins even when the flag
+	// is only given to the app, like for the Destination of IntFlag
+	tests := []struct {
+		args     []string
+		expected []int
+	}{
+		{args: []string{"app", "--num", "3", "cmd"}, expected: []int{1}},
+		{args: []string{"app", "cmd", "--num", "4"}, expected: []int{4}},
+		{args: []string{"app", "--num", "3", "cmd", "--num", "4,5"}, expected: []int{4, 5}},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			var dest, atApp []int
+			app := &App{
+				Flags: []Flag{
+					&IntSliceFlag{Name: "num", Value: NewIntSlice(1), Destination: &dest},
+				},
+				Before: func(*Context) error {
+					atApp = append([]int{}, dest...)
+					return nil
+				},
+				Commands: []*Command{{
+					Name: "cmd",
+					Flags: []Flag{
+						&IntSliceFlag{Name: "num", Value: NewIntSlice(1), Destination: &dest},
+					},
+					Action: func(c *Context) error {
+						expect(t, c.IntSlice("num"), test.expected)
+						return nil
+					},
+				}},
+			}
+			expect(t, app.Run(test.args), nil)
+			expect(t, dest, test.expected)
+			if test.args[1] == "--num" {
+				expect(t, atApp, []int{3})
+			}
+		})
+	}
+}
+
+func TestParseMultiStringMap(t *testing.T) {
+	_ = (&App{
+		Flags: []Flag{
+			&StringMapFlag{Name: "label", Aliases: []string{"l"}},
+		},
+		Action: func(ctx *Context) error {
+			expected := map[string]string{"env": "prod", "team": "infra", "query": "a=b"}
+			if !reflect.DeepEqual(ctx.StringMap("label"), expected) {
+				t.Errorf("main name not set: %v != %v", expected, ctx.StringMap("label"))
+			}
+			if !reflect.DeepEqual(ctx.StringMap("l"), expected) {
+				t.Errorf("short name not set: %v != %v", expected, ctx.StringMap("l"))
+			}
+			return nil
+		},
+	}).Run([]string{"run", "-l", "env=dev", "-l", "team=infra", "-l", "env=prod", "-l", "query=a=b"})
+}
+
+func TestParseMultiStringMapWithDefaults(t *testing.T) {
+	defaults := map[string]string{"env": "dev"}
+	_ = (&App{
+		Flags: []Flag{
+			&StringMapFlag{Name: "label", Aliases: []string{"l"}, Value: NewStringMap(defaults)},
+		},
+		Action: func(ctx *Context) error {
+			expected := map[string]string{"team": "infra"}
+			if !reflect.DeepEqual(ctx.StringMap("label"), expected) {
+				t.Errorf("main name not set: %v != %v", expected, ctx.StringMap("label"))
+			}
+			if !reflect.DeepEqual(ctx.StringMap("l"), expected) {
+				t.Errorf("short name not set: %v != %v", expected, ctx.StringMap("l"))
+			}
+			return nil
+		},
+	}).Run([]string{"run", "-l", "team=infra"})
+
+	expect(t, defaults, map[string]string{"env": "dev"})
+}
+
+func TestParseMultiStringMapFromEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_LABELS", "env=prod, team=infra")
+
+	_ = (&App{
+		Flags: []Flag{
+			&StringMapFlag{Name: "label", Aliases: []string{"l"}, EnvVars: []string{"APP_LABELS"}},
+		},
+		Action: func(ctx *Context) error {
+			expected := map[string]string{"env": "prod", "team": "infra"}
+			if !reflect.DeepEqual(ctx.StringMap("label"), expected) {
+				t.Errorf("main name not set from env: %v", ctx.StringMap("label"))
+			}
+			if !reflect.DeepEqual(ctx.StringMap("l"), expected) {
+				t.Errorf("short name not set from env: %v", ctx.StringMap("l"))
+			}
+			return nil
+		},
+	}).Run([]string{"run"})
+}
+
 func TestParseMultiInt(t *testing.T) {
 	_ = (&App{
 		Flags: []Flag{
@@ -1093,6 +2225,93 @@ func TestParseMultiInt(t *testing.T) {
 	}).Run([]string{"run", "-s", "10"})
 }
 
+func TestParseSizedInts(t *testing.T) {
+	var (
+		i32 int32
+		i16 int16
+		i8  int8
+		u32 uint32
+		u16 uint16
+		u8  uint8
+	)
+
+	actionRan := false
+	err := (&App{
+		Flags: []Flag{
+			&Int32Flag{Name: "i32", Destination: &i32},
+			&Int16Flag{Name: "i16", Destination: &i16},
+			&Int8Flag{Name: "i8", Aliases: []string{"p"}, Destination: &i8},
+			&Uint32Flag{Name: "u32", Destination: &u32},
+			&Uint16Flag{Name: "u16", Destination: &u16},
+			&Uint8Flag{Name: "u8", Destination: &u8},
+		},
+		Action: func(ctx *Context) error {
+			actionRan = true
+			expect(t, ctx.Int32("i32"), int32(-2147483648))
+			expect(t, ctx.Int16("i16"), int16(32767))
+			expect(t, ctx.Int8("i8"), int8(-128))
+			expect(t, ctx.Int8("p"), int8(-128))
+			expect(t, ctx.Uint32("u32"), uint32(4294967295))
+			expect(t, ctx.Uint16("u16"), uint16(65535))
+			expect(t, ctx.Uint8("u8"), uint8(255))
+			return nil
+		},
+	}).Run([]string{"run", "--i32", "-2147483648", "--i16", "32767", "-p", "-128",
+		"--u32", "4294967295", "--u16", "65535", "--u8", "0xff"})
+	expect(t, err, nil)
+	expect(t, actionRan, true)
+	expect(t, i32, int32(-2147483648))
+	expect(t, i16, int16(32767))
+	expect(t, i8, int8(-128))
+	expect(t, u32, uint32(4294967295))
+	expect(t, u16, uint16(65535))
+	expect(t, u8, uint8(255))
+}
+
+func TestParseSizedInts_OutOfRange(t *testing.T) {
+	cases := []struct {
+		flag     Flag
+		arg      string
+		expected string
+	}{
+		{&Int8Flag{Name: "prio"}, "128", "invalid value \"128\" for flag -prio: 128 is out of range [-128, 127]"},
+		{&Int8Flag{Name: "prio"}, "-129", "invalid value \"-129\" for flag -prio: -129 is out of range [-128, 127]"},
+		{&Int16Flag{Name: "prio"}, "32768", "invalid value \"32768\" for flag -prio: 32768 is out of range [-32768, 32767]"},
+		{&Int32Flag{Name: "prio"}, "2147483648", "invalid value \"2147483648\" for flag -prio: 2147483648 is out of range [-2147483648, 2147483647]"},
+		{&Uint8Flag{Name: "port"}, "256", "invalid value \"256\" for flag -port: 256 is out of range [0, 255]"},
+		{&Uint16Flag{Name: "port"}, "65536", "invalid value \"65536\" for flag -port: 65536 is out of range [0, 65535]"},
+		{&Uint16Flag{Name: "port"}, "-1", "invalid value \"-1\" for flag -port: -1 is out of range [0, 65535]"},
+		{&Uint32Flag{Name: "port"}, "4294967296", "invalid value \"4294967296\" for flag -port: 4294967296 is out of range [0, 4294967295]"},
+	}
+
+	for _, c := range cases {
+		set := flag.NewFlagSet("test", 0)
+		set.SetOutput(ioutil.Discard)
+		expect(t, c.flag.Apply(set), nil)
+
+		err := set.Parse([]string{"--" + c.flag.Names()[0], c.arg})
+		if err == nil {
+			t.Errorf("expected an error for %s", c.arg)
+			continue
+		}
+		expect(t, err.Error(), c.expected)
+	}
+}
+
+func TestSizedIntFlagApply_EnvOutOfRange(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_PORT", "70000")
+
+	fl := &Uint16Flag{Name: "port", EnvVars: []string{"APP_PORT"}}
+	err := fl.Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), "could not parse \"70000\" as uint16 value for flag port: 70000 is out of range [0, 65535]")
+}
+
+func TestSizedIntFlagHelpOutput(t *testing.T) {
+	expect(t, (&Int8Flag{Name: "prio", Value: -3}).String(), "--prio value\t(default: -3)")
+	expect(t, (&Uint16Flag{Name: "port", Value: 8080}).String(), "--port value\t(default: 8080)")
+}
+
 func TestParseDestinationInt(t *testing.T) {
 	var dest int
 	_ = (&App{
@@ -1390,33 +2609,173 @@ func TestParseMultiFloat64FromEnvCascade(t *testing.T) {
 	}).Run([]string{"run"})
 }
 
-func TestParseMultiFloat64SliceFromEnv(t *testing.T) {
-	os.Clearenv()
-	_ = os.Setenv("APP_INTERVALS", "0.1,-10.5")
-
+func TestParseMultiFloat32(t *testing.T) {
 	_ = (&App{
 		Flags: []Flag{
-			&Float64SliceFlag{Name: "intervals", Aliases: []string{"i"}, Value: NewFloat64Slice(), EnvVars: []string{"APP_INTERVALS"}},
+			&Float32Flag{Name: "serve", Aliases: []string{"s"}},
 		},
 		Action: func(ctx *Context) error {
-			if !reflect.DeepEqual(ctx.Float64Slice("intervals"), []float64{0.1, -10.5}) {
-				t.Errorf("main name not set from env")
+			if ctx.Float32("serve") != 10.2 {
+				t.Errorf("main name not set")
 			}
-			if !reflect.DeepEqual(ctx.Float64Slice("i"), []float64{0.1, -10.5}) {
-				t.Errorf("short name not set from env")
+			if ctx.Float32("s") != 10.2 {
+				t.Errorf("short name not set")
 			}
 			return nil
 		},
-	}).Run([]string{"run"})
+	}).Run([]string{"run", "-s", "10.2"})
 }
 
-func TestParseMultiFloat64SliceFromEnvCascade(t *testing.T) {
-	os.Clearenv()
-	_ = os.Setenv("APP_INTERVALS", "0.1234,-10.5")
-
-	_ = (&App{
-		Flags: []Flag{
-			&Float64SliceFlag{Name: "intervals", Aliases: []string{"i"}, Value: NewFloat64Slice(), EnvVars: []string{"COMPAT_INTERVALS", "APP_INTERVALS"}},
+func TestParseMultiFloat32Slice(t *testing.T) {
+	for _, args := range [][]string{
+		{"run", "-c", "52.5", "-c", "13.25"},
+		{"run", "-c", "52.5,13.25"},
+		{"run", "--coords", "52.5, 13.25"},
+	} {
+		actionRan := false
+		err := (&App{
+			Flags: []Flag{
+				&Float32SliceFlag{Name: "coords", Aliases: []string{"c"}, Value: NewFloat32Slice(1)},
+			},
+			Action: func(ctx *Context) error {
+				actionRan = true
+				expect(t, ctx.Float32Slice("coords"), []float32{52.5, 13.25})
+				expect(t, ctx.Float32Slice("c"), []float32{52.5, 13.25})
+				return nil
+			},
+		}).Run(args)
+		expect(t, err, nil)
+		expect(t, actionRan, true)
+	}
+}
+
+func TestParseMultiFloat32SliceFromEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_COORDS", "0.5,-10.5")
+
+	_ = (&App{
+		Flags: []Flag{
+			&Float32SliceFlag{Name: "coords", Value: NewFloat32Slice(), EnvVars: []string{"APP_COORDS"}},
+		},
+		Action: func(ctx *Context) error {
+			expect(t, ctx.Float32Slice("coords"), []float32{0.5, -10.5})
+			return nil
+		},
+	}).Run([]string{"run"})
+}
+
+func TestFloat32SliceFlag_OutOfRange(t *testing.T) {
+	app := &App{
+		Writer: ioutil.Discard,
+		Flags: []Flag{
+			&Float32SliceFlag{Name: "coords"},
+		},
+	}
+	err := app.Run([]string{"run", "--coords", "1,1e39"})
+	if err == nil || !strings.Contains(err.Error(), "-coords") || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected an out of range error naming the flag, got %v", err)
+	}
+}
+
+func TestFloat32SliceFlagHelpOutput(t *testing.T) {
+	fl := Float32SliceFlag{Name: "heads", Aliases: []string{"H"}, Value: NewFloat32Slice(0.1234, -10.5)}
+	expect(t, fl.String(), "--heads value, -H value\t(default: 0.1234, -10.5)")
+}
+
+func TestParseMultiInt32Slice(t *testing.T) {
+	actionRan := false
+	err := (&App{
+		Flags: []Flag{
+			&Int32SliceFlag{Name: "ids", Aliases: []string{"i"}, Value: NewInt32Slice(1)},
+			&Uint32SliceFlag{Name: "ports", Aliases: []string{"p"}, Value: NewUint32Slice(80)},
+		},
+		Action: func(ctx *Context) error {
+			actionRan = true
+			expect(t, ctx.Int32Slice("ids"), []int32{-2147483648, 2147483647})
+			expect(t, ctx.Int32Slice("i"), []int32{-2147483648, 2147483647})
+			expect(t, ctx.Uint32Slice("ports"), []uint32{0, 4294967295})
+			expect(t, ctx.Uint32Slice("p"), []uint32{0, 4294967295})
+			return nil
+		},
+	}).Run([]string{"run", "-i", "-2147483648", "-i", "2147483647", "-p", "0", "-p", "0xffffffff"})
+	expect(t, err, nil)
+	expect(t, actionRan, true)
+}
+
+func TestParseMultiInt32SliceFromEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_IDS", "3,-4")
+	_ = os.Setenv("APP_PORTS", "80,443")
+
+	err := (&App{
+		Flags: []Flag{
+			&Int32SliceFlag{Name: "ids", EnvVars: []string{"APP_IDS"}},
+			&Uint32SliceFlag{Name: "ports", EnvVars: []string{"APP_PORTS"}},
+		},
+		Action: func(ctx *Context) error {
+			expect(t, ctx.Int32Slice("ids"), []int32{3, -4})
+			expect(t, ctx.Uint32Slice("ports"), []uint32{80, 443})
+			return nil
+		},
+	}).Run([]string{"run"})
+	expect(t, err, nil)
+}
+
+func TestInt32SliceFlag_OutOfRange(t *testing.T) {
+	for _, args := range [][]string{
+		{"run", "--ids", "2147483648"},
+		{"run", "--ports", "4294967296"},
+		{"run", "--ports", "-1"},
+	} {
+		app := &App{
+			Writer: ioutil.Discard,
+			Flags: []Flag{
+				&Int32SliceFlag{Name: "ids"},
+				&Uint32SliceFlag{Name: "ports"},
+			},
+		}
+		err := app.Run(args)
+		if err == nil || !strings.Contains(err.Error(), "flag "+args[1][1:]) || !strings.Contains(err.Error(), "out of range") {
+			t.Errorf("expected an out of range error naming the flag for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestInt32SliceFlagHelpOutput(t *testing.T) {
+	fl := Int32SliceFlag{Name: "ids", Aliases: []string{"i"}, Value: NewInt32Slice(-1, 2)}
+	expect(t, fl.String(), "--ids value, -i value\t(default: -1, 2)")
+
+	ufl := Uint32SliceFlag{Name: "ports", Value: NewUint32Slice(80, 443)}
+	expect(t, ufl.String(), "--ports value\t(default: 80, 443)")
+}
+
+func TestParseMultiFloat64SliceFromEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_INTERVALS", "0.1,-10.5")
+
+	_ = (&App{
+		Flags: []Flag{
+			&Float64SliceFlag{Name: "intervals", Aliases: []string{"i"}, Value: NewFloat64Slice(), EnvVars: []string{"APP_INTERVALS"}},
+		},
+		Action: func(ctx *Context) error {
+			if !reflect.DeepEqual(ctx.Float64Slice("intervals"), []float64{0.1, -10.5}) {
+				t.Errorf("main name not set from env")
+			}
+			if !reflect.DeepEqual(ctx.Float64Slice("i"), []float64{0.1, -10.5}) {
+				t.Errorf("short name not set from env")
+			}
+			return nil
+		},
+	}).Run([]string{"run"})
+}
+
+func TestParseMultiFloat64SliceFromEnvCascade(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_INTERVALS", "0.1234,-10.5")
+
+	_ = (&App{
+		Flags: []Flag{
+			&Float64SliceFlag{Name: "intervals", Aliases: []string{"i"}, Value: NewFloat64Slice(), EnvVars: []string{"COMPAT_INTERVALS", "APP_INTERVALS"}},
 		},
 		Action: func(ctx *Context) error {
 			if !reflect.DeepEqual(ctx.Float64Slice("intervals"), []float64{0.1234, -10.5}) {
@@ -1471,6 +2830,108 @@ func TestParseBoolShortOptionHandle(t *testing.T) {
 	}).Run([]string{"run", "foobar", "-so"})
 }
 
+func TestParseBoolCount(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"run"}, 0},
+		{[]string{"run", "-v"}, 1},
+		{[]string{"run", "--verbose", "--verbose"}, 2},
+		{[]string{"run", "-v", "-v", "-v"}, 3},
+		{[]string{"run", "-vvv"}, 3},
+		{[]string{"run", "-v", "-vv"}, 3},
+	}
+
+	for _, c := range cases {
+		var count int
+		actionRan := false
+		err := (&App{
+			UseShortOptionHandling: true,
+			Flags: []Flag{
+				&BoolFlag{Name: "verbose", Aliases: []string{"v"}, Count: &count},
+			},
+			Action: func(ctx *Context) error {
+				actionRan = true
+				expect(t, ctx.Count("verbose"), c.expected)
+				expect(t, ctx.Count("v"), c.expected)
+				expect(t, ctx.IsSet("verbose"), c.expected > 0)
+				expect(t, ctx.Bool("v"), c.expected > 0)
+				return nil
+			},
+		}).Run(c.args)
+		expect(t, err, nil)
+		expect(t, actionRan, true)
+		expect(t, count, c.expected)
+	}
+}
+
+func TestParseCountFlag(t *testing.T) {
+	cases := []struct {
+		args     []string
+		env      string
+		expected int
+	}{
+		{[]string{"run"}, "", 1},
+		{[]string{"run", "-v"}, "", 1},
+		{[]string{"run", "--verbose", "--verbose"}, "", 2},
+		{[]string{"run", "-v", "-v", "-v"}, "", 3},
+		{[]string{"run", "-vvv"}, "", 3},
+		{[]string{"run", "--verbose", "--verbose", "--verbose=false"}, "", 0},
+		{[]string{"run"}, "4", 4},
+		{[]string{"run", "-vv"}, "4", 2},
+	}
+
+	for _, c := range cases {
+		os.Clearenv()
+		if c.env != "" {
+			_ = os.Setenv("APP_VERBOSITY", c.env)
+		}
+
+		var count, fromAction int
+		err := (&App{
+			UseShortOptionHandling: true,
+			Flags: []Flag{
+				&CountFlag{
+					Name:        "verbose",
+					Aliases:     []string{"v"},
+					Value:       1,
+					EnvVars:     []string{"APP_VERBOSITY"},
+					Destination: &count,
+					Action: func(_ *Context, v int) error {
+						fromAction = v
+						return nil
+					},
+				},
+			},
+			Action: func(ctx *Context) error {
+				expect(t, ctx.Count("verbose"), c.expected)
+				expect(t, ctx.Count("v"), c.expected)
+				expect(t, ctx.Value("v"), c.expected)
+				return nil
+			},
+		}).Run(c.args)
+		expect(t, err, nil)
+		expect(t, count, c.expected)
+		if len(c.args) > 1 {
+			expect(t, fromAction, c.expected)
+		}
+	}
+
+	os.Clearenv()
+	_ = os.Setenv("APP_VERBOSITY", "loud")
+	err := (&CountFlag{Name: "verbose", EnvVars: []string{"APP_VERBOSITY"}}).Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), `could not parse "loud" as count value for flag verbose: strconv.Atoi: parsing "loud": invalid syntax`)
+}
+
+func TestCountFlagHelpOutput(t *testing.T) {
+	os.Clearenv()
+	expect(t, (&CountFlag{Name: "verbose", Aliases: []string{"v"}, Usage: "more output"}).String(),
+		"--verbose, -v\tmore output (may be repeated)")
+	expect(t, (&CountFlag{Name: "v", Usage: "more output", Value: 2, EnvVars: []string{"APP_VERBOSITY"}}).String(),
+		"-v\tmore output (may be repeated) (default: 2)"+withEnvHint([]string{"APP_VERBOSITY"}, ""))
+}
+
 func TestParseDestinationBool(t *testing.T) {
 	var dest bool
 	_ = (&App{
@@ -1575,9 +3036,9 @@ func TestParseMultiBoolT(t *testing.T) {
 	}).Run([]string{"run", "--implode=false"})
 }
 
-type Parser [2]string
+type pairValue [2]string
 
-func (p *Parser) Set(value string) error {
+func (p *pairValue) Set(value string) error {
 	parts := strings.Split(value, ",")
 	if len(parts) != 2 {
 		return fmt.Errorf("invalid format")
@@ -1589,24 +3050,24 @@ func (p *Parser) Set(value string) error {
 	return nil
 }
 
-func (p *Parser) String() string {
+func (p *pairValue) String() string {
 	return fmt.Sprintf("%s,%s", p[0], p[1])
 }
 
-func (p *Parser) Get() interface{} {
+func (p *pairValue) Get() interface{} {
 	return p
 }
 
 func TestParseGeneric(t *testing.T) {
 	_ = (&App{
 		Flags: []Flag{
-			&GenericFlag{Name: "serve", Aliases: []string{"s"}, Value: &Parser{}},
+			&GenericFlag{Name: "serve", Aliases: []string{"s"}, Value: &pairValue{}},
 		},
 		Action: func(ctx *Context) error {
-			if !reflect.DeepEqual(ctx.Generic("serve"), &Parser{"10", "20"}) {
+			if !reflect.DeepEqual(ctx.Generic("serve"), &pairValue{"10", "20"}) {
 				t.Errorf("main name not set")
 			}
-			if !reflect.DeepEqual(ctx.Generic("s"), &Parser{"10", "20"}) {
+			if !reflect.DeepEqual(ctx.Generic("s"), &pairValue{"10", "20"}) {
 				t.Errorf("short name not set")
 			}
 			return nil
@@ -1622,15 +3083,15 @@ func TestParseGenericFromEnv(t *testing.T) {
 			&GenericFlag{
 				Name:    "serve",
 				Aliases: []string{"s"},
-				Value:   &Parser{},
+				Value:   &pairValue{},
 				EnvVars: []string{"APP_SERVE"},
 			},
 		},
 		Action: func(ctx *Context) error {
-			if !reflect.DeepEqual(ctx.Generic("serve"), &Parser{"20", "30"}) {
+			if !reflect.DeepEqual(ctx.Generic("serve"), &pairValue{"20", "30"}) {
 				t.Errorf("main name not set from env")
 			}
-			if !reflect.DeepEqual(ctx.Generic("s"), &Parser{"20", "30"}) {
+			if !reflect.DeepEqual(ctx.Generic("s"), &pairValue{"20", "30"}) {
 				t.Errorf("short name not set from env")
 			}
 			return nil
@@ -1645,12 +3106,12 @@ func TestParseGenericFromEnvCascade(t *testing.T) {
 		Flags: []Flag{
 			&GenericFlag{
 				Name:    "foos",
-				Value:   &Parser{},
+				Value:   &pairValue{},
 				EnvVars: []string{"COMPAT_FOO", "APP_FOO"},
 			},
 		},
 		Action: func(ctx *Context) error {
-			if !reflect.DeepEqual(ctx.Generic("foos"), &Parser{"99", "2000"}) {
+			if !reflect.DeepEqual(ctx.Generic("foos"), &pairValue{"99", "2000"}) {
 				t.Errorf("value not set from env")
 			}
 			return nil
@@ -1658,6 +3119,189 @@ func TestParseGenericFromEnvCascade(t *testing.T) {
 	}).Run([]string{"run"})
 }
 
+// selectorValue is a Generic whose String is not parseable, so copying it
+// between flag names relies on its Serializer
+type selectorValue struct {
+	key, op, value string
+}
+
+func (s *selectorValue) Set(value string) error {
+	parts := strings.SplitN(value, "=", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("expected key=op=value, got %q", value)
+	}
+	s.key, s.op, s.value = parts[0], parts[1], parts[2]
+	return nil
+}
+
+func (s *selectorValue) String() string {
+	return fmt.Sprintf("%s %s %s", s.key, s.op, s.value)
+}
+
+func (s *selectorValue) Serialize() string {
+	return fmt.Sprintf("%s=%s=%s", s.key, s.op, s.value)
+}
+
+func newSelectorSlice(defaults ...Generic) *GenericSlice {
+	return NewGenericSlice(func() Generic { return &selectorValue{} }, defaults...)
+}
+
+func TestParseGenericSlice(t *testing.T) {
+	actionRan := false
+	_ = (&App{
+		Flags: []Flag{
+			&GenericSliceFlag{Name: "selector", Aliases: []string{"s"}, Value: newSelectorSlice()},
+		},
+		Action: func(ctx *Context) error {
+			actionRan = true
+			expected := []interface{}{
+				&selectorValue{"app", "eq", "web"},
+				&selectorValue{"tier", "ne", "db"},
+			}
+			expect(t, ctx.GenericSlice("selector"), expected)
+			expect(t, ctx.GenericSlice("s"), expected)
+			expect(t, ctx.GenericSlice("missing"), []interface{}(nil))
+			return nil
+		},
+	}).Run([]string{"run", "-s", "app=eq=web", "-s", "tier=ne=db"})
+	expect(t, actionRan, true)
+}
+
+func TestParseGenericSlice_ReplacesDefaults(t *testing.T) {
+	fl := &GenericSliceFlag{Name: "selector", Value: newSelectorSlice(&selectorValue{"app", "eq", "api"})}
+	expect(t, fl.String(), "--selector value\t(default: app eq api)")
+
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	expect(t, fl.Apply(set), nil)
+	expect(t, set.Parse([]string{"--selector", "tier=ne=db"}), nil)
+	expect(t, lookupGenericSlice("selector", set), []interface{}{&selectorValue{"tier", "ne", "db"}})
+
+	err := set.Parse([]string{"--selector", "tier"})
+	if err == nil || !strings.Contains(err.Error(), "expected key=op=value") {
+		t.Errorf("expected the factory made value to reject the input, got %v", err)
+	}
+}
+
+func TestParseGenericSliceFromEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_SELECTORS", "app=eq=web, tier=ne=db")
+	fl := &GenericSliceFlag{Name: "selector", EnvVars: []string{"APP_SELECTORS"}, Value: newSelectorSlice()}
+	set := flag.NewFlagSet("test", 0)
+	expect(t, fl.Apply(set), nil)
+	expect(t, len(lookupGenericSlice("selector", set)), 2)
+
+	err := (&GenericSliceFlag{Name: "selector"}).Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), "flag selector needs a Value made with NewGenericSlice")
+}
+
+func TestFlagFromEnvOrFile_FirstNonEmpty(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_EMPTY", "")
+	_ = os.Setenv("APP_TOKEN", "secret")
+
+	val, ok, _ := flagFromEnvOrFile([]string{"APP_MISSING", "APP_EMPTY", "APP_TOKEN"}, "")
+	expect(t, val, "secret")
+	expect(t, ok, true)
+
+	val, ok, _ = flagFromEnvOrFile([]string{"APP_MISSING", "APP_EMPTY"}, "")
+	expect(t, val, "")
+	expect(t, ok, true)
+
+	_, ok, _ = flagFromEnvOrFile([]string{"APP_MISSING"}, "")
+	expect(t, ok, false)
+}
+
+func TestEnvVarsEmptyValue(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_TOKEN", "")
+
+	actionRan := false
+	err := (&App{
+		Flags: []Flag{
+			&StringFlag{Name: "token", Value: "default", EnvVars: []string{"APP_TOKEN"}, Required: true},
+		},
+		Action: func(ctx *Context) error {
+			actionRan = true
+			expect(t, ctx.String("token"), "")
+			expect(t, ctx.IsSet("token"), true)
+			return nil
+		},
+	}).Run([]string{"run"})
+	expect(t, err, nil)
+	expect(t, actionRan, true)
+
+	numeric := []Flag{
+		&IntFlag{Name: "token", EnvVars: []string{"APP_TOKEN"}},
+		&UintFlag{Name: "token", EnvVars: []string{"APP_TOKEN"}},
+		&Float64Flag{Name: "token", EnvVars: []string{"APP_TOKEN"}},
+		&DurationFlag{Name: "token", EnvVars: []string{"APP_TOKEN"}},
+		&Float64SliceFlag{Name: "token", EnvVars: []string{"APP_TOKEN"}},
+	}
+	for _, fl := range numeric {
+		err := fl.Apply(flag.NewFlagSet("test", 0))
+		if err == nil || !strings.Contains(err.Error(), `could not parse ""`) {
+			t.Errorf("expected a parse error for %T, got %v", fl, err)
+		}
+	}
+}
+
+func TestSliceFlagSeparator(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_PORTS", "80; 443")
+	_ = os.Setenv("APP_TAGS", "a,b|c")
+	_ = os.Setenv("APP_BACKOFF", "1s;1m")
+
+	set := flag.NewFlagSet("test", 0)
+	expect(t, (&IntSliceFlag{Name: "ports", EnvVars: []string{"APP_PORTS"}, Separator: ";"}).Apply(set), nil)
+	expect(t, (&StringSliceFlag{Name: "tags", EnvVars: []string{"APP_TAGS"}, Separator: "|"}).Apply(set), nil)
+	expect(t, (&DurationSliceFlag{Name: "backoff", EnvVars: []string{"APP_BACKOFF"}, Separator: ";"}).Apply(set), nil)
+
+	expect(t, lookupIntSlice("ports", set), []int{80, 443})
+	expect(t, lookupStringSlice("tags", set), []string{"a,b", "c"})
+	expect(t, lookupDurationSlice("backoff", set), []time.Duration{time.Second, time.Minute})
+}
+
+func TestSliceFlagSeparator_Whitespace(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("MYAPP_TAGS", "a,b c")
+
+	set := flag.NewFlagSet("test", 0)
+	expect(t, (&StringSliceFlag{Name: "comma", EnvVars: []string{"MYAPP_TAGS"}}).Apply(set), nil)
+	expect(t, (&StringSliceFlag{Name: "space", EnvVars: []string{"MYAPP_TAGS"}, Separator: " "}).Apply(set), nil)
+	expect(t, lookupStringSlice("comma", set), []string{"a", "b c"})
+	expect(t, lookupStringSlice("space", set), []string{"a,b", "c"})
+
+	_ = os.Setenv("MYAPP_PORTS", " 80\t443\n 8080 ")
+	expect(t, (&IntSliceFlag{Name: "ports", EnvVars: []string{"MYAPP_PORTS"}, Separator: " "}).Apply(set), nil)
+	expect(t, lookupIntSlice("ports", set), []int{80, 443, 8080})
+}
+
+func TestStringSliceFlatten(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("MYAPP_TAGS", "a,b c")
+
+	set := flag.NewFlagSet("test", 0)
+	fl := &StringSliceFlag{Name: "tags", EnvVars: []string{"MYAPP_TAGS"}}
+	expect(t, fl.Apply(set), nil)
+	expect(t, set.Parse([]string{"--tags", "d;e f", "--tags", "g"}), nil)
+	ctx := NewContext(nil, set, nil)
+
+	expect(t, ctx.StringSlice("tags"), []string{"d;e f", "g"})
+	expect(t, ctx.StringSliceFlatten("tags"), []string{"d;e", "f", "g"})
+	expect(t, ctx.StringSliceFlatten("tags", ';', ','), []string{"d", "e f", "g"})
+	expect(t, ctx.StringSliceFlatten("nope"), []string(nil))
+
+	// flattening leaves the value and its serialization alone
+	expect(t, ctx.StringSlice("tags"), []string{"d;e f", "g"})
+	expect(t, fl.Value.Serialize(), slPfx+`["d;e f","g"]`)
+
+	set = flag.NewFlagSet("test", 0)
+	expect(t, (&StringSliceFlag{Name: "tags", EnvVars: []string{"MYAPP_TAGS"}, NoSplit: true}).Apply(set), nil)
+	ctx = NewContext(nil, set, nil)
+	expect(t, ctx.StringSliceFlatten("tags"), []string{"a", "b", "c"})
+}
+
 func TestFlagFromFile(t *testing.T) {
 	os.Clearenv()
 	os.Setenv("APP_FOO", "123")
@@ -1686,13 +3330,109 @@ func TestFlagFromFile(t *testing.T) {
 	}
 
 	for _, filePathTest := range filePathTests {
-		got, _ := flagFromEnvOrFile(filePathTest.name, filePathTest.path)
+		got, _, _ := flagFromEnvOrFile(filePathTest.name, filePathTest.path)
 		if want := filePathTest.expected; got != want {
 			t.Errorf("Did not expect %v - Want %v", got, want)
 		}
 	}
 }
 
+func TestFlagFromFile_Candidates(t *testing.T) {
+	os.Clearenv()
+
+	dir, err := ioutil.TempDir("", "urfave_cli_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secret := filepath.Join(dir, "db_password")
+	if err := ioutil.WriteFile(secret, []byte("  hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	val, ok, err := flagFromEnvOrFile(nil, missing+", "+secret)
+	expect(t, err, nil)
+	expect(t, ok, true)
+	expect(t, val, "hunter2")
+
+	_, ok, err = flagFromEnvOrFile(nil, missing)
+	expect(t, err, nil)
+	expect(t, ok, false)
+
+	// a directory exists but cannot be read
+	_, _, err = flagFromEnvOrFile(nil, dir+","+secret)
+	if err == nil {
+		t.Error("expected an error reading a directory")
+	}
+
+	fl := &StringFlag{Name: "password", FilePath: dir}
+	err = fl.Apply(flag.NewFlagSet("test", 0))
+	if err == nil || !strings.HasPrefix(err.Error(), "could not read file for flag password: ") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestFlagFromFile_Precedence(t *testing.T) {
+	os.Clearenv()
+
+	temp, err := ioutil.TempFile("", "urfave_cli_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.WriteString(temp, "from-file\n")
+	_ = temp.Close()
+	defer os.Remove(temp.Name())
+
+	tests := []struct {
+		args     []string
+		env      string
+		path     string
+		expected string
+		isSet    bool
+	}{
+		{args: []string{"app"}, path: "file-does-not-exist", expected: "default"},
+		{args: []string{"app"}, path: temp.Name(), expected: "from-file", isSet: true},
+		{args: []string{"app"}, env: "from-env", path: temp.Name(), expected: "from-env", isSet: true},
+		{args: []string{"app", "--password", "from-cli"}, env: "from-env", path: temp.Name(), expected: "from-cli", isSet: true},
+	}
+
+	for _, test := range tests {
+		os.Clearenv()
+		if test.env != "" {
+			_ = os.Setenv("APP_PASSWORD", test.env)
+		}
+
+		var value string
+		var isSet bool
+		app := &App{
+			Flags: []Flag{&StringFlag{
+				Name:     "password",
+				Value:    "default",
+				EnvVars:  []string{"APP_PASSWORD"},
+				FilePath: test.path,
+			}},
+			Action: func(ctx *Context) error {
+				value = ctx.String("password")
+				isSet = ctx.IsSet("password")
+				return nil
+			},
+		}
+		expect(t, app.Run(test.args), nil)
+		expect(t, value, test.expected)
+		expect(t, isSet, test.isSet)
+	}
+}
+
+func TestFlagFromFile_Hint(t *testing.T) {
+	fl := &StringFlag{Name: "password", Usage: "the password", EnvVars: []string{"APP_PASSWORD"}, FilePath: "/run/secrets/db_password"}
+	expect(t, fl.String(), "--password value\tthe password"+withEnvHint([]string{"APP_PASSWORD"}, "")+" [/run/secrets/db_password]")
+
+	sl := &StringSliceFlag{Name: "hosts", FilePath: "/etc/hosts.list"}
+	expect(t, sl.String(), "--hosts value\t [/etc/hosts.list]")
+}
+
 func TestStringSlice_Serialized_Set(t *testing.T) {
 	sl0 := NewStringSlice("a", "b")
 	ser0 := sl0.Serialize()
@@ -1741,6 +3481,22 @@ func TestInt64Slice_Serialized_Set(t *testing.T) {
 	}
 }
 
+func TestStringMap_Serialized_Set(t *testing.T) {
+	m0 := NewStringMap(map[string]string{"a": "1", "b": "x=y"})
+	ser0 := m0.Serialize()
+
+	if len(ser0) < len(slPfx) {
+		t.Fatalf("serialized shorter than expected: %q", ser0)
+	}
+
+	m1 := NewStringMap(map[string]string{"c": "3"})
+	_ = m1.Set(ser0)
+
+	if !reflect.DeepEqual(m0.Value(), m1.Value()) {
+		t.Fatalf("pre and post serialization do not match: %v != %v", m0, m1)
+	}
+}
+
 func TestTimestamp_set(t *testing.T) {
 	ts := Timestamp{
 		timestamp:  nil,
@@ -1797,3 +3553,693 @@ func TestTimestampFlagApply_Fail_Parse_Wrong_Time(t *testing.T) {
 	err := set.Parse([]string{"--time", "2006-01-02T15:04:05Z"})
 	expect(t, err, fmt.Errorf("invalid value \"2006-01-02T15:04:05Z\" for flag -time: parsing time \"2006-01-02T15:04:05Z\" as \"Jan 2, 2006 at 3:04pm (MST)\": cannot parse \"2006-01-02T15:04:05Z\" as \"Jan\""))
 }
+
+func TestTimestampFlagApply_Layouts(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"2006-01-02T15:04:05Z", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02 15:04", time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"2006-01-02", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		fl := TimestampFlag{Name: "time", Layout: time.RFC3339, Layouts: []string{"2006-01-02 15:04", "2006-01-02"}}
+		set := flag.NewFlagSet("test", 0)
+		_ = fl.Apply(set)
+
+		err := set.Parse([]string{"--time", test.value})
+		expect(t, err, nil)
+		expect(t, *fl.Value.timestamp, test.expected)
+	}
+}
+
+func TestTimestampFlagApply_Layouts_Fail(t *testing.T) {
+	fl := TimestampFlag{Name: "time", Layouts: []string{"2006-01-02 15:04", "2006-01-02"}}
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--time", "yesterday"})
+	expect(t, err, fmt.Errorf("invalid value \"yesterday\" for flag -time: parsing time \"yesterday\": does not match any of the layouts \"2006-01-02 15:04\", \"2006-01-02\""))
+}
+
+func TestTimestampFlagApply_Timezone(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	fl := TimestampFlag{Name: "time", Layouts: []string{"2006-01-02 15:04", time.RFC3339}, Timezone: loc}
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--time", "2006-01-02 15:04"})
+	expect(t, err, nil)
+	expect(t, fl.Value.timestamp.Equal(time.Date(2006, 1, 2, 13, 4, 0, 0, time.UTC)), true)
+
+	// a timestamp with a zone of its own keeps it
+	err = set.Parse([]string{"--time", "2006-01-02T15:04:05Z"})
+	expect(t, err, nil)
+	expect(t, fl.Value.timestamp.Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)), true)
+}
+
+func TestContext_Timestamp_Unset(t *testing.T) {
+	fl := &TimestampFlag{Name: "time", Layouts: []string{"2006-01-02"}}
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+	expect(t, set.Parse(nil), nil)
+
+	c := NewContext(nil, set, nil)
+	if ts := c.Timestamp("time"); ts != nil {
+		t.Errorf("expected no timestamp, got %v", ts)
+	}
+}
+
+func TestTimestampSliceFlagHelpOutput(t *testing.T) {
+	t1, _ := time.Parse(time.RFC3339, "2024-01-02T15:00:00Z")
+	t2, _ := time.Parse(time.RFC3339, "2024-01-03T09:00:00Z")
+	fl := &TimestampSliceFlag{Name: "at", Layout: time.RFC3339, Value: NewTimestampSlice(t1, t2)}
+
+	expect(t, fl.String(), "--at value\t(default: 2024-01-02T15:00:00Z, 2024-01-03T09:00:00Z)")
+}
+
+func TestTimestampSliceFlagApply(t *testing.T) {
+	fl := TimestampSliceFlag{Name: "at", Aliases: []string{"a"}, Layout: time.RFC3339}
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--at", "2024-01-02T15:00:00Z", "-a", "2024-01-03T09:00:00Z"})
+	expect(t, err, nil)
+
+	values := fl.Value.Value()
+	expect(t, len(values), 2)
+	expect(t, values[0].Format(time.RFC3339), "2024-01-02T15:00:00Z")
+	expect(t, values[1].Format(time.RFC3339), "2024-01-03T09:00:00Z")
+}
+
+func TestTimestampSliceFlagApply_Fail_Parse(t *testing.T) {
+	fl := TimestampSliceFlag{Name: "at", Layout: time.RFC3339}
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--at", "2024-01-02T15:00:00Z", "--at", "tomorrow"})
+	expect(t, err, fmt.Errorf("invalid value \"tomorrow\" for flag -at: occurrence 2 does not match layout \"2006-01-02T15:04:05Z07:00\": parsing time \"tomorrow\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"tomorrow\" as \"2006\""))
+}
+
+func TestTimestampSliceFlagApply_RequiresLayout(t *testing.T) {
+	fl := TimestampSliceFlag{Name: "at"}
+	set := flag.NewFlagSet("test", 0)
+
+	expect(t, fl.Apply(set), fmt.Errorf("timestamp Layout is required"))
+}
+
+func TestParseMultiTimestampSlice(t *testing.T) {
+	_ = (&App{
+		Flags: []Flag{
+			&TimestampSliceFlag{Name: "at", Aliases: []string{"a"}, Layout: "2006-01-02"},
+		},
+		Action: func(ctx *Context) error {
+			for _, name := range []string{"at", "a"} {
+				values := ctx.TimestampSlice(name)
+				if len(values) != 2 || values[0].Format("2006-01-02") != "2024-01-02" || values[1].Format("2006-01-02") != "2024-01-03" {
+					t.Errorf("%s not set: %v", name, values)
+				}
+			}
+			return nil
+		},
+	}).Run([]string{"run", "-a", "2024-01-02", "-a", "2024-01-03"})
+}
+
+func TestTimestampSlice_Serialized_Set(t *testing.T) {
+	t1, _ := time.Parse(time.RFC3339, "2024-01-02T15:00:00Z")
+	t2, _ := time.Parse(time.RFC3339, "2024-01-03T09:00:00Z")
+	sl0 := NewTimestampSlice(t1, t2)
+	ser0 := sl0.Serialize()
+
+	if len(ser0) < len(slPfx) {
+		t.Fatalf("serialized shorter than expected: %q", ser0)
+	}
+
+	sl1 := NewTimestampSlice(time.Now())
+	_ = sl1.Set(ser0)
+
+	if sl0.String() != sl1.String() {
+		t.Fatalf("pre and post serialization do not match: %v != %v", sl0, sl1)
+	}
+}
+
+func TestDurationSliceFlagHelpOutput(t *testing.T) {
+	fl := &DurationSliceFlag{Name: "backoff", Aliases: []string{"b"}, Value: NewDurationSlice(time.Second, 90*time.Second)}
+
+	expect(t, fl.String(), "--backoff value, -b value\t(default: 1s, 1m30s)")
+}
+
+func TestDurationSliceFlagApply(t *testing.T) {
+	fl := DurationSliceFlag{Name: "backoff", Aliases: []string{"b"}}
+	set := flag.NewFlagSet("test", 0)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--backoff", "1s,5s", "-b", "30s"})
+	expect(t, err, nil)
+	expect(t, fl.Value.Value(), []time.Duration{time.Second, 5 * time.Second, 30 * time.Second})
+}
+
+func TestDurationSliceFlagApply_EmptyElement(t *testing.T) {
+	fl := DurationSliceFlag{Name: "backoff"}
+	set := flag.NewFlagSet("test", 0)
+	set.SetOutput(ioutil.Discard)
+	_ = fl.Apply(set)
+
+	err := set.Parse([]string{"--backoff", "1s,,5s"})
+	expect(t, err, fmt.Errorf("invalid value \"1s,,5s\" for flag -backoff: empty duration in \"1s,,5s\""))
+}
+
+func TestSliceFlagCommaSeparatedValues(t *testing.T) {
+	cases := []struct {
+		args    []string
+		tags    []string
+		ids     []int64
+		noSplit []string
+	}{
+		{
+			args:    []string{"--tag", "a,b", "--tag", "c", "--id", "1,2", "--id", "3", "--raw", "a,b", "--ratio", "0.5,1"},
+			tags:    []string{"a", "b", "c"},
+			ids:     []int64{1, 2, 3},
+			noSplit: []string{"a,b"},
+		},
+		{
+			args:    []string{"--tag=a,b", "--tag=c", "--id=1,2", "--id=3", "--raw=a,b", "--ratio=0.5,1"},
+			tags:    []string{"a", "b", "c"},
+			ids:     []int64{1, 2, 3},
+			noSplit: []string{"a,b"},
+		},
+		{
+			args:    []string{"--tag", "a,,b", "--tag", "c,", "--id", "1, 2", "--id", "3", "--raw", "a,", "--ratio", " 0.5 ,1"},
+			tags:    []string{"a", "", "b", "c", ""},
+			ids:     []int64{1, 2, 3},
+			noSplit: []string{"a,"},
+		},
+		{
+			args:    []string{"--tag", " a", "--tag", `b\,c,d`, "--id", "1,2,3", "--raw", " a,b", "--ratio", "0.5,1"},
+			tags:    []string{" a", "b,c", "d"},
+			ids:     []int64{1, 2, 3},
+			noSplit: []string{" a,b"},
+		},
+	}
+
+	for _, c := range cases {
+		tags := &StringSliceFlag{Name: "tag"}
+		ids := &Int64SliceFlag{Name: "id"}
+		raw := &StringSliceFlag{Name: "raw", NoSplit: true}
+		ratios := &Float64SliceFlag{Name: "ratio"}
+
+		set := flag.NewFlagSet("test", 0)
+		for _, fl := range []Flag{tags, ids, raw, ratios} {
+			expect(t, fl.Apply(set), nil)
+		}
+		expect(t, set.Parse(c.args), nil)
+
+		expect(t, tags.Value.Value(), c.tags)
+		expect(t, ids.Value.Value(), c.ids)
+		expect(t, raw.Value.Value(), c.noSplit)
+		expect(t, ratios.Value.Value(), []float64{0.5, 1})
+	}
+}
+
+func TestSliceFlagEmptyElements(t *testing.T) {
+	for _, args := range [][]string{{"--id", "1,,2"}, {"--id", "1,2,"}, {"--id", ",1"}, {"--ratio", "0.5,,1"}, {"--port", "80,"}} {
+		set := flag.NewFlagSet("test", 0)
+		set.SetOutput(ioutil.Discard)
+		for _, fl := range []Flag{&Int64SliceFlag{Name: "id"}, &Float64SliceFlag{Name: "ratio"}, &Uint32SliceFlag{Name: "port"}} {
+			expect(t, fl.Apply(set), nil)
+		}
+
+		err := set.Parse(args)
+		if err == nil || !strings.Contains(err.Error(), "empty") {
+			t.Errorf("expected an empty element error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestParseMultiDurationSlice(t *testing.T) {
+	_ = (&App{
+		Flags: []Flag{
+			&DurationSliceFlag{Name: "backoff", Aliases: []string{"b"}, Value: NewDurationSlice(time.Minute)},
+		},
+		Action: func(ctx *Context) error {
+			expected := []time.Duration{time.Second, 5 * time.Second}
+			if !reflect.DeepEqual(ctx.DurationSlice("backoff"), expected) {
+				t.Errorf("main name not set: %v != %v", expected, ctx.DurationSlice("backoff"))
+			}
+			if !reflect.DeepEqual(ctx.DurationSlice("b"), expected) {
+				t.Errorf("short name not set: %v != %v", expected, ctx.DurationSlice("b"))
+			}
+			return nil
+		},
+	}).Run([]string{"run", "-b", "1s", "-b", "5s"})
+}
+
+func TestParseMultiDurationSliceFromEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_BACKOFF", "1s, 5s,30s")
+
+	_ = (&App{
+		Flags: []Flag{
+			&DurationSliceFlag{Name: "backoff", Aliases: []string{"b"}, EnvVars: []string{"APP_BACKOFF"}},
+		},
+		Action: func(ctx *Context) error {
+			expected := []time.Duration{time.Second, 5 * time.Second, 30 * time.Second}
+			if !reflect.DeepEqual(ctx.DurationSlice("backoff"), expected) {
+				t.Errorf("main name not set from env: %v", ctx.DurationSlice("backoff"))
+			}
+			if !reflect.DeepEqual(ctx.DurationSlice("b"), expected) {
+				t.Errorf("short name not set from env: %v", ctx.DurationSlice("b"))
+			}
+			return nil
+		},
+	}).Run([]string{"run"})
+}
+
+func TestDurationSlice_Serialized_Set(t *testing.T) {
+	sl0 := NewDurationSlice(time.Second, time.Hour)
+	ser0 := sl0.Serialize()
+
+	if len(ser0) < len(slPfx) {
+		t.Fatalf("serialized shorter than expected: %q", ser0)
+	}
+
+	sl1 := NewDurationSlice(time.Minute)
+	_ = sl1.Set(ser0)
+
+	if sl0.String() != sl1.String() {
+		t.Fatalf("pre and post serialization do not match: %v != %v", sl0, sl1)
+	}
+}
+
+func TestFlagValidator(t *testing.T) {
+	portValidator := func(v interface{}) error {
+		if port := v.(int); port < 1 || port > 65535 {
+			return fmt.Errorf("%d is not a valid port", port)
+		}
+		return nil
+	}
+
+	var validated []string
+	tagsValidator := func(v interface{}) error {
+		validated = append(validated, "tags")
+		for _, tag := range v.([]string) {
+			if tag == "" {
+				return errors.New("empty tag")
+			}
+		}
+		return nil
+	}
+	failingValidator := func(interface{}) error {
+		validated = append(validated, "fail")
+		return errors.New("always fails")
+	}
+
+	cases := []struct {
+		args              []string
+		expectedErr       string
+		expectedValidated []string
+	}{
+		{[]string{"run"}, "", nil},
+		{[]string{"run", "-p", "8080", "--tag", "a"}, "", []string{"tags"}},
+		{[]string{"run", "--port", "70000"}, "invalid value for flag port: 70000 is not a valid port", nil},
+		{[]string{"run", "--tag", "", "--other", "x"}, "invalid value for flag tag: empty tag", []string{"tags"}},
+		{[]string{"run", "--tag", "a", "--other", "x"}, "invalid value for flag other: always fails", []string{"tags", "fail"}},
+	}
+
+	for _, c := range cases {
+		validated = nil
+		actionRan := false
+		app := &App{
+			Writer: ioutil.Discard,
+			Flags: []Flag{
+				&IntFlag{Name: "port", Aliases: []string{"p"}, Value: 0, Validator: portValidator},
+				&StringSliceFlag{Name: "tag", Validator: tagsValidator},
+				&StringFlag{Name: "other", Validator: failingValidator, Required: true},
+			},
+			Action: func(*Context) error {
+				actionRan = true
+				return nil
+			},
+		}
+
+		err := app.Run(c.args)
+		expect(t, validated, c.expectedValidated)
+		if c.expectedErr == "" {
+			if err != nil && strings.Contains(err.Error(), "invalid value") {
+				t.Errorf("unexpected validation error %v", err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("expected error %q", c.expectedErr)
+			continue
+		}
+		expect(t, err.Error(), c.expectedErr)
+		expect(t, actionRan, false)
+	}
+}
+
+func TestFlagValidator_Command(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_LEVEL", "9")
+
+	err := (&App{
+		Writer: ioutil.Discard,
+		Commands: []*Command{
+			{
+				Name: "cmd",
+				Flags: []Flag{
+					&UintFlag{Name: "level", EnvVars: []string{"APP_LEVEL"}, Validator: func(v interface{}) error {
+						if v.(uint) > 5 {
+							return errors.New("must be at most 5")
+						}
+						return nil
+					}},
+				},
+				Action: func(*Context) error {
+					t.Errorf("action should not run")
+					return nil
+				},
+			},
+		},
+	}).Run([]string{"run", "cmd"})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	expect(t, err.Error(), "invalid value for flag level: must be at most 5")
+}
+
+func TestURLFlagHelpOutput(t *testing.T) {
+	fl := &URLFlag{Name: "endpoint", Aliases: []string{"e"}, Value: "https://example.com", Usage: "service endpoint"}
+	expect(t, fl.String(), "--endpoint value, -e value\tservice endpoint (default: \"https://example.com\")")
+}
+
+func TestParseURL(t *testing.T) {
+	cases := []struct {
+		args        []string
+		expectedURL string
+		expectedErr string
+	}{
+		{[]string{"run"}, "https://default.example.com/api", ""},
+		{[]string{"run", "-e", "https://user@example.com:8443/v1?x=1"}, "https://user@example.com:8443/v1?x=1", ""},
+		{[]string{"run", "-e", "HTTPS://example.com"}, "https://example.com", ""},
+		{[]string{"run", "-e", "ftp://example.com"}, "", `invalid value "ftp://example.com" for flag -e: scheme "ftp" is not allowed, expected one of https, http`},
+		{[]string{"run", "-e", "https:///path"}, "", `invalid value "https:///path" for flag -e: "https:///path" has no host`},
+		{[]string{"run", "-e", "%zz"}, "", `invalid value "%zz" for flag -e: parse "%zz": invalid URL escape "%zz"`},
+	}
+
+	for _, c := range cases {
+		var (
+			actual    string
+			raw       string
+			actionRan bool
+		)
+		err := (&App{
+			Writer: ioutil.Discard,
+			Flags: []Flag{
+				&URLFlag{
+					Name:        "endpoint",
+					Aliases:     []string{"e"},
+					Value:       "https://default.example.com/api",
+					Schemes:     []string{"https", "http"},
+					RequireHost: true,
+				},
+			},
+			Action: func(ctx *Context) error {
+				actionRan = true
+				actual = ctx.URL("endpoint").String()
+				raw = ctx.String("e")
+				return nil
+			},
+		}).Run(c.args)
+
+		if c.expectedErr != "" {
+			if err == nil {
+				t.Errorf("expected error %q", c.expectedErr)
+				continue
+			}
+			expect(t, err.Error(), c.expectedErr)
+			expect(t, actionRan, false)
+			continue
+		}
+
+		expect(t, err, nil)
+		expect(t, actual, c.expectedURL)
+		if len(c.args) > 1 {
+			expect(t, raw, c.args[2])
+		}
+	}
+}
+
+func TestParseURL_Unset(t *testing.T) {
+	_ = (&App{
+		Flags: []Flag{
+			&URLFlag{Name: "endpoint"},
+		},
+		Action: func(ctx *Context) error {
+			if ctx.URL("endpoint") != nil {
+				t.Errorf("expected a nil URL")
+			}
+			expect(t, ctx.String("endpoint"), "")
+			return nil
+		},
+	}).Run([]string{"run"})
+}
+
+func TestURLFlagApply_InvalidEnv(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_ENDPOINT", "localhost:8080")
+
+	fl := &URLFlag{Name: "endpoint", EnvVars: []string{"APP_ENDPOINT"}, Schemes: []string{"https"}}
+	err := fl.Apply(flag.NewFlagSet("test", 0))
+	expect(t, err.Error(), `could not parse "localhost:8080" as url value for flag endpoint: scheme "localhost" is not allowed, expected one of https`)
+}
+
+func TestFlagDeprecated(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		env      string
+		expected string
+	}{
+		{
+			name:     "set",
+			args:     []string{"app", "--old", "x"},
+			expected: "flag --old is deprecated: use --new instead\n",
+		},
+		{
+			name:     "set by alias",
+			args:     []string{"app", "-o", "x"},
+			expected: "flag --old is deprecated: use --new instead\n",
+		},
+		{
+			name:     "set by env",
+			args:     []string{"app"},
+			env:      "x",
+			expected: "flag --old is deprecated: use --new instead\n",
+		},
+		{
+			name: "not set",
+			args: []string{"app", "--new", "x"},
+		},
+		{
+			name:     "set in a subcommand",
+			args:     []string{"app", "sub", "--old", "x"},
+			expected: "flag --old is deprecated: use --new instead\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			os.Clearenv()
+			if c.env != "" {
+				_ = os.Setenv("APP_OLD", c.env)
+			}
+
+			errOut := &bytes.Buffer{}
+			app := newTestApp()
+			app.ErrWriter = errOut
+			app.PersistentFlags = []Flag{
+				&StringFlag{Name: "old", Aliases: []string{"o"}, EnvVars: []string{"APP_OLD"}, Deprecated: "use --new instead"},
+			}
+			app.Flags = []Flag{&StringFlag{Name: "new"}}
+			app.Action = func(*Context) error { return nil }
+			app.Commands = []*Command{{Name: "sub", Action: func(*Context) error { return nil }}}
+
+			err := app.Run(c.args)
+			expect(t, err, nil)
+			expect(t, errOut.String(), c.expected)
+		})
+	}
+}
+
+func TestFlagDeprecated_CommandFlagNamedLikeParent(t *testing.T) {
+	errOut := &bytes.Buffer{}
+	app := newTestApp()
+	app.ErrWriter = errOut
+	app.Flags = []Flag{&StringFlag{Name: "name", Deprecated: "use --root-name instead"}}
+	app.Commands = []*Command{{
+		Name:   "sub",
+		Flags:  []Flag{&StringFlag{Name: "name", Deprecated: "use --sub-name instead"}},
+		Action: func(*Context) error { return nil },
+	}}
+
+	expect(t, app.Run([]string{"p", "--name", "x", "sub", "--name", "y"}), nil)
+	expect(t, errOut.String(), "flag --name is deprecated: use --root-name instead\n"+
+		"flag --name is deprecated: use --sub-name instead\n")
+}
+
+func TestFlagDeprecated_Help(t *testing.T) {
+	os.Clearenv()
+
+	old := &StringFlag{Name: "old", Aliases: []string{"o"}, Usage: "the old way", EnvVars: []string{"APP_OLD"}, Deprecated: "use --new instead"}
+	expect(t, old.String(), "--old value, -o value\tthe old way [$APP_OLD] (DEPRECATED: use --new instead)")
+
+	slice := &StringSliceFlag{Name: "olds", Deprecated: "use --news instead"}
+	expect(t, slice.String(), "--olds value\t (DEPRECATED: use --news instead)")
+
+	var value string
+	app := &App{
+		Name:      "app",
+		Writer:    &bytes.Buffer{},
+		ErrWriter: ioutil.Discard,
+		Flags:     []Flag{old, &StringFlag{Name: "new", Usage: "the new way"}},
+		Commands: []*Command{{
+			Name:  "sub",
+			Flags: []Flag{&BoolFlag{Name: "legacy", Deprecated: "no longer needed"}, &BoolFlag{Name: "fast"}},
+		}},
+		Action: func(c *Context) error {
+			value = c.String("new") + c.String("old")
+			return nil
+		},
+	}
+	expect(t, len(app.VisibleFlags()), 2)
+
+	app.HideDeprecated = true
+	expect(t, app.Run([]string{"app", "--old", "x"}), nil)
+	// the value of a hidden deprecated flag is still honored
+	expect(t, value, "x")
+
+	var names []string
+	for _, f := range app.VisibleFlags() {
+		names = append(names, f.Names()[0])
+	}
+	expect(t, names, []string{"new", "help"})
+	expect(t, len(app.Commands[0].VisibleFlags()), 1)
+	expect(t, app.Commands[0].VisibleFlags()[0].Names()[0], "fast")
+}
+
+func TestFlagAction(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("APP_REGION", "EU")
+
+	var ran []string
+	app := newTestApp()
+	app.Flags = []Flag{
+		&StringFlag{
+			Name:    "region",
+			EnvVars: []string{"APP_REGION"},
+			Action: func(c *Context, v string) error {
+				ran = append(ran, "region="+v)
+				return c.Set("region", strings.ToLower(v))
+			},
+		},
+		&IntFlag{
+			Name: "port",
+			Action: func(c *Context, v int) error {
+				ran = append(ran, fmt.Sprintf("port=%d", v))
+				return nil
+			},
+		},
+		&StringSliceFlag{
+			Name: "tags",
+			Action: func(c *Context, v []string) error {
+				ran = append(ran, "tags="+strings.Join(v, ","))
+				return nil
+			},
+		},
+		&BoolFlag{
+			Name: "unset",
+			Action: func(*Context, bool) error {
+				ran = append(ran, "unset")
+				return nil
+			},
+		},
+	}
+	app.Before = func(c *Context) error {
+		ran = append(ran, "before")
+		return nil
+	}
+	app.Action = func(c *Context) error {
+		ran = append(ran, "action region="+c.String("region"))
+		return nil
+	}
+
+	err := app.Run([]string{"app", "--tags", "a", "--port", "8080", "--tags", "b"})
+	expect(t, err, nil)
+	expect(t, ran, []string{"region=EU", "port=8080", "tags=a,b", "before", "action region=eu"})
+}
+
+func TestFlagAction_CommandFlagNamedLikeParent(t *testing.T) {
+	var ran []string
+	action := func(level string) func(*Context, string) error {
+		return func(c *Context, v string) error {
+			ran = append(ran, level+"="+v)
+			return nil
+		}
+	}
+	app := newTestApp()
+	app.Flags = []Flag{&StringFlag{Name: "name", Action: action("root")}}
+	app.Commands = []*Command{{
+		Name:   "sub",
+		Flags:  []Flag{&StringFlag{Name: "name", Action: action("sub")}},
+		Action: func(*Context) error { return nil },
+	}}
+
+	expect(t, app.Run([]string{"p", "--name", "x", "sub", "--name", "y"}), nil)
+	expect(t, ran, []string{"root=x", "sub=y"})
+}
+
+func TestFlagAction_Error(t *testing.T) {
+	var ran []string
+	app := newTestApp()
+	app.PersistentFlags = []Flag{
+		&IntFlag{
+			Name: "port",
+			Action: func(c *Context, v int) error {
+				ran = append(ran, "port")
+				if v < 1024 {
+					return fmt.Errorf("%d is a privileged port", v)
+				}
+				return nil
+			},
+		},
+	}
+	app.Commands = []*Command{{
+		Name: "serve",
+		Before: func(*Context) error {
+			ran = append(ran, "before")
+			return nil
+		},
+		Action: func(*Context) error {
+			ran = append(ran, "action")
+			return nil
+		},
+	}}
+
+	err := app.Run([]string{"app", "serve", "--port", "80"})
+	if err == nil {
+		t.Fatal("expected an error from the flag action")
+	}
+	expect(t, err.Error(), "flag port: 80 is a privileged port")
+	expect(t, ran, []string{"port"})
+
+	ran = nil
+	err = app.Run([]string{"app", "--port", "8080", "serve"})
+	expect(t, err, nil)
+	expect(t, ran, []string{"port", "before", "action"})
+}
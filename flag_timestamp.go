@@ -11,6 +11,10 @@ type Timestamp struct {
 	timestamp  *time.Time
 	hasBeenSet bool
 	layout     string
+	// layouts, when set, are tried in order instead of layout
+	layouts []string
+	// location is the time zone of the timestamps without one, UTC if nil
+	location *time.Location
 }
 
 // Timestamp constructor
@@ -31,16 +35,43 @@ func (t *Timestamp) SetLayout(layout string) {
 	t.layout = layout
 }
 
+// SetLayouts sets the layouts tried in order for future parsing, the first
+// one matching is used
+func (t *Timestamp) SetLayouts(layouts ...string) {
+	t.layouts = layouts
+}
+
+// SetLocation sets the time zone of the timestamps parsed without one
+func (t *Timestamp) SetLocation(loc *time.Location) {
+	t.location = loc
+}
+
 // Parses the string value to timestamp
 func (t *Timestamp) Set(value string) error {
-	timestamp, err := time.Parse(t.layout, value)
-	if err != nil {
-		return err
+	layouts := t.layouts
+	if len(layouts) == 0 {
+		layouts = []string{t.layout}
 	}
 
-	t.timestamp = &timestamp
-	t.hasBeenSet = true
-	return nil
+	var err error
+	for _, layout := range layouts {
+		var timestamp time.Time
+		if t.location != nil {
+			timestamp, err = time.ParseInLocation(layout, value, t.location)
+		} else {
+			timestamp, err = time.Parse(layout, value)
+		}
+		if err == nil {
+			t.timestamp = &timestamp
+			t.hasBeenSet = true
+			return nil
+		}
+	}
+
+	if len(layouts) == 1 {
+		return err
+	}
+	return fmt.Errorf("parsing time %q: does not match any of the layouts %s", value, quoteNames(layouts))
 }
 
 // String returns a readable representation of this value (for usage defaults)
@@ -63,6 +94,7 @@ type TimestampFlag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -70,7 +102,35 @@ type TimestampFlag struct {
 	Layout      string
 	Value       *Timestamp
 	DefaultText string
-	HasBeenSet  bool
+	// Validator, when set, is run with the value of the flag, a *time.Time, once
+	// the flags are parsed and only if the flag has been set. Its error rejects
+	// the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, *time.Time) error
+	// Layouts are tried in order after Layout, the first one matching is
+	// used. Layout may be left empty when they are set.
+	Layouts []string
+	// Timezone is the time zone of the timestamps parsed without one, UTC
+	// when nil
+	Timezone *time.Location
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -115,13 +175,20 @@ func (f *TimestampFlag) GetValue() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *TimestampFlag) Apply(set *flag.FlagSet) error {
-	if f.Layout == "" {
+	layouts := f.layouts()
+	if len(layouts) == 0 {
 		return fmt.Errorf("timestamp Layout is required")
 	}
 	f.Value = &Timestamp{}
-	f.Value.SetLayout(f.Layout)
+	f.Value.SetLayout(layouts[0])
+	f.Value.SetLayouts(layouts...)
+	f.Value.SetLocation(f.Timezone)
 
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
 		if err := f.Value.Set(val); err != nil {
 			return fmt.Errorf("could not parse %q as timestamp value for flag %s: %s", val, f.Name, err)
 		}
@@ -134,6 +201,22 @@ func (f *TimestampFlag) Apply(set *flag.FlagSet) error {
 	return nil
 }
 
+// layouts returns Layout, if set, followed by Layouts
+func (f *TimestampFlag) layouts() []string {
+	if f.Layout == "" {
+		return f.Layouts
+	}
+	return append([]string{f.Layout}, f.Layouts...)
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *TimestampFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Timestamp(f.Names()[0]))
+	}
+	return nil
+}
+
 // Timestamp gets the timestamp from a flag name
 func (c *Context) Timestamp(name string) *time.Time {
 	if fs := lookupFlagSet(name, c); fs != nil {
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimestampSlice wraps []time.Time to satisfy flag.Value
+type TimestampSlice struct {
+	slice      []time.Time
+	hasBeenSet bool
+	layout     string
+}
+
+// NewTimestampSlice makes a *TimestampSlice with default values
+func NewTimestampSlice(defaults ...time.Time) *TimestampSlice {
+	return &TimestampSlice{slice: append([]time.Time{}, defaults...)}
+}
+
+// SetLayout sets the timestamp string layout for future parsing
+func (t *TimestampSlice) SetLayout(layout string) {
+	t.layout = layout
+}
+
+// Set parses the value into a timestamp and appends it to the list of values
+func (t *TimestampSlice) Set(value string) error {
+	if !t.hasBeenSet {
+		t.slice = []time.Time{}
+		t.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(value, slPfx) {
+		// Deserializing assumes overwrite
+		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &t.slice)
+		t.hasBeenSet = true
+		return nil
+	}
+
+	timestamp, err := time.Parse(t.layout, value)
+	if err != nil {
+		return fmt.Errorf("occurrence %d does not match layout %q: %s", len(t.slice)+1, t.layout, err)
+	}
+
+	t.slice = append(t.slice, timestamp)
+
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (t *TimestampSlice) String() string {
+	return fmt.Sprintf("%v", t.slice)
+}
+
+// Serialize allows TimestampSlice to fulfill Serializer
+func (t *TimestampSlice) Serialize() string {
+	jsonBytes, _ := json.Marshal(t.slice)
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Value returns the slice of timestamps set by this flag
+func (t *TimestampSlice) Value() []*time.Time {
+	ret := make([]*time.Time, len(t.slice))
+	for i := range t.slice {
+		timestamp := t.slice[i]
+		ret[i] = &timestamp
+	}
+	return ret
+}
+
+// Get returns the slice of timestamps set by this flag
+func (t *TimestampSlice) Get() interface{} {
+	return *t
+}
+
+// TimestampSliceFlag is a flag with type *TimestampSlice
+type TimestampSliceFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Layout      string
+	Value       *TimestampSlice
+	DefaultText string
+	// Validator, when set, is run with the value of the flag, a []*time.Time, once
+	// the flags are parsed and only if the flag has been set. Its error rejects
+	// the value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []*time.Time) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *TimestampSliceFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *TimestampSliceFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *TimestampSliceFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *TimestampSliceFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *TimestampSliceFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *TimestampSliceFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *TimestampSliceFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *TimestampSliceFlag) Apply(set *flag.FlagSet) error {
+	if f.Layout == "" {
+		return fmt.Errorf("timestamp Layout is required")
+	}
+	if f.Value == nil {
+		f.Value = &TimestampSlice{}
+	}
+	f.Value.SetLayout(f.Layout)
+
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		f.Value = &TimestampSlice{}
+		f.Value.SetLayout(f.Layout)
+
+		for _, s := range splitEnvValue(val, f.Separator) {
+			if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
+				return fmt.Errorf("could not parse %q as timestamp slice value for flag %s: %s", val, f.Name, err)
+			}
+		}
+
+		// Set this to false so that we reset the slice if we then set values from
+		// flags that have already been set by the environment.
+		f.Value.hasBeenSet = false
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		set.Var(f.Value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *TimestampSliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.TimestampSlice(f.Names()[0]))
+	}
+	return nil
+}
+
+// TimestampSlice looks up the value of a local TimestampSliceFlag, returns
+// nil if not found
+func (c *Context) TimestampSlice(name string) []*time.Time {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupTimestampSlice(name, fs)
+	}
+	return nil
+}
+
+func lookupTimestampSlice(name string, set *flag.FlagSet) []*time.Time {
+	f := set.Lookup(name)
+	if f != nil {
+		if slice, ok := f.Value.(*TimestampSlice); ok {
+			return slice.Value()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// uint16Value is the flag.Value for Uint16Flag, rejecting values which do not fit
+// in 16 bits
+type uint16Value uint16
+
+func newUint16Value(val uint16, p *uint16) *uint16Value {
+	*p = val
+	return (*uint16Value)(p)
+}
+
+func (i *uint16Value) Set(s string) error {
+	v, err := parseSizedUint(s, 16)
+	if err != nil {
+		return err
+	}
+	*i = uint16Value(v)
+	return nil
+}
+
+func (i *uint16Value) Get() interface{} { return uint16(*i) }
+
+func (i *uint16Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// Uint16Flag is a flag with type uint16
+type Uint16Flag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       uint16
+	DefaultText string
+	Destination *uint16
+	// Validator, when set, is run with the value of the flag, a uint16, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, uint16) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// localeParsers parse the values from the environment or a file
+	localeParsers
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *Uint16Flag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *Uint16Flag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *Uint16Flag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *Uint16Flag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *Uint16Flag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *Uint16Flag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *Uint16Flag) GetValue() string {
+	return fmt.Sprintf("%d", f.Value)
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *Uint16Flag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valInt, err := parseSizedUint(parseNumber(f.numberParser, val), 16)
+		if err != nil {
+			return fmt.Errorf("could not parse %q as uint16 value for flag %s: %s", val, f.Name, err)
+		}
+
+		f.Value = uint16(valInt)
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newUint16Value(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newUint16Value(f.Value, new(uint16)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Uint16Flag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Uint16(f.Names()[0]))
+	}
+	return nil
+}
+
+// Uint16 looks up the value of a local Uint16Flag, returns
+// 0 if not found
+func (c *Context) Uint16(name string) uint16 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupUint16(name, fs)
+	}
+	return 0
+}
+
+func lookupUint16(name string, set *flag.FlagSet) uint16 {
+	f := set.Lookup(name)
+	if f != nil {
+		parsed, err := parseSizedUint(f.Value.String(), 16)
+		if err != nil {
+			return 0
+		}
+		return uint16(parsed)
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// uint32Value is the flag.Value for Uint32Flag, rejecting values which do not fit
+// in 32 bits
+type uint32Value uint32
+
+func newUint32Value(val uint32, p *uint32) *uint32Value {
+	*p = val
+	return (*uint32Value)(p)
+}
+
+func (i *uint32Value) Set(s string) error {
+	v, err := parseSizedUint(s, 32)
+	if err != nil {
+		return err
+	}
+	*i = uint32Value(v)
+	return nil
+}
+
+func (i *uint32Value) Get() interface{} { return uint32(*i) }
+
+func (i *uint32Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// Uint32Flag is a flag with type uint32
+type Uint32Flag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       uint32
+	DefaultText string
+	Destination *uint32
+	// Validator, when set, is run with the value of the flag, a uint32, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, uint32) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// localeParsers parse the values from the environment or a file
+	localeParsers
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *Uint32Flag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *Uint32Flag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *Uint32Flag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *Uint32Flag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *Uint32Flag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *Uint32Flag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *Uint32Flag) GetValue() string {
+	return fmt.Sprintf("%d", f.Value)
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *Uint32Flag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valInt, err := parseSizedUint(parseNumber(f.numberParser, val), 32)
+		if err != nil {
+			return fmt.Errorf("could not parse %q as uint32 value for flag %s: %s", val, f.Name, err)
+		}
+
+		f.Value = uint32(valInt)
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newUint32Value(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newUint32Value(f.Value, new(uint32)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Uint32Flag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Uint32(f.Names()[0]))
+	}
+	return nil
+}
+
+// Uint32 looks up the value of a local Uint32Flag, returns
+// 0 if not found
+func (c *Context) Uint32(name string) uint32 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupUint32(name, fs)
+	}
+	return 0
+}
+
+func lookupUint32(name string, set *flag.FlagSet) uint32 {
+	f := set.Lookup(name)
+	if f != nil {
+		parsed, err := parseSizedUint(f.Value.String(), 32)
+		if err != nil {
+			return 0
+		}
+		return uint32(parsed)
+	}
+	return 0
+}
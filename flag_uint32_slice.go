@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Uint32Slice wraps []uint32 to satisfy flag.Value
+type Uint32Slice struct {
+	slice      []uint32
+	hasBeenSet bool
+}
+
+// NewUint32Slice makes an *Uint32Slice with default values
+func NewUint32Slice(defaults ...uint32) *Uint32Slice {
+	return &Uint32Slice{slice: append([]uint32{}, defaults...)}
+}
+
+// Set parses the comma separated value into uint32s and appends them to the list
+// of values. Values out of the range of uint32 are an error.
+func (i *Uint32Slice) Set(value string) error {
+	if !i.hasBeenSet {
+		i.slice = []uint32{}
+		i.hasBeenSet = true
+	}
+
+	if strings.HasPrefix(value, slPfx) {
+		// Deserializing assumes overwrite
+		_ = json.Unmarshal([]byte(strings.Replace(value, slPfx, "", 1)), &i.slice)
+		i.hasBeenSet = true
+		return nil
+	}
+
+	elems, err := splitSliceElements(value, "uint")
+	if err != nil {
+		return err
+	}
+	for _, s := range elems {
+		tmp, err := parseSizedUint(s, 32)
+		if err != nil {
+			return err
+		}
+
+		i.slice = append(i.slice, uint32(tmp))
+	}
+
+	return nil
+}
+
+// String returns a readable representation of this value (for usage defaults)
+func (i *Uint32Slice) String() string {
+	return fmt.Sprintf("%#v", i.slice)
+}
+
+// Serialize allows Uint32Slice to fulfill Serializer
+func (i *Uint32Slice) Serialize() string {
+	jsonBytes, _ := json.Marshal(i.slice)
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes))
+}
+
+// Value returns the slice of uint32s set by this flag
+func (i *Uint32Slice) Value() []uint32 {
+	return i.slice
+}
+
+// Get returns the slice of uint32s set by this flag
+func (i *Uint32Slice) Get() interface{} {
+	return *i
+}
+
+// Uint32SliceFlag is a flag with type *Uint32Slice
+type Uint32SliceFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       *Uint32Slice
+	DefaultText string
+	// Validator, when set, is run with the value of the flag, a []uint32, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// Separator splits the values from the environment or a file, a comma
+	// by default. A blank Separator splits on runs of whitespace.
+	Separator string
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, []uint32) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *Uint32SliceFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *Uint32SliceFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *Uint32SliceFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *Uint32SliceFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *Uint32SliceFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *Uint32SliceFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *Uint32SliceFlag) GetValue() string {
+	if f.Value != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *Uint32SliceFlag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		f.Value = &Uint32Slice{}
+
+		for _, s := range splitEnvValue(val, f.Separator) {
+			if err := f.Value.Set(strings.TrimSpace(s)); err != nil {
+				return fmt.Errorf("could not parse %q as uint32 slice value for flag %s: %s", val, f.Name, err)
+			}
+		}
+
+		// Set this to false so that we reset the slice if we then set values from
+		// flags that have already been set by the environment.
+		f.Value.hasBeenSet = false
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Value == nil {
+			f.Value = &Uint32Slice{}
+		}
+		set.Var(f.Value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Uint32SliceFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Uint32Slice(f.Names()[0]))
+	}
+	return nil
+}
+
+// Uint32Slice looks up the value of a local Uint32SliceFlag, returns
+// nil if not found
+func (c *Context) Uint32Slice(name string) []uint32 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupUint32Slice(name, fs)
+	}
+	return nil
+}
+
+func lookupUint32Slice(name string, set *flag.FlagSet) []uint32 {
+	f := set.Lookup(name)
+	if f != nil {
+		if slice, ok := f.Value.(*Uint32Slice); ok {
+			return slice.Value()
+		}
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ type Uint64Flag struct {
 	Name        string
 	Aliases     []string
 	Usage       string
+	Category    string
 	EnvVars     []string
 	FilePath    string
 	Required    bool
@@ -18,7 +19,38 @@ type Uint64Flag struct {
 	Value       uint64
 	DefaultText string
 	Destination *uint64
-	HasBeenSet  bool
+	// Validator, when set, is run with the value of the flag, a uint64, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, uint64) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// Unit annotates the default in help, like "(default: 30 seconds)".
+	// With HumanizeDefault, defaults in "bytes", "seconds" or "milliseconds"
+	// are shown as a size or a duration instead, like 1MiB or 1h30m.
+	Unit string
+	// HumanizeDefault shows a default with a known Unit as a size or a
+	// duration
+	HumanizeDefault bool
+	// localeParsers parse the values from the environment or a file
+	localeParsers
 }
 
 // IsSet returns whether or not the flag has been set through env or file
@@ -54,16 +86,18 @@ func (f *Uint64Flag) GetUsage() string {
 
 // Apply populates the flag given the flag set and environment
 func (f *Uint64Flag) Apply(set *flag.FlagSet) error {
-	if val, ok := flagFromEnvOrFile(f.EnvVars, f.FilePath); ok {
-		if val != "" {
-			valInt, err := strconv.ParseUint(val, 0, 64)
-			if err != nil {
-				return fmt.Errorf("could not parse %q as uint64 value for flag %s: %s", val, f.Name, err)
-			}
-
-			f.Value = valInt
-			f.HasBeenSet = true
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valInt, err := strconv.ParseUint(parseNumber(f.numberParser, val), 0, 64)
+		if err != nil {
+			return fmt.Errorf("could not parse %q as uint64 value for flag %s: %s", val, f.Name, err)
 		}
+
+		f.Value = valInt
+		f.HasBeenSet = true
 	}
 
 	for _, name := range f.Names() {
@@ -83,6 +117,14 @@ func (f *Uint64Flag) GetValue() string {
 	return fmt.Sprintf("%d", f.Value)
 }
 
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Uint64Flag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Uint64(f.Names()[0]))
+	}
+	return nil
+}
+
 // Uint64 looks up the value of a local Uint64Flag, returns
 // 0 if not found
 func (c *Context) Uint64(name string) uint64 {
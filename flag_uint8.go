@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// uint8Value is the flag.Value for Uint8Flag, rejecting values which do not fit
+// in 8 bits
+type uint8Value uint8
+
+func newUint8Value(val uint8, p *uint8) *uint8Value {
+	*p = val
+	return (*uint8Value)(p)
+}
+
+func (i *uint8Value) Set(s string) error {
+	v, err := parseSizedUint(s, 8)
+	if err != nil {
+		return err
+	}
+	*i = uint8Value(v)
+	return nil
+}
+
+func (i *uint8Value) Get() interface{} { return uint8(*i) }
+
+func (i *uint8Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// Uint8Flag is a flag with type uint8
+type Uint8Flag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       uint8
+	DefaultText string
+	Destination *uint8
+	// Validator, when set, is run with the value of the flag, a uint8, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, uint8) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+	// localeParsers parse the values from the environment or a file
+	localeParsers
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *Uint8Flag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *Uint8Flag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *Uint8Flag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *Uint8Flag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *Uint8Flag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *Uint8Flag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *Uint8Flag) GetValue() string {
+	return fmt.Sprintf("%d", f.Value)
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *Uint8Flag) Apply(set *flag.FlagSet) error {
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		valInt, err := parseSizedUint(parseNumber(f.numberParser, val), 8)
+		if err != nil {
+			return fmt.Errorf("could not parse %q as uint8 value for flag %s: %s", val, f.Name, err)
+		}
+
+		f.Value = uint8(valInt)
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		if f.Destination != nil {
+			set.Var(newUint8Value(f.Value, f.Destination), name, f.Usage)
+			continue
+		}
+		set.Var(newUint8Value(f.Value, new(uint8)), name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *Uint8Flag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.Uint8(f.Names()[0]))
+	}
+	return nil
+}
+
+// Uint8 looks up the value of a local Uint8Flag, returns
+// 0 if not found
+func (c *Context) Uint8(name string) uint8 {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupUint8(name, fs)
+	}
+	return 0
+}
+
+func lookupUint8(name string, set *flag.FlagSet) uint8 {
+	f := set.Lookup(name)
+	if f != nil {
+		parsed, err := parseSizedUint(f.Value.String(), 8)
+		if err != nil {
+			return 0
+		}
+		return uint8(parsed)
+	}
+	return 0
+}
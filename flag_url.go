@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// urlValue is the flag.Value for URLFlag. It keeps the original string so
+// that it can still be retrieved with Context.String.
+type urlValue struct {
+	raw         string
+	url         *url.URL
+	schemes     []string
+	requireHost bool
+}
+
+// Set parses and validates the URL
+func (u *urlValue) Set(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	if len(u.schemes) > 0 && !hasName(u.schemes, strings.ToLower(parsed.Scheme)) {
+		return fmt.Errorf("scheme %q is not allowed, expected one of %s", parsed.Scheme, strings.Join(u.schemes, ", "))
+	}
+	if u.requireHost && parsed.Host == "" {
+		return fmt.Errorf("%q has no host", value)
+	}
+
+	u.raw = value
+	u.url = parsed
+	return nil
+}
+
+// String returns the URL as it was given
+func (u *urlValue) String() string {
+	return u.raw
+}
+
+// Get returns the parsed URL
+func (u *urlValue) Get() interface{} {
+	return u.Value()
+}
+
+// Value returns a copy of the parsed URL, or nil if unset
+func (u *urlValue) Value() *url.URL {
+	if u.url == nil {
+		return nil
+	}
+	parsed := *u.url
+	return &parsed
+}
+
+// URLFlag is a flag with type *url.URL
+type URLFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Category    string
+	EnvVars     []string
+	FilePath    string
+	Required    bool
+	Hidden      bool
+	Value       string
+	DefaultText string
+	// Schemes, when not empty, lists the lower case schemes allowed
+	Schemes []string
+	// RequireHost makes URLs without a host an error
+	RequireHost bool
+	// Validator, when set, is run with the value of the flag, a *url.URL, once the
+	// flags are parsed and only if the flag has been set. Its error rejects the
+	// value.
+	Validator  func(interface{}) error
+	HasBeenSet bool
+	// CompletionFunc, when set, returns the candidates for the value of the
+	// flag during shell completion
+	CompletionFunc func(*Context) []string
+	// DocsURL, when set, is the link to the documentation of the flag, see
+	// App.DocsURL
+	DocsURL string
+	// Deprecated, when set, is the message of the warning printed when the
+	// flag is set, like "use --new instead"
+	Deprecated string
+	// Action, when set, is run with the value of the flag once the flags are
+	// parsed, if the flag has been set
+	Action func(*Context, *url.URL) error
+	// RequiredIf, when set, makes the flag required when the condition holds
+	// for the other flags, like FlagIs("mode", "signed")
+	RequiredIf RequiredCondition
+	// RequiredUnless, when set, makes the flag required unless the condition
+	// holds for the other flags
+	RequiredUnless RequiredCondition
+}
+
+// IsSet returns whether or not the flag has been set through env or file
+func (f *URLFlag) IsSet() bool {
+	return f.HasBeenSet
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *URLFlag) String() string {
+	return FlagStringer(f)
+}
+
+// Names returns the names of the flag
+func (f *URLFlag) Names() []string {
+	return flagNames(f.Name, f.Aliases)
+}
+
+// IsRequired returns whether or not the flag is required
+func (f *URLFlag) IsRequired() bool {
+	return f.Required
+}
+
+// TakesValue returns true of the flag takes a value, otherwise false
+func (f *URLFlag) TakesValue() bool {
+	return true
+}
+
+// GetUsage returns the usage string for the flag
+func (f *URLFlag) GetUsage() string {
+	return f.Usage
+}
+
+// GetValue returns the flags value as string representation and an empty
+// string if the flag takes no value at all.
+func (f *URLFlag) GetValue() string {
+	return f.Value
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *URLFlag) Apply(set *flag.FlagSet) error {
+	value := &urlValue{
+		schemes:     f.Schemes,
+		requireHost: f.RequireHost,
+	}
+
+	if f.Value != "" {
+		if err := value.Set(f.Value); err != nil {
+			return fmt.Errorf("could not parse %q as url value for flag %s: %s", f.Value, f.Name, err)
+		}
+	}
+
+	val, ok, err := flagFromEnvOrFile(f.EnvVars, f.FilePath)
+	if err != nil {
+		return fmt.Errorf("could not read file for flag %s: %s", f.Name, err)
+	}
+	if ok {
+		if err := value.Set(val); err != nil {
+			return fmt.Errorf("could not parse %q as url value for flag %s: %s", val, f.Name, err)
+		}
+
+		f.HasBeenSet = true
+	}
+
+	for _, name := range f.Names() {
+		set.Var(value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// RunAction runs the Action of the flag with its value, if it has one
+func (f *URLFlag) RunAction(c *Context) error {
+	if f.Action != nil {
+		return f.Action(c, c.URL(f.Names()[0]))
+	}
+	return nil
+}
+
+// URL looks up the value of a local URLFlag, returns
+// nil if not found
+func (c *Context) URL(name string) *url.URL {
+	if fs := lookupFlagSet(name, c); fs != nil {
+		return lookupURL(name, fs)
+	}
+	return nil
+}
+
+func lookupURL(name string, set *flag.FlagSet) *url.URL {
+	f := set.Lookup(name)
+	if f != nil {
+		if u, ok := f.Value.(*urlValue); ok {
+			return u.Value()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// flagFileFlag makes the flag named name of App.FlagFileFlag
+func flagFileFlag(name string) Flag {
+	return &StringFlag{
+		Name:      name,
+		Usage:     "load flags from `FILE`, one \"name value\" per line",
+		TakesFile: true,
+	}
+}
+
+// applyFlagFile sets the flags listed in the file given to the
+// App.FlagFileFlag flag, if any. The flags given on the command line are left
+// alone so they override the file. It only runs for the root App, whose flags
+// are the only ones set.
+func (a *App) applyFlagFile(set *flag.FlagSet) error {
+	if a.FlagFileFlag == "" {
+		return nil
+	}
+	f := set.Lookup(a.FlagFileFlag)
+	if f == nil || f.Value.String() == "" {
+		return nil
+	}
+	return parseFlagFile(f.Value.String(), a.Flags, set)
+}
+
+// parseFlagFile sets the flags of set listed in the file at path, unless one
+// of their names has been set already. Each line
+// holds a flag name, with or without dashes, and its value separated by
+// spaces, while a boolean flag may be given without a value. Blank lines and
+// lines starting with # are skipped.
+func parseFlagFile(path string, flags []Flag, set *flag.FlagSet) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	visited := make(map[string]bool)
+	set.Visit(func(f *flag.Flag) {
+		visited[f.Name] = true
+	})
+
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			name, value = line[:i], strings.TrimSpace(line[i:])
+		}
+		name = strings.TrimLeft(name, "-")
+
+		f := set.Lookup(name)
+		if f == nil {
+			return fmt.Errorf("%s:%d: flag provided but not defined: -%s", path, lineno, name)
+		}
+		names := []string{name}
+		for _, fl := range flags {
+			if hasName(fl.Names(), name) {
+				names = fl.Names()
+			}
+		}
+		if anyVisited(names, visited) {
+			continue
+		}
+		if value == "" {
+			if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+				value = "true"
+			} else {
+				return fmt.Errorf("%s:%d: flag needs an argument: -%s", path, lineno, name)
+			}
+		}
+		if err := set.Set(name, value); err != nil {
+			return fmt.Errorf("%s:%d: invalid value %q for flag -%s: %s", path, lineno, value, name, err)
+		}
+	}
+	return scanner.Err()
+}
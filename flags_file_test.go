@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFlagFile(t *testing.T, contents string) (string, func()) {
+	dir, err := ioutil.TempDir("", "urfave_cli_flagfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "flags.txt")
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { _ = os.RemoveAll(dir) }
+}
+
+func flagFileTestApp(action ActionFunc) *App {
+	return &App{
+		Name:         "app",
+		FlagFileFlag: "flags-from-file",
+		Flags: []Flag{
+			&StringFlag{Name: "region", Value: "eu"},
+			&IntFlag{Name: "port", Aliases: []string{"p"}},
+			&BoolFlag{Name: "verbose"},
+			&StringSliceFlag{Name: "tag"},
+		},
+		Commands: []*Command{{
+			Name:  "deploy",
+			Flags: []Flag{&BoolFlag{Name: "force"}},
+		}},
+		Writer:    ioutil.Discard,
+		ErrWriter: ioutil.Discard,
+		Action:    action,
+	}
+}
+
+func TestApp_FlagFileFlag(t *testing.T) {
+	path, cleanup := writeFlagFile(t, `
+# deployment defaults
+--region us-east-1
+port 8080
+verbose
+
+tag a
+tag b
+`)
+	defer cleanup()
+
+	actionRan := false
+	err := flagFileTestApp(func(ctx *Context) error {
+		actionRan = true
+		expect(t, ctx.String("region"), "us-east-1")
+		expect(t, ctx.Int("port"), 9090)
+		expect(t, ctx.Int("p"), 9090)
+		expect(t, ctx.Bool("verbose"), true)
+		expect(t, ctx.StringSlice("tag"), []string{"a", "b"})
+		return nil
+	}).Run([]string{"app", "--flags-from-file", path, "-p", "9090"})
+	expect(t, err, nil)
+	expect(t, actionRan, true)
+}
+
+func TestApp_FlagFileFlag_Errors(t *testing.T) {
+	cases := []struct {
+		contents string
+		err      string
+	}{
+		{"region us\n\nmissing 1\n", ":3: flag provided but not defined: -missing"},
+		{"# comment\nport many\n", `:2: invalid value "many" for flag -port`},
+		{"region\n", ":1: flag needs an argument: -region"},
+		// the file only sets the flags of the App
+		{"force\n", ":1: flag provided but not defined: -force"},
+	}
+	for _, c := range cases {
+		path, cleanup := writeFlagFile(t, c.contents)
+
+		actionRan := false
+		err := flagFileTestApp(func(*Context) error {
+			actionRan = true
+			return nil
+		}).Run([]string{"app", "--flags-from-file", path})
+		if err == nil || !strings.Contains(err.Error(), path+c.err) {
+			t.Errorf("expected an error containing %q, got %v", path+c.err, err)
+		}
+		expect(t, actionRan, false)
+
+		cleanup()
+	}
+}
+
+func TestApp_FlagFileFlag_PersistentFlags(t *testing.T) {
+	path, cleanup := writeFlagFile(t, "level 3\n")
+	defer cleanup()
+
+	level := 0
+	app := &App{
+		Name:            "app",
+		FlagFileFlag:    "flags-from-file",
+		PersistentFlags: []Flag{&IntFlag{Name: "level"}},
+		Commands: []*Command{{
+			Name: "deploy",
+			Action: func(ctx *Context) error {
+				level = ctx.Int("level")
+				return nil
+			},
+		}},
+	}
+	expect(t, app.Run([]string{"app", "--flags-from-file", path, "deploy"}), nil)
+	expect(t, level, 3)
+}
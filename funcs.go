@@ -8,12 +8,18 @@ type BashCompleteFunc func(*Context)
 type BeforeFunc func(*Context) error
 
 // AfterFunc is an action to execute after any subcommands are run, but after the
-// subcommand has finished it is run even if Action() panics
+// subcommand has finished it is run even if Action() panics. The error the
+// action returned is available through Context.ActionErr. When commands are
+// nested, the After of the innermost command runs first.
 type AfterFunc func(*Context) error
 
 // ActionFunc is the action to execute when no subcommands are specified
 type ActionFunc func(*Context) error
 
+// ArgsRewriterFunc rewrites the arguments, without the program name, before
+// they are parsed
+type ArgsRewriterFunc func(args []string) []string
+
 // CommandNotFoundFunc is executed if the proper command cannot be found
 type CommandNotFoundFunc func(*Context, string)
 
@@ -42,3 +48,7 @@ type FlagEnvHintFunc func(envVars []string, str string) string
 // FlagFileHintFunc is used by the default FlagStringFunc to annotate flag help
 // with the file path details.
 type FlagFileHintFunc func(filePath, str string) string
+
+// SourceConflictFunc is called with the value of a flag chosen among the
+// disagreeing values of its sources, and the values ignored
+type SourceConflictFunc func(flag string, chosen SourceValue, ignored []SourceValue)
@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 	"text/tabwriter"
 	"text/template"
@@ -44,6 +46,8 @@ var helpSubcommand = &Command{
 // Prints help for the App or Command
 type helpPrinter func(w io.Writer, templ string, data interface{})
 
+type helpAllPrinter func(w io.Writer, command *Command, depth int)
+
 // Prints help for the App or Command with custom template function.
 type helpPrinterCustom func(w io.Writer, templ string, data interface{}, customFunc map[string]interface{})
 
@@ -61,6 +65,11 @@ var HelpPrinter helpPrinter = printHelp
 // the ExtraInfo field is set on an App.
 var HelpPrinterCustom helpPrinterCustom = printHelpCustom
 
+// HelpAllPrinter writes the help of one command for --help-all, indented by
+// its depth in the command tree. The help of the App itself is written by
+// HelpPrinter first.
+var HelpAllPrinter helpAllPrinter = printHelpAll
+
 // VersionPrinter prints the version for the App
 var VersionPrinter = printVersion
 
@@ -72,13 +81,21 @@ func ShowAppHelpAndExit(c *Context, exitCode int) {
 
 // ShowAppHelp is an action that displays the help.
 func ShowAppHelp(c *Context) error {
+	return pageHelp(c, c.App.Writer, func(w io.Writer) error {
+		return showAppHelp(c, w)
+	})
+}
+
+func showAppHelp(c *Context, w io.Writer) error {
+	w = c.App.helpWriter(w)
+
 	template := c.App.CustomAppHelpTemplate
 	if template == "" {
 		template = AppHelpTemplate
 	}
 
 	if c.App.ExtraInfo == nil {
-		HelpPrinter(c.App.Writer, template, c.App)
+		HelpPrinter(w, template, c.App)
 		return nil
 	}
 
@@ -87,7 +104,7 @@ func ShowAppHelp(c *Context) error {
 			"ExtraInfo": c.App.ExtraInfo,
 		}
 	}
-	HelpPrinterCustom(c.App.Writer, template, c.App, customAppData())
+	HelpPrinterCustom(w, template, c.App, customAppData())
 
 	return nil
 }
@@ -132,10 +149,92 @@ func cliArgContains(flagName string) bool {
 }
 
 func printFlagSuggestions(lastArg string, flags []Flag, writer io.Writer) {
+	forEachFlagCompletion(lastArg, flags, cliArgContains, func(completion string, _ Flag) {
+		_, _ = fmt.Fprintln(writer, completion)
+	})
+}
+
+// printChoiceSuggestions prints the Choices of the flag named by lastArg and
+// reports whether there were any
+func printChoiceSuggestions(lastArg string, flags []Flag, writer io.Writer) bool {
+	choices := flagChoices(strings.TrimLeft(lastArg, "-"), flags)
+	for _, choice := range choices {
+		_, _ = fmt.Fprintln(writer, choice)
+	}
+	return len(choices) > 0
+}
+
+// flagChoices returns the Choices of the named flag, if it has any
+func flagChoices(name string, flags []Flag) []string {
+	for _, f := range flags {
+		if sf, ok := f.(*StringFlag); ok && hasName(sf.Names(), name) {
+			return sf.Choices
+		}
+	}
+	return nil
+}
+
+// printFlagValueCompletions prints the candidates returned by the
+// CompletionFunc of the flag preceding the completion, and reports whether
+// there is such a flag
+func printFlagValueCompletions(c *Context, flags []Flag) bool {
+	if len(os.Args) <= 2 {
+		return false
+	}
+	lastArg := os.Args[len(os.Args)-2]
+	if !strings.HasPrefix(lastArg, "-") {
+		return false
+	}
+
+	name := strings.TrimLeft(lastArg, "-")
+	for _, f := range flags {
+		if !hasName(f.Names(), name) {
+			continue
+		}
+		complete := flagCompletionFunc(f)
+		if complete == nil {
+			return false
+		}
+		for _, candidate := range complete(c) {
+			_, _ = fmt.Fprintln(c.App.Writer, candidate)
+		}
+		return true
+	}
+	return false
+}
+
+// flagCompletionFunc returns the CompletionFunc of f, if it takes a value and
+// has one
+func flagCompletionFunc(f Flag) func(*Context) []string {
+	if df, ok := f.(DocGenerationFlag); !ok || !df.TakesValue() {
+		return nil
+	}
+	field := flagValue(f).FieldByName("CompletionFunc")
+	if !field.IsValid() || field.Kind() != reflect.Func || field.IsNil() {
+		return nil
+	}
+	complete, _ := field.Interface().(func(*Context) []string)
+	return complete
+}
+
+// readsFile reports whether the flag named by lastArg is a FileContentsFlag
+func readsFile(lastArg string, flags []Flag) bool {
+	name := strings.TrimLeft(lastArg, "-")
+	for _, f := range flags {
+		if ff, ok := f.(*FileContentsFlag); ok && hasName(ff.Names(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachFlagCompletion calls fn for every flag name that completes lastArg
+// and has not been seen yet.
+func forEachFlagCompletion(lastArg string, flags []Flag, seen func(name string) bool, fn func(completion string, f Flag)) {
 	cur := strings.TrimPrefix(lastArg, "-")
 	cur = strings.TrimPrefix(cur, "-")
 	for _, flag := range flags {
-		if bflag, ok := flag.(*BoolFlag); ok && bflag.Hidden {
+		if isHiddenFlag(flag) {
 			continue
 		}
 		for _, name := range flag.Names() {
@@ -151,9 +250,8 @@ func printFlagSuggestions(lastArg string, flags []Flag, writer io.Writer) {
 				continue
 			}
 			// match if last argument matches this flag and it is not repeated
-			if strings.HasPrefix(name, cur) && cur != name && !cliArgContains(name) {
-				flagCompletion := fmt.Sprintf("%s%s", strings.Repeat("-", count), name)
-				_, _ = fmt.Fprintln(writer, flagCompletion)
+			if strings.HasPrefix(name, cur) && cur != name && !seen(name) {
+				fn(fmt.Sprintf("%s%s", strings.Repeat("-", count), name), flag)
 			}
 		}
 	}
@@ -164,6 +262,16 @@ func DefaultCompleteWithFlags(cmd *Command) func(c *Context) {
 		if len(os.Args) > 2 {
 			lastArg := os.Args[len(os.Args)-2]
 			if strings.HasPrefix(lastArg, "-") {
+				if cmd != nil && printChoiceSuggestions(lastArg, cmd.Flags, c.App.Writer) {
+					return
+				}
+				if printChoiceSuggestions(lastArg, c.App.Flags, c.App.Writer) {
+					return
+				}
+				// leave the completion of file names to the shell
+				if (cmd != nil && readsFile(lastArg, cmd.Flags)) || readsFile(lastArg, c.App.Flags) {
+					return
+				}
 				printFlagSuggestions(lastArg, c.App.Flags, c.App.Writer)
 				if cmd != nil {
 					printFlagSuggestions(lastArg, cmd.Flags, c.App.Writer)
@@ -179,6 +287,83 @@ func DefaultCompleteWithFlags(cmd *Command) func(c *Context) {
 	}
 }
 
+// ShowAllHelp prints the help of the App followed by the help of all of its
+// visible commands and subcommands, or the markdown documentation of the App
+// when its HelpAllFormat is "markdown"
+func ShowAllHelp(c *Context) error {
+	return pageHelp(c, c.App.Writer, func(w io.Writer) error {
+		return showAllHelp(c, w)
+	})
+}
+
+func showAllHelp(c *Context, w io.Writer) error {
+	switch c.App.HelpAllFormat {
+	case "", "text":
+	case "markdown":
+		md, err := c.App.ToMarkdown()
+		if err != nil {
+			return err
+		}
+		_, _ = io.WriteString(w, md)
+		return nil
+	default:
+		return fmt.Errorf("unknown help format %q, expected text or markdown", c.App.HelpAllFormat)
+	}
+
+	w = c.App.helpWriter(w)
+	if err := showAppHelp(c, w); err != nil {
+		return err
+	}
+
+	showCommandsHelp(w, c.App.HelpName, c.App.Commands, 1, map[*Command]bool{})
+	return nil
+}
+
+// showCommandsHelp walks the command tree depth first, skipping hidden
+// commands and the commands already on the current path
+func showCommandsHelp(w io.Writer, parent string, commands []*Command, depth int, path map[*Command]bool) {
+	for _, cmd := range commands {
+		if cmd.Hidden || path[cmd] || cmd == helpCommand || cmd == helpSubcommand {
+			continue
+		}
+
+		command := *cmd
+		command.HelpName = fmt.Sprintf("%s %s", parent, cmd.Name)
+		_, _ = fmt.Fprintln(w)
+		HelpAllPrinter(w, &command, depth)
+
+		path[cmd] = true
+		showCommandsHelp(w, command.HelpName, cmd.Subcommands, depth+1, path)
+		delete(path, cmd)
+	}
+}
+
+func printHelpAll(w io.Writer, command *Command, depth int) {
+	indent := strings.Repeat("   ", depth)
+
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	if width := writerWidth(w) - len(indent); width > 0 {
+		out = &widthWriter{Writer: out, width: width}
+	}
+
+	templ := command.CustomHelpTemplate
+	if templ == "" {
+		templ = CommandHelpTemplate
+	}
+	_, _ = fmt.Fprintf(out, "=== %s ===\n\n", command.HelpName)
+	HelpPrinter(out, templ, command)
+
+	help := strings.TrimRight(buf.String(), " \n") + "\n"
+	for _, line := range strings.SplitAfter(help, "\n") {
+		if strings.TrimSpace(line) == "" {
+			_, _ = io.WriteString(w, strings.TrimLeft(line, " \t"))
+			continue
+		}
+		_, _ = io.WriteString(w, indent+line)
+	}
+}
+
 // ShowCommandHelpAndExit - exits with code after showing help
 func ShowCommandHelpAndExit(c *Context, command string, code int) {
 	_ = ShowCommandHelp(c, command)
@@ -187,9 +372,17 @@ func ShowCommandHelpAndExit(c *Context, command string, code int) {
 
 // ShowCommandHelp prints help for the given command
 func ShowCommandHelp(ctx *Context, command string) error {
+	return pageHelp(ctx, ctx.App.Writer, func(w io.Writer) error {
+		return showCommandHelp(ctx, command, w)
+	})
+}
+
+func showCommandHelp(ctx *Context, command string, w io.Writer) error {
+	w = ctx.App.helpWriter(w)
+
 	// show the subcommand help for a command with subcommands
 	if command == "" {
-		HelpPrinter(ctx.App.Writer, SubcommandHelpTemplate, ctx.App)
+		HelpPrinter(w, SubcommandHelpTemplate, ctx.App)
 		return nil
 	}
 
@@ -200,14 +393,15 @@ func ShowCommandHelp(ctx *Context, command string) error {
 				templ = CommandHelpTemplate
 			}
 
-			HelpPrinter(ctx.App.Writer, templ, c)
+			HelpPrinter(w, templ, c)
 
 			return nil
 		}
 	}
 
 	if ctx.App.CommandNotFound == nil {
-		return Exit(fmt.Sprintf("No help topic for '%v'", command), 3)
+		suggestion := didYouMean(suggestCommand(ctx.App.Commands, command))
+		return Exit(fmt.Sprintf("No help topic for '%v'%s", command, suggestion), 3)
 	}
 
 	ctx.App.CommandNotFound(ctx, command)
@@ -220,11 +414,19 @@ func ShowSubcommandHelp(c *Context) error {
 		return nil
 	}
 
+	return pageHelp(c, c.App.Writer, func(w io.Writer) error {
+		return showSubcommandHelp(c, w)
+	})
+}
+
+func showSubcommandHelp(c *Context, w io.Writer) error {
+	w = c.App.helpWriter(w)
+
 	if c.Command != nil {
-		return ShowCommandHelp(c, c.Command.Name)
+		return showCommandHelp(c, c.Command.Name, w)
 	}
 
-	return ShowCommandHelp(c, "")
+	return showCommandHelp(c, "", w)
 }
 
 // ShowVersion prints the version number of the App
@@ -239,6 +441,9 @@ func printVersion(c *Context) {
 // ShowCompletions prints the lists of commands within a given context
 func ShowCompletions(c *Context) {
 	a := c.App
+	if a != nil && printFlagValueCompletions(c, a.Flags) {
+		return
+	}
 	if a != nil && a.BashComplete != nil {
 		a.BashComplete(c)
 	}
@@ -248,6 +453,9 @@ func ShowCompletions(c *Context) {
 func ShowCommandCompletions(ctx *Context, command string) {
 	c := ctx.App.Command(command)
 	if c != nil {
+		if printFlagValueCompletions(ctx, c.Flags) || printFlagValueCompletions(ctx, ctx.App.Flags) {
+			return
+		}
 		if c.BashComplete != nil {
 			c.BashComplete(ctx)
 		} else {
@@ -262,8 +470,15 @@ func ShowCommandCompletions(ctx *Context, command string) {
 // The customFuncs map will be combined with a default template.FuncMap to
 // allow using arbitrary functions in template rendering.
 func printHelpCustom(out io.Writer, templ string, data interface{}, customFuncs map[string]interface{}) {
+	wrapAt := writerWidth(out)
 	funcMap := template.FuncMap{
 		"join": strings.Join,
+		"wrap": func(input string, offset int) string {
+			return wrap(input, offset, wrapAt)
+		},
+		"flagLines": func(flags []Flag, indent int, alignWith ...[]Flag) []string {
+			return flagLines(flags, indent, wrapAt, alignWith...)
+		},
 	}
 	for key, value := range customFuncs {
 		funcMap[key] = value
@@ -308,6 +523,20 @@ func checkHelp(c *Context) bool {
 	return found
 }
 
+func checkHelpAll(c *Context) bool {
+	if HelpAllFlag == nil {
+		return false
+	}
+
+	found := false
+	for _, name := range HelpAllFlag.Names() {
+		if c.Bool(name) {
+			found = true
+		}
+	}
+	return found
+}
+
 func checkCommandHelp(c *Context, name string) bool {
 	if c.Bool("h") || c.Bool("help") {
 		_ = ShowCommandHelp(c, name)
@@ -366,3 +595,161 @@ func checkCommandCompletions(c *Context, name string) bool {
 	ShowCommandCompletions(c, name)
 	return true
 }
+
+// flagLines renders flags for help as aligned columns of short names, long
+// names with their placeholder and descriptions, the latter wrapped at
+// wrapAt. The column widths are computed over alignWith, or flags when it is
+// empty, so that several lists of flags can share them. A custom FlagStringer
+// is used as is instead.
+func flagLines(flags []Flag, indent int, wrapAt int, alignWith ...[]Flag) []string {
+	padding := strings.Repeat(" ", indent)
+	if reflect.ValueOf(FlagStringer).Pointer() != reflect.ValueOf(stringifyFlag).Pointer() {
+		var lines []string
+		for _, f := range flags {
+			lines = append(lines, padding+FlagStringer(f))
+		}
+		return lines
+	}
+
+	if len(alignWith) == 0 {
+		alignWith = [][]Flag{flags}
+	}
+	// flags without long names span both name columns
+	shortWidth, longWidth, spanWidth := 0, 0, 0
+	for _, fl := range alignWith {
+		for _, f := range fl {
+			short, long := flagNameColumns(f)
+			if long == "" {
+				if n := utf8.RuneCountInString(short); n > spanWidth {
+					spanWidth = n
+				}
+				continue
+			}
+			if n := utf8.RuneCountInString(short); n > shortWidth {
+				shortWidth = n
+			}
+			if n := utf8.RuneCountInString(long); n > longWidth {
+				longWidth = n
+			}
+		}
+	}
+	if shortWidth > 0 && spanWidth > shortWidth+1+longWidth {
+		longWidth = spanWidth - shortWidth - 1
+	} else if shortWidth == 0 && spanWidth > longWidth {
+		longWidth = spanWidth
+	}
+
+	offset := indent + longWidth + 2
+	if shortWidth > 0 {
+		offset += shortWidth + 1
+	}
+
+	var lines []string
+	for _, f := range flags {
+		short, long := flagNameColumns(f)
+
+		line := padding
+		switch {
+		case long == "" && shortWidth > 0:
+			line += padRight(short, shortWidth+1+longWidth) + "  "
+		case long == "":
+			line += padRight(short, longWidth) + "  "
+		case shortWidth > 0:
+			line += padRight(short, shortWidth) + " " + padRight(long, longWidth) + "  "
+		default:
+			line += padRight(long, longWidth) + "  "
+		}
+
+		description := ""
+		if parts := strings.SplitN(FlagStringer(f), "\t", 2); len(parts) == 2 {
+			description = parts[1]
+		}
+		line += wrap(description, offset, wrapAt)
+
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+// flagNameColumns returns the short names and the long names of a flag as
+// shown in help. The placeholder follows the long names, or the short names
+// of a flag without long names.
+func flagNameColumns(f Flag) (short, long string) {
+	var shorts, longs []string
+	for _, name := range f.Names() {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if len(name) == 1 {
+			shorts = append(shorts, prefixFor(name)+name)
+		} else {
+			longs = append(longs, prefixFor(name)+name)
+		}
+	}
+
+	placeholder := flagPlaceholder(f)
+	short = strings.Join(shorts, ", ")
+	long = strings.Join(longs, ", ")
+	switch {
+	case len(longs) > 0 && len(shorts) > 0:
+		short += ","
+		fallthrough
+	case len(longs) > 0:
+		if placeholder != "" {
+			long += " " + placeholder
+		}
+	case placeholder != "":
+		short += " " + placeholder
+	}
+	return short, long
+}
+
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// wrap word wraps input so that lines indented by offset columns fit within
+// wrapAt columns. Input is returned unchanged when wrapAt is 0.
+func wrap(input string, offset int, wrapAt int) string {
+	if wrapAt <= offset {
+		return input
+	}
+
+	padding := strings.Repeat(" ", offset)
+	lines := strings.Split(input, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(line, wrapAt-offset, padding)
+	}
+
+	return strings.Join(lines, "\n"+padding)
+}
+
+func wrapLine(line string, width int, padding string) string {
+	if utf8.RuneCountInString(line) <= width {
+		return line
+	}
+
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return line
+	}
+
+	wrapped := words[0]
+	spaceLeft := width - utf8.RuneCountInString(wrapped)
+	for _, word := range words[1:] {
+		wordLen := utf8.RuneCountInString(word)
+		if wordLen+1 > spaceLeft {
+			wrapped += "\n" + padding + word
+			spaceLeft = width - wordLen
+		} else {
+			wrapped += " " + word
+			spaceLeft -= 1 + wordLen
+		}
+	}
+
+	return wrapped
+}
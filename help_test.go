@@ -9,6 +9,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func Test_ShowAppHelp_NoAuthor(t *testing.T) {
@@ -411,6 +412,40 @@ func TestShowCommandHelp_CommandAliases(t *testing.T) {
 	}
 }
 
+func TestShowCommandHelp_HiddenFlag(t *testing.T) {
+	var dump string
+	app := &App{
+		Commands: []*Command{
+			{
+				Name: "frobbly",
+				Flags: []Flag{
+					&StringFlag{Name: "debug-dump", Hidden: true},
+					&BoolFlag{Name: "loud"},
+				},
+				Action: func(ctx *Context) error {
+					dump = ctx.String("debug-dump")
+					return nil
+				},
+			},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	app.Writer = output
+	_ = app.Run([]string{"foo", "help", "frobbly"})
+
+	if !strings.Contains(output.String(), "--loud") {
+		t.Errorf("expected output to include the visible flag; got: %q", output.String())
+	}
+	if strings.Contains(output.String(), "debug-dump") {
+		t.Errorf("expected output to exclude the hidden flag; got: %q", output.String())
+	}
+
+	err := app.Run([]string{"foo", "frobbly", "--debug-dump", "all"})
+	expect(t, err, nil)
+	expect(t, dump, "all")
+}
+
 func TestShowSubcommandHelp_CommandAliases(t *testing.T) {
 	app := &App{
 		Commands: []*Command{
@@ -907,3 +942,349 @@ func TestHideHelpCommand_WithSubcommands(t *testing.T) {
 		t.Errorf("Run returned unexpected error: %v", err)
 	}
 }
+
+func TestHelpToStdoutOnUsageError(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		toStdout   bool
+		wantOut    bool
+		wantErrOut bool
+	}{
+		{"app help flag", []string{"foo", "-h"}, false, true, false},
+		{"app help command", []string{"foo", "help"}, false, true, false},
+		{"command help flag", []string{"foo", "cmd", "--help"}, false, true, false},
+		{"app usage error", []string{"foo", "--nope"}, false, false, true},
+		{"command usage error", []string{"foo", "cmd", "--nope"}, false, false, true},
+		{"subcommand usage error", []string{"foo", "parent", "--nope"}, false, false, true},
+		{"app required flag", []string{"foo", "req"}, false, false, true},
+		{"usage error to writer", []string{"foo", "--nope"}, true, true, false},
+		{"command usage error to writer", []string{"foo", "cmd", "--nope"}, true, true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+
+			app := &App{
+				Writer:                   out,
+				ErrWriter:                errOut,
+				HelpToStdoutOnUsageError: test.toStdout,
+				Commands: []*Command{
+					{Name: "cmd", Action: func(*Context) error { return nil }},
+					{
+						Name:        "parent",
+						Subcommands: []*Command{{Name: "child"}},
+					},
+					{
+						Name:   "req",
+						Flags:  []Flag{&StringFlag{Name: "needed", Required: true}},
+						Action: func(*Context) error { return nil },
+					},
+				},
+			}
+
+			_ = app.Run(test.args)
+
+			if gotOut := out.Len() > 0; gotOut != test.wantOut {
+				t.Errorf("expected output on Writer to be %v, got %q", test.wantOut, out.String())
+			}
+			if gotErrOut := errOut.Len() > 0; gotErrOut != test.wantErrOut {
+				t.Errorf("expected output on ErrWriter to be %v, got %q", test.wantErrOut, errOut.String())
+			}
+			if test.wantErrOut && !strings.Contains(errOut.String(), "USAGE:") {
+				t.Errorf("expected help on ErrWriter, got %q", errOut.String())
+			}
+		})
+	}
+}
+
+func TestNewApp_UsageErrorToErrWriter(t *testing.T) {
+	// the zero value of an App and NewApp agree
+	expect(t, NewApp().HelpToStdoutOnUsageError, false)
+	expect(t, (&App{ErrWriter: ioutil.Discard}).usageErrWriter(), ioutil.Discard)
+}
+
+func helpAllTestApp() *App {
+	return &App{
+		Name:          "ship",
+		HelpName:      "ship",
+		Usage:         "ships things",
+		EnableHelpAll: true,
+		Commands: []*Command{
+			{
+				Name:  "deploy",
+				Usage: "deploy things",
+				Flags: []Flag{
+					&StringFlag{Name: "region", Usage: "where to deploy"},
+					&BoolFlag{Name: "yolo", Hidden: true},
+				},
+				Subcommands: []*Command{
+					{Name: "rollback", Usage: "undo a deploy"},
+					{Name: "internal", Hidden: true},
+				},
+			},
+			{Name: "status", Usage: "show status"},
+		},
+	}
+}
+
+func TestShowAllHelp(t *testing.T) {
+	app := helpAllTestApp()
+	output := &bytes.Buffer{}
+	app.Writer = output
+
+	err := app.Run([]string{"ship", "--help-all"})
+	expect(t, err, nil)
+	expectFileContent(t, "testdata/expected-help-all.txt", output.String())
+}
+
+func TestShowAllHelp_Markdown(t *testing.T) {
+	app := helpAllTestApp()
+	app.HelpAllFormat = "markdown"
+	output := &bytes.Buffer{}
+	app.Writer = output
+
+	err := app.Run([]string{"ship", "--help-all"})
+	expect(t, err, nil)
+
+	expected, _ := app.ToMarkdown()
+	expect(t, output.String(), expected)
+	if strings.Contains(expected, "yolo") || strings.Contains(expected, "internal") {
+		t.Errorf("expected markdown to exclude hidden flags and commands; got: %s", expected)
+	}
+
+	app = helpAllTestApp()
+	app.HelpAllFormat = "html"
+	app.Writer = ioutil.Discard
+	err = app.Run([]string{"ship", "--help-all"})
+	if err == nil || !strings.Contains(err.Error(), `unknown help format "html"`) {
+		t.Errorf("expected an unknown format error, got %v", err)
+	}
+}
+
+func TestShowAllHelp_NotEnabled(t *testing.T) {
+	app := helpAllTestApp()
+	app.EnableHelpAll = false
+	app.Writer = ioutil.Discard
+	app.ErrWriter = ioutil.Discard
+
+	err := app.Run([]string{"ship", "--help-all"})
+	if err == nil || !strings.Contains(err.Error(), "flag provided but not defined: -help-all") {
+		t.Errorf("expected --help-all to be undefined, got %v", err)
+	}
+}
+
+func TestShowAllHelp_Cycle(t *testing.T) {
+	deploy := &Command{Name: "deploy"}
+	// a command listing itself must not loop forever
+	deploy.Subcommands = []*Command{deploy}
+
+	app := &App{
+		Name:          "ship",
+		HelpName:      "ship",
+		EnableHelpAll: true,
+		Commands:      []*Command{deploy},
+	}
+	output := &bytes.Buffer{}
+	app.Writer = output
+
+	err := app.Run([]string{"ship", "--help-all"})
+	expect(t, err, nil)
+	expect(t, strings.Count(output.String(), "=== ship deploy ==="), 1)
+}
+
+func TestShowAllHelp_HelpAllPrinter(t *testing.T) {
+	oldPrinter := HelpAllPrinter
+	defer func() {
+		HelpAllPrinter = oldPrinter
+	}()
+
+	HelpAllPrinter = func(w io.Writer, command *Command, depth int) {
+		fmt.Fprintf(w, "%d %s", depth, command.HelpName)
+	}
+
+	app := &App{
+		Name:          "ship",
+		HelpName:      "ship",
+		EnableHelpAll: true,
+		Commands: []*Command{
+			{Name: "deploy", Subcommands: []*Command{{Name: "rollback"}}},
+		},
+		HideHelpCommand: true,
+	}
+
+	output := &bytes.Buffer{}
+	app.Writer = output
+	_ = app.Run([]string{"ship", "--help-all"})
+
+	if !strings.HasSuffix(output.String(), "\n1 ship deploy\n2 ship deploy rollback") {
+		t.Errorf("expected the custom printer to be used; got: %q", output.String())
+	}
+}
+
+func TestShowAppHelp_FlagCategories(t *testing.T) {
+	app := &App{
+		Name:     "app",
+		HelpName: "app",
+		Flags: []Flag{
+			&StringFlag{Name: "port", Category: "network", Usage: "port"},
+			&BoolFlag{Name: "verbose", Usage: "talk more"},
+			&StringFlag{Name: "host", Category: "network", Usage: "host"},
+			&StringFlag{Name: "cache-dir", Category: "cache", Usage: "cache"},
+			&StringFlag{Name: "secret", Category: "auth", Hidden: true},
+			&BoolFlag{Name: "debug", Usage: "debug"},
+		},
+		HideHelp: true,
+	}
+
+	output := &bytes.Buffer{}
+	app.Writer = output
+	_ = ShowAppHelp(NewContext(app, flag.NewFlagSet("app", 0), nil))
+
+	expected := `GLOBAL OPTIONS:
+   MISC:
+     --verbose          talk more (default: false)
+     --debug            debug (default: false)
+   cache:
+     --cache-dir value  cache
+   network:
+     --port value       port
+     --host value       host
+`
+	if !strings.HasSuffix(output.String(), expected) {
+		t.Errorf("expected flags grouped by category; got: %q", output.String())
+	}
+
+	app.KeepFlagCategoryOrder = true
+	output.Reset()
+	_ = ShowAppHelp(NewContext(app, flag.NewFlagSet("app", 0), nil))
+
+	expected = `GLOBAL OPTIONS:
+   MISC:
+     --verbose          talk more (default: false)
+     --debug            debug (default: false)
+   network:
+     --port value       port
+     --host value       host
+   cache:
+     --cache-dir value  cache
+`
+	if !strings.HasSuffix(output.String(), expected) {
+		t.Errorf("expected categories in the order they are first seen; got: %q", output.String())
+	}
+}
+
+func TestShowCommandHelp_FlagCategories(t *testing.T) {
+	app := &App{
+		Commands: []*Command{
+			{
+				Name: "serve",
+				Flags: []Flag{
+					&IntFlag{Name: "port", Category: "network", Usage: "port to listen on"},
+					&BoolFlag{Name: "quiet", Usage: "talk less"},
+				},
+			},
+			{
+				Name: "plain",
+				Flags: []Flag{
+					&IntFlag{Name: "port", Usage: "port to listen on"},
+					&BoolFlag{Name: "quiet", Usage: "talk less"},
+				},
+			},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	app.Writer = output
+	_ = app.Run([]string{"app", "help", "serve"})
+
+	if !strings.HasSuffix(output.String(), "OPTIONS:\n   MISC:\n     --quiet       talk less (default: false)\n   network:\n     --port value  port to listen on (default: 0)\n") {
+		t.Errorf("expected command flags grouped by category; got: %q", output.String())
+	}
+
+	output.Reset()
+	_ = app.Run([]string{"app", "help", "plain"})
+	if !strings.HasSuffix(output.String(), "OPTIONS:\n   --port value  port to listen on (default: 0)\n   --quiet       talk less (default: false)\n") {
+		t.Errorf("expected uncategorized flags in declaration order; got: %q", output.String())
+	}
+
+	app = &App{
+		KeepFlagCategoryOrder: true,
+		Commands: []*Command{
+			{
+				Name: "serve",
+				Flags: []Flag{
+					&IntFlag{Name: "port", Category: "network", Usage: "port to listen on"},
+					&BoolFlag{Name: "quiet", Usage: "talk less"},
+					&BoolFlag{Name: "tls", Category: "auth", Usage: "serve over TLS"},
+					&StringFlag{Name: "host", Category: "network", Usage: "host to listen on"},
+				},
+			},
+		},
+	}
+	output.Reset()
+	app.Writer = output
+	_ = app.Run([]string{"app", "help", "serve"})
+	if !strings.HasSuffix(output.String(), "OPTIONS:\n   MISC:\n     --quiet       talk less (default: false)\n   network:\n     --port value  port to listen on (default: 0)\n     --host value  host to listen on\n   auth:\n     --tls         serve over TLS (default: false)\n") {
+		t.Errorf("expected command flag categories in the order they are first seen; got: %q", output.String())
+	}
+}
+
+func TestShowAppHelp_FlagColumns(t *testing.T) {
+	app := &App{
+		Name:     "app",
+		HelpName: "app",
+		Usage:    "aligns flags",
+		Flags: []Flag{
+			&BoolFlag{Name: "x", Usage: "short only"},
+			&IntFlag{Name: "n", Usage: "short only with a value"},
+			&BoolFlag{Name: "dry-run", Usage: "long only"},
+			&StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "write to `FILE`"},
+			&StringFlag{Name: "config", Usage: "load the `VERY_LONG_PLACEHOLDER_NAME` configuration file"},
+			&StringFlag{
+				Name:    "level",
+				Aliases: []string{"l"},
+				Usage:   "a long description which continues at the description column once wrapped",
+			},
+		},
+		HideHelp:    true,
+		HideVersion: true,
+		Terminal:    &fakeTerminal{width: 90},
+	}
+
+	output := &bytes.Buffer{}
+	app.Writer = output
+	_ = ShowAppHelp(NewContext(app, flag.NewFlagSet("app", 0), nil))
+
+	expectFileContent(t, "testdata/expected-flag-columns.txt", output.String())
+}
+
+func TestShowAppHelp_HumanizedDefaults(t *testing.T) {
+	app := &App{
+		Name:     "app",
+		HelpName: "app",
+		Usage:    "humanizes defaults",
+		Flags: []Flag{
+			&ByteSizeFlag{Name: "max-upload", Value: 1048576, Usage: "largest upload"},
+			&ByteSizeFlag{Name: "max-upload-raw", Value: 1048576, RawDefault: true, Usage: "largest upload, in bytes"},
+			&DurationFlag{Name: "timeout", Value: 90 * time.Minute, Usage: "time to wait"},
+			&DurationFlag{Name: "timeout-raw", Value: 30 * time.Second, RawDefault: true, Usage: "time to wait, in nanoseconds"},
+			&IntFlag{Name: "chunk", Value: 65536, Unit: "bytes", HumanizeDefault: true, Usage: "size of a chunk"},
+			&IntFlag{Name: "chunk-raw", Value: 65536, Unit: "bytes", Usage: "size of a chunk"},
+			&Int64Flag{Name: "ttl", Value: 3600, Unit: "seconds", HumanizeDefault: true, Usage: "time to live"},
+			&UintFlag{Name: "delay", Value: 1500, Unit: "milliseconds", HumanizeDefault: true, Usage: "delay between retries"},
+			&IntFlag{Name: "workers", Value: 4, Unit: "threads", HumanizeDefault: true, Usage: "number of workers"},
+		},
+		HideHelp:    true,
+		HideVersion: true,
+		Terminal:    &fakeTerminal{width: 100},
+	}
+
+	output := &bytes.Buffer{}
+	app.Writer = output
+	_ = ShowAppHelp(NewContext(app, flag.NewFlagSet("app", 0), nil))
+
+	expectFileContent(t, "testdata/expected-help-humanized.txt", output.String())
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HistoryFilter reports whether a line should be kept out of a History
+type HistoryFilter func(line string) bool
+
+// History keeps the lines entered in an interactive session so that they can
+// be recalled later. It is safe to use the zero value, which is only kept in
+// memory until a Path is set and Save is called.
+type History struct {
+	// Path of the history file, see DefaultHistoryPath
+	Path string
+	// MaxEntries is the number of most recent entries kept, 0 keeps all of
+	// them
+	MaxEntries int
+	// Filters keep lines out of the history, typically because they contain
+	// secrets
+	Filters []HistoryFilter
+
+	entries []string
+}
+
+// DefaultHistoryPath returns the default location of the history file of the
+// named app, under the user cache directory
+func DefaultHistoryPath(appName string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName, "history"), nil
+}
+
+// SensitiveFlagsFilter returns a HistoryFilter matching the lines which set
+// any of the named flags
+func SensitiveFlagsFilter(names ...string) HistoryFilter {
+	return func(line string) bool {
+		for _, field := range strings.Fields(line) {
+			if field == "--" {
+				return false
+			}
+			if !strings.HasPrefix(field, "-") {
+				continue
+			}
+			name := strings.SplitN(strings.TrimLeft(field, "-"), "=", 2)[0]
+			if hasName(names, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Add appends line to the history, unless it is blank, equal to the previous
+// entry or matched by one of the Filters. It reports whether the line was
+// added.
+func (h *History) Add(line string) bool {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" || strings.ContainsAny(line, "\r\n") {
+		return false
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == line {
+		return false
+	}
+	for _, filter := range h.Filters {
+		if filter(line) {
+			return false
+		}
+	}
+
+	h.entries = append(h.entries, line)
+	h.trim()
+	return true
+}
+
+// Entries returns a copy of the entries, oldest first
+func (h *History) Entries() []string {
+	return append([]string{}, h.entries...)
+}
+
+// Load replaces the entries with the contents of the history file. A missing
+// file is not an error.
+func (h *History) Load() error {
+	f, err := os.Open(h.Path)
+	if os.IsNotExist(err) {
+		h.entries = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h.entries = nil
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.Add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// Save writes the entries to the history file, creating its directory if
+// needed. The file is only readable by the current user.
+func (h *History) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0700); err != nil {
+		return err
+	}
+
+	var data []byte
+	for _, line := range h.entries {
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+	return ioutil.WriteFile(h.Path, data, 0600)
+}
+
+func (h *History) trim() {
+	if h.MaxEntries > 0 && len(h.entries) > h.MaxEntries {
+		h.entries = append([]string{}, h.entries[len(h.entries)-h.MaxEntries:]...)
+	}
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistory_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urfave_cli_history")
+	expect(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	h := &History{
+		Path:       filepath.Join(dir, "app", "history"),
+		MaxEntries: 3,
+		Filters: []HistoryFilter{
+			SensitiveFlagsFilter("password", "p"),
+			func(line string) bool { return strings.HasPrefix(line, " ") },
+		},
+	}
+
+	expect(t, h.Add("login --user bob"), true)
+	expect(t, h.Add("login --user bob"), false)
+	expect(t, h.Add("login --password=hunter2"), false)
+	expect(t, h.Add("login -p hunter2"), false)
+	expect(t, h.Add("echo -- --password"), true)
+	expect(t, h.Add(" secret"), false)
+	expect(t, h.Add(""), false)
+	expect(t, h.Add("status"), true)
+	expect(t, h.Add("deploy"), true)
+	expect(t, h.Entries(), []string{"echo -- --password", "status", "deploy"})
+
+	expect(t, h.Save(), nil)
+
+	reloaded := &History{Path: h.Path, MaxEntries: 2}
+	expect(t, reloaded.Load(), nil)
+	expect(t, reloaded.Entries(), []string{"status", "deploy"})
+}
+
+func TestHistory_LoadMissingFile(t *testing.T) {
+	h := &History{Path: filepath.Join(os.TempDir(), "urfave_cli_missing", "history")}
+	expect(t, h.Load(), nil)
+	expect(t, len(h.Entries()), 0)
+}
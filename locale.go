@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NumberParser converts a number written in a locale specific form, like
+// "0,5", to the form understood by strconv, like "0.5". It returns false when
+// s is not in that form, in which case the standard parsing applies.
+type NumberParser func(s string) (string, bool)
+
+// BoolParser parses a boolean written in a locale specific form, like "ja".
+// It returns false as its second value when s is not in that form, in which
+// case the standard parsing applies.
+type BoolParser func(s string) (value bool, ok bool)
+
+// CommaDecimalNumberParser is a NumberParser for numbers using a comma as the
+// decimal separator and dots or spaces to group digits, like "1.234,5"
+func CommaDecimalNumberParser(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if strings.Count(s, ",") != 1 {
+		return "", false
+	}
+
+	n := strings.NewReplacer(".", "", " ", "", "\u00a0", "", ",", ".").Replace(s)
+	if _, err := strconv.ParseFloat(n, 64); err != nil {
+		return "", false
+	}
+	return n, true
+}
+
+// parseNumber converts s with parse, if any, leaving it as is when parse
+// fails or s is serialized
+func parseNumber(parse NumberParser, s string) string {
+	if parse == nil || strings.HasPrefix(s, slPfx) {
+		return s
+	}
+	if n, ok := parse(s); ok {
+		return n
+	}
+	return s
+}
+
+// parseBool parses s with parse, if any, else with strconv
+func parseBool(parse BoolParser, s string) (bool, error) {
+	if parse != nil {
+		if v, ok := parse(s); ok {
+			return v, nil
+		}
+	}
+	return strconv.ParseBool(s)
+}
+
+// localeParsers holds the NumberParser and BoolParser of the App for the flags
+// embedding it, which parse their values from the environment or a file with
+// them
+type localeParsers struct {
+	numberParser NumberParser
+	boolParser   BoolParser
+}
+
+func (p *localeParsers) useLocaleParsers(numbers NumberParser, bools BoolParser) {
+	p.numberParser = numbers
+	p.boolParser = bools
+}
+
+// useLocaleParsers makes the flags embedding localeParsers parse their values
+// from the environment or a file with numbers and bools
+func useLocaleParsers(flags []Flag, numbers NumberParser, bools BoolParser) {
+	for _, f := range flags {
+		if lf, ok := f.(interface {
+			useLocaleParsers(NumberParser, BoolParser)
+		}); ok {
+			lf.useLocaleParsers(numbers, bools)
+		}
+	}
+}
+
+// numberValue converts the values of a numeric flag.Value with a NumberParser
+// before they are parsed
+type numberValue struct {
+	flag.Value
+	parse NumberParser
+}
+
+// Set converts the value and sets it
+func (v *numberValue) Set(s string) error {
+	return v.Value.Set(parseNumber(v.parse, s))
+}
+
+// String returns the value of the wrapped flag.Value, or "" for the zero
+// numberValue the flag package builds when it prints the defaults
+func (v *numberValue) String() string {
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+// Get returns the value of the wrapped flag.Value
+func (v *numberValue) Get() interface{} {
+	if getter, ok := v.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return nil
+}
+
+// setLocaleParsers makes the numeric and bool flags of set parse their values
+// from the command line with numbers and bools first
+func setLocaleParsers(set *flag.FlagSet, numbers NumberParser, bools BoolParser) {
+	if numbers == nil && bools == nil {
+		return
+	}
+
+	set.VisitAll(func(f *flag.Flag) {
+		switch v := f.Value.(type) {
+		case *boolValue:
+			v.parseBool = bools
+		case *countValue:
+			v.parseBool = bools
+		case *Float64Slice:
+			v.parseNumber = numbers
+		case *Float32Slice:
+			v.parseNumber = numbers
+		case *float32Value, *int8Value, *int16Value, *int32Value, *uint8Value, *uint16Value, *uint32Value:
+			if numbers != nil {
+				f.Value = &numberValue{Value: v, parse: numbers}
+			}
+		case flag.Getter:
+			if numbers != nil && isStdNumberValue(v) {
+				f.Value = &numberValue{Value: v, parse: numbers}
+			}
+		}
+	})
+}
+
+// isStdNumberValue returns whether v is one of the numeric values of the flag
+// package, as used by IntFlag or Float64Flag, while the sized ones of Int8Flag
+// and the like are handled by setLocaleParsers itself
+func isStdNumberValue(v flag.Getter) bool {
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Ptr || t.Elem().PkgPath() != "flag" {
+		return false
+	}
+
+	switch v.Get().(type) {
+	case int, int64, uint, uint64, float64:
+		return true
+	}
+	return false
+}
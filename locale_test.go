@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func localeTestApp(numbers NumberParser, bools BoolParser, action ActionFunc) *App {
+	flags := []Flag{
+		&Float64Flag{Name: "threshold", Aliases: []string{"t"}},
+		&Float64SliceFlag{Name: "weight"},
+		&IntFlag{Name: "count"},
+		&BoolFlag{Name: "enabled", Negatable: true},
+	}
+	return &App{
+		Name:         "app",
+		Writer:       ioutil.Discard,
+		ErrWriter:    ioutil.Discard,
+		NumberParser: numbers,
+		BoolParser:   bools,
+		Flags:        flags,
+		Action:       action,
+		Commands: []*Command{{
+			Name:   "sub",
+			Flags:  flags,
+			Action: action,
+		}},
+	}
+}
+
+func TestCommaDecimalNumberParser(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+		ok       bool
+	}{
+		{in: "0,5", expected: "0.5", ok: true},
+		{in: "-1.234,5", expected: "-1234.5", ok: true},
+		{in: "1 234,25", expected: "1234.25", ok: true},
+		{in: "1.5"},
+		{in: "1,2,3"},
+		{in: "a,b"},
+	}
+
+	for _, test := range tests {
+		n, ok := CommaDecimalNumberParser(test.in)
+		expect(t, ok, test.ok)
+		expect(t, n, test.expected)
+	}
+}
+
+func TestLocaleParsers(t *testing.T) {
+	ja := func(s string) (bool, bool) {
+		switch s {
+		case "ja":
+			return true, true
+		case "nein":
+			return false, true
+		}
+		return false, false
+	}
+
+	for _, args := range [][]string{
+		{"app", "--threshold", "0,5", "--weight", `0\,25`, "--weight", `1.234\,5`, "--count", "3", "--enabled=ja"},
+		{"app", "sub", "-t", "0,5", "--weight", `0\,25,1.234\,5`, "--count", "3", "--no-enabled=nein"},
+	} {
+		var threshold float64
+		var weights []float64
+		var count int
+		var enabled bool
+		app := localeTestApp(CommaDecimalNumberParser, ja, func(ctx *Context) error {
+			threshold = ctx.Float64("threshold")
+			weights = ctx.Float64Slice("weight")
+			count = ctx.Int("count")
+			enabled = ctx.Bool("enabled")
+			return nil
+		})
+
+		expect(t, app.Run(args), nil)
+		expect(t, threshold, 0.5)
+		expect(t, weights, []float64{0.25, 1234.5})
+		expect(t, count, 3)
+		expect(t, enabled, true)
+	}
+}
+
+func TestLocaleParsers_Fallback(t *testing.T) {
+	var threshold float64
+	var weights []float64
+	var enabled bool
+	app := localeTestApp(CommaDecimalNumberParser, nil, func(ctx *Context) error {
+		threshold = ctx.Float64("threshold")
+		weights = ctx.Float64Slice("weight")
+		enabled = ctx.Bool("enabled")
+		return nil
+	})
+
+	expect(t, app.Run([]string{"app", "--threshold", "1.5", "--weight", "0.5,1", "--enabled=true"}), nil)
+	expect(t, threshold, 1.5)
+	expect(t, weights, []float64{0.5, 1})
+	expect(t, enabled, true)
+
+	err := app.Run([]string{"app", "--threshold", "1,2,3"})
+	if err == nil || !strings.Contains(err.Error(), "-threshold") {
+		t.Errorf("expected an error naming the flag, got %v", err)
+	}
+
+	err = app.Run([]string{"app", "sub", "--weight", "x,y"})
+	if err == nil || !strings.Contains(err.Error(), "-weight") {
+		t.Errorf("expected an error naming the flag, got %v", err)
+	}
+}
+
+func TestNumberValue_ZeroString(t *testing.T) {
+	expect(t, (&numberValue{}).String(), "")
+}
+
+func TestLocaleParsers_Unset(t *testing.T) {
+	app := localeTestApp(nil, nil, func(*Context) error { return nil })
+	err := app.Run([]string{"app", "--threshold", "0,5"})
+	if err == nil {
+		t.Error("expected an error without a NumberParser")
+	}
+	err = app.Run([]string{"app", "--enabled=ja"})
+	if err == nil {
+		t.Error("expected an error without a BoolParser")
+	}
+}
+
+func TestLocaleParsers_EnvAndFile(t *testing.T) {
+	defer resetEnvVar("APP_THRESHOLD")()
+	defer resetEnvVar("APP_WEIGHTS")()
+	defer resetEnvVar("APP_ENABLED")()
+	_ = os.Setenv("APP_THRESHOLD", "0,5")
+	_ = os.Setenv("APP_WEIGHTS", "0,25;1.234,5")
+	_ = os.Setenv("APP_ENABLED", "ja")
+
+	file, err := ioutil.TempFile("", "urfave_cli_locale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString("1,5")
+	_ = file.Close()
+	defer os.Remove(file.Name())
+
+	var (
+		threshold float64
+		ratio     float32
+		weights   []float64
+		enabled   bool
+	)
+	app := &App{
+		Writer:       ioutil.Discard,
+		NumberParser: CommaDecimalNumberParser,
+		BoolParser: func(s string) (bool, bool) {
+			return s == "ja", s == "ja" || s == "nein"
+		},
+		Flags: []Flag{
+			&Float64Flag{Name: "threshold", EnvVars: []string{"APP_THRESHOLD"}},
+			&Float32Flag{Name: "ratio", FilePath: file.Name()},
+			&Float64SliceFlag{Name: "weight", EnvVars: []string{"APP_WEIGHTS"}, Separator: ";"},
+			&BoolFlag{Name: "enabled", EnvVars: []string{"APP_ENABLED"}},
+		},
+		Action: func(ctx *Context) error {
+			threshold = ctx.Float64("threshold")
+			ratio = ctx.Float32("ratio")
+			weights = ctx.Float64Slice("weight")
+			enabled = ctx.Bool("enabled")
+			return nil
+		},
+	}
+
+	expect(t, app.Run([]string{"app"}), nil)
+	expect(t, threshold, 0.5)
+	expect(t, ratio, float32(1.5))
+	expect(t, weights, []float64{0.25, 1234.5})
+	expect(t, enabled, true)
+}
+
+func TestLocaleParsers_SizedNumbers(t *testing.T) {
+	grouping := func(s string) (string, bool) {
+		return strings.Replace(s, "_", "", -1), strings.Contains(s, "_")
+	}
+
+	var values []interface{}
+	app := &App{
+		Writer:       ioutil.Discard,
+		NumberParser: grouping,
+		Flags: []Flag{
+			&Int8Flag{Name: "i8"},
+			&Int16Flag{Name: "i16"},
+			&Int32Flag{Name: "i32"},
+			&Uint8Flag{Name: "u8"},
+			&Uint16Flag{Name: "u16"},
+			&Uint32Flag{Name: "u32"},
+		},
+		Action: func(ctx *Context) error {
+			values = []interface{}{ctx.Int8("i8"), ctx.Int16("i16"), ctx.Int32("i32"), ctx.Uint8("u8"), ctx.Uint16("u16"), ctx.Uint32("u32")}
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"app", "--i8", "1_2", "--i16", "1_000", "--i32", "-100_000", "--u8", "2_5", "--u16", "60_000", "--u32", "4_000_000"})
+	expect(t, err, nil)
+	expect(t, values, []interface{}{int8(12), int16(1000), int32(-100000), uint8(25), uint16(60000), uint32(4000000)})
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ParseErrorKind classifies the errors reported to
+// MetricsCollector.IncParseError
+type ParseErrorKind int
+
+const (
+	// ParseErrorFlags is an invalid command line, environment variable or
+	// flag file
+	ParseErrorFlags ParseErrorKind = iota
+	// ParseErrorValidation is a value rejected by the Validator of a flag
+	ParseErrorValidation
+	// ParseErrorRequired is a required flag which is not set
+	ParseErrorRequired
+
+	numParseErrorKinds
+)
+
+// String returns the name of the kind, usable as a metric label
+func (k ParseErrorKind) String() string {
+	switch k {
+	case ParseErrorFlags:
+		return "flags"
+	case ParseErrorValidation:
+		return "validation"
+	case ParseErrorRequired:
+		return "required"
+	}
+	return "unknown"
+}
+
+// MetricsCollector receives counters and timings from an App. A parse is the
+// parsing of the flags of the App or of a command on the command line, from
+// the arguments to the check of the required flags; a run is a whole call to
+// App.Run or App.RunContext.
+type MetricsCollector interface {
+	IncParse()
+	IncParseError(kind ParseErrorKind)
+	ObserveParseDuration(d time.Duration)
+	ObserveRunDuration(d time.Duration)
+}
+
+// CounterMetrics is a MetricsCollector counting parses, parse errors and runs
+// and summing their durations with atomic operations. The zero value is ready
+// to use.
+type CounterMetrics struct {
+	parses        int64
+	parseDuration int64
+	runs          int64
+	runDuration   int64
+	parseErrors   [numParseErrorKinds]int64
+}
+
+// IncParse counts a parse
+func (m *CounterMetrics) IncParse() {
+	atomic.AddInt64(&m.parses, 1)
+}
+
+// IncParseError counts a parse which failed with an error of kind
+func (m *CounterMetrics) IncParseError(kind ParseErrorKind) {
+	if kind >= 0 && kind < numParseErrorKinds {
+		atomic.AddInt64(&m.parseErrors[kind], 1)
+	}
+}
+
+// ObserveParseDuration adds the duration of a parse
+func (m *CounterMetrics) ObserveParseDuration(d time.Duration) {
+	atomic.AddInt64(&m.parseDuration, int64(d))
+}
+
+// ObserveRunDuration counts a run and adds its duration
+func (m *CounterMetrics) ObserveRunDuration(d time.Duration) {
+	atomic.AddInt64(&m.runs, 1)
+	atomic.AddInt64(&m.runDuration, int64(d))
+}
+
+// Parses returns the number of parses
+func (m *CounterMetrics) Parses() int64 {
+	return atomic.LoadInt64(&m.parses)
+}
+
+// ParseErrors returns the number of parses which failed with an error of kind
+func (m *CounterMetrics) ParseErrors(kind ParseErrorKind) int64 {
+	if kind < 0 || kind >= numParseErrorKinds {
+		return 0
+	}
+	return atomic.LoadInt64(&m.parseErrors[kind])
+}
+
+// ParseDuration returns the total duration of the parses
+func (m *CounterMetrics) ParseDuration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&m.parseDuration))
+}
+
+// Runs returns the number of runs
+func (m *CounterMetrics) Runs() int64 {
+	return atomic.LoadInt64(&m.runs)
+}
+
+// RunDuration returns the total duration of the runs
+func (m *CounterMetrics) RunDuration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&m.runDuration))
+}
+
+// parseMetrics reports a single parse to a MetricsCollector. A nil
+// *parseMetrics reports nothing.
+type parseMetrics struct {
+	collector MetricsCollector
+	start     time.Time
+	done      bool
+}
+
+// startParse counts a parse and starts timing it, if a MetricsCollector is set
+func startParse(collector MetricsCollector) *parseMetrics {
+	if collector == nil {
+		return nil
+	}
+	collector.IncParse()
+	return &parseMetrics{collector: collector, start: time.Now()}
+}
+
+// fail counts an error of kind and observes the duration of the parse
+func (m *parseMetrics) fail(kind ParseErrorKind) {
+	if m == nil || m.done {
+		return
+	}
+	m.collector.IncParseError(kind)
+	m.finish()
+}
+
+// finish observes the duration of the parse, once
+func (m *parseMetrics) finish() {
+	if m == nil || m.done {
+		return
+	}
+	m.done = true
+	m.collector.ObserveParseDuration(time.Since(m.start))
+}
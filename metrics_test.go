@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+)
+
+func metricsTestApp(metrics MetricsCollector) *App {
+	return &App{
+		Name:             "app",
+		Writer:           ioutil.Discard,
+		ErrWriter:        ioutil.Discard,
+		MetricsCollector: metrics,
+		Flags: []Flag{
+			&IntFlag{
+				Name: "port",
+				Validator: func(v interface{}) error {
+					if v.(int) > 65535 {
+						return errors.New("port out of range")
+					}
+					return nil
+				},
+			},
+		},
+		Action: func(*Context) error { return nil },
+		Commands: []*Command{{
+			Name: "remote",
+			Subcommands: []*Command{{
+				Name:   "add",
+				Flags:  []Flag{&StringFlag{Name: "url", Required: true}},
+				Action: func(*Context) error { return nil },
+			}},
+		}},
+	}
+}
+
+func TestCounterMetrics(t *testing.T) {
+	metrics := &CounterMetrics{}
+
+	err := metricsTestApp(metrics).Run([]string{"app", "--port", "80", "remote", "add", "--url", "x"})
+	expect(t, err, nil)
+	// the app, remote and add are parsed
+	expect(t, metrics.Parses(), int64(3))
+	expect(t, metrics.Runs(), int64(1))
+	for _, kind := range []ParseErrorKind{ParseErrorFlags, ParseErrorValidation, ParseErrorRequired} {
+		expect(t, metrics.ParseErrors(kind), int64(0))
+	}
+	if metrics.ParseDuration() <= 0 || metrics.RunDuration() < metrics.ParseDuration() {
+		t.Errorf("unexpected durations: parse %s, run %s", metrics.ParseDuration(), metrics.RunDuration())
+	}
+
+	_ = metricsTestApp(metrics).Run([]string{"app", "--nope"})
+	_ = metricsTestApp(metrics).Run([]string{"app", "--port", "70000"})
+	_ = metricsTestApp(metrics).Run([]string{"app", "remote", "add"})
+	_ = metricsTestApp(metrics).Run([]string{"app", "remote", "--nope"})
+
+	expect(t, metrics.Parses(), int64(10))
+	expect(t, metrics.Runs(), int64(5))
+	expect(t, metrics.ParseErrors(ParseErrorFlags), int64(2))
+	expect(t, metrics.ParseErrors(ParseErrorValidation), int64(1))
+	expect(t, metrics.ParseErrors(ParseErrorRequired), int64(1))
+}
+
+func TestCounterMetrics_UnknownKind(t *testing.T) {
+	metrics := &CounterMetrics{}
+	metrics.IncParseError(ParseErrorKind(42))
+	expect(t, metrics.ParseErrors(ParseErrorKind(42)), int64(0))
+	expect(t, ParseErrorKind(42).String(), "unknown")
+	expect(t, ParseErrorRequired.String(), "required")
+}
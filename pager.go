@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// NoPagerFlag disables the paging of help. It is only added to an App when
+// EnablePager is true.
+var NoPagerFlag Flag = &BoolFlag{
+	Name:  "no-pager",
+	Usage: "do not page the help",
+}
+
+// defaultPager is run when $PAGER is not set. -F makes less exit at once when
+// the text fits on the screen.
+const defaultPager = "less -F -R -X"
+
+// pageHelp calls show with a buffer when the help it writes to w may be paged,
+// and pipes it through the pager if it does not fit on the terminal. The help
+// is written to w directly when the pager can not be started.
+func pageHelp(c *Context, w io.Writer, show func(w io.Writer) error) error {
+	pager := helpPager(c, w)
+	if pager == nil {
+		return show(w)
+	}
+
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	if width := c.App.terminal().Width(w); width > 0 {
+		out = &widthWriter{Writer: out, width: width}
+	}
+	err := show(out)
+
+	if strings.Count(buf.String(), "\n") < c.App.terminalHeight(w) || runPager(pager, &buf, w, c.App.errWriter()) != nil {
+		_, _ = buf.WriteTo(w)
+	}
+	return err
+}
+
+// helpPager returns the command line of the pager for the help written to w,
+// or nil when the help is not paged: when EnablePager is not set, w is not a
+// terminal of a known height, or paging was disabled by --no-pager, NO_PAGER
+// or an empty $PAGER.
+func helpPager(c *Context, w io.Writer) []string {
+	if c == nil || c.App == nil || !c.App.EnablePager {
+		return nil
+	}
+	if !c.App.terminal().IsTerminal(w) || c.App.terminalHeight(w) <= 0 {
+		return nil
+	}
+	if val, ok := os.LookupEnv("NO_PAGER"); ok && val != "" {
+		return nil
+	}
+	if NoPagerFlag != nil {
+		for _, name := range NoPagerFlag.Names() {
+			if c.isSet(name) && lookupBool(name, findFlagSet(name, c)) {
+				return nil
+			}
+		}
+	}
+
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = defaultPager
+	}
+	args, err := splitShellWords(pager)
+	if err != nil || len(args) == 0 || args[0] == "cat" {
+		return nil
+	}
+	return args
+}
+
+// runPager runs the pager with r as its input and w as its output, and waits
+// for it to exit. The interrupts received meanwhile are forwarded to the pager
+// rather than ending the App. An error is only returned when the pager could
+// not be started.
+func runPager(pager []string, r io.Reader, w, errWriter io.Writer) error {
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = r
+	cmd.Stdout = w
+	cmd.Stderr = errWriter
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// the pager exiting with an error, like when it is quit early, is not an
+	// error of the help
+	_ = cmd.Wait()
+	close(done)
+	return nil
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type fakeHeightTerminal struct {
+	fakeTerminal
+	height int
+}
+
+func (t *fakeHeightTerminal) Height(io.Writer) int {
+	return t.height
+}
+
+// stubPager puts a pager named name on PATH, which records its arguments and
+// its input in the returned directory, and prints "paged"
+func stubPager(t *testing.T, name string) (dir string, restore func()) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub pager is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "urfave_cli_pager")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only builtins are used, as other tests may clear PATH
+	script := "#!/bin/sh\n" +
+		"echo \"$@\" > '" + filepath.Join(dir, "args") + "'\n" +
+		"while IFS= read -r line; do echo \"$line\"; done > '" + filepath.Join(dir, "input") + "'\n" +
+		"echo paged\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path := os.Getenv("PATH")
+	_ = os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	return dir, func() {
+		_ = os.Setenv("PATH", path)
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func pagerTestApp(output io.Writer, height int) *App {
+	app := &App{
+		Name:        "mytool",
+		HelpName:    "mytool",
+		Writer:      output,
+		EnablePager: true,
+		HideVersion: true,
+		Terminal:    &fakeHeightTerminal{fakeTerminal: fakeTerminal{width: 80}, height: height},
+		Action:      func(c *Context) error { return nil },
+	}
+	for _, name := range []string{"build", "clean", "deploy", "lint", "test"} {
+		app.Commands = append(app.Commands, &Command{
+			Name:   name,
+			Usage:  "runs " + name,
+			Action: func(c *Context) error { return nil },
+		})
+	}
+	return app
+}
+
+func TestPager_longHelp(t *testing.T) {
+	dir, restore := stubPager(t, "less")
+	defer restore()
+	defer resetEnvVar("PAGER")()
+	_ = os.Unsetenv("PAGER")
+
+	output := &bytes.Buffer{}
+	err := pagerTestApp(output, 5).Run([]string{"mytool", "--help"})
+	expect(t, err, nil)
+	expect(t, output.String(), "paged\n")
+
+	args, _ := ioutil.ReadFile(filepath.Join(dir, "args"))
+	expect(t, string(args), "-F -R -X\n")
+	input, _ := ioutil.ReadFile(filepath.Join(dir, "input"))
+	if !strings.Contains(string(input), "runs deploy") {
+		t.Errorf("expected the help to be paged, got %q", input)
+	}
+}
+
+func TestPager_PAGER(t *testing.T) {
+	dir, restore := stubPager(t, "mypager")
+	defer restore()
+	defer resetEnvVar("PAGER")()
+	_ = os.Setenv("PAGER", "mypager --raw")
+
+	output := &bytes.Buffer{}
+	err := pagerTestApp(output, 5).Run([]string{"mytool", "help", "deploy"})
+	expect(t, err, nil)
+
+	args, _ := ioutil.ReadFile(filepath.Join(dir, "args"))
+	expect(t, string(args), "--raw\n")
+	input, _ := ioutil.ReadFile(filepath.Join(dir, "input"))
+	if !strings.Contains(string(input), "mytool deploy - runs deploy") {
+		t.Errorf("expected the command help to be paged, got %q", input)
+	}
+}
+
+func TestPager_notPaged(t *testing.T) {
+	_, restore := stubPager(t, "less")
+	defer restore()
+	defer resetEnvVar("PAGER")()
+	defer resetEnvVar("NO_PAGER")()
+	_ = os.Unsetenv("PAGER")
+
+	cases := []struct {
+		name    string
+		args    []string
+		height  int
+		env     map[string]string
+		disable bool
+	}{
+		{name: "fits", args: []string{"mytool", "--help"}, height: 100},
+		{name: "no pager flag", args: []string{"mytool", "--no-pager", "--help"}, height: 5},
+		{name: "NO_PAGER", args: []string{"mytool", "--help"}, height: 5, env: map[string]string{"NO_PAGER": "1"}},
+		{name: "empty PAGER", args: []string{"mytool", "--help"}, height: 5, env: map[string]string{"PAGER": ""}},
+		{name: "pager not found", args: []string{"mytool", "--help"}, height: 5, env: map[string]string{"PAGER": "no-such-pager-here"}},
+		{name: "not enabled", args: []string{"mytool", "--help"}, height: 5, disable: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_ = os.Unsetenv("NO_PAGER")
+			_ = os.Unsetenv("PAGER")
+			for k, v := range c.env {
+				_ = os.Setenv(k, v)
+			}
+
+			output := &bytes.Buffer{}
+			app := pagerTestApp(output, c.height)
+			app.EnablePager = !c.disable
+			err := app.Run(c.args)
+			expect(t, err, nil)
+
+			if !strings.Contains(output.String(), "runs deploy") {
+				t.Errorf("expected the help to be written directly, got %q", output.String())
+			}
+		})
+	}
+}
+
+func TestPager_commandOutput(t *testing.T) {
+	_, restore := stubPager(t, "less")
+	defer restore()
+	defer resetEnvVar("PAGER")()
+	_ = os.Unsetenv("PAGER")
+
+	output := &bytes.Buffer{}
+	app := pagerTestApp(output, 2)
+	app.Action = func(c *Context) error {
+		_, _ = io.WriteString(c.App.Writer, strings.Repeat("line\n", 10))
+		return nil
+	}
+
+	err := app.Run([]string{"mytool"})
+	expect(t, err, nil)
+	expect(t, output.String(), strings.Repeat("line\n", 10))
+}
+
+// resetEnvVar returns a func restoring the environment variable name
+func resetEnvVar(name string) func() {
+	val, ok := os.LookupEnv(name)
+	return func() {
+		if ok {
+			_ = os.Setenv(name, val)
+		} else {
+			_ = os.Unsetenv(name)
+		}
+	}
+}
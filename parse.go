@@ -5,6 +5,69 @@ import (
 	"strings"
 )
 
+const unknownFlagPrefix = "flag provided but not defined: -"
+
+// UnknownFlagError is returned when parsing encounters a flag that has not
+// been defined
+type UnknownFlagError interface {
+	error
+	// FlagName returns the name of the unknown flag, without dashes
+	FlagName() string
+	// Token returns the command line argument the unknown flag came from
+	Token() string
+	// Suggestion returns the closest known flag, or an empty string if no
+	// known flag is close enough
+	Suggestion() string
+}
+
+type errUnknownFlag struct {
+	name       string
+	token      string
+	suggestion string
+}
+
+func (e *errUnknownFlag) Error() string {
+	return unknownFlagPrefix + e.name + didYouMean(e.suggestion)
+}
+
+func (e *errUnknownFlag) FlagName() string {
+	return e.name
+}
+
+func (e *errUnknownFlag) Token() string {
+	return e.token
+}
+
+func (e *errUnknownFlag) Suggestion() string {
+	return e.suggestion
+}
+
+// classifyParseError turns the stringly typed errors returned by
+// flag.FlagSet.Parse into structured errors where possible.
+func classifyParseError(err error, args []string) error {
+	if err == nil {
+		return nil
+	}
+
+	name := strings.TrimPrefix(err.Error(), unknownFlagPrefix)
+	if name == err.Error() {
+		return err
+	}
+
+	token := "-" + name
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0] == name {
+			token = arg
+			break
+		}
+	}
+
+	return &errUnknownFlag{name: name, token: token}
+}
+
 type iterativeParser interface {
 	newFlagSet() (*flag.FlagSet, error)
 	useShortOptionHandling() bool
@@ -18,7 +81,7 @@ type iterativeParser interface {
 // completion when, the user-supplied options may be incomplete.
 func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComplete bool) error {
 	for {
-		err := set.Parse(args)
+		err := classifyParseError(set.Parse(args), args)
 		if !ip.useShortOptionHandling() || err == nil {
 			if shellComplete {
 				return nil
@@ -26,11 +89,11 @@ func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComple
 			return err
 		}
 
-		errStr := err.Error()
-		trimmed := strings.TrimPrefix(errStr, "flag provided but not defined: -")
-		if errStr == trimmed {
+		ufErr, ok := err.(*errUnknownFlag)
+		if !ok {
 			return err
 		}
+		trimmed := ufErr.name
 
 		// regenerate the initial args with the split short opts
 		argsWereSplit := false
@@ -92,3 +155,50 @@ func splitShortOptions(set *flag.FlagSet, arg string) []string {
 func isSplittable(flagArg string) bool {
 	return strings.HasPrefix(flagArg, "-") && !strings.HasPrefix(flagArg, "--") && len(flagArg) > 2
 }
+
+// separateUnknownDashTokens moves the arguments looking like flags, but which
+// are not flags of set, after a "--" with the positional arguments, so that
+// they are parsed as such. Parsing still stops at the first positional
+// argument or at "--".
+func separateUnknownDashTokens(set *flag.FlagSet, args []string, shortOptions bool) []string {
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			positional = append(positional, args[i:]...)
+			break
+		}
+
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		f := set.Lookup(name)
+		if f == nil && shortOptions {
+			// the value of combined short options is the one of the last
+			if opts := splitShortOptions(set, arg); len(opts) > 1 {
+				f = set.Lookup(opts[len(opts)-1][1:])
+			}
+		}
+		if f == nil {
+			positional = append(positional, arg)
+			continue
+		}
+
+		flags = append(flags, arg)
+		// the value of a flag may be the next argument, whatever it looks like
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+			continue
+		}
+		if !strings.Contains(arg, "=") && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+
+	if len(positional) == 0 {
+		return flags
+	}
+	return append(append(flags, "--"), positional...)
+}
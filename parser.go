@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"strings"
+)
+
+// SuggestionKind describes what a Suggestion refers to
+type SuggestionKind int
+
+const (
+	// SuggestCommand is a subcommand that may be fed next
+	SuggestCommand SuggestionKind = iota
+	// SuggestFlag is a flag that may be fed next
+	SuggestFlag
+	// SuggestFlagValue is the value expected by the previously fed flag
+	SuggestFlagValue
+	// SuggestPositional means a positional argument is expected
+	SuggestPositional
+)
+
+// Suggestion is a candidate for the next token fed to a Parser
+type Suggestion struct {
+	Kind SuggestionKind
+	// Value is the command name, the flag as typed on the command line, the
+	// default value of a flag expecting a value or the ArgsUsage of the
+	// command expecting positional arguments
+	Value string
+	// Usage is the usage string of the command or flag
+	Usage string
+	// Flag is set for SuggestFlag and SuggestFlagValue suggestions
+	Flag Flag
+	// Command is set for SuggestCommand suggestions
+	Command *Command
+}
+
+// Parser parses the arguments of an App one token at a time. It is useful for
+// building interactive wizards that need to know what could come next.
+type Parser struct {
+	app     *App
+	levels  []*parserLevel
+	pending *flag.Flag
+}
+
+type parserLevel struct {
+	command     *Command
+	flags       []Flag
+	commands    []*Command
+	argsUsage   string
+	set         *flag.FlagSet
+	args        []string
+	skipFlags   bool
+	terminated  bool
+	hasCommands bool
+}
+
+// NewParser creates a Parser for the given App
+func NewParser(app *App) (*Parser, error) {
+	app.Setup()
+
+	set, err := app.newFlagSet()
+	if err != nil {
+		return nil, err
+	}
+
+	hasCommands := false
+	for _, c := range app.Commands {
+		if c != helpCommand {
+			hasCommands = true
+		}
+	}
+
+	return &Parser{
+		app: app,
+		levels: []*parserLevel{{
+			flags:       app.Flags,
+			commands:    app.Commands,
+			argsUsage:   app.ArgsUsage,
+			set:         set,
+			hasCommands: hasCommands,
+		}},
+	}, nil
+}
+
+// Feed parses the next token. If the token cannot be accepted an error is
+// returned and the state of the Parser is left unchanged.
+func (p *Parser) Feed(token string) error {
+	lvl := p.levels[len(p.levels)-1]
+
+	if p.pending != nil {
+		if err := p.setFlag(lvl, p.pending.Name, token); err != nil {
+			return err
+		}
+		p.pending = nil
+		p.appendToAncestors(token)
+		return nil
+	}
+
+	positional := lvl.skipFlags || lvl.terminated || len(lvl.args) > 0
+
+	switch {
+	case !positional && token == "--":
+		lvl.terminated = true
+	case !positional && len(token) > 1 && strings.HasPrefix(token, "-"):
+		name := strings.TrimLeft(token, "-")
+		value := ""
+		hasValue := false
+		if i := strings.Index(name, "="); i >= 0 {
+			name, value, hasValue = name[:i], name[i+1:], true
+		}
+
+		f := lvl.set.Lookup(name)
+		if f == nil {
+			return &errUnknownFlag{name: name, token: token}
+		}
+
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() && !hasValue {
+			value, hasValue = "true", true
+		}
+
+		if !hasValue {
+			p.pending = f
+			break
+		}
+
+		if err := p.setFlag(lvl, name, value); err != nil {
+			return err
+		}
+	default:
+		if cmd := p.command(lvl, token); cmd != nil && !positional {
+			if err := p.enter(cmd); err != nil {
+				return err
+			}
+			// lvl is now an ancestor of the level of cmd
+			p.appendToAncestors(token)
+			return nil
+		}
+		lvl.args = append(lvl.args, token)
+	}
+
+	p.appendToAncestors(token)
+	return nil
+}
+
+// Suggestions returns the candidates for the next token
+func (p *Parser) Suggestions() []Suggestion {
+	lvl := p.levels[len(p.levels)-1]
+
+	if p.pending != nil {
+		var f Flag
+		for _, fl := range lvl.flags {
+			if hasName(fl.Names(), p.pending.Name) {
+				f = fl
+			}
+		}
+
+		s := Suggestion{Kind: SuggestFlagValue, Flag: f}
+		if df, ok := f.(DocGenerationFlag); ok {
+			s.Value = df.GetValue()
+			s.Usage = df.GetUsage()
+		}
+
+		// a flag with choices suggests each of them
+		if choices := flagChoices(p.pending.Name, lvl.flags); len(choices) > 0 {
+			var suggestions []Suggestion
+			for _, choice := range choices {
+				s.Value = choice
+				suggestions = append(suggestions, s)
+			}
+			return suggestions
+		}
+		return []Suggestion{s}
+	}
+
+	positionalOnly := lvl.skipFlags || lvl.terminated || len(lvl.args) > 0
+
+	var suggestions []Suggestion
+	if !positionalOnly {
+		for _, c := range lvl.commands {
+			if c.Hidden {
+				continue
+			}
+			suggestions = append(suggestions, Suggestion{
+				Kind:    SuggestCommand,
+				Value:   c.Name,
+				Usage:   c.Usage,
+				Command: c,
+			})
+		}
+
+		forEachFlagCompletion("-", lvl.flags, lvl.seen, func(completion string, f Flag) {
+			s := Suggestion{Kind: SuggestFlag, Value: completion, Flag: f}
+			if df, ok := f.(DocGenerationFlag); ok {
+				s.Usage = df.GetUsage()
+			}
+			suggestions = append(suggestions, s)
+		})
+	}
+
+	if !lvl.hasCommands || lvl.argsUsage != "" || positionalOnly {
+		suggestions = append(suggestions, Suggestion{
+			Kind:  SuggestPositional,
+			Value: lvl.argsUsage,
+		})
+	}
+
+	return suggestions
+}
+
+// Context returns the Context for the tokens fed so far
+func (p *Parser) Context() *Context {
+	ctx := &Context{Context: context.Background()}
+	for i, lvl := range p.levels {
+		_ = lvl.set.Parse(append([]string{"--"}, lvl.args...))
+		ctx = NewContext(p.app, lvl.set, ctx)
+		if i > 0 {
+			ctx.Command = lvl.command
+		}
+	}
+	return ctx
+}
+
+func (p *Parser) setFlag(lvl *parserLevel, name, value string) error {
+	if err := lvl.set.Set(name, value); err != nil {
+		return err
+	}
+
+	// keep aliases in sync the same way normalizeFlags does
+	for _, fl := range lvl.flags {
+		names := fl.Names()
+		if !hasName(names, name) {
+			continue
+		}
+		for _, alias := range names {
+			if alias != name {
+				copyFlag(alias, lvl.set.Lookup(name), lvl.set)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Parser) appendToAncestors(token string) {
+	for _, lvl := range p.levels[:len(p.levels)-1] {
+		lvl.args = append(lvl.args, token)
+	}
+}
+
+func (p *Parser) command(lvl *parserLevel, name string) *Command {
+	for _, c := range lvl.commands {
+		if c.HasName(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (p *Parser) enter(cmd *Command) error {
+	flags := cmd.Flags
+	if !cmd.HideHelp && HelpFlag != nil && !hasFlag(flags, HelpFlag) {
+		flags = append(append([]Flag{}, flags...), HelpFlag)
+	}
+
+	set, err := flagSet(cmd.Name, flags)
+	if err != nil {
+		return err
+	}
+
+	p.levels = append(p.levels, &parserLevel{
+		command:     cmd,
+		flags:       flags,
+		commands:    cmd.Subcommands,
+		argsUsage:   cmd.ArgsUsage,
+		set:         set,
+		skipFlags:   cmd.SkipFlagParsing,
+		hasCommands: len(cmd.Subcommands) > 0,
+	})
+	return nil
+}
+
+// seen reports whether name has been set at this level
+func (lvl *parserLevel) seen(name string) bool {
+	visited := false
+	lvl.set.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			visited = true
+		}
+	})
+	return visited
+}
+
+func hasName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
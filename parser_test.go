@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newParserTestApp() *App {
+	return &App{
+		Name:      "git",
+		HideHelp:  true,
+		ArgsUsage: "",
+		Flags: []Flag{
+			&BoolFlag{Name: "verbose", Aliases: []string{"v"}, Usage: "talk more"},
+		},
+		Commands: []*Command{
+			{
+				Name:  "remote",
+				Usage: "manage remotes",
+				Subcommands: []*Command{
+					{
+						Name:      "add",
+						Usage:     "add a remote",
+						ArgsUsage: "<url>",
+						HideHelp:  true,
+						Flags: []Flag{
+							&StringFlag{Name: "name", Value: "origin", Usage: "remote name"},
+						},
+					},
+					{Name: "secret", Hidden: true},
+				},
+				HideHelp: true,
+			},
+		},
+	}
+}
+
+func suggestionValues(suggestions []Suggestion) []string {
+	var values []string
+	for _, s := range suggestions {
+		values = append(values, s.Value)
+	}
+	return values
+}
+
+func TestParser_Feed(t *testing.T) {
+	p, err := NewParser(newParserTestApp())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		token    string
+		expected []string
+	}{
+		{"", []string{"remote", "--verbose", "-v"}},
+		{"--verbose", []string{"remote"}},
+		{"remote", []string{"add"}},
+		{"add", []string{"--name", "<url>"}},
+		{"--name", []string{"origin"}},
+		{"upstream", []string{"<url>"}},
+		{"https://example.com/repo.git", []string{"<url>"}},
+	}
+
+	for _, step := range steps {
+		if step.token != "" {
+			if err := p.Feed(step.token); err != nil {
+				t.Fatalf("unexpected error feeding %q: %v", step.token, err)
+			}
+		}
+
+		got := suggestionValues(p.Suggestions())
+		if !reflect.DeepEqual(got, step.expected) {
+			t.Errorf("after %q expected suggestions %v, got %v", step.token, step.expected, got)
+		}
+	}
+
+	ctx := p.Context()
+	expect(t, ctx.Command.Name, "add")
+	expect(t, ctx.String("name"), "upstream")
+	expect(t, ctx.Bool("verbose"), true)
+	expect(t, ctx.Bool("v"), true)
+	expect(t, ctx.Args().Slice(), []string{"https://example.com/repo.git"})
+	expect(t, ctx.Lineage()[1].Args().Slice(), []string{"add", "--name", "upstream", "https://example.com/repo.git"})
+}
+
+func TestParser_SuggestionKinds(t *testing.T) {
+	p, _ := NewParser(newParserTestApp())
+	_ = p.Feed("remote")
+	_ = p.Feed("add")
+
+	suggestions := p.Suggestions()
+	expect(t, len(suggestions), 2)
+	expect(t, suggestions[0].Kind, SuggestFlag)
+	expect(t, suggestions[0].Usage, "remote name")
+	expect(t, suggestions[1].Kind, SuggestPositional)
+
+	_ = p.Feed("--name")
+	suggestions = p.Suggestions()
+	expect(t, len(suggestions), 1)
+	expect(t, suggestions[0].Kind, SuggestFlagValue)
+	expect(t, suggestions[0].Flag.Names(), []string{"name"})
+}
+
+func TestParser_SuggestsChoices(t *testing.T) {
+	app := newParserTestApp()
+	app.Flags = append(app.Flags, &StringFlag{Name: "format", Choices: []string{"json", "yaml"}})
+	p, _ := NewParser(app)
+
+	_ = p.Feed("--format")
+	suggestions := p.Suggestions()
+	expect(t, len(suggestions), 2)
+	expect(t, suggestions[0].Kind, SuggestFlagValue)
+	expect(t, suggestions[0].Value, "json")
+	expect(t, suggestions[1].Value, "yaml")
+
+	if err := p.Feed("xml"); err == nil {
+		t.Errorf("expected an error feeding a value outside the choices")
+	}
+}
+
+func TestParser_Feed_Errors(t *testing.T) {
+	app := newParserTestApp()
+	app.Flags = append(app.Flags, &IntFlag{Name: "depth"})
+	p, _ := NewParser(app)
+
+	err := p.Feed("--verbsoe")
+	if _, ok := err.(UnknownFlagError); !ok {
+		t.Errorf("expected an UnknownFlagError, got %#v", err)
+	}
+
+	_ = p.Feed("--depth")
+	if err := p.Feed("deep"); err == nil {
+		t.Errorf("expected an error feeding an invalid int")
+	}
+
+	// the rejected value leaves the flag pending
+	expect(t, p.Suggestions()[0].Kind, SuggestFlagValue)
+	expect(t, p.Feed("3"), nil)
+	expect(t, p.Context().Int("depth"), 3)
+}
+
+func TestParser_Feed_CommandError(t *testing.T) {
+	app := newParserTestApp()
+	app.Commands = append(app.Commands, &Command{
+		Name:  "broken",
+		Flags: []Flag{&StringFlag{Name: "has space"}},
+	})
+	p, _ := NewParser(app)
+
+	if err := p.Feed("broken"); err == nil {
+		t.Fatal("expected an error entering a command with an invalid flag")
+	}
+
+	// the rejected command is not taken for an argument
+	expect(t, p.Context().Args().Slice(), []string{})
+	expect(t, p.Feed("remote"), nil)
+	expect(t, p.Context().Command.Name, "remote")
+	expect(t, p.Context().Args().Slice(), []string{})
+	expect(t, p.Context().Lineage()[1].Args().Slice(), []string{"remote"})
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// ToPowerShellCompletion creates a PowerShell completion script for the
+// `*App`, registering an argument completer for its commands and flags. The
+// variables of the script are named after the App so the completers of
+// several apps do not collide.
+// The function errors if either parsing or writing of the string fails.
+func (a *App) ToPowerShellCompletion() (string, error) {
+	var w bytes.Buffer
+	if err := a.writePowerShellCompletionTemplate(&w); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+type powerShellCompletionTemplate struct {
+	App         *App
+	Root        string
+	Identifier  string
+	Commands    []string
+	Completions []string
+}
+
+func (a *App) writePowerShellCompletionTemplate(w io.Writer) error {
+	const name = "cli"
+	t, err := template.New(name).Parse(PowerShellCompletionTemplate)
+	if err != nil {
+		return err
+	}
+
+	// Add global flags
+	flags := a.VisibleFlags()
+
+	// Add help flag
+	if !a.HideHelp {
+		flags = append(flags, HelpFlag)
+	}
+
+	// Add version flag
+	if !a.HideVersion {
+		flags = append(flags, VersionFlag)
+	}
+
+	root := escapePowerShellQuotes(a.Name)
+	commands := []string{}
+	completions := a.preparePowerShellCompletions(root, flags, a.VisibleCommands(), &commands, map[string]bool{})
+
+	return t.ExecuteTemplate(w, name, &powerShellCompletionTemplate{
+		App:         a,
+		Root:        root,
+		Identifier:  completionIdentifier(a.Name),
+		Commands:    commands,
+		Completions: completions,
+	})
+}
+
+// preparePowerShellCompletions returns the completions of the command at path
+// followed by the ones of its visible subcommands, and adds the paths of the
+// subcommands by any of their names to commands. The first command with a name
+// wins, as PowerShell rejects duplicate keys.
+func (a *App) preparePowerShellCompletions(path string, flags []Flag, subcommands []*Command, commands *[]string, seen map[string]bool) []string {
+	results := []string{}
+	for _, command := range subcommands {
+		if command.Hidden {
+			continue
+		}
+		for _, name := range command.Names() {
+			results = append(results, powerShellCompletionResult(
+				name, "ParameterValue", command.Usage))
+		}
+	}
+	for _, f := range flags {
+		flag, ok := f.(DocGenerationFlag)
+		if !ok {
+			continue
+		}
+		_, usage := unquoteUsage(expandUsage(flag, flag.GetUsage()))
+		for _, name := range flag.Names() {
+			if name = strings.TrimSpace(name); name != "" {
+				results = append(results, powerShellCompletionResult(
+					prefixFor(name)+name, "ParameterName", usage))
+			}
+		}
+	}
+
+	completions := []string{fmt.Sprintf("        '%s' = @(\n%s\n        )",
+		path, strings.Join(results, "\n"))}
+
+	for _, command := range subcommands {
+		if command.Hidden {
+			continue
+		}
+		subPath := path + ";" + escapePowerShellQuotes(command.Name)
+		for _, name := range command.Names() {
+			key := path + ";" + escapePowerShellQuotes(name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			*commands = append(*commands, fmt.Sprintf("'%s' = '%s'", key, subPath))
+		}
+
+		subFlags := command.VisibleFlags()
+		if !command.HideHelp {
+			subFlags = append(subFlags, HelpFlag)
+		}
+		completions = append(completions,
+			a.preparePowerShellCompletions(subPath, subFlags, command.Subcommands, commands, seen)...)
+	}
+
+	return completions
+}
+
+// powerShellCompletionResult returns a CompletionResult of text, whose
+// tooltip is the usage or text itself, as PowerShell needs one
+func powerShellCompletionResult(text, resultType, usage string) string {
+	tooltip := usage
+	if tooltip == "" {
+		tooltip = text
+	}
+	text = escapePowerShellQuotes(text)
+	return fmt.Sprintf("            [CompletionResult]::new('%s', '%s', [CompletionResultType]::%s, '%s')",
+		text, text, resultType, escapePowerShellQuotes(tooltip))
+}
+
+func escapePowerShellQuotes(input string) string {
+	return strings.Replace(input, `'`, `''`, -1)
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPowerShellCompletion(t *testing.T) {
+	// Given
+	app := testApp()
+
+	// When
+	res, err := app.ToPowerShellCompletion()
+
+	// Then
+	expect(t, err, nil)
+	expectFileContent(t, "testdata/expected-powershell-full.ps1", res)
+}
+
+func TestPowerShellCompletion_Identifier(t *testing.T) {
+	app := &App{
+		Name:        "my-app",
+		HideVersion: true,
+		Commands: []*Command{
+			{Name: "run", Aliases: []string{"r"}},
+			{Name: "remove", Aliases: []string{"r"}},
+			{Name: "secret", Hidden: true},
+		},
+	}
+
+	res, err := app.ToPowerShellCompletion()
+	expect(t, err, nil)
+
+	for _, expected := range []string{
+		"$__my_appCompleter = {",
+		"Register-ArgumentCompleter -Native -CommandName 'my-app' -ScriptBlock $__my_appCompleter",
+		"'my-app;r' = 'my-app;run'",
+	} {
+		if !strings.Contains(res, expected) {
+			t.Errorf("expected %q in:\n%s", expected, res)
+		}
+	}
+	for _, unexpected := range []string{"'my-app;r' = 'my-app;remove'", "secret"} {
+		if strings.Contains(res, unexpected) {
+			t.Errorf("unexpected %q in:\n%s", unexpected, res)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// profileFlagName is the name of the flag selecting the profile of
+// App.Profiles
+const profileFlagName = "profile"
+
+// ProfileSource supplies named sets of flag values, like the dev, staging and
+// prod settings of a tool, see App.Profiles
+type ProfileSource interface {
+	// Profiles returns the names of the profiles
+	Profiles() ([]string, error)
+	// ProfileValues returns the flag values of the profile name by flag name,
+	// or the base values shared by all of the profiles when name is empty
+	ProfileValues(name string) (map[string]string, error)
+}
+
+// profilesFile is the ProfileSource returned by ProfilesFile
+type profilesFile struct {
+	path string
+}
+
+// ProfilesFile returns a ProfileSource reading the profiles from the file at
+// path each time they are needed. Each profile is a section starting with its
+// name in brackets, like [prod], and the lines before the first section hold
+// the base values. As in the file of App.FlagFileFlag, each line holds a flag
+// name and its value separated by spaces, while a boolean flag may be given
+// without a value. Blank lines and lines starting with # are skipped.
+func ProfilesFile(path string) ProfileSource {
+	return &profilesFile{path: path}
+}
+
+func (p *profilesFile) Profiles() ([]string, error) {
+	var names []string
+	err := p.scan(func(section, _, _ string) {
+		if section != "" && !hasName(names, section) {
+			names = append(names, section)
+		}
+	})
+	return names, err
+}
+
+func (p *profilesFile) ProfileValues(name string) (map[string]string, error) {
+	values := map[string]string{}
+	err := p.scan(func(section, flagName, value string) {
+		if section == name && flagName != "" {
+			values[flagName] = value
+		}
+	})
+	return values, err
+}
+
+// scan calls fn with each of the values of the file and the name of their
+// section, empty for the base values. A section without values is reported
+// with an empty flag name.
+func (p *profilesFile) scan(fn func(section, name, value string)) error {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.TrimSpace(line[1:len(line)-1]) == "" {
+				return fmt.Errorf("%s:%d: invalid profile section %s", p.path, lineno, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			fn(section, "", "")
+			continue
+		}
+
+		name, value := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			name, value = line[:i], strings.TrimSpace(line[i:])
+		}
+		fn(section, strings.TrimLeft(name, "-"), value)
+	}
+	return scanner.Err()
+}
+
+// profileFlag makes the persistent flag selecting the profile of App.Profiles
+func profileFlag(envVars []string) Flag {
+	return &StringFlag{
+		Name:    profileFlagName,
+		Usage:   "take the flags not given from `PROFILE`, see the profiles command",
+		EnvVars: envVars,
+	}
+}
+
+var profilesCommand = &Command{
+	Name:  "profiles",
+	Usage: "Manages the profiles of flag values",
+	Subcommands: []*Command{
+		{
+			Name:  "list",
+			Usage: "Lists the profiles, marking the selected one with *",
+			Action: func(c *Context) error {
+				app := rootApp(c)
+				names, err := app.Profiles.Profiles()
+				if err != nil {
+					return err
+				}
+
+				selected := selectedProfile(c)
+				for _, name := range names {
+					mark := " "
+					if name == selected {
+						mark = "*"
+					}
+					_, _ = fmt.Fprintf(c.App.Writer, "%s %s\n", mark, name)
+				}
+				return nil
+			},
+		},
+	},
+}
+
+// selectedProfile returns the profile given to the innermost command, or else
+// the one from the environment, or "" if none is
+func selectedProfile(cCtx *Context) string {
+	selected := ""
+	for _, ctx := range cCtx.Lineage() {
+		if ctx.flagSet == nil {
+			continue
+		}
+		f := ctx.flagSet.Lookup(profileFlagName)
+		if f == nil {
+			continue
+		}
+
+		given := false
+		ctx.flagSet.Visit(func(ff *flag.Flag) {
+			given = given || ff.Name == profileFlagName
+		})
+		if given {
+			return f.Value.String()
+		}
+		if selected == "" {
+			selected = f.Value.String()
+		}
+	}
+	return selected
+}
+
+// applyProfile sets the flags given neither on the command line nor through
+// env or file to the values of the selected profile, or else to the base
+// values of App.Profiles. The values are also added as sources of the flags
+// for OnSourceConflict. A profile given to a command applies to the flags of
+// its ancestors as well, replacing the values of the profile they took.
+func applyProfile(flags []Flag, cCtx *Context) error {
+	app := rootApp(cCtx)
+	if app == nil || app.Profiles == nil {
+		return nil
+	}
+
+	base, err := app.Profiles.ProfileValues("")
+	if err != nil {
+		return fmt.Errorf("could not read the profiles: %s", err)
+	}
+
+	var values map[string]string
+	profile := selectedProfile(cCtx)
+	if profile != "" {
+		names, err := app.Profiles.Profiles()
+		if err != nil {
+			return fmt.Errorf("could not read the profiles: %s", err)
+		}
+		if !hasName(names, profile) {
+			return fmt.Errorf("unknown profile %q, known profiles: %s", profile, strings.Join(names, ", "))
+		}
+		if values, err = app.Profiles.ProfileValues(profile); err != nil {
+			return fmt.Errorf("could not read profile %q: %s", profile, err)
+		}
+	}
+
+	if err := setProfileValues(flags, cCtx, profile, values, base, true); err != nil {
+		return err
+	}
+
+	if cCtx.flagSet.Lookup(profileFlagName) == nil || !anyVisited([]string{profileFlagName}, visitedFlags(cCtx.flagSet)) {
+		return nil
+	}
+	for _, ctx := range cCtx.Lineage()[1:] {
+		if ctx.flagSet == nil {
+			continue
+		}
+		if err := setProfileValues(contextFlags(ctx), ctx, profile, values, base, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setProfileValues sets the flags of the context which are neither given on
+// the command line, unless by a profile, nor set through env or file
+func setProfileValues(flags []Flag, cCtx *Context, profile string, values, base map[string]string, addSources bool) error {
+	visited := visitedFlags(cCtx.flagSet)
+	for _, f := range flags {
+		names := f.Names()
+		if len(names) == 0 || names[0] == profileFlagName {
+			continue
+		}
+
+		value, source, ok := profileValue(names, values, profile)
+		if !ok {
+			value, source, ok = profileValue(names, base, "base")
+		}
+		if !ok {
+			continue
+		}
+		if addSources {
+			cCtx.AddSourceValue(names[0], SourceValue{Kind: SourceConfig, Detail: "profile " + source, Value: value})
+		}
+
+		ff := cCtx.flagSet.Lookup(names[0])
+		if ff == nil || f.IsSet() || anyVisited(names, visited) && !cCtx.profileFlags[names[0]] {
+			continue
+		}
+		if value == "" {
+			if bf, ok := ff.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+				value = "true"
+			}
+		}
+		if err := cCtx.flagSet.Set(names[0], value); err != nil {
+			return fmt.Errorf("invalid value %q for flag %s from profile %s: %s", value, names[0], source, err)
+		}
+		// the aliases are copied like normalizeFlags does
+		for _, name := range names[1:] {
+			if cCtx.flagSet.Lookup(name) != nil {
+				copyFlag(name, ff, cCtx.flagSet)
+			}
+		}
+
+		if cCtx.profileFlags == nil {
+			cCtx.profileFlags = map[string]bool{}
+		}
+		cCtx.profileFlags[names[0]] = true
+	}
+	return nil
+}
+
+func visitedFlags(set *flag.FlagSet) map[string]bool {
+	visited := make(map[string]bool)
+	set.Visit(func(f *flag.Flag) {
+		visited[f.Name] = true
+	})
+	return visited
+}
+
+// profileValue returns the value of the flag with names in values, and the
+// profile it comes from
+func profileValue(names []string, values map[string]string, profile string) (string, string, bool) {
+	for _, name := range names {
+		if value, ok := values[name]; ok {
+			return value, profile, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const testProfiles = `# shared by all of the profiles
+region eu-west-1
+replicas 1
+
+[dev]
+endpoint http://localhost:8080
+debug
+
+[prod]
+endpoint https://api.example.com
+replicas 3
+`
+
+type profileValues struct {
+	endpoint string
+	region   string
+	replicas int
+	debug    bool
+}
+
+func profilesTestApp(t *testing.T, got *profileValues) (*App, func()) {
+	file, err := ioutil.TempFile("", "urfave_cli_profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(testProfiles)
+	_ = file.Close()
+
+	app := newTestApp()
+	app.Profiles = ProfilesFile(file.Name())
+	app.ProfileEnvVars = []string{"MYAPP_PROFILE"}
+	app.Flags = []Flag{
+		&StringFlag{Name: "endpoint", Value: "http://localhost", EnvVars: []string{"MYAPP_ENDPOINT"}},
+		&StringFlag{Name: "region", Aliases: []string{"r"}, Value: "us-east-1"},
+	}
+	app.Commands = []*Command{{
+		Name: "deploy",
+		Flags: []Flag{
+			&IntFlag{Name: "replicas"},
+			&BoolFlag{Name: "debug"},
+		},
+		Action: func(c *Context) error {
+			*got = profileValues{
+				endpoint: c.String("endpoint"),
+				region:   c.String("r"),
+				replicas: c.Int("replicas"),
+				debug:    c.Bool("debug"),
+			}
+			return nil
+		},
+	}}
+	return app, func() { _ = os.Remove(file.Name()) }
+}
+
+func TestApp_Profiles(t *testing.T) {
+	defer resetEnvVar("MYAPP_PROFILE")()
+	defer resetEnvVar("MYAPP_ENDPOINT")()
+
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		expected profileValues
+	}{
+		{
+			name:     "base",
+			args:     []string{"app", "deploy"},
+			expected: profileValues{endpoint: "http://localhost", region: "eu-west-1", replicas: 1},
+		},
+		{
+			name:     "dev",
+			args:     []string{"app", "--profile", "dev", "deploy"},
+			expected: profileValues{endpoint: "http://localhost:8080", region: "eu-west-1", replicas: 1, debug: true},
+		},
+		{
+			name:     "prod from env",
+			args:     []string{"app", "deploy"},
+			env:      map[string]string{"MYAPP_PROFILE": "prod"},
+			expected: profileValues{endpoint: "https://api.example.com", region: "eu-west-1", replicas: 3},
+		},
+		{
+			name:     "command line over env",
+			args:     []string{"app", "deploy", "--profile", "dev"},
+			env:      map[string]string{"MYAPP_PROFILE": "prod"},
+			expected: profileValues{endpoint: "http://localhost:8080", region: "eu-west-1", replicas: 1, debug: true},
+		},
+		{
+			name:     "flags over the profile",
+			args:     []string{"app", "--profile", "prod", "-r", "ap-south-1", "deploy", "--replicas", "5"},
+			env:      map[string]string{"MYAPP_ENDPOINT": "https://staging.example.com"},
+			expected: profileValues{endpoint: "https://staging.example.com", region: "ap-south-1", replicas: 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = os.Unsetenv("MYAPP_PROFILE")
+			_ = os.Unsetenv("MYAPP_ENDPOINT")
+			for name, value := range test.env {
+				_ = os.Setenv(name, value)
+			}
+
+			var got profileValues
+			app, cleanup := profilesTestApp(t, &got)
+			defer cleanup()
+
+			expect(t, app.Run(test.args), nil)
+			expect(t, got, test.expected)
+		})
+	}
+}
+
+func TestApp_Profiles_Unknown(t *testing.T) {
+	defer resetEnvVar("MYAPP_PROFILE")()
+	_ = os.Unsetenv("MYAPP_PROFILE")
+
+	var got profileValues
+	app, cleanup := profilesTestApp(t, &got)
+	defer cleanup()
+
+	err := app.Run([]string{"app", "--profile", "qa", "deploy"})
+	expect(t, err.Error(), `unknown profile "qa", known profiles: dev, prod`)
+}
+
+func TestApp_Profiles_List(t *testing.T) {
+	defer resetEnvVar("MYAPP_PROFILE")()
+	_ = os.Setenv("MYAPP_PROFILE", "prod")
+
+	var got profileValues
+	app, cleanup := profilesTestApp(t, &got)
+	defer cleanup()
+
+	output := &bytes.Buffer{}
+	app.Writer = output
+	expect(t, app.Run([]string{"app", "profiles", "list"}), nil)
+	expect(t, output.String(), "  dev\n* prod\n")
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// PromptInterruptedExitCode is the exit code used when a prompt is
+// interrupted because the context of the run was cancelled
+var PromptInterruptedExitCode = 130
+
+type errPromptInterrupted struct{}
+
+func (errPromptInterrupted) Error() string {
+	return "prompt interrupted"
+}
+
+func (errPromptInterrupted) ExitCode() int {
+	return PromptInterruptedExitCode
+}
+
+// ErrPromptInterrupted is returned by Prompt and Confirm when the context of
+// the run is cancelled, or its deadline exceeded, before an answer was read.
+// It is an ExitCoder using PromptInterruptedExitCode.
+var ErrPromptInterrupted ExitCoder = errPromptInterrupted{}
+
+// Prompt writes message to App.Writer and reads a line from App.Reader. It
+// returns ErrPromptInterrupted as soon as the context is done, even if the
+// read is still blocked. The lines are read by a single goroutine per App,
+// so a line entered after a prompt was interrupted is the answer to the next
+// prompt rather than being lost. When the App.Terminal is a
+// terminalRestorer, it is restored as the prompt is interrupted.
+func (c *Context) Prompt(message string) (string, error) {
+	var (
+		r io.Reader
+		w io.Writer
+	)
+	if c.App != nil {
+		r, w = c.App.Reader, c.App.Writer
+	}
+	if r == nil {
+		return "", fmt.Errorf("no reader to prompt from")
+	}
+	if w != nil {
+		_, _ = fmt.Fprint(w, message)
+	}
+
+	lines := rootApp(c).promptLines(r)
+	select {
+	case <-c.Done():
+		if restorer, ok := c.App.terminal().(terminalRestorer); ok {
+			_ = restorer.Restore(r)
+		}
+		if w != nil {
+			_, _ = fmt.Fprintln(w)
+		}
+		return "", ErrPromptInterrupted
+	case res := <-lines.next():
+		lines.received()
+		return res.line, res.err
+	}
+}
+
+// terminalRestorer is implemented by the Terminals which change the state of
+// the terminal read from while prompting, like turning echo off, to restore
+// it when a prompt is interrupted
+type terminalRestorer interface {
+	Restore(r io.Reader) error
+}
+
+// lineResult is a line read by a lineReader
+type lineResult struct {
+	line string
+	err  error
+}
+
+// lineReader reads the lines of a reader in a single goroutine, one line per
+// request, so that nothing is read from the reader that was not asked for
+// and an interrupted request is answered on the next one
+type lineReader struct {
+	r        io.Reader
+	requests chan struct{}
+	results  chan lineResult
+
+	mu      sync.Mutex
+	pending bool
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	lr := &lineReader{
+		r:        r,
+		requests: make(chan struct{}, 1),
+		results:  make(chan lineResult, 1),
+	}
+	go lr.run()
+	return lr
+}
+
+func (lr *lineReader) run() {
+	for range lr.requests {
+		line, err := readLine(lr.r)
+		lr.results <- lineResult{line, err}
+	}
+}
+
+// next returns the channel the next line is sent on, requesting it unless
+// the request of an interrupted prompt is still pending
+func (lr *lineReader) next() <-chan lineResult {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	if !lr.pending {
+		lr.pending = true
+		lr.requests <- struct{}{}
+	}
+	return lr.results
+}
+
+// received clears the pending request once its line is received
+func (lr *lineReader) received() {
+	lr.mu.Lock()
+	lr.pending = false
+	lr.mu.Unlock()
+}
+
+// Confirm prompts for a yes or no answer, asking again until one is given.
+// Like Prompt it returns ErrPromptInterrupted when the context is done.
+func (c *Context) Confirm(message string) (bool, error) {
+	for {
+		answer, err := c.Prompt(message + " [y/n] ")
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// readLine reads up to and excluding the next newline one byte at a time, so
+// that nothing past the line is consumed from r
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				break
+			}
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
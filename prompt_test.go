@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingReader blocks reads until closed
+type blockingReader struct {
+	closed chan struct{}
+}
+
+func (r *blockingReader) Read([]byte) (int, error) {
+	<-r.closed
+	return 0, io.EOF
+}
+
+// restoringTerminal records the readers it is restored for
+type restoringTerminal struct {
+	fakeTerminal
+	restored []io.Reader
+}
+
+func (t *restoringTerminal) Restore(r io.Reader) error {
+	t.restored = append(t.restored, r)
+	return nil
+}
+
+func TestContext_Prompt(t *testing.T) {
+	out := &bytes.Buffer{}
+	app := &App{Reader: strings.NewReader("first\r\nsecond"), Writer: out}
+	c := NewContext(app, nil, nil)
+
+	answer, err := c.Prompt("name: ")
+	expect(t, err, nil)
+	expect(t, answer, "first")
+
+	answer, err = c.Prompt("again: ")
+	expect(t, err, nil)
+	expect(t, answer, "second")
+
+	_, err = c.Prompt("more: ")
+	expect(t, err, io.EOF)
+	expect(t, out.String(), "name: again: more: ")
+}
+
+func TestContext_Confirm(t *testing.T) {
+	app := &App{Reader: strings.NewReader("maybe\nYes\nn\n"), Writer: &bytes.Buffer{}}
+	c := NewContext(app, nil, nil)
+
+	ok, err := c.Confirm("continue?")
+	expect(t, err, nil)
+	expect(t, ok, true)
+
+	ok, err = c.Confirm("continue?")
+	expect(t, err, nil)
+	expect(t, ok, false)
+}
+
+func TestContext_PromptInterrupted(t *testing.T) {
+	r := &blockingReader{closed: make(chan struct{})}
+	defer close(r.closed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	app := &App{Reader: r, Writer: &bytes.Buffer{}}
+	c := NewContext(app, nil, &Context{Context: ctx})
+
+	start := time.Now()
+	_, err := c.Confirm("continue?")
+	if time.Since(start) > time.Second {
+		t.Errorf("prompt did not return in time")
+	}
+	expect(t, err, ErrPromptInterrupted)
+
+	defer func(code int) { PromptInterruptedExitCode = code }(PromptInterruptedExitCode)
+	PromptInterruptedExitCode = 3
+	expect(t, err.(ExitCoder).ExitCode(), 3)
+}
+
+func TestContext_PromptAfterInterrupted(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+
+	terminal := &restoringTerminal{}
+	app := &App{Reader: r, Writer: &bytes.Buffer{}, Terminal: terminal}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewContext(app, nil, &Context{Context: ctx}).Prompt("name: ")
+	expect(t, err, ErrPromptInterrupted)
+	expect(t, terminal.restored, []io.Reader{r})
+
+	// the line entered after the interruption answers the next prompt
+	go func() {
+		_, _ = io.WriteString(w, "late\nnext\n")
+	}()
+	c := NewContext(app, nil, &Context{Context: context.Background()})
+	answer, err := c.Prompt("name: ")
+	expect(t, err, nil)
+	expect(t, answer, "late")
+
+	answer, err = c.Prompt("again: ")
+	expect(t, err, nil)
+	expect(t, answer, "next")
+}
@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"flag"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maskedValue = "*****"
+
+// ReconstructArgs returns the arguments, without the program name, which
+// reproduce the flags set on the command line and the positional arguments of
+// this context and its ancestors. Flags are rendered with their canonical
+// name, slices as repeated occurrences and maps as key=value pairs. Flags set
+// through env or file are left out. The values of the flags named in masked
+// are replaced, which is useful to log invocations without leaking secrets.
+func (c *Context) ReconstructArgs(masked ...string) []string {
+	var lineage []*Context
+	for _, ctx := range c.Lineage() {
+		if ctx.flagSet != nil {
+			lineage = append([]*Context{ctx}, lineage...)
+		}
+	}
+
+	var args []string
+	for i, ctx := range lineage {
+		args = append(args, reconstructFlags(ctx, masked)...)
+
+		positionals := ctx.Args().Slice()
+		if i < len(lineage)-1 {
+			// the first positional is the name of the next command
+			if len(positionals) > 0 {
+				args = append(args, positionals[0])
+			}
+			continue
+		}
+
+		for _, arg := range positionals {
+			if strings.HasPrefix(arg, "-") {
+				args = append(args, "--")
+				break
+			}
+		}
+		args = append(args, positionals...)
+	}
+
+	return args
+}
+
+func reconstructFlags(ctx *Context, masked []string) []string {
+	flags := contextFlags(ctx)
+
+	visited := make(map[string]bool)
+	ctx.flagSet.Visit(func(f *flag.Flag) {
+		visited[f.Name] = true
+	})
+
+	var args []string
+	for _, fl := range flags {
+		names := fl.Names()
+		if len(names) == 0 {
+			continue
+		}
+
+		var ff *flag.Flag
+		for _, name := range names {
+			if visited[name] {
+				ff = ctx.flagSet.Lookup(name)
+				break
+			}
+		}
+		if ff == nil {
+			continue
+		}
+
+		prefix := prefixFor(names[0]) + names[0]
+		isMasked := false
+		for _, name := range masked {
+			if hasName(names, name) {
+				isMasked = true
+			}
+		}
+
+		for _, val := range reconstructValues(ff.Value) {
+			switch {
+			case isMasked:
+				args = append(args, prefix+"="+maskedValue)
+			case val == "":
+				// a bool flag set to true
+				args = append(args, prefix)
+			default:
+				args = append(args, prefix+"="+val)
+			}
+		}
+	}
+
+	return args
+}
+
+// contextFlags returns the flags declared by the command or the App of ctx
+func contextFlags(ctx *Context) []Flag {
+	// contexts created for an App carry an empty Command
+	if ctx.Command != nil && ctx.Command.Name != "" {
+		return ctx.Command.Flags
+	} else if ctx.App != nil {
+		return ctx.App.Flags
+	}
+	return nil
+}
+
+// boolFlagValue is the interface the stdlib flag package uses to detect bool
+// flags, which may be given without a value
+type boolFlagValue interface {
+	flag.Value
+	IsBoolFlag() bool
+}
+
+// reconstructValues returns one value per occurrence needed to reproduce v.
+// An empty string stands for a bool flag given without a value.
+func reconstructValues(v flag.Value) []string {
+	var vals []string
+
+	switch v := v.(type) {
+	case *StringSlice:
+		// the values as given, as the split ones could have escaped commas
+		if v.noSplit {
+			vals = append(vals, v.Value()...)
+		} else {
+			vals = append(vals, v.Raw()...)
+		}
+	case *PathSlice:
+		vals = append(vals, v.Value()...)
+	case *IntSlice:
+		for _, i := range v.Value() {
+			vals = append(vals, strconv.Itoa(i))
+		}
+	case *Int64Slice:
+		for _, i := range v.Value() {
+			vals = append(vals, strconv.FormatInt(i, 10))
+		}
+	case *Int32Slice:
+		for _, i := range v.Value() {
+			vals = append(vals, strconv.FormatInt(int64(i), 10))
+		}
+	case *Uint32Slice:
+		for _, i := range v.Value() {
+			vals = append(vals, strconv.FormatUint(uint64(i), 10))
+		}
+	case *Float32Slice:
+		for _, f := range v.Value() {
+			vals = append(vals, strconv.FormatFloat(float64(f), 'g', -1, 32))
+		}
+	case *Float64Slice:
+		for _, f := range v.Value() {
+			vals = append(vals, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case *DurationSlice:
+		for _, d := range v.Value() {
+			vals = append(vals, d.String())
+		}
+	case *TimestampSlice:
+		for _, t := range v.slice {
+			vals = append(vals, t.Format(v.layout))
+		}
+	case *GenericSlice:
+		// a value is rendered like Serialize does, as String may not be
+		// parseable
+		for _, g := range v.Value() {
+			if s, ok := g.(Serializer); ok {
+				vals = append(vals, s.Serialize())
+			} else {
+				vals = append(vals, g.String())
+			}
+		}
+	case *StringMap:
+		for k, val := range v.Value() {
+			vals = append(vals, k+"="+val)
+		}
+		sort.Strings(vals)
+	case *Timestamp:
+		if t := v.Value(); t != nil {
+			vals = append(vals, t.Format(v.layout))
+		}
+	case *boolValue:
+		if v.String() != "true" {
+			return []string{"false"}
+		}
+		// repeat the flag so that its count is reproduced as well
+		for i := 0; i < v.Count() || i == 0; i++ {
+			vals = append(vals, "")
+		}
+	case *countValue:
+		if v.Count() == 0 {
+			return []string{"false"}
+		}
+		for i := 0; i < v.Count(); i++ {
+			vals = append(vals, "")
+		}
+	case boolFlagValue:
+		if v.IsBoolFlag() && v.String() == "true" {
+			return []string{""}
+		}
+		vals = append(vals, v.String())
+	default:
+		vals = append(vals, v.String())
+	}
+
+	return vals
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestContext_ReconstructArgs(t *testing.T) {
+	type state struct {
+		args     []string
+		name     string
+		tags     []string
+		labels   map[string]string
+		verbose  int
+		token    string
+		rootArgs []string
+	}
+
+	var (
+		got           state
+		reconstructed []string
+		masked        []string
+	)
+
+	newApp := func() *App {
+		return &App{
+			Writer: ioutil.Discard,
+			Flags: []Flag{
+				&IntFlag{Name: "level"},
+			},
+			Commands: []*Command{
+				{
+					Name:    "deploy",
+					Aliases: []string{"d"},
+					Flags: []Flag{
+						&StringFlag{Name: "name", Aliases: []string{"n"}},
+						&StringSliceFlag{Name: "tag", Aliases: []string{"t"}},
+						&StringMapFlag{Name: "label"},
+						&BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+						&StringFlag{Name: "token"},
+						&BoolFlag{Name: "dry-run"},
+					},
+					Action: func(ctx *Context) error {
+						got = state{
+							args:    ctx.Args().Slice(),
+							name:    ctx.String("name"),
+							tags:    ctx.StringSlice("tag"),
+							labels:  ctx.StringMap("label"),
+							verbose: ctx.Count("verbose"),
+							token:   ctx.String("token"),
+						}
+						reconstructed = ctx.ReconstructArgs()
+						masked = ctx.ReconstructArgs("token")
+						return nil
+					},
+				},
+			},
+		}
+	}
+
+	err := newApp().Run([]string{"app", "--level", "2", "d", "-n", "web", "-t", "a", "-t", "b",
+		"--label", "env=prod", "--label", "tier=1", "-v", "-v", "--token", "s3cr3t", "target", "-x"})
+	expect(t, err, nil)
+
+	expect(t, reconstructed, []string{"--level=2", "d", "--name=web", "--tag=a", "--tag=b",
+		"--label=env=prod", "--label=tier=1", "--verbose", "--verbose", "--token=s3cr3t", "--", "target", "-x"})
+	expect(t, masked[9], "--token=*****")
+
+	first := got
+	err = newApp().Run(append([]string{"app"}, reconstructed...))
+	expect(t, err, nil)
+	expect(t, got, first)
+}
+
+func TestContext_ReconstructArgs_EscapedComma(t *testing.T) {
+	var (
+		tags          []string
+		reconstructed []string
+	)
+
+	newApp := func() *App {
+		return &App{
+			Writer: ioutil.Discard,
+			Flags: []Flag{
+				&StringSliceFlag{Name: "tag"},
+			},
+			Action: func(ctx *Context) error {
+				tags = ctx.StringSlice("tag")
+				reconstructed = ctx.ReconstructArgs()
+				return nil
+			},
+		}
+	}
+
+	err := newApp().Run([]string{"app", "--tag", `a\,b`, "--tag", "c,d"})
+	expect(t, err, nil)
+	expect(t, tags, []string{"a,b", "c", "d"})
+	expect(t, reconstructed, []string{`--tag=a\,b`, "--tag=c,d"})
+
+	err = newApp().Run(append([]string{"app"}, reconstructed...))
+	expect(t, err, nil)
+	expect(t, tags, []string{"a,b", "c", "d"})
+}
+
+func TestContext_ReconstructArgs_GenericSlice(t *testing.T) {
+	var (
+		selectors     []interface{}
+		reconstructed []string
+	)
+
+	newApp := func() *App {
+		return &App{
+			Writer: ioutil.Discard,
+			Flags: []Flag{
+				&GenericSliceFlag{Name: "selector", Aliases: []string{"s"}, Value: newSelectorSlice()},
+			},
+			Action: func(ctx *Context) error {
+				selectors = ctx.GenericSlice("selector")
+				reconstructed = ctx.ReconstructArgs()
+				return nil
+			},
+		}
+	}
+
+	err := newApp().Run([]string{"app", "-s", "app=eq=web", "-s", "tier=ne=db"})
+	expect(t, err, nil)
+	expect(t, reconstructed, []string{"--selector=app=eq=web", "--selector=tier=ne=db"})
+
+	first := selectors
+	err = newApp().Run(append([]string{"app"}, reconstructed...))
+	expect(t, err, nil)
+	expect(t, selectors, first)
+}
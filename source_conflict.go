@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"flag"
+	"io/ioutil"
+	"strings"
+	"syscall"
+)
+
+// The kinds of the sources of a flag value, in the order of their precedence
+const (
+	SourceCommandLine = "command line"
+	SourceEnv         = "env"
+	SourceFile        = "file"
+	SourceConfig      = "config"
+)
+
+// SourceValue is a value of a flag given by one of its sources
+type SourceValue struct {
+	// Kind is the kind of the source, like SourceEnv
+	Kind string
+	// Detail tells the sources of a kind apart, like the name of the
+	// environment variable or the path of the file
+	Detail string
+	// Value is the value as given by the source, masked for the
+	// SensitiveFlags of the App
+	Value string
+}
+
+// AddSourceValue records the value of the flag name held by a source other
+// than the command line, the environment and files, like a config file. The
+// value is reported to OnSourceConflict when it disagrees with the value of
+// another source, whether it is the one chosen or not. Input sources call it
+// from LoadFlagValues.
+func (c *Context) AddSourceValue(name string, value SourceValue) {
+	if c.sourceValues == nil {
+		c.sourceValues = map[string][]SourceValue{}
+	}
+	c.sourceValues[name] = append(c.sourceValues[name], value)
+}
+
+// commandLineValues returns the values of the flags of set given on the
+// command line, by their name, as they were given in the args set parsed up
+// to the first positional argument, so that they compare with the raw values
+// of the other sources. The values
+// of a flag given several times are joined with commas, while a flag given
+// without a value, like a bool flag, has the value it was set to.
+func commandLineValues(set *flag.FlagSet, args []string) map[string]string {
+	raw := map[string][]string{}
+	noValue := map[string]bool{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+
+		name := strings.TrimPrefix(arg[1:], "-")
+		value, hasValue := "", false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+		f := set.Lookup(name)
+		if f == nil {
+			continue
+		}
+		if !hasValue {
+			if bf, ok := f.Value.(boolFlagValue); ok && bf.IsBoolFlag() {
+				noValue[name] = true
+				continue
+			}
+			if i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+		}
+		raw[name] = append(raw[name], value)
+	}
+
+	values := map[string]string{}
+	set.Visit(func(f *flag.Flag) {
+		if r, ok := raw[f.Name]; ok && !noValue[f.Name] {
+			values[f.Name] = strings.Join(r, ",")
+			return
+		}
+		values[f.Name] = f.Value.String()
+	})
+	return values
+}
+
+// reportSourceConflicts calls OnSourceConflict for the flags whose sources
+// hold disagreeing values. The value of the first source wins: the command
+// line, then the environment variables and files in the order they are
+// declared, then the values added by AddSourceValue. Defaults never conflict.
+func (a *App) reportSourceConflicts(flags []Flag, cCtx *Context, commandLine map[string]string) {
+	if a.OnSourceConflict == nil {
+		return
+	}
+
+	for _, f := range flags {
+		values := flagSourceValues(f, cCtx, commandLine)
+		if len(values) < 2 {
+			continue
+		}
+
+		disagree := false
+		for _, v := range values[1:] {
+			if v.Value != values[0].Value {
+				disagree = true
+			}
+		}
+		if !disagree {
+			continue
+		}
+
+		if a.isSensitiveFlag(f) {
+			for i := range values {
+				values[i].Value = maskedValue
+			}
+		}
+		a.OnSourceConflict(f.Names()[0], values[0], values[1:])
+	}
+}
+
+// flagSourceValues returns the values of f given by its sources, in the order
+// of their precedence
+func flagSourceValues(f Flag, cCtx *Context, commandLine map[string]string) []SourceValue {
+	var values []SourceValue
+	for _, name := range f.Names() {
+		if v, ok := commandLine[name]; ok {
+			values = append(values, SourceValue{Kind: SourceCommandLine, Detail: prefixFor(name) + name, Value: v})
+			break
+		}
+	}
+
+	for _, envVar := range flagStringSliceField(f, "EnvVars") {
+		envVar = strings.TrimSpace(envVar)
+		if v, ok := syscall.Getenv(envVar); ok && v != "" {
+			values = append(values, SourceValue{Kind: SourceEnv, Detail: envVar, Value: v})
+		}
+	}
+
+	for _, path := range strings.Split(flagStringField(f, "FilePath"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		if data, err := ioutil.ReadFile(path); err == nil {
+			values = append(values, SourceValue{Kind: SourceFile, Detail: path, Value: strings.TrimSpace(string(data))})
+		}
+	}
+
+	for _, name := range f.Names() {
+		values = append(values, cCtx.sourceValues[name]...)
+	}
+	return values
+}
+
+func (a *App) isSensitiveFlag(f Flag) bool {
+	for _, name := range f.Names() {
+		if hasName(a.SensitiveFlags, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type sourceConflict struct {
+	flag    string
+	chosen  SourceValue
+	ignored []SourceValue
+}
+
+func sourceConflictTestApp(conflicts *[]sourceConflict, config map[string]string) *App {
+	app := newTestApp()
+	app.Flags = []Flag{
+		&StringFlag{Name: "timeout", Value: "10s", EnvVars: []string{"MYAPP_TIMEOUT", "TIMEOUT"}},
+		&StringFlag{Name: "token", Value: "none", EnvVars: []string{"MYAPP_TOKEN"}},
+	}
+	app.LoadFlagValues = func(c *Context) error {
+		for name, value := range config {
+			c.AddSourceValue(name, SourceValue{Kind: SourceConfig, Detail: "app.yaml", Value: value})
+		}
+		return nil
+	}
+	app.OnSourceConflict = func(flag string, chosen SourceValue, ignored []SourceValue) {
+		*conflicts = append(*conflicts, sourceConflict{flag: flag, chosen: chosen, ignored: ignored})
+	}
+	app.Action = func(*Context) error { return nil }
+	return app
+}
+
+func TestApp_OnSourceConflict(t *testing.T) {
+	defer resetEnvVar("MYAPP_TIMEOUT")()
+	defer resetEnvVar("TIMEOUT")()
+	defer resetEnvVar("MYAPP_TOKEN")()
+	_ = os.Setenv("MYAPP_TIMEOUT", "30s")
+	_ = os.Setenv("TIMEOUT", "30s")
+	_ = os.Unsetenv("MYAPP_TOKEN")
+
+	var conflicts []sourceConflict
+	app := sourceConflictTestApp(&conflicts, map[string]string{"timeout": "1m", "token": "s3cret"})
+	err := app.Run([]string{"app"})
+	expect(t, err, nil)
+
+	expect(t, conflicts, []sourceConflict{{
+		flag:   "timeout",
+		chosen: SourceValue{Kind: SourceEnv, Detail: "MYAPP_TIMEOUT", Value: "30s"},
+		ignored: []SourceValue{
+			{Kind: SourceEnv, Detail: "TIMEOUT", Value: "30s"},
+			{Kind: SourceConfig, Detail: "app.yaml", Value: "1m"},
+		},
+	}})
+}
+
+func TestApp_OnSourceConflict_CommandLineAndFile(t *testing.T) {
+	defer resetEnvVar("MYAPP_TIMEOUT")()
+	defer resetEnvVar("TIMEOUT")()
+	defer resetEnvVar("MYAPP_TOKEN")()
+	_ = os.Unsetenv("MYAPP_TIMEOUT")
+	_ = os.Unsetenv("TIMEOUT")
+	_ = os.Setenv("MYAPP_TOKEN", "from-env")
+
+	file, err := ioutil.TempFile("", "urfave_cli_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString("from-file\n")
+	_ = file.Close()
+	defer os.Remove(file.Name())
+
+	var conflicts []sourceConflict
+	app := sourceConflictTestApp(&conflicts, nil)
+	app.Flags[1].(*StringFlag).FilePath = file.Name()
+	app.SensitiveFlags = []string{"token"}
+
+	err = app.Run([]string{"app", "--token", "from-cli", "--timeout", "5s"})
+	expect(t, err, nil)
+
+	expect(t, conflicts, []sourceConflict{{
+		flag:   "token",
+		chosen: SourceValue{Kind: SourceCommandLine, Detail: "--token", Value: maskedValue},
+		ignored: []SourceValue{
+			{Kind: SourceEnv, Detail: "MYAPP_TOKEN", Value: maskedValue},
+			{Kind: SourceFile, Detail: file.Name(), Value: maskedValue},
+		},
+	}})
+}
+
+func TestApp_OnSourceConflict_Agreeing(t *testing.T) {
+	defer resetEnvVar("MYAPP_TIMEOUT")()
+	defer resetEnvVar("TIMEOUT")()
+	_ = os.Setenv("MYAPP_TIMEOUT", "30s")
+	_ = os.Unsetenv("TIMEOUT")
+
+	var conflicts []sourceConflict
+	app := sourceConflictTestApp(&conflicts, map[string]string{"timeout": "30s"})
+	err := app.Run([]string{"app"})
+	expect(t, err, nil)
+
+	// the defaults differ, but never conflict
+	expect(t, len(conflicts), 0)
+}
+
+func TestApp_OnSourceConflict_CommandLineAgreeing(t *testing.T) {
+	defer resetEnvVar("ZZ_TIMEOUT")()
+	defer resetEnvVar("ZZ_TAGS")()
+	defer resetEnvVar("ZZ_VERBOSE")()
+	_ = os.Setenv("ZZ_TIMEOUT", "60s")
+	_ = os.Setenv("ZZ_TAGS", "a,b")
+	_ = os.Setenv("ZZ_VERBOSE", "true")
+
+	var conflicts []sourceConflict
+	app := sourceConflictTestApp(&conflicts, nil)
+	app.Flags = []Flag{
+		&DurationFlag{Name: "timeout", EnvVars: []string{"ZZ_TIMEOUT"}},
+		&StringSliceFlag{Name: "tags", EnvVars: []string{"ZZ_TAGS"}},
+		&BoolFlag{Name: "verbose", EnvVars: []string{"ZZ_VERBOSE"}},
+	}
+
+	for _, args := range [][]string{
+		{"app", "--timeout", "60s", "--tags", "a,b", "--verbose"},
+		{"app", "--timeout=60s", "--tags", "a", "--tags", "b", "--verbose=true"},
+	} {
+		expect(t, app.Run(args), nil)
+	}
+	expect(t, len(conflicts), 0)
+
+	expect(t, app.Run([]string{"app", "--timeout", "1m"}), nil)
+	expect(t, conflicts, []sourceConflict{{
+		flag:    "timeout",
+		chosen:  SourceValue{Kind: SourceCommandLine, Detail: "--timeout", Value: "1m"},
+		ignored: []SourceValue{{Kind: SourceEnv, Detail: "ZZ_TIMEOUT", Value: "60s"}},
+	}})
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+)
+
+// SuggestionsMinDistance is the largest edit distance between a mistyped flag
+// or command name and a known one for the known one to be suggested
+var SuggestionsMinDistance = 2
+
+// suggestFlag returns the visible flag closest to name in the lineage of ctx,
+// prefixed with dashes, or an empty string if none is close enough
+func suggestFlag(name string, ctx *Context) string {
+	var candidates []string
+	hidden := map[string]bool{}
+	for _, c := range ctx.Lineage() {
+		if c.Command != nil {
+			for _, f := range c.Command.Flags {
+				candidates = append(candidates, f.Names()...)
+			}
+			hiddenFlagNames(c.Command.Flags, hidden)
+		}
+		if c.App != nil {
+			hiddenFlagNames(c.App.Flags, hidden)
+		}
+		if c.flagSet != nil {
+			c.flagSet.VisitAll(func(f *flag.Flag) {
+				candidates = append(candidates, f.Name)
+			})
+		}
+	}
+
+	var visible []string
+	for _, candidate := range candidates {
+		if !hidden[candidate] {
+			visible = append(visible, candidate)
+		}
+	}
+
+	if suggestion := closestName(name, visible); suggestion != "" {
+		return prefixFor(suggestion) + suggestion
+	}
+	return ""
+}
+
+// hiddenFlagNames adds the names of the hidden flags to names
+func hiddenFlagNames(flags []Flag, names map[string]bool) {
+	for _, f := range flags {
+		if isHiddenFlag(f) {
+			for _, name := range f.Names() {
+				names[name] = true
+			}
+		}
+	}
+}
+
+// suggestCommand returns the name of the visible command closest to name, or
+// an empty string if none is close enough
+func suggestCommand(commands []*Command, name string) string {
+	var candidates []string
+	for _, c := range commands {
+		if !c.Hidden {
+			candidates = append(candidates, c.Names()...)
+		}
+	}
+
+	return closestName(name, candidates)
+}
+
+func closestName(name string, candidates []string) string {
+	suggestion := ""
+	best := SuggestionsMinDistance + 1
+	for _, candidate := range candidates {
+		if candidate == "" || candidate == name {
+			continue
+		}
+		if d := levenshtein(name, candidate); d < best {
+			best = d
+			suggestion = candidate
+		}
+	}
+	return suggestion
+}
+
+// addFlagSuggestion attaches a "did you mean" suggestion to unknown flag
+// errors
+func addFlagSuggestion(err error, ctx *Context) {
+	if ufErr, ok := err.(*errUnknownFlag); ok {
+		ufErr.suggestion = suggestFlag(ufErr.name, ctx)
+	}
+}
+
+func didYouMean(suggestion string) string {
+	if suggestion == "" {
+		return ""
+	}
+	return fmt.Sprintf(". Did you mean '%s'?", suggestion)
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(br)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
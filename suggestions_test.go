@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"verbose", "verbose", 0},
+		{"verbsoe", "verbose", 2},
+		{"verbos", "verbose", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"héllo", "hello", 1},
+	}
+
+	for _, test := range tests {
+		expect(t, levenshtein(test.a, test.b), test.expected)
+	}
+}
+
+func TestSuggestFlag(t *testing.T) {
+	parentSet := flag.NewFlagSet("parent", 0)
+	parentSet.Bool("verbose", false, "")
+	parentCtx := NewContext(nil, parentSet, nil)
+
+	set := flag.NewFlagSet("child", 0)
+	set.String("output", "", "")
+	set.Bool("q", false, "")
+	ctx := NewContext(nil, set, parentCtx)
+
+	expect(t, suggestFlag("verbsoe", ctx), "--verbose")
+	expect(t, suggestFlag("ouptut", ctx), "--output")
+	expect(t, suggestFlag("w", ctx), "-q")
+	expect(t, suggestFlag("something", ctx), "")
+}
+
+func TestSuggestFlag_MinDistance(t *testing.T) {
+	defer func(d int) { SuggestionsMinDistance = d }(SuggestionsMinDistance)
+
+	set := flag.NewFlagSet("test", 0)
+	set.Bool("verbose", false, "")
+	ctx := NewContext(nil, set, nil)
+
+	SuggestionsMinDistance = 1
+	expect(t, suggestFlag("verbsoe", ctx), "")
+	expect(t, suggestFlag("verbos", ctx), "--verbose")
+}
+
+func TestSuggestCommand(t *testing.T) {
+	commands := []*Command{
+		{Name: "remote", Aliases: []string{"rem"}},
+		{Name: "status"},
+		{Name: "secret", Hidden: true},
+	}
+
+	expect(t, suggestCommand(commands, "remtoe"), "remote")
+	expect(t, suggestCommand(commands, "statsu"), "status")
+	expect(t, suggestCommand(commands, "secrte"), "")
+	expect(t, suggestCommand(commands, "push"), "")
+}
+
+func TestApp_Run_SuggestsFlag(t *testing.T) {
+	app := newTestApp()
+	app.Flags = []Flag{&BoolFlag{Name: "verbose"}}
+
+	err := app.Run([]string{"foo", "--verbsoe"})
+	expect(t, err.Error(), "flag provided but not defined: -verbsoe. Did you mean '--verbose'?")
+}
+
+func TestApp_Run_SuggestsFlag_hidden(t *testing.T) {
+	app := newTestApp()
+	app.Flags = []Flag{&BoolFlag{Name: "verbose"}, &StringFlag{Name: "debug-dump", Hidden: true}}
+
+	err := app.Run([]string{"foo", "--debug-dupm"})
+	expect(t, err.Error(), "flag provided but not defined: -debug-dupm")
+
+	err = app.Run([]string{"foo", "--debug-dump", "all"})
+	expect(t, err, nil)
+}
+
+func TestApp_Run_SuggestsSubcommand(t *testing.T) {
+	app := newTestApp()
+	app.Commands = []*Command{
+		{
+			Name: "remote",
+			Subcommands: []*Command{
+				{Name: "add"},
+				{Name: "remove"},
+			},
+		},
+	}
+
+	err := app.Run([]string{"foo", "remote", "remvoe"})
+	expect(t, err.Error(), "No help topic for 'remvoe'. Did you mean 'remove'?")
+}
@@ -13,7 +13,7 @@ VERSION:
    {{.Version}}{{end}}{{end}}{{if .Description}}
 
 DESCRIPTION:
-   {{.Description}}{{end}}{{if len .Authors}}
+   {{wrap .Description 3}}{{end}}{{if len .Authors}}
 
 AUTHOR{{with $length := len .Authors}}{{if ne 1 $length}}S{{end}}{{end}}:
    {{range $index, $author := .Authors}}{{if $index}}
@@ -22,14 +22,20 @@ AUTHOR{{with $length := len .Authors}}{{if ne 1 $length}}S{{end}}{{end}}:
 COMMANDS:{{range .VisibleCategories}}{{if .Name}}
    {{.Name}}:{{range .VisibleCommands}}
      {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{else}}{{range .VisibleCommands}}
-   {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{end}}{{end}}{{end}}{{if .VisibleFlags}}
+   {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{end}}{{end}}{{end}}{{if .VisibleFlagCategories}}
 
-GLOBAL OPTIONS:
-   {{range $index, $option := .VisibleFlags}}{{if $index}}
-   {{end}}{{$option}}{{end}}{{end}}{{if .Copyright}}
+GLOBAL OPTIONS:{{range .VisibleFlagCategories}}
+   {{.Name}}:{{range flagLines .Flags 5 $.VisibleFlags}}
+{{.}}{{end}}{{end}}{{else if .VisibleFlags}}
+
+GLOBAL OPTIONS:{{range flagLines .VisibleFlags 3}}
+{{.}}{{end}}{{end}}{{if .Copyright}}
 
 COPYRIGHT:
-   {{.Copyright}}{{end}}
+   {{.Copyright}}{{end}}{{if .DocsLink}}
+
+DOCUMENTATION:
+   {{.DocsLink}}{{end}}
 `
 
 // CommandHelpTemplate is the text template for the command help topic.
@@ -45,11 +51,17 @@ CATEGORY:
    {{.Category}}{{end}}{{if .Description}}
 
 DESCRIPTION:
-   {{.Description}}{{end}}{{if .VisibleFlags}}
+   {{wrap .Description 3}}{{end}}{{if .VisibleFlagCategories}}
+
+OPTIONS:{{range .VisibleFlagCategories}}
+   {{.Name}}:{{range flagLines .Flags 5 $.VisibleFlags}}
+{{.}}{{end}}{{end}}{{else if .VisibleFlags}}
 
-OPTIONS:
-   {{range .VisibleFlags}}{{.}}
-   {{end}}{{end}}
+OPTIONS:{{range flagLines .VisibleFlags 3}}
+{{.}}{{end}}{{end}}{{if .DocsLink}}
+
+DOCUMENTATION:
+   {{.DocsLink}}{{end}}
 `
 
 // SubcommandHelpTemplate is the text template for the subcommand help topic.
@@ -62,16 +74,22 @@ USAGE:
    {{if .UsageText}}{{.UsageText}}{{else}}{{.HelpName}} command{{if .VisibleFlags}} [command options]{{end}} {{if .ArgsUsage}}{{.ArgsUsage}}{{else}}[arguments...]{{end}}{{end}}{{if .Description}}
 
 DESCRIPTION:
-   {{.Description}}{{end}}
+   {{wrap .Description 3}}{{end}}
 
 COMMANDS:{{range .VisibleCategories}}{{if .Name}}
    {{.Name}}:{{range .VisibleCommands}}
      {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{else}}{{range .VisibleCommands}}
-   {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{end}}{{end}}{{if .VisibleFlags}}
+   {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{end}}{{end}}{{if .VisibleFlagCategories}}
+
+OPTIONS:{{range .VisibleFlagCategories}}
+   {{.Name}}:{{range flagLines .Flags 5 $.VisibleFlags}}
+{{.}}{{end}}{{end}}{{else if .VisibleFlags}}
+
+OPTIONS:{{range flagLines .VisibleFlags 3}}
+{{.}}{{end}}{{end}}{{if .DocsLink}}
 
-OPTIONS:
-   {{range .VisibleFlags}}{{.}}
-   {{end}}{{end}}
+DOCUMENTATION:
+   {{.DocsLink}}{{end}}
 `
 
 var MarkdownDocTemplate = `% {{ .App.Name }} 8
@@ -103,7 +121,11 @@ var MarkdownDocTemplate = `% {{ .App.Name }} 8
 {{ end }}{{ if .Commands }}
 # COMMANDS
 {{ range $v := .Commands }}
-{{ $v }}{{ end }}{{ end }}`
+{{ $v }}{{ end }}{{ end }}{{ if .App.DocsLink }}
+# SEE ALSO
+
+[Documentation]({{ .App.DocsLink }})
+{{ end }}`
 
 var FishCompletionTemplate = `# {{ .App.Name }} fish shell completion
 
@@ -118,3 +140,76 @@ end
 
 {{ range $v := .Completions }}{{ $v }}
 {{ end }}`
+
+var BashCompletionTemplate = `#! /bin/bash
+# {{ .App.Name }} bash shell completion
+
+_cli_bash_autocomplete() {
+  if [[ "${COMP_WORDS[0]}" != "source" ]]; then
+    local cur opts base
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ "$cur" == "-"* ]]; then
+      opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} ${cur} --generate-bash-completion )
+    else
+      opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion )
+    fi
+    COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
+    return 0
+  fi
+}
+
+complete -o bashdefault -o default -o nospace -F _cli_bash_autocomplete {{ .App.Name }}
+`
+
+var ZshCompletionTemplate = `#compdef {{ .App.Name }}
+# {{ .App.Name }} zsh shell completion
+{{ range $v := .Functions }}
+{{ $v }}
+{{ end }}{{ if .App.EnableBashCompletion }}
+__{{ .Function }}_complete() {
+  local -a opts
+  opts=("${(@f)$(_CLI_ZSH_AUTOCOMPLETE_HACK=1 ${__{{ .Function }}_words[@]} --generate-bash-completion 2>/dev/null)}")
+
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  fi
+}
+{{ end }}
+if [[ "$funcstack[1]" == "_{{ .Function }}" ]]; then
+  _{{ .Function }} "$@"
+else
+  compdef _{{ .Function }} {{ .App.Name }}
+fi
+`
+
+var PowerShellCompletionTemplate = `using namespace System.Management.Automation
+# {{ .App.Name }} powershell completion
+
+$__{{ .Identifier }}Completer = {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $commands = @{
+{{ range $v := .Commands }}        {{ $v }}
+{{ end }}    }
+
+    $completions = @{
+{{ range $v := .Completions }}{{ $v }}
+{{ end }}    }
+
+    $command = '{{ .Root }}'
+    foreach ($element in $commandAst.CommandElements | Select-Object -Skip 1) {
+        if ($element.Extent.EndOffset -ge $cursorPosition) {
+            break
+        }
+        $next = "$command;$element"
+        if ($commands.ContainsKey($next)) {
+            $command = $commands[$next]
+        }
+    }
+
+    $completions[$command] | Where-Object { $_.CompletionText -like "$wordToComplete*" }
+}
+
+Register-ArgumentCompleter -Native -CommandName '{{ .Root }}' -ScriptBlock $__{{ .Identifier }}Completer
+`
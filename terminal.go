@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// Terminal detects whether output goes to a terminal and how wide it is. All
+// features depending on the terminal go through App.Terminal, so that it can
+// be replaced in tests.
+type Terminal interface {
+	// IsTerminal reports whether w is a terminal
+	IsTerminal(w io.Writer) bool
+	// Width returns the number of columns of w, or 0 if unknown
+	Width(w io.Writer) int
+}
+
+// terminalHeighter is implemented by the Terminals which know the number of
+// rows of a terminal, used by App.EnablePager
+type terminalHeighter interface {
+	// Height returns the number of rows of w, or 0 if unknown
+	Height(w io.Writer) int
+}
+
+// DefaultTerminal is the Terminal used when App.Terminal is not set
+var DefaultTerminal Terminal = osTerminal{}
+
+type osTerminal struct{}
+
+func (osTerminal) IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func (t osTerminal) Width(w io.Writer) int {
+	if !t.IsTerminal(w) {
+		return 0
+	}
+
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+
+	return terminalWidth(w.(*os.File))
+}
+
+func (t osTerminal) Height(w io.Writer) int {
+	if !t.IsTerminal(w) {
+		return 0
+	}
+
+	if lines, err := strconv.Atoi(os.Getenv("LINES")); err == nil && lines > 0 {
+		return lines
+	}
+
+	return terminalHeight(w.(*os.File))
+}
+
+// widthWriter carries the width of the terminal an io.Writer writes to, so
+// that help printers can wrap their output
+type widthWriter struct {
+	io.Writer
+	width int
+}
+
+func (a *App) terminal() Terminal {
+	if a.Terminal != nil {
+		return a.Terminal
+	}
+	return DefaultTerminal
+}
+
+// helpWriter returns w annotated with its width when it is known
+func (a *App) helpWriter(w io.Writer) io.Writer {
+	if _, ok := w.(*widthWriter); ok {
+		return w
+	}
+	if width := a.terminal().Width(w); width > 0 {
+		return &widthWriter{Writer: w, width: width}
+	}
+	return w
+}
+
+// terminalHeight returns the number of rows of the terminal w writes to, or 0
+// if unknown
+func (a *App) terminalHeight(w io.Writer) int {
+	if t, ok := a.terminal().(terminalHeighter); ok {
+		return t.Height(w)
+	}
+	return 0
+}
+
+func writerWidth(w io.Writer) int {
+	if ww, ok := w.(*widthWriter); ok {
+		return ww.width
+	}
+	return 0
+}
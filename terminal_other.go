@@ -0,0 +1,14 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package cli
+
+import "os"
+
+func terminalWidth(*os.File) int {
+	return 0
+}
+
+func terminalHeight(*os.File) int {
+	return 0
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+type fakeTerminal struct {
+	width int
+}
+
+func (t *fakeTerminal) IsTerminal(io.Writer) bool {
+	return true
+}
+
+func (t *fakeTerminal) Width(io.Writer) int {
+	return t.width
+}
+
+func TestApp_Terminal_WrapsHelp(t *testing.T) {
+	description := "This is a long description which does not fit in a narrow terminal at all"
+
+	for _, args := range [][]string{{"app", "--help"}, {"app", "help", "cmd"}} {
+		output := &bytes.Buffer{}
+		app := &App{
+			Writer:      output,
+			Terminal:    &fakeTerminal{width: 30},
+			Description: description,
+			Commands: []*Command{
+				{Name: "cmd", Description: description},
+			},
+		}
+
+		err := app.Run(args)
+		expect(t, err, nil)
+
+		expected := "   This is a long description\n" +
+			"   which does not fit in a\n" +
+			"   narrow terminal at all\n"
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("expected description to be wrapped at 30 columns, got:\n%s", output.String())
+		}
+	}
+}
+
+func TestApp_Terminal_NoWidth(t *testing.T) {
+	description := "This is a long description which does not fit in a narrow terminal at all"
+	output := &bytes.Buffer{}
+	app := &App{
+		Writer:      output,
+		Terminal:    &fakeTerminal{},
+		Description: description,
+	}
+
+	err := app.Run([]string{"app", "--help"})
+	expect(t, err, nil)
+
+	if !strings.Contains(output.String(), "   "+description+"\n") {
+		t.Errorf("expected description not to be wrapped, got:\n%s", output.String())
+	}
+}
+
+func TestApp_Terminal_ColorEnabled(t *testing.T) {
+	os.Clearenv()
+
+	var enabled bool
+	app := &App{
+		Writer:   &bytes.Buffer{},
+		Terminal: &fakeTerminal{width: 80},
+		Action: func(c *Context) error {
+			enabled = c.ColorEnabled()
+			return nil
+		},
+	}
+
+	err := app.Run([]string{"app"})
+	expect(t, err, nil)
+	expect(t, enabled, true)
+}
+
+func TestWrap(t *testing.T) {
+	expect(t, wrap("one two three", 2, 0), "one two three")
+	expect(t, wrap("one two three", 2, 11), "one two\n  three")
+	expect(t, wrap("one\ntwo three four", 2, 11), "one\n  two three\n  four")
+}
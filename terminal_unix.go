@@ -0,0 +1,36 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package cli
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func terminalWidth(f *os.File) int {
+	_, cols := terminalSize(f)
+	return cols
+}
+
+func terminalHeight(f *os.File) int {
+	rows, _ := terminalSize(f)
+	return rows
+}
+
+// terminalSize returns the number of rows and columns of the terminal f, or 0
+// and 0 if unknown
+func terminalSize(f *os.File) (rows, cols int) {
+	var ws struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(),
+		uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0, 0
+	}
+
+	return int(ws.rows), int(ws.cols)
+}
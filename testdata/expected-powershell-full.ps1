@@ -0,0 +1,84 @@
+using namespace System.Management.Automation
+# greet powershell completion
+
+$__greetCompleter = {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $commands = @{
+        'greet;config' = 'greet;config'
+        'greet;c' = 'greet;config'
+        'greet;config;sub-config' = 'greet;config;sub-config'
+        'greet;config;s' = 'greet;config;sub-config'
+        'greet;config;ss' = 'greet;config;sub-config'
+        'greet;info' = 'greet;info'
+        'greet;i' = 'greet;info'
+        'greet;in' = 'greet;info'
+        'greet;some-command' = 'greet;some-command'
+    }
+
+    $completions = @{
+        'greet' = @(
+            [CompletionResult]::new('config', 'config', [CompletionResultType]::ParameterValue, 'another usage test')
+            [CompletionResult]::new('c', 'c', [CompletionResultType]::ParameterValue, 'another usage test')
+            [CompletionResult]::new('info', 'info', [CompletionResultType]::ParameterValue, 'retrieve generic information')
+            [CompletionResult]::new('i', 'i', [CompletionResultType]::ParameterValue, 'retrieve generic information')
+            [CompletionResult]::new('in', 'in', [CompletionResultType]::ParameterValue, 'retrieve generic information')
+            [CompletionResult]::new('some-command', 'some-command', [CompletionResultType]::ParameterValue, 'some-command')
+            [CompletionResult]::new('--socket', '--socket', [CompletionResultType]::ParameterName, 'some ''usage'' text')
+            [CompletionResult]::new('-s', '-s', [CompletionResultType]::ParameterName, 'some ''usage'' text')
+            [CompletionResult]::new('--flag', '--flag', [CompletionResultType]::ParameterName, '--flag')
+            [CompletionResult]::new('--fl', '--fl', [CompletionResultType]::ParameterName, '--fl')
+            [CompletionResult]::new('-f', '-f', [CompletionResultType]::ParameterName, '-f')
+            [CompletionResult]::new('--another-flag', '--another-flag', [CompletionResultType]::ParameterName, 'another usage text')
+            [CompletionResult]::new('-b', '-b', [CompletionResultType]::ParameterName, 'another usage text')
+            [CompletionResult]::new('--help', '--help', [CompletionResultType]::ParameterName, 'show help')
+            [CompletionResult]::new('-h', '-h', [CompletionResultType]::ParameterName, 'show help')
+            [CompletionResult]::new('--version', '--version', [CompletionResultType]::ParameterName, 'print the version')
+            [CompletionResult]::new('-v', '-v', [CompletionResultType]::ParameterName, 'print the version')
+        )
+        'greet;config' = @(
+            [CompletionResult]::new('sub-config', 'sub-config', [CompletionResultType]::ParameterValue, 'another usage test')
+            [CompletionResult]::new('s', 's', [CompletionResultType]::ParameterValue, 'another usage test')
+            [CompletionResult]::new('ss', 'ss', [CompletionResultType]::ParameterValue, 'another usage test')
+            [CompletionResult]::new('--flag', '--flag', [CompletionResultType]::ParameterName, '--flag')
+            [CompletionResult]::new('--fl', '--fl', [CompletionResultType]::ParameterName, '--fl')
+            [CompletionResult]::new('-f', '-f', [CompletionResultType]::ParameterName, '-f')
+            [CompletionResult]::new('--another-flag', '--another-flag', [CompletionResultType]::ParameterName, 'another usage text')
+            [CompletionResult]::new('-b', '-b', [CompletionResultType]::ParameterName, 'another usage text')
+            [CompletionResult]::new('--help', '--help', [CompletionResultType]::ParameterName, 'show help')
+            [CompletionResult]::new('-h', '-h', [CompletionResultType]::ParameterName, 'show help')
+        )
+        'greet;config;sub-config' = @(
+            [CompletionResult]::new('--sub-flag', '--sub-flag', [CompletionResultType]::ParameterName, '--sub-flag')
+            [CompletionResult]::new('--sub-fl', '--sub-fl', [CompletionResultType]::ParameterName, '--sub-fl')
+            [CompletionResult]::new('-s', '-s', [CompletionResultType]::ParameterName, '-s')
+            [CompletionResult]::new('--sub-command-flag', '--sub-command-flag', [CompletionResultType]::ParameterName, 'some usage text')
+            [CompletionResult]::new('-s', '-s', [CompletionResultType]::ParameterName, 'some usage text')
+            [CompletionResult]::new('--help', '--help', [CompletionResultType]::ParameterName, 'show help')
+            [CompletionResult]::new('-h', '-h', [CompletionResultType]::ParameterName, 'show help')
+        )
+        'greet;info' = @(
+            [CompletionResult]::new('--help', '--help', [CompletionResultType]::ParameterName, 'show help')
+            [CompletionResult]::new('-h', '-h', [CompletionResultType]::ParameterName, 'show help')
+        )
+        'greet;some-command' = @(
+            [CompletionResult]::new('--help', '--help', [CompletionResultType]::ParameterName, 'show help')
+            [CompletionResult]::new('-h', '-h', [CompletionResultType]::ParameterName, 'show help')
+        )
+    }
+
+    $command = 'greet'
+    foreach ($element in $commandAst.CommandElements | Select-Object -Skip 1) {
+        if ($element.Extent.EndOffset -ge $cursorPosition) {
+            break
+        }
+        $next = "$command;$element"
+        if ($commands.ContainsKey($next)) {
+            $command = $commands[$next]
+        }
+    }
+
+    $completions[$command] | Where-Object { $_.CompletionText -like "$wordToComplete*" }
+}
+
+Register-ArgumentCompleter -Native -CommandName 'greet' -ScriptBlock $__greetCompleter
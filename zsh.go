@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// ToZshCompletion creates a zsh completion script for the `*App`, defining a
+// `_<name>` function that completes its commands and flags. Unless they take a
+// file or have Choices, the values of flags are completed by calling back into
+// the app with --generate-bash-completion when EnableBashCompletion is set.
+// The function errors if either parsing or writing of the string fails.
+func (a *App) ToZshCompletion() (string, error) {
+	var w bytes.Buffer
+	if err := a.writeZshCompletionTemplate(&w); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+type zshCompletionTemplate struct {
+	App       *App
+	Function  string
+	Functions []string
+}
+
+func (a *App) writeZshCompletionTemplate(w io.Writer) error {
+	const name = "cli"
+	t, err := template.New(name).Parse(ZshCompletionTemplate)
+	if err != nil {
+		return err
+	}
+	function := completionIdentifier(a.Name)
+
+	// Add global flags
+	flags := a.VisibleFlags()
+
+	// Add help flag
+	if !a.HideHelp {
+		flags = append(flags, HelpFlag)
+	}
+
+	// Add version flag
+	if !a.HideVersion {
+		flags = append(flags, VersionFlag)
+	}
+
+	return t.ExecuteTemplate(w, name, &zshCompletionTemplate{
+		App:       a,
+		Function:  function,
+		Functions: a.prepareZshFunctions(function, "_"+function, flags, a.VisibleCommands()),
+	})
+}
+
+// prepareZshFunctions returns the completion function named function for a
+// command with the given flags and subcommands, followed by the functions of
+// the visible subcommands
+func (a *App) prepareZshFunctions(root, function string, flags []Flag, subcommands []*Command) []string {
+	commands := []*Command{}
+	for _, command := range subcommands {
+		if !command.Hidden {
+			commands = append(commands, command)
+		}
+	}
+
+	specs := []string{}
+	for _, f := range flags {
+		if spec := a.zshFlagSpec(root, f); spec != "" {
+			specs = append(specs, spec)
+		}
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("%s() {\n", function))
+	if function == "_"+root {
+		// the line is kept for the subcommands, which only see their own words
+		body.WriteString(fmt.Sprintf("  local -a __%s_words\n", root))
+		body.WriteString(fmt.Sprintf("  __%s_words=(\"${(@)words[1,CURRENT-1]}\")\n\n", root))
+	}
+
+	if len(commands) == 0 {
+		specs = append(specs, "'*: :_files'")
+		body.WriteString("  _arguments \\\n    ")
+		body.WriteString(strings.Join(specs, " \\\n    "))
+		body.WriteString("\n}")
+		return []string{body.String()}
+	}
+
+	specs = append(specs, "'1: :->cmds'", "'*::arg:->args'")
+	body.WriteString("  local line state\n\n")
+	body.WriteString("  _arguments -C \\\n    ")
+	body.WriteString(strings.Join(specs, " \\\n    "))
+	body.WriteString("\n\n  case $state in\n    cmds)\n      local -a commands\n      commands=(\n")
+	for _, command := range commands {
+		for _, name := range command.Names() {
+			body.WriteString(fmt.Sprintf("        '%s'\n", zshQuote(
+				strings.Replace(name, ":", `\:`, -1)+":"+command.Usage)))
+		}
+	}
+	body.WriteString("      )\n      _describe 'command' commands\n      ;;\n")
+	body.WriteString("    args)\n      case $line[1] in\n")
+
+	functions := []string{}
+	for _, command := range commands {
+		subFunction := function + "_" + completionIdentifier(command.Name)
+		body.WriteString(fmt.Sprintf("        %s)\n          %s\n          ;;\n",
+			strings.Join(command.Names(), "|"), subFunction))
+
+		subFlags := command.VisibleFlags()
+		if !command.HideHelp {
+			subFlags = append(subFlags, HelpFlag)
+		}
+		functions = append(functions,
+			a.prepareZshFunctions(root, subFunction, subFlags, command.Subcommands)...)
+	}
+	body.WriteString("      esac\n      ;;\n  esac\n}")
+
+	return append([]string{body.String()}, functions...)
+}
+
+// zshFlagSpec returns the _arguments spec of the flag, describing its names,
+// usage and how to complete its value
+func (a *App) zshFlagSpec(root string, f Flag) string {
+	flag, ok := f.(DocGenerationFlag)
+	if !ok {
+		return ""
+	}
+
+	opts := []string{}
+	for _, name := range flag.Names() {
+		if name = strings.TrimSpace(name); name != "" {
+			opts = append(opts, prefixFor(name)+name)
+		}
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+
+	var spec strings.Builder
+	if _, usage := unquoteUsage(expandUsage(flag, flag.GetUsage())); usage != "" {
+		spec.WriteString("[" + zshEscape(usage, "[]") + "]")
+	}
+	if flag.TakesValue() {
+		spec.WriteString(":" + zshEscape(flagPlaceholder(f), ":") + ":")
+		spec.WriteString(a.zshValueAction(root, f))
+	}
+
+	if len(opts) == 1 {
+		return "'" + opts[0] + zshQuote(spec.String()) + "'"
+	}
+	return fmt.Sprintf("'(%s)'{%s}'%s'",
+		strings.Join(opts, " "), strings.Join(opts, ","), zshQuote(spec.String()))
+}
+
+// zshValueAction returns the _arguments action completing the value of f
+func (a *App) zshValueAction(root string, f Flag) string {
+	if sf, ok := f.(*StringFlag); ok && len(sf.Choices) > 0 {
+		choices := make([]string, 0, len(sf.Choices))
+		for _, choice := range sf.Choices {
+			choices = append(choices, zshEscape(choice, " ()"))
+		}
+		return "(" + strings.Join(choices, " ") + ")"
+	}
+
+	if _, ok := f.(*FileContentsFlag); ok {
+		return "_files"
+	}
+	if takesFile := flagValue(f).FieldByName("TakesFile"); takesFile.IsValid() &&
+		takesFile.Kind() == reflect.Bool && takesFile.Bool() {
+		return "_files"
+	}
+
+	if a.EnableBashCompletion {
+		return fmt.Sprintf("__%s_complete", root)
+	}
+	return ""
+}
+
+// completionIdentifier replaces the characters of name which are not allowed in
+// the functions and variables of completion scripts
+func completionIdentifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// zshEscape escapes backslashes and the given special characters of s
+func zshEscape(s, special string) string {
+	var escaped strings.Builder
+	for _, r := range s {
+		if r == '\\' || strings.ContainsRune(special, r) {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+// zshQuote escapes the single quotes of s for use within single quotes
+func zshQuote(s string) string {
+	return strings.Replace(s, `'`, `'\''`, -1)
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestZshCompletion(t *testing.T) {
+	// Given
+	app := testApp()
+	app.EnableBashCompletion = true
+
+	// When
+	res, err := app.ToZshCompletion()
+
+	// Then
+	expect(t, err, nil)
+	expectFileContent(t, "testdata/expected-zsh-full.zsh", res)
+}
+
+func TestZshFlagSpec(t *testing.T) {
+	app := &App{}
+	cases := []struct {
+		flag     Flag
+		expected string
+	}{
+		{
+			flag:     &BoolFlag{Name: "verbose", Usage: "be [very] verbose"},
+			expected: `'--verbose[be \[very\] verbose]'`,
+		},
+		{
+			flag:     &StringFlag{Name: "format", Aliases: []string{"f"}, Usage: "the `FMT` to use", Choices: []string{"json", "yaml"}},
+			expected: `'(--format -f)'{--format,-f}'[the FMT to use]:FMT:(json yaml)'`,
+		},
+		{
+			flag:     &FileContentsFlag{Name: "config", Usage: "don't read it"},
+			expected: `'--config[don'\''t read it]:value:_files'`,
+		},
+		{
+			flag:     &IntFlag{Name: "count"},
+			expected: `'--count:value:'`,
+		},
+	}
+
+	for _, c := range cases {
+		expect(t, app.zshFlagSpec("app", c.flag), c.expected)
+	}
+
+	app.EnableBashCompletion = true
+	expect(t, app.zshFlagSpec("app", &IntFlag{Name: "count"}), `'--count:value:__app_complete'`)
+}